@@ -0,0 +1,166 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"github.com/user/invoicer/export"
+	"github.com/user/invoicer/models"
+)
+
+// ClientInput is the body of client create and update requests.
+type ClientInput struct {
+	Name              string          `json:"name"`
+	Address           string          `json:"address"`
+	Emails            []string        `json:"emails"`
+	DefaultHourlyRate decimal.Decimal `json:"default_hourly_rate"`
+	DefaultCurrency   string          `json:"default_currency"`
+	VATID             string          `json:"vat_id"`
+	Street            string          `json:"street"`
+	City              string          `json:"city"`
+	PostalCode        string          `json:"postal_code"`
+	Country           string          `json:"country"`
+	PeppolID          string          `json:"peppol_id"`
+	BuyerReference    string          `json:"buyer_reference"`
+	InvoiceTemplate   string          `json:"invoice_template"`
+}
+
+// apply validates in and copies it onto client.
+func (in ClientInput) apply(client *models.Client) error {
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return errorf(http.StatusUnprocessableEntity, "name is required")
+	}
+	if in.DefaultHourlyRate.IsNegative() {
+		return errorf(http.StatusUnprocessableEntity, "default_hourly_rate cannot be negative")
+	}
+	currency, err := models.ParseCurrency(in.DefaultCurrency)
+	if err != nil {
+		return errorf(http.StatusUnprocessableEntity, "%v", err)
+	}
+	invoiceTemplate, err := export.ParseInvoiceTemplateName(in.InvoiceTemplate)
+	if err != nil {
+		return errorf(http.StatusUnprocessableEntity, "%v", err)
+	}
+	emails := []string{}
+	for _, email := range in.Emails {
+		if email = strings.TrimSpace(email); email != "" {
+			emails = append(emails, email)
+		}
+	}
+
+	client.Update(name, strings.TrimSpace(in.Address), emails, in.DefaultHourlyRate)
+	client.DefaultCurrency = currency
+	client.VATID = strings.TrimSpace(in.VATID)
+	client.Street = strings.TrimSpace(in.Street)
+	client.City = strings.TrimSpace(in.City)
+	client.PostalCode = strings.TrimSpace(in.PostalCode)
+	client.Country = strings.ToUpper(strings.TrimSpace(in.Country))
+	client.PeppolID = strings.TrimSpace(in.PeppolID)
+	client.BuyerReference = strings.TrimSpace(in.BuyerReference)
+	client.InvoiceTemplate = invoiceTemplate
+	return nil
+}
+
+func (s *Server) listClients(w http.ResponseWriter, r *http.Request) error {
+	clients, err := s.storage.GetAllClients()
+	if err != nil {
+		return fmt.Errorf("failed to load clients: %w", err)
+	}
+	name := strings.ToLower(r.URL.Query().Get("name"))
+	matched := []models.Client{}
+	for _, client := range clients {
+		if strings.Contains(strings.ToLower(client.Name), name) {
+			matched = append(matched, client)
+		}
+	}
+	return writeJSON(w, http.StatusOK, matched)
+}
+
+func (s *Server) createClient(w http.ResponseWriter, r *http.Request) error {
+	var in ClientInput
+	if err := decode(w, r, &in); err != nil {
+		return err
+	}
+	client := models.NewClient("", "", nil, decimal.Zero)
+	if err := in.apply(client); err != nil {
+		return err
+	}
+	if err := s.checkClientName(client); err != nil {
+		return err
+	}
+	if err := s.storage.SaveClient(client); err != nil {
+		return fmt.Errorf("failed to save client: %w", err)
+	}
+	w.Header().Set("Location", Prefix+"/clients/"+client.ID)
+	return writeClient(w, http.StatusCreated, client)
+}
+
+func (s *Server) getClient(w http.ResponseWriter, r *http.Request) error {
+	client, err := s.findClient(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	return writeClient(w, http.StatusOK, client)
+}
+
+// updateClient replaces the client's details. Invoices already issued keep
+// the name they were issued under.
+func (s *Server) updateClient(w http.ResponseWriter, r *http.Request) error {
+	client, err := s.findClient(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	if err := checkIfMatch(r, client.Version); err != nil {
+		return err
+	}
+	var in ClientInput
+	if err := decode(w, r, &in); err != nil {
+		return err
+	}
+	if err := in.apply(client); err != nil {
+		return err
+	}
+	if err := s.checkClientName(client); err != nil {
+		return err
+	}
+	if err := s.storage.UpdateClient(client); err != nil {
+		return fmt.Errorf("failed to save client: %w", err)
+	}
+	return writeClient(w, http.StatusOK, client)
+}
+
+func writeClient(w http.ResponseWriter, status int, client *models.Client) error {
+	w.Header().Set("ETag", etag(client.Version))
+	return writeJSON(w, status, client)
+}
+
+func (s *Server) findClient(id string) (*models.Client, error) {
+	clients, err := s.storage.GetAllClients()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load clients: %w", err)
+	}
+	for _, client := range clients {
+		if client.ID == id {
+			return &client, nil
+		}
+	}
+	return nil, errorf(http.StatusNotFound, "client %q not found", id)
+}
+
+// checkClientName rejects a name another client already has, as the CLI
+// looks clients up by name.
+func (s *Server) checkClientName(client *models.Client) error {
+	clients, err := s.storage.GetAllClients()
+	if err != nil {
+		return fmt.Errorf("failed to load clients: %w", err)
+	}
+	for _, other := range clients {
+		if other.ID != client.ID && strings.EqualFold(other.Name, client.Name) {
+			return errorf(http.StatusConflict, "a client named %q already exists", client.Name)
+		}
+	}
+	return nil
+}
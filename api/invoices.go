@@ -0,0 +1,468 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/user/invoicer/audit"
+	"github.com/user/invoicer/export"
+	"github.com/user/invoicer/models"
+)
+
+// LineItemInput is the body of line item create and update requests, and
+// each line of a new invoice. Catalogue taxes on a line are kept when it is
+// updated.
+type LineItemInput struct {
+	Description   string          `json:"description"`
+	Quantity      decimal.Decimal `json:"quantity"`
+	UnitPrice     decimal.Decimal `json:"unit_price"`
+	DiscountRate  decimal.Decimal `json:"discount_rate"`
+	FixedDiscount decimal.Decimal `json:"fixed_discount"`
+}
+
+func (in LineItemInput) apply(item *models.LineItem) error {
+	description := strings.TrimSpace(in.Description)
+	switch {
+	case description == "":
+		return errorf(http.StatusUnprocessableEntity, "line item description is required")
+	case !in.Quantity.IsPositive():
+		return errorf(http.StatusUnprocessableEntity, "line item quantity must be positive")
+	case in.UnitPrice.IsNegative():
+		return errorf(http.StatusUnprocessableEntity, "line item unit_price cannot be negative")
+	case in.DiscountRate.IsNegative() || in.DiscountRate.GreaterThan(decimal.NewFromInt(100)):
+		return errorf(http.StatusUnprocessableEntity, "line item discount_rate must be between 0 and 100")
+	case in.FixedDiscount.IsNegative():
+		return errorf(http.StatusUnprocessableEntity, "line item fixed_discount cannot be negative")
+	}
+	item.Description = description
+	item.Quantity = in.Quantity
+	item.UnitPrice = in.UnitPrice
+	item.SetDiscount(in.DiscountRate, in.FixedDiscount)
+	return nil
+}
+
+// InvoiceChanges are the invoice fields that can be changed after creation.
+// Fields left out are not changed. Dates are YYYY-MM-DD.
+type InvoiceChanges struct {
+	DueDate          *string          `json:"due_date,omitempty"`
+	ServiceStartDate *string          `json:"service_start_date,omitempty"`
+	ServiceEndDate   *string          `json:"service_end_date,omitempty"`
+	DiscountRate     *decimal.Decimal `json:"discount_rate,omitempty"`
+	FixedDiscount    *decimal.Decimal `json:"fixed_discount,omitempty"`
+	TaxRate          *decimal.Decimal `json:"tax_rate,omitempty"`
+}
+
+func (c InvoiceChanges) apply(invoice *models.Invoice) error {
+	dates := []struct {
+		name  string
+		value *string
+		dst   **time.Time
+	}{
+		{"service_start_date", c.ServiceStartDate, &invoice.ServiceStartDate},
+		{"service_end_date", c.ServiceEndDate, &invoice.ServiceEndDate},
+	}
+	if c.DueDate != nil {
+		due, err := parseDate("due_date", *c.DueDate)
+		if err != nil {
+			return err
+		}
+		invoice.DueDate = due
+	}
+	for _, d := range dates {
+		if d.value != nil {
+			t, err := parseDate(d.name, *d.value)
+			if err != nil {
+				return err
+			}
+			*d.dst = &t
+		}
+	}
+	if invoice.ServiceStartDate != nil && invoice.ServiceEndDate != nil && invoice.ServiceStartDate.After(*invoice.ServiceEndDate) {
+		return errorf(http.StatusUnprocessableEntity, "the service period ends before it starts")
+	}
+
+	rates := []struct {
+		name  string
+		value *decimal.Decimal
+		dst   *decimal.Decimal
+	}{
+		{"discount_rate", c.DiscountRate, &invoice.DiscountRate},
+		{"fixed_discount", c.FixedDiscount, &invoice.FixedDiscount},
+		{"tax_rate", c.TaxRate, &invoice.TaxRate},
+	}
+	for _, rate := range rates {
+		if rate.value == nil {
+			continue
+		}
+		if rate.value.IsNegative() {
+			return errorf(http.StatusUnprocessableEntity, "%s cannot be negative", rate.name)
+		}
+		*rate.dst = *rate.value
+	}
+	if invoice.DiscountRate.GreaterThan(decimal.NewFromInt(100)) {
+		return errorf(http.StatusUnprocessableEntity, "discount_rate cannot be more than 100")
+	}
+	invoice.CalculateTotals()
+	return nil
+}
+
+// InvoiceInput is the body of invoice create requests. The currency
+// defaults to the client's.
+type InvoiceInput struct {
+	ClientID  string          `json:"client_id"`
+	Currency  string          `json:"currency"`
+	LineItems []LineItemInput `json:"line_items"`
+	InvoiceChanges
+}
+
+// StatusInput is the body of status change requests.
+type StatusInput struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+func parseDate(name, value string) (time.Time, error) {
+	t, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(value), time.Local)
+	if err != nil {
+		return time.Time{}, errorf(http.StatusUnprocessableEntity, "invalid %s %q, use YYYY-MM-DD", name, value)
+	}
+	return t, nil
+}
+
+func (s *Server) listInvoices(w http.ResponseWriter, r *http.Request) error {
+	query := r.URL.Query()
+	var status models.InvoiceStatus
+	if name := query.Get("status"); name != "" {
+		var err error
+		if status, err = models.ParseInvoiceStatus(name); err != nil {
+			return errorf(http.StatusBadRequest, "%v", err)
+		}
+	}
+	var from, to time.Time
+	if value := query.Get("from"); value != "" {
+		var err error
+		if from, err = parseDate("from", value); err != nil {
+			return errorf(http.StatusBadRequest, "%v", err)
+		}
+	}
+	if value := query.Get("to"); value != "" {
+		var err error
+		if to, err = parseDate("to", value); err != nil {
+			return errorf(http.StatusBadRequest, "%v", err)
+		}
+	}
+	clientID := query.Get("client_id")
+
+	invoices, err := s.storage.GetAllInvoices()
+	if err != nil {
+		return fmt.Errorf("failed to load invoices: %w", err)
+	}
+	matched := []models.Invoice{}
+	for _, invoice := range invoices {
+		switch {
+		case status != "" && invoice.Status != status,
+			clientID != "" && invoice.ClientID != clientID,
+			!from.IsZero() && invoice.Date.Before(from),
+			!to.IsZero() && !invoice.Date.Before(to.AddDate(0, 0, 1)):
+			continue
+		}
+		matched = append(matched, invoice)
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		if !matched[i].Date.Equal(matched[j].Date) {
+			return matched[i].Date.Before(matched[j].Date)
+		}
+		return matched[i].Number < matched[j].Number
+	})
+	return writeJSON(w, http.StatusOK, matched)
+}
+
+func (s *Server) createInvoice(w http.ResponseWriter, r *http.Request) error {
+	var in InvoiceInput
+	if err := decode(w, r, &in); err != nil {
+		return err
+	}
+	if in.ClientID == "" {
+		return errorf(http.StatusUnprocessableEntity, "client_id is required")
+	}
+	client, err := s.findClient(in.ClientID)
+	var se *statusError
+	if errors.As(err, &se) && se.status == http.StatusNotFound {
+		// The request is at fault, not its URL
+		se.status = http.StatusUnprocessableEntity
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	invoice := models.NewInvoice(client.ID, client.Name, "")
+	invoice.Currency = client.DefaultCurrency.Code()
+	if in.Currency != "" {
+		if invoice.Currency, err = models.ParseCurrency(in.Currency); err != nil {
+			return errorf(http.StatusUnprocessableEntity, "%v", err)
+		}
+	}
+	for _, line := range in.LineItems {
+		item := models.NewLineItem("", decimal.Zero, decimal.Zero)
+		if err := line.apply(item); err != nil {
+			return err
+		}
+		invoice.AddLineItem(*item)
+	}
+	if err := in.InvoiceChanges.apply(invoice); err != nil {
+		return err
+	}
+
+	// The number is taken last so that invalid requests do not use one up
+	seq, err := s.storage.GetNextInvoiceNumber(now.Year())
+	if err != nil {
+		return fmt.Errorf("failed to get next invoice number: %w", err)
+	}
+	invoice.Number = models.GenerateInvoiceNumber(now.Year(), seq)
+	if err := s.storage.SaveInvoice(invoice); err != nil {
+		return fmt.Errorf("failed to save invoice: %w", err)
+	}
+	w.Header().Set("Location", Prefix+"/invoices/"+invoice.ID)
+	return writeInvoice(w, http.StatusCreated, invoice)
+}
+
+func (s *Server) getInvoice(w http.ResponseWriter, r *http.Request) error {
+	invoice, err := s.findInvoice(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	return writeInvoice(w, http.StatusOK, invoice)
+}
+
+func (s *Server) updateInvoice(w http.ResponseWriter, r *http.Request) error {
+	return s.modifyInvoice(w, r, http.StatusOK, func(invoice *models.Invoice) error {
+		var changes InvoiceChanges
+		if err := decode(w, r, &changes); err != nil {
+			return err
+		}
+		return changes.apply(invoice)
+	})
+}
+
+func (s *Server) listLineItems(w http.ResponseWriter, r *http.Request) error {
+	invoice, err := s.findInvoice(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	w.Header().Set("ETag", etag(invoice.Version))
+	return writeJSON(w, http.StatusOK, invoice.LineItems)
+}
+
+// addLineItem, updateLineItem and deleteLineItem respond with the whole
+// invoice, as its totals and ETag change with its lines.
+func (s *Server) addLineItem(w http.ResponseWriter, r *http.Request) error {
+	return s.modifyInvoice(w, r, http.StatusCreated, func(invoice *models.Invoice) error {
+		var in LineItemInput
+		if err := decode(w, r, &in); err != nil {
+			return err
+		}
+		item := models.NewLineItem("", decimal.Zero, decimal.Zero)
+		if err := in.apply(item); err != nil {
+			return err
+		}
+		invoice.AddLineItem(*item)
+		w.Header().Set("Location", Prefix+"/invoices/"+invoice.ID+"/line-items/"+item.ID)
+		return nil
+	})
+}
+
+func (s *Server) updateLineItem(w http.ResponseWriter, r *http.Request) error {
+	return s.modifyInvoice(w, r, http.StatusOK, func(invoice *models.Invoice) error {
+		item, err := findLineItem(invoice, r.PathValue("item"))
+		if err != nil {
+			return err
+		}
+		var in LineItemInput
+		if err := decode(w, r, &in); err != nil {
+			return err
+		}
+		if err := in.apply(item); err != nil {
+			return err
+		}
+		invoice.CalculateTotals()
+		return nil
+	})
+}
+
+func (s *Server) deleteLineItem(w http.ResponseWriter, r *http.Request) error {
+	return s.modifyInvoice(w, r, http.StatusOK, func(invoice *models.Invoice) error {
+		item, err := findLineItem(invoice, r.PathValue("item"))
+		if err != nil {
+			return err
+		}
+		invoice.RemoveLineItem(item.ID)
+		return nil
+	})
+}
+
+// modifyInvoice applies change to the invoice in the request path if the
+// request's If-Match names its current version, then saves it and responds
+// with it. Only drafts can be changed; issued invoices are corrected with
+// credit notes.
+func (s *Server) modifyInvoice(w http.ResponseWriter, r *http.Request, status int, change func(*models.Invoice) error) error {
+	invoice, err := s.findInvoice(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	if err := checkIfMatch(r, invoice.Version); err != nil {
+		return err
+	}
+	if invoice.Status != models.StatusDraft {
+		return errorf(http.StatusConflict, "invoice %s is %s, and only drafts can be edited", invoice.Number, invoice.Status)
+	}
+	if err := change(invoice); err != nil {
+		return err
+	}
+	if err := s.storage.UpdateInvoice(invoice); err != nil {
+		return fmt.Errorf("failed to save invoice: %w", err)
+	}
+	return writeInvoice(w, status, invoice)
+}
+
+func (s *Server) changeStatus(w http.ResponseWriter, r *http.Request) error {
+	invoice, err := s.findInvoice(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	if err := checkIfMatch(r, invoice.Version); err != nil {
+		return err
+	}
+	var in StatusInput
+	if err := decode(w, r, &in); err != nil {
+		return err
+	}
+	status, err := models.ParseInvoiceStatus(in.Status)
+	if err != nil {
+		return errorf(http.StatusUnprocessableEntity, "%v", err)
+	}
+
+	if err := audit.NewService(s.storage).ChangeStatus(invoice, status, strings.TrimSpace(in.Reason), ChangedBy); err != nil {
+		if invoice.Status != status {
+			return err
+		}
+		// Saved, but the audit entry could not be written
+		log.Printf("api: invoice %s: %v", invoice.Number, err)
+	}
+	return writeInvoice(w, http.StatusOK, invoice)
+}
+
+func (s *Server) listAuditEntries(w http.ResponseWriter, r *http.Request) error {
+	invoice, err := s.findInvoice(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	entries, err := s.storage.GetAuditEntries(invoice.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load audit entries: %w", err)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].ChangedAt.Before(entries[j].ChangedAt)
+	})
+	return writeJSON(w, http.StatusOK, entries)
+}
+
+// exportPDF renders the invoice, appending the receipts of its expenses if
+// receipts=true, and sends the PDF. renderer overrides the configured
+// renderer and template the client's or configured invoice template.
+func (s *Server) exportPDF(w http.ResponseWriter, r *http.Request) error {
+	invoice, err := s.findInvoice(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	withReceipts := false
+	if value := r.URL.Query().Get("receipts"); value != "" {
+		if withReceipts, err = strconv.ParseBool(value); err != nil {
+			return errorf(http.StatusBadRequest, "invalid receipts %q, use true or false", value)
+		}
+	}
+	renderer, err := export.ParseRenderer(r.URL.Query().Get("renderer"))
+	if err != nil {
+		return errorf(http.StatusBadRequest, "%v", err)
+	}
+	chosen, err := export.ParseInvoiceTemplateName(r.URL.Query().Get("template"))
+	if err != nil {
+		return errorf(http.StatusBadRequest, "%v", err)
+	}
+	client, err := s.storage.GetClient(invoice.ClientID)
+	if err != nil {
+		return fmt.Errorf("failed to load client: %w", err)
+	}
+	templatePath, err := export.InvoiceTemplatePath(s.config, export.InvoiceTemplateName(s.config, client, chosen))
+	if err != nil {
+		return errorf(http.StatusBadRequest, "%v", err)
+	}
+	var receipts []export.Receipt
+	if withReceipts {
+		if receipts, err = export.InvoiceReceipts(s.storage, s.config, invoice.ID); err != nil {
+			return fmt.Errorf("failed to load receipts: %w", err)
+		}
+	}
+	timesheet, err := export.InvoiceTimeEntries(s.storage, invoice.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load time entries: %w", err)
+	}
+
+	dir, err := os.MkdirTemp("", "invoicer-api-")
+	if err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	if err := export.ExportInvoiceToPDF(invoice, client, s.config, dir, templatePath, receipts, timesheet, renderer); err != nil {
+		return err
+	}
+
+	pdfPath := export.GetExportPath(invoice, dir)
+	f, err := os.Open(pdfPath)
+	if err != nil {
+		return fmt.Errorf("failed to open PDF: %w", err)
+	}
+	defer f.Close()
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filepath.Base(pdfPath)))
+	// No validators: the PDF also depends on the template and settings
+	http.ServeContent(w, r, filepath.Base(pdfPath), time.Time{}, f)
+	return nil
+}
+
+func writeInvoice(w http.ResponseWriter, status int, invoice *models.Invoice) error {
+	w.Header().Set("ETag", etag(invoice.Version))
+	return writeJSON(w, status, invoice)
+}
+
+// findInvoice looks an invoice up by ID or number.
+func (s *Server) findInvoice(ref string) (*models.Invoice, error) {
+	invoices, err := s.storage.GetAllInvoices()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load invoices: %w", err)
+	}
+	for _, invoice := range invoices {
+		if invoice.ID == ref || invoice.Number == ref {
+			return &invoice, nil
+		}
+	}
+	return nil, errorf(http.StatusNotFound, "invoice %q not found", ref)
+}
+
+func findLineItem(invoice *models.Invoice, id string) (*models.LineItem, error) {
+	for i := range invoice.LineItems {
+		if invoice.LineItems[i].ID == id {
+			return &invoice.LineItems[i], nil
+		}
+	}
+	return nil, errorf(http.StatusNotFound, "line item %q not found on invoice %s", id, invoice.Number)
+}
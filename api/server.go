@@ -0,0 +1,178 @@
+// Package api serves invoicer's data as a versioned JSON HTTP API, for
+// dashboards and other programs that should not shell out to the CLI.
+package api
+
+import (
+	"crypto/subtle"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/models"
+)
+
+// Prefix is the path every version 1 endpoint lives under.
+const Prefix = "/api/v1"
+
+// ChangedBy identifies status changes made through the API in the audit log.
+const ChangedBy = "api"
+
+// maxBodySize caps request bodies; the largest are invoices with many lines.
+const maxBodySize = 1 << 20
+
+//go:embed openapi.json
+var openAPI []byte
+
+// Server is an http.Handler for the API. Every request except the OpenAPI
+// document must carry the configured token as "Authorization: Bearer".
+type Server struct {
+	storage models.Storage
+	config  *config.Config
+	mux     *http.ServeMux
+}
+
+// NewServer returns a server for storage. It fails if no API token is
+// configured, so that the data is never served without authentication.
+func NewServer(storage models.Storage, cfg *config.Config) (*Server, error) {
+	if strings.TrimSpace(cfg.APIToken) == "" {
+		return nil, errors.New("no API token configured; set \"api_token\" in config.json")
+	}
+
+	s := &Server{storage: storage, config: cfg, mux: http.NewServeMux()}
+	s.route("GET /clients", s.listClients)
+	s.route("POST /clients", s.createClient)
+	s.route("GET /clients/{id}", s.getClient)
+	s.route("PUT /clients/{id}", s.updateClient)
+	s.route("GET /invoices", s.listInvoices)
+	s.route("POST /invoices", s.createInvoice)
+	s.route("GET /invoices/{id}", s.getInvoice)
+	s.route("PATCH /invoices/{id}", s.updateInvoice)
+	s.route("GET /invoices/{id}/line-items", s.listLineItems)
+	s.route("POST /invoices/{id}/line-items", s.addLineItem)
+	s.route("PUT /invoices/{id}/line-items/{item}", s.updateLineItem)
+	s.route("DELETE /invoices/{id}/line-items/{item}", s.deleteLineItem)
+	s.route("POST /invoices/{id}/status", s.changeStatus)
+	s.route("GET /invoices/{id}/audit", s.listAuditEntries)
+	s.route("GET /invoices/{id}/pdf", s.exportPDF)
+	s.mux.HandleFunc("GET "+Prefix+"/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openAPI)
+	})
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != Prefix+"/openapi.json" && !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="invoicer"`)
+		writeError(w, errorf(http.StatusUnauthorized, "missing or invalid API token"))
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(s.config.APIToken)) == 1
+}
+
+// handlerFunc is an API handler. Returned errors are written as JSON error
+// responses by route.
+type handlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+func (s *Server) route(pattern string, h handlerFunc) {
+	method, path, _ := strings.Cut(pattern, " ")
+	s.mux.HandleFunc(method+" "+Prefix+path, func(w http.ResponseWriter, r *http.Request) {
+		if err := h(w, r); err != nil {
+			writeError(w, err)
+		}
+	})
+}
+
+// Error is the body of every error response.
+type Error struct {
+	Error string `json:"error"`
+}
+
+// statusError is an error with the HTTP status it is reported with.
+type statusError struct {
+	status int
+	err    error
+}
+
+func (e *statusError) Error() string {
+	return e.err.Error()
+}
+
+func (e *statusError) Unwrap() error {
+	return e.err
+}
+
+func errorf(status int, format string, args ...any) error {
+	return &statusError{status: status, err: fmt.Errorf(format, args...)}
+}
+
+// writeError reports err with its status. Conflicting saves are reported as
+// a failed precondition, as the client's ETag was current when checked but
+// no longer is; rejected status changes as unprocessable.
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var se *statusError
+	var te *models.TransitionError
+	switch {
+	case errors.As(err, &se):
+		status = se.status
+	case errors.Is(err, models.ErrConflict):
+		status = http.StatusPreconditionFailed
+	case errors.As(err, &te):
+		status = http.StatusUnprocessableEntity
+	}
+	writeJSON(w, status, Error{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// decode reads a JSON request body into v, rejecting unknown fields so that
+// misspelt ones are not silently ignored.
+func decode(w http.ResponseWriter, r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		if errors.Is(err, io.EOF) {
+			return errorf(http.StatusBadRequest, "request body is empty")
+		}
+		return errorf(http.StatusBadRequest, "invalid request body: %v", err)
+	}
+	return nil
+}
+
+// etag is the entity tag of a record at version.
+func etag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// checkIfMatch requires the request to name the version of the record it
+// was based on, so that concurrent edits are not lost.
+func checkIfMatch(r *http.Request, version int) error {
+	match := r.Header.Get("If-Match")
+	if match == "" {
+		return errorf(http.StatusPreconditionRequired, "send the record's ETag in an If-Match header")
+	}
+	for _, tag := range strings.Split(match, ",") {
+		if tag = strings.TrimSpace(tag); tag == "*" || tag == etag(version) {
+			return nil
+		}
+	}
+	return errorf(http.StatusPreconditionFailed, "the record has changed; fetch it again (current ETag %s)", etag(version))
+}
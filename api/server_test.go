@@ -0,0 +1,178 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/models"
+	"github.com/user/invoicer/storage"
+)
+
+const testToken = "secret"
+
+// newTestServer serves a fresh data directory holding one draft invoice.
+func newTestServer(t *testing.T) (*Server, *models.Invoice) {
+	t.Helper()
+	store, err := storage.NewJSONStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := models.NewClient("Acme", "1 Main St", nil, decimal.NewFromInt(100))
+	if err := store.SaveClient(client); err != nil {
+		t.Fatal(err)
+	}
+	invoice := models.NewInvoice(client.ID, client.Name, "2026-01")
+	invoice.AddLineItem(*models.NewLineItem("Consulting", decimal.NewFromInt(2), decimal.NewFromInt(100)))
+	if err := store.SaveInvoice(invoice); err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(store, &config.Config{APIToken: testToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server, invoice
+}
+
+func request(t *testing.T, h http.Handler, method, path, ifMatch, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest(method, Prefix+path, strings.NewReader(body))
+	r.Header.Set("Authorization", "Bearer "+testToken)
+	if ifMatch != "" {
+		r.Header.Set("If-Match", ifMatch)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestNewServerNeedsToken(t *testing.T) {
+	if _, err := NewServer(nil, &config.Config{APIToken: " "}); err == nil {
+		t.Error("NewServer() without a token succeeded")
+	}
+}
+
+func TestAuthorization(t *testing.T) {
+	server, _ := newTestServer(t)
+	for _, header := range []string{"", "Bearer wrong", "secret"} {
+		r := httptest.NewRequest(http.MethodGet, Prefix+"/invoices", nil)
+		if header != "" {
+			r.Header.Set("Authorization", header)
+		}
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, r)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: status %d, want 401", header, w.Code)
+		}
+	}
+	// The OpenAPI document is public
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, Prefix+"/openapi.json", nil))
+	if w.Code != http.StatusOK || !json.Valid(w.Body.Bytes()) {
+		t.Errorf("openapi.json: status %d", w.Code)
+	}
+}
+
+func TestUpdateInvoiceIfMatch(t *testing.T) {
+	server, invoice := newTestServer(t)
+
+	w := request(t, server, http.MethodGet, "/invoices/"+invoice.Number, "", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET: status %d: %s", w.Code, w.Body)
+	}
+	tag := w.Header().Get("ETag")
+	if tag != etag(invoice.Version) {
+		t.Fatalf("ETag = %s, want %s", tag, etag(invoice.Version))
+	}
+
+	body := `{"due_date": "2026-02-15"}`
+	if w := request(t, server, http.MethodPatch, "/invoices/"+invoice.ID, "", body); w.Code != http.StatusPreconditionRequired {
+		t.Errorf("PATCH without If-Match: status %d, want 428", w.Code)
+	}
+	w = request(t, server, http.MethodPatch, "/invoices/"+invoice.ID, tag, body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("PATCH: status %d: %s", w.Code, w.Body)
+	}
+	newTag := w.Header().Get("ETag")
+	if newTag == tag {
+		t.Error("the ETag did not change with the invoice")
+	}
+	var updated models.Invoice
+	if err := json.Unmarshal(w.Body.Bytes(), &updated); err != nil {
+		t.Fatal(err)
+	}
+	if got := updated.DueDate.Format("2006-01-02"); got != "2026-02-15" {
+		t.Errorf("due date = %s, want 2026-02-15", got)
+	}
+
+	// Based on the version before the first update
+	w = request(t, server, http.MethodPatch, "/invoices/"+invoice.ID, tag, `{"due_date": "2026-03-01"}`)
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("PATCH with a stale ETag: status %d, want 412", w.Code)
+	}
+	if w := request(t, server, http.MethodPatch, "/invoices/"+invoice.ID, "*", `{"due_date": "2026-03-01"}`); w.Code != http.StatusOK {
+		t.Errorf("PATCH with If-Match *: status %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestIssuedInvoicesCannotBeEdited(t *testing.T) {
+	server, invoice := newTestServer(t)
+
+	w := request(t, server, http.MethodPost, "/invoices/"+invoice.ID+"/status", etag(invoice.Version), `{"status": "sent"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status change: status %d: %s", w.Code, w.Body)
+	}
+	tag := w.Header().Get("ETag")
+	item := invoice.LineItems[0].ID
+
+	tests := []struct {
+		method, path, body string
+	}{
+		{http.MethodPatch, "/invoices/" + invoice.ID, `{"due_date": "2026-03-01"}`},
+		{http.MethodPost, "/invoices/" + invoice.ID + "/line-items", `{"description": "Extra", "quantity": 1, "unit_price": 10}`},
+		{http.MethodPut, "/invoices/" + invoice.ID + "/line-items/" + item, `{"description": "Changed", "quantity": 1, "unit_price": 10}`},
+		{http.MethodDelete, "/invoices/" + invoice.ID + "/line-items/" + item, ""},
+	}
+	for _, tt := range tests {
+		if w := request(t, server, tt.method, tt.path, tag, tt.body); w.Code != http.StatusConflict {
+			t.Errorf("%s %s: status %d, want 409: %s", tt.method, tt.path, w.Code, w.Body)
+		}
+	}
+
+	// A status change the workflow does not allow
+	if w := request(t, server, http.MethodPost, "/invoices/"+invoice.ID+"/status", tag, `{"status": "draft"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("sent to draft without a reason: status %d, want 422: %s", w.Code, w.Body)
+	}
+}
+
+func TestLineItems(t *testing.T) {
+	server, invoice := newTestServer(t)
+
+	w := request(t, server, http.MethodPost, "/invoices/"+invoice.ID+"/line-items", etag(invoice.Version), `{"description": "Travel", "quantity": 1, "unit_price": "50.00"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST line item: status %d: %s", w.Code, w.Body)
+	}
+	var updated models.Invoice
+	if err := json.Unmarshal(w.Body.Bytes(), &updated); err != nil {
+		t.Fatal(err)
+	}
+	if len(updated.LineItems) != 2 || !updated.Subtotal.Equal(decimal.NewFromInt(250)) {
+		t.Errorf("got %d lines and subtotal %s, want 2 and 250", len(updated.LineItems), updated.Subtotal)
+	}
+	if !strings.HasPrefix(w.Header().Get("Location"), Prefix+"/invoices/"+invoice.ID+"/line-items/") {
+		t.Errorf("Location = %s", w.Header().Get("Location"))
+	}
+
+	w = request(t, server, http.MethodPost, "/invoices/"+invoice.ID+"/line-items", w.Header().Get("ETag"), `{"description": "Bad", "quantity": 0, "unit_price": 1}`)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("POST line item without a quantity: status %d, want 422", w.Code)
+	}
+	if w := request(t, server, http.MethodGet, "/invoices/missing", "", ""); w.Code != http.StatusNotFound {
+		t.Errorf("GET a missing invoice: status %d, want 404", w.Code)
+	}
+}
@@ -1,6 +1,8 @@
 package audit
 
 import (
+	"fmt"
+
 	"github.com/user/invoicer/models"
 )
 
@@ -22,4 +24,32 @@ func (s *Service) LogStatusChange(invoice *models.Invoice, oldStatus models.Invo
 	)
 	
 	return s.storage.SaveAuditEntry(entry)
-}
\ No newline at end of file
+}
+
+// LogCreditNote records that note was issued against invoice.
+func (s *Service) LogCreditNote(invoice *models.Invoice, note *models.CreditNote) error {
+	return s.storage.SaveAuditEntry(models.NewCreditNoteAuditEntry(invoice, note))
+}
+
+// ChangeStatus moves invoice to newStatus, saves it and records the change in
+// the audit log under changedBy. If the transition is rejected or the save
+// fails the invoice keeps its old status; an audit failure is reported after
+// the save succeeded.
+func (s *Service) ChangeStatus(invoice *models.Invoice, newStatus models.InvoiceStatus, reason, changedBy string) error {
+	oldStatus := invoice.Status
+	if err := invoice.UpdateStatus(newStatus, reason); err != nil {
+		return err
+	}
+	
+	if err := s.storage.UpdateInvoice(invoice); err != nil {
+		invoice.Status = oldStatus
+		return fmt.Errorf("failed to save invoice: %w", err)
+	}
+	
+	entry := models.NewAuditEntry(invoice.ID, invoice.Number, oldStatus, invoice.Status, reason)
+	entry.ChangedBy = changedBy
+	if err := s.storage.SaveAuditEntry(entry); err != nil {
+		return fmt.Errorf("status updated but audit log failed: %w", err)
+	}
+	return nil
+}
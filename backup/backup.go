@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/storage"
 )
 
 type Metadata struct {
@@ -63,7 +64,7 @@ func CreateBackup(outputPath string) error {
 		return fmt.Errorf("failed to add metadata: %w", err)
 	}
 
-	dataFiles := []string{"clients.json", "invoices.json", "audit.json"}
+	dataFiles := []string{"clients.json", "invoices.json", "audit.json", "payments.json", "recurring.json", "estimates.json", "credit_notes.json", "taxes.json", "catalog.json", "time_entries.json", "client_mappings.json", "expenses.json"}
 	for _, fileName := range dataFiles {
 		filePath := filepath.Join(cfg.DataDir(), fileName)
 		if _, err := os.Stat(filePath); err == nil {
@@ -73,6 +74,23 @@ func CreateBackup(outputPath string) error {
 		}
 	}
 
+	if _, err := os.Stat(cfg.DatabasePath()); err == nil {
+		if err := addDatabaseToTar(tarWriter, cfg.DatabasePath(), "data/invoicer.db"); err != nil {
+			return fmt.Errorf("failed to add invoicer.db: %w", err)
+		}
+	}
+
+	receipts, _ := os.ReadDir(cfg.ReceiptsDir())
+	for _, receipt := range receipts {
+		if !receipt.Type().IsRegular() {
+			continue
+		}
+		filePath := filepath.Join(cfg.ReceiptsDir(), receipt.Name())
+		if err := addFileToTar(tarWriter, filePath, filepath.Join("data", "receipts", receipt.Name())); err != nil {
+			return fmt.Errorf("failed to add receipt %s: %w", receipt.Name(), err)
+		}
+	}
+
 	configPath, err := config.ConfigPath()
 	if err == nil {
 		if _, err := os.Stat(configPath); err == nil {
@@ -82,10 +100,14 @@ func CreateBackup(outputPath string) error {
 		}
 	}
 
-	templatePath := filepath.Join(cfg.TemplatesDir(), "invoice.tex")
-	if _, err := os.Stat(templatePath); err == nil {
-		if err := addFileToTar(tarWriter, templatePath, "templates/invoice.tex"); err != nil {
-			return fmt.Errorf("failed to add template: %w", err)
+	// invoice.tex and the named invoice templates next to it
+	templatePaths, _ := filepath.Glob(filepath.Join(cfg.TemplatesDir(), "invoice-*.tex"))
+	templatePaths = append([]string{filepath.Join(cfg.TemplatesDir(), "invoice.tex")}, templatePaths...)
+	for _, templatePath := range templatePaths {
+		if _, err := os.Stat(templatePath); err == nil {
+			if err := addFileToTar(tarWriter, templatePath, "templates/"+filepath.Base(templatePath)); err != nil {
+				return fmt.Errorf("failed to add template: %w", err)
+			}
 		}
 	}
 
@@ -284,4 +306,20 @@ func addFileToTar(tw *tar.Writer, sourcePath, tarPath string) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// addDatabaseToTar adds a snapshot of the SQLite database, which may be in
+// use by another invoicer, rather than the file as it is.
+func addDatabaseToTar(tw *tar.Writer, dbPath, tarPath string) error {
+	dir, err := os.MkdirTemp("", "invoicer-backup")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	snapshot := filepath.Join(dir, "invoicer.db")
+	if err := storage.SnapshotSQLite(dbPath, snapshot); err != nil {
+		return err
+	}
+	return addFileToTar(tw, snapshot, tarPath)
+}
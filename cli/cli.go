@@ -0,0 +1,296 @@
+// Package cli implements invoicer's non-interactive subcommands, for driving
+// billing from shell scripts and cron.
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/models"
+)
+
+// Exit codes returned by Run.
+const (
+	ExitOK    = 0
+	ExitError = 1
+	// ExitUsage is returned for unknown commands and invalid arguments
+	ExitUsage = 2
+)
+
+// ChangedBy identifies status changes made from the command line in the
+// audit log.
+const ChangedBy = "cli"
+
+type action struct {
+	name    string
+	summary string
+	run     func(r *runner, args []string) error
+}
+
+// commands lists the subcommands and their actions, in the order the usage
+// shows them.
+var commands = []struct {
+	name    string
+	actions []action
+}{
+	{"client", []action{
+		{"list", "List clients", (*runner).clientList},
+		{"add", "Add a client", (*runner).clientAdd},
+		{"show", "Show a client and what it owes", (*runner).clientShow},
+	}},
+	{"invoice", []action{
+		{"list", "List invoices, optionally filtered", (*runner).invoiceList},
+		{"create", "Create an invoice", (*runner).invoiceCreate},
+		{"show", "Show an invoice", (*runner).invoiceShow},
+		{"status", "Change an invoice's status", (*runner).invoiceStatus},
+		{"export", "Export an invoice to PDF", (*runner).invoiceExport},
+	}},
+	{"templates", []action{
+		{"list", "List the invoice templates and where they come from", (*runner).templatesList},
+	}},
+}
+
+// servers are the commands that take no action and run until stopped.
+var servers = []action{
+	{"serve", "Serve the JSON API over HTTP", (*runner).serve},
+}
+
+// IsCommand reports whether name is a subcommand that works on the data, as
+// opposed to help or a mistyped command.
+func IsCommand(name string) bool {
+	for _, c := range commands {
+		if c.name == name {
+			return true
+		}
+	}
+	for _, s := range servers {
+		if s.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+type runner struct {
+	storage models.Storage
+	config  *config.Config
+	stdout  io.Writer
+	stderr  io.Writer
+}
+
+// usageError is returned for invalid arguments, after the usage has been
+// printed.
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string {
+	return e.err.Error()
+}
+
+// errHelp is returned when help was asked for and printed.
+var errHelp = errors.New("help requested")
+
+// Run runs the subcommand in args, e.g. ["invoice", "list", "--json"], and
+// returns the process exit code. storage and cfg may be nil if args is not
+// a command, in which case only the usage is shown.
+func Run(storage models.Storage, cfg *config.Config, args []string, stdout, stderr io.Writer) int {
+	r := &runner{storage: storage, config: cfg, stdout: stdout, stderr: stderr}
+
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		r.usage(stdout)
+		return ExitOK
+	}
+
+	for _, s := range servers {
+		if s.name == args[0] {
+			return r.exit(s.run(r, args[1:]))
+		}
+	}
+	for _, c := range commands {
+		if c.name != args[0] {
+			continue
+		}
+		if len(args) < 2 {
+			fmt.Fprintf(stderr, "invoicer %s: missing action\n\n", c.name)
+			r.usage(stderr)
+			return ExitUsage
+		}
+		for _, a := range c.actions {
+			if a.name == args[1] {
+				return r.exit(a.run(r, args[2:]))
+			}
+		}
+		fmt.Fprintf(stderr, "invoicer %s: unknown action %q\n\n", c.name, args[1])
+		r.usage(stderr)
+		return ExitUsage
+	}
+
+	fmt.Fprintf(stderr, "invoicer: unknown command %q\n\n", args[0])
+	r.usage(stderr)
+	return ExitUsage
+}
+
+func (r *runner) exit(err error) int {
+	var usage *usageError
+	switch {
+	case err == nil, errors.Is(err, errHelp):
+		return ExitOK
+	case errors.As(err, &usage):
+		return ExitUsage
+	}
+	fmt.Fprintf(r.stderr, "invoicer: %v\n", err)
+	return ExitError
+}
+
+func (r *runner) usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: invoicer [command] [action] [flags]")
+	fmt.Fprintln(w, "\nWithout a command, invoicer starts the interactive interface.")
+	fmt.Fprintln(w, "\nCommands:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, c := range commands {
+		for _, a := range c.actions {
+			fmt.Fprintf(tw, "  %s %s\t%s\n", c.name, a.name, a.summary)
+		}
+	}
+	for _, s := range servers {
+		fmt.Fprintf(tw, "  %s\t%s\n", s.name, s.summary)
+	}
+	tw.Flush()
+	fmt.Fprintln(w, "\nRun 'invoicer <command> <action> -h' for an action's flags. Exit status is 0 on")
+	fmt.Fprintln(w, "success, 1 if the action failed and 2 if it was called incorrectly.")
+}
+
+// newFlags returns a flag set for an action, printing usage (the arguments
+// after the action's name) before the flags on error.
+func (r *runner) newFlags(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(r.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(r.stderr, "Usage: invoicer %s %s\n", name, usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse parses args with fs, allowing flags to follow the positional
+// arguments, and checks that there are exactly want positional arguments.
+func (r *runner) parse(fs *flag.FlagSet, args []string, want int) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, errHelp
+			}
+			return nil, &usageError{err}
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	if len(positional) != want {
+		err := fmt.Errorf("expected %d argument(s), got %d", want, len(positional))
+		fmt.Fprintf(r.stderr, "%v\n", err)
+		fs.Usage()
+		return nil, &usageError{err}
+	}
+	return positional, nil
+}
+
+// usageErrorf reports an invalid flag value the way the flag package does.
+func (r *runner) usageErrorf(fs *flag.FlagSet, format string, args ...any) error {
+	err := fmt.Errorf(format, args...)
+	fmt.Fprintf(r.stderr, "%v\n", err)
+	fs.Usage()
+	return &usageError{err}
+}
+
+func (r *runner) printJSON(v any) error {
+	enc := json.NewEncoder(r.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (r *runner) table() *tabwriter.Writer {
+	return tabwriter.NewWriter(r.stdout, 0, 0, 2, ' ', 0)
+}
+
+// stringList is a flag that may be repeated.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// parseDate reads a YYYY-MM-DD date as local midnight.
+func parseDate(value string) (time.Time, error) {
+	t, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(value), time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, use YYYY-MM-DD", value)
+	}
+	return t, nil
+}
+
+// findClient looks a client up by ID or, ignoring case, by name.
+func (r *runner) findClient(ref string) (*models.Client, error) {
+	clients, err := r.storage.GetAllClients()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load clients: %w", err)
+	}
+	var matches []models.Client
+	for _, client := range clients {
+		if client.ID == ref {
+			return &client, nil
+		}
+		if strings.EqualFold(client.Name, strings.TrimSpace(ref)) {
+			matches = append(matches, client)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("client %q not found", ref)
+	case 1:
+		return &matches[0], nil
+	}
+	return nil, fmt.Errorf("%d clients are named %q; use the client's ID", len(matches), ref)
+}
+
+// findInvoice looks an invoice up by number or ID.
+func (r *runner) findInvoice(ref string) (*models.Invoice, error) {
+	invoices, err := r.storage.GetAllInvoices()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load invoices: %w", err)
+	}
+	for _, invoice := range invoices {
+		if invoice.Number == ref || invoice.ID == ref {
+			return &invoice, nil
+		}
+	}
+	return nil, fmt.Errorf("invoice %q not found", ref)
+}
+
+// sortInvoices orders invoices by date, then number.
+func sortInvoices(invoices []models.Invoice) {
+	sort.SliceStable(invoices, func(i, j int) bool {
+		if !invoices[i].Date.Equal(invoices[j].Date) {
+			return invoices[i].Date.Before(invoices[j].Date)
+		}
+		return invoices[i].Number < invoices[j].Number
+	})
+}
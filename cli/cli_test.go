@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/models"
+	"github.com/user/invoicer/storage"
+)
+
+// newTestRun returns a Run over a fresh data directory holding the client
+// Acme and the bundled templates.
+func newTestRun(t *testing.T) (models.Storage, func(args ...string) (int, string, string)) {
+	t.Helper()
+	store, err := storage.NewJSONStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveClient(models.NewClient("Acme", "1 Main St", []string{"ap@acme.test"}, decimal.NewFromInt(100))); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{DataPath: t.TempDir()}
+	if err := cfg.EnsureDirectories(); err != nil {
+		t.Fatal(err)
+	}
+	return store, func(args ...string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		code := Run(store, cfg, args, &stdout, &stderr)
+		return code, stdout.String(), stderr.String()
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	_, run := newTestRun(t)
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"no command", nil, ExitOK},
+		{"help", []string{"help"}, ExitOK},
+		{"action help", []string{"invoice", "list", "-h"}, ExitOK},
+		{"list", []string{"client", "list"}, ExitOK},
+		{"create", []string{"invoice", "create", "--client", "acme", "--item", "Work:2:50"}, ExitOK},
+		{"unknown command", []string{"bill"}, ExitUsage},
+		{"missing action", []string{"invoice"}, ExitUsage},
+		{"unknown action", []string{"invoice", "delete"}, ExitUsage},
+		{"unknown flag", []string{"invoice", "list", "--colour"}, ExitUsage},
+		{"missing required flag", []string{"invoice", "create"}, ExitUsage},
+		{"bad item", []string{"invoice", "create", "--client", "acme", "--item", "Work"}, ExitUsage},
+		{"missing argument", []string{"invoice", "show"}, ExitUsage},
+		{"bad status", []string{"invoice", "status", "2026-01", "cancelled"}, ExitUsage},
+		{"unknown client", []string{"invoice", "create", "--client", "Globex", "--item", "Work:1:10"}, ExitError},
+		{"unknown invoice", []string{"invoice", "show", "1999-01"}, ExitError},
+		{"nothing to invoice", []string{"invoice", "create", "--client", "acme"}, ExitError},
+		{"duplicate client", []string{"client", "add", "--name", "Acme"}, ExitError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, stderr := run(tt.args...)
+			if code != tt.want {
+				t.Errorf("Run(%q) = %d, want %d; stderr:\n%s", tt.args, code, tt.want, stderr)
+			}
+			if code == ExitError && !strings.HasPrefix(stderr, "invoicer: ") {
+				t.Errorf("Run(%q) stderr = %q, want the error", tt.args, stderr)
+			}
+			if code == ExitUsage && !strings.Contains(stderr, "Usage:") {
+				t.Errorf("Run(%q) stderr = %q, want the usage", tt.args, stderr)
+			}
+		})
+	}
+}
+
+func TestInvoiceCreateAndStatus(t *testing.T) {
+	store, run := newTestRun(t)
+
+	code, stdout, stderr := run("invoice", "create", "--client", "ACME", "--item", "Design:3:80", "--item", "Hosting:1:20.50", "--json")
+	if code != ExitOK {
+		t.Fatalf("invoice create = %d: %s", code, stderr)
+	}
+	var invoice models.Invoice
+	if err := json.Unmarshal([]byte(stdout), &invoice); err != nil {
+		t.Fatal(err)
+	}
+	if len(invoice.LineItems) != 2 || !invoice.Total.Equal(decimal.RequireFromString("260.50")) {
+		t.Errorf("created invoice has %d lines and total %s", len(invoice.LineItems), invoice.Total)
+	}
+	if want := models.GenerateInvoiceNumber(time.Now().Year(), 1); invoice.Number != want {
+		t.Errorf("number = %s, want %s", invoice.Number, want)
+	}
+
+	if code, _, stderr := run("invoice", "status", invoice.Number, "sent"); code != ExitOK {
+		t.Fatalf("invoice status sent = %d: %s", code, stderr)
+	}
+	// Voiding must be explained, and the refusal is an error, not misuse
+	code, _, stderr = run("invoice", "status", invoice.Number, "void")
+	if code != ExitError || !strings.Contains(stderr, "a reason is required") {
+		t.Errorf("invoice status void without a reason = %d: %s", code, stderr)
+	}
+	if code, _, stderr := run("invoice", "status", invoice.Number, "void", "--reason", "duplicate"); code != ExitOK {
+		t.Errorf("invoice status void = %d: %s", code, stderr)
+	}
+
+	saved, err := store.GetInvoiceByNumber(invoice.Number)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Status != models.StatusVoid {
+		t.Errorf("status = %s, want void", saved.Status)
+	}
+	entries, err := store.GetAuditEntries(saved.ID)
+	if err != nil || len(entries) != 2 || entries[1].ChangedBy != ChangedBy {
+		t.Errorf("audit entries = %+v, %v", entries, err)
+	}
+}
+
+func TestInvoiceExportPrintsOnlyThePath(t *testing.T) {
+	_, run := newTestRun(t)
+	code, stdout, stderr := run("invoice", "create", "--client", "acme", "--item", "Work:1:10", "--json")
+	if code != ExitOK {
+		t.Fatalf("invoice create = %d: %s", code, stderr)
+	}
+	var invoice models.Invoice
+	if err := json.Unmarshal([]byte(stdout), &invoice); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	code, stdout, stderr = run("invoice", "export", invoice.Number, "--dir", dir, "--renderer", "go")
+	if code != ExitOK {
+		t.Fatalf("invoice export = %d: %s", code, stderr)
+	}
+	if want := filepath.Join(dir, "invoice_"+invoice.Number+".pdf") + "\n"; stdout != want {
+		t.Errorf("stdout = %q, want %q", stdout, want)
+	}
+}
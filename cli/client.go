@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"github.com/user/invoicer/export"
+	"github.com/user/invoicer/models"
+)
+
+func (r *runner) clientList(args []string) error {
+	fs := r.newFlags("client list", "[flags]")
+	asJSON := fs.Bool("json", false, "Print the clients as JSON")
+	name := fs.String("name", "", "Only list clients whose name contains this text")
+	if _, err := r.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	clients, err := r.storage.GetAllClients()
+	if err != nil {
+		return fmt.Errorf("failed to load clients: %w", err)
+	}
+	matched := []models.Client{}
+	for _, client := range clients {
+		if strings.Contains(strings.ToLower(client.Name), strings.ToLower(*name)) {
+			matched = append(matched, client)
+		}
+	}
+
+	if *asJSON {
+		return r.printJSON(matched)
+	}
+	tw := r.table()
+	fmt.Fprintln(tw, "NAME\tCURRENCY\tHOURLY RATE\tEMAILS\tID")
+	for _, client := range matched {
+		currency := client.DefaultCurrency.Code()
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", client.Name, currency, currency.Format(client.DefaultHourlyRate),
+			strings.Join(client.Emails, ", "), client.ID)
+	}
+	return tw.Flush()
+}
+
+func (r *runner) clientAdd(args []string) error {
+	fs := r.newFlags("client add", "--name NAME [flags]")
+	asJSON := fs.Bool("json", false, "Print the new client as JSON")
+	name := fs.String("name", "", "Client name (required)")
+	address := fs.String("address", "", "Postal address")
+	rate := fs.String("rate", "0", "Default hourly rate")
+	currency := fs.String("currency", string(models.DefaultCurrency), "Default currency")
+	var emails stringList
+	fs.Var(&emails, "email", "Email address; repeat for several")
+	vatID := fs.String("vat-id", "", "VAT ID, for e-invoices")
+	street := fs.String("street", "", "Street and number, for e-invoices")
+	city := fs.String("city", "", "City, for e-invoices")
+	postalCode := fs.String("postal-code", "", "Postal code, for e-invoices")
+	country := fs.String("country", "", "Two-letter country code, for e-invoices")
+	peppolID := fs.String("peppol-id", "", "Peppol participant ID as SCHEME:ID, for e-invoices")
+	buyerReference := fs.String("buyer-reference", "", "Reference to quote on e-invoices")
+	templateName := fs.String("template", "", "Invoice template for the client, see templates list (default: from the config)")
+	if _, err := r.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(*name) == "" {
+		return r.usageErrorf(fs, "--name is required")
+	}
+	hourlyRate, err := decimal.NewFromString(*rate)
+	if err != nil || hourlyRate.IsNegative() {
+		return r.usageErrorf(fs, "invalid hourly rate %q", *rate)
+	}
+	code, err := models.ParseCurrency(*currency)
+	if err != nil {
+		return r.usageErrorf(fs, "%v", err)
+	}
+	invoiceTemplate, err := export.ParseInvoiceTemplateName(*templateName)
+	if err != nil {
+		return r.usageErrorf(fs, "%v", err)
+	}
+	if invoiceTemplate != "" {
+		if _, err := export.InvoiceTemplatePath(r.config, invoiceTemplate); err != nil {
+			return err
+		}
+	}
+
+	if _, err := r.findClient(*name); err == nil {
+		return fmt.Errorf("a client named %q already exists", strings.TrimSpace(*name))
+	}
+
+	client := models.NewClient(strings.TrimSpace(*name), *address, []string(emails), hourlyRate)
+	if client.Emails == nil {
+		client.Emails = []string{}
+	}
+	client.DefaultCurrency = code
+	client.VATID = strings.TrimSpace(*vatID)
+	client.Street = strings.TrimSpace(*street)
+	client.City = strings.TrimSpace(*city)
+	client.PostalCode = strings.TrimSpace(*postalCode)
+	client.Country = strings.ToUpper(strings.TrimSpace(*country))
+	client.PeppolID = strings.TrimSpace(*peppolID)
+	client.BuyerReference = strings.TrimSpace(*buyerReference)
+	client.InvoiceTemplate = invoiceTemplate
+	if err := r.storage.SaveClient(client); err != nil {
+		return fmt.Errorf("failed to save client: %w", err)
+	}
+
+	if *asJSON {
+		return r.printJSON(client)
+	}
+	fmt.Fprintf(r.stdout, "Created client %s (%s)\n", client.Name, client.ID)
+	return nil
+}
+
+func (r *runner) clientShow(args []string) error {
+	fs := r.newFlags("client show", "CLIENT [flags]")
+	asJSON := fs.Bool("json", false, "Print the client as JSON")
+	positional, err := r.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	client, err := r.findClient(positional[0])
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return r.printJSON(client)
+	}
+
+	invoices, err := r.storage.GetAllInvoices()
+	if err != nil {
+		return fmt.Errorf("failed to load invoices: %w", err)
+	}
+	count := 0
+	outstanding := models.Amounts{}
+	for _, invoice := range invoices {
+		if invoice.ClientID != client.ID {
+			continue
+		}
+		count++
+		if invoice.AcceptsPayments() && !invoice.BalanceDue().IsZero() {
+			outstanding.Add(invoice.Currency.Code(), invoice.BalanceDue())
+		}
+	}
+
+	currency := client.DefaultCurrency.Code()
+	tw := r.table()
+	fmt.Fprintf(tw, "Name:\t%s\n", client.Name)
+	fmt.Fprintf(tw, "ID:\t%s\n", client.ID)
+	if client.Address != "" {
+		fmt.Fprintf(tw, "Address:\t%s\n", client.Address)
+	}
+	if len(client.Emails) > 0 {
+		fmt.Fprintf(tw, "Emails:\t%s\n", strings.Join(client.Emails, ", "))
+	}
+	for _, field := range [][2]string{
+		{"VAT ID", client.VATID},
+		{"Street", client.Street},
+		{"City", client.City},
+		{"Postal code", client.PostalCode},
+		{"Country", client.Country},
+		{"Peppol ID", client.PeppolID},
+		{"Buyer reference", client.BuyerReference},
+		{"Invoice template", client.InvoiceTemplate},
+	} {
+		if field[1] != "" {
+			fmt.Fprintf(tw, "%s:\t%s\n", field[0], field[1])
+		}
+	}
+	fmt.Fprintf(tw, "Hourly rate:\t%s\n", currency.Format(client.DefaultHourlyRate))
+	fmt.Fprintf(tw, "Currency:\t%s\n", currency)
+	fmt.Fprintf(tw, "Invoices:\t%d\n", count)
+	if balance := outstanding.String(); balance != "" {
+		fmt.Fprintf(tw, "Outstanding:\t%s\n", balance)
+	}
+	return tw.Flush()
+}
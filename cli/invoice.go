@@ -0,0 +1,373 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/user/invoicer/audit"
+	"github.com/user/invoicer/export"
+	"github.com/user/invoicer/models"
+)
+
+func (r *runner) invoiceList(args []string) error {
+	fs := r.newFlags("invoice list", "[flags]")
+	asJSON := fs.Bool("json", false, "Print the invoices as JSON")
+	statusName := fs.String("status", "", "Only list invoices with this status")
+	clientRef := fs.String("client", "", "Only list invoices for this client (name or ID)")
+	fromDate := fs.String("from", "", "Only list invoices dated on or after this day (YYYY-MM-DD)")
+	toDate := fs.String("to", "", "Only list invoices dated on or before this day (YYYY-MM-DD)")
+	if _, err := r.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	var status models.InvoiceStatus
+	if *statusName != "" {
+		var err error
+		if status, err = models.ParseInvoiceStatus(*statusName); err != nil {
+			return r.usageErrorf(fs, "%v", err)
+		}
+	}
+	var from, to time.Time
+	if *fromDate != "" {
+		var err error
+		if from, err = parseDate(*fromDate); err != nil {
+			return r.usageErrorf(fs, "--from: %v", err)
+		}
+	}
+	if *toDate != "" {
+		var err error
+		if to, err = parseDate(*toDate); err != nil {
+			return r.usageErrorf(fs, "--to: %v", err)
+		}
+	}
+	var clientID string
+	if *clientRef != "" {
+		client, err := r.findClient(*clientRef)
+		if err != nil {
+			return err
+		}
+		clientID = client.ID
+	}
+
+	invoices, err := r.storage.GetAllInvoices()
+	if err != nil {
+		return fmt.Errorf("failed to load invoices: %w", err)
+	}
+	matched := []models.Invoice{}
+	for _, invoice := range invoices {
+		switch {
+		case status != "" && invoice.Status != status,
+			clientID != "" && invoice.ClientID != clientID,
+			!from.IsZero() && invoice.Date.Before(from),
+			!to.IsZero() && !invoice.Date.Before(to.AddDate(0, 0, 1)):
+			continue
+		}
+		matched = append(matched, invoice)
+	}
+	sortInvoices(matched)
+
+	if *asJSON {
+		return r.printJSON(matched)
+	}
+	tw := r.table()
+	fmt.Fprintln(tw, "NUMBER\tDATE\tDUE\tCLIENT\tSTATUS\tTOTAL\tBALANCE")
+	for _, invoice := range matched {
+		currency := invoice.Currency.Code()
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", invoice.Number, invoice.Date.Format("2006-01-02"),
+			invoice.DueDate.Format("2006-01-02"), invoice.ClientName, invoice.Status,
+			currency.Format(invoice.Total), currency.Format(invoice.BalanceDue()))
+	}
+	return tw.Flush()
+}
+
+// parseItem reads a line item given as DESCRIPTION:QUANTITY:UNIT_PRICE. The
+// description may itself contain colons.
+func parseItem(value string) (*models.LineItem, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) < 3 {
+		return nil, fmt.Errorf("invalid item %q, use DESCRIPTION:QUANTITY:UNIT_PRICE", value)
+	}
+	n := len(parts)
+	description := strings.TrimSpace(strings.Join(parts[:n-2], ":"))
+	if description == "" {
+		return nil, fmt.Errorf("item %q has no description", value)
+	}
+	quantity, err := decimal.NewFromString(strings.TrimSpace(parts[n-2]))
+	if err != nil || !quantity.IsPositive() {
+		return nil, fmt.Errorf("item %q has an invalid quantity", value)
+	}
+	price, err := decimal.NewFromString(strings.TrimSpace(parts[n-1]))
+	if err != nil || price.IsNegative() {
+		return nil, fmt.Errorf("item %q has an invalid unit price", value)
+	}
+	return models.NewLineItem(description, quantity, price), nil
+}
+
+func (r *runner) invoiceCreate(args []string) error {
+	fs := r.newFlags("invoice create", "--client CLIENT [--item DESCRIPTION:QUANTITY:UNIT_PRICE]... [flags]")
+	asJSON := fs.Bool("json", false, "Print the new invoice as JSON")
+	clientRef := fs.String("client", "", "Client to bill, by name or ID (required)")
+	dueDays := fs.Int("due-days", 30, "Days until the invoice is due")
+	currencyName := fs.String("currency", "", "Currency (default: the client's)")
+	serviceStart := fs.String("service-start", "", "First day of the service period (default: start of this month)")
+	serviceEnd := fs.String("service-end", "", "Last day of the service period (default: end of this month)")
+	billTime := fs.Bool("time", false, "Add the client's unbilled time in the service period")
+	billExpenses := fs.Bool("expenses", false, "Add the client's unbilled expenses in the service period")
+	var items stringList
+	fs.Var(&items, "item", "Line item as DESCRIPTION:QUANTITY:UNIT_PRICE; repeat for several")
+	if _, err := r.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	if *clientRef == "" {
+		return r.usageErrorf(fs, "--client is required")
+	}
+	if *dueDays < 0 {
+		return r.usageErrorf(fs, "--due-days cannot be negative")
+	}
+	var lines []models.LineItem
+	for _, value := range items {
+		item, err := parseItem(value)
+		if err != nil {
+			return r.usageErrorf(fs, "%v", err)
+		}
+		lines = append(lines, *item)
+	}
+
+	client, err := r.findClient(*clientRef)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	seq, err := r.storage.GetNextInvoiceNumber(now.Year())
+	if err != nil {
+		return fmt.Errorf("failed to get next invoice number: %w", err)
+	}
+	invoice := models.NewInvoice(client.ID, client.Name, models.GenerateInvoiceNumber(now.Year(), seq))
+	invoice.DueDate = invoice.Date.AddDate(0, 0, *dueDays)
+	invoice.Currency = client.DefaultCurrency.Code()
+	if *currencyName != "" {
+		if invoice.Currency, err = models.ParseCurrency(*currencyName); err != nil {
+			return r.usageErrorf(fs, "%v", err)
+		}
+	}
+	if *serviceStart != "" {
+		start, err := parseDate(*serviceStart)
+		if err != nil {
+			return r.usageErrorf(fs, "--service-start: %v", err)
+		}
+		invoice.ServiceStartDate = &start
+	}
+	if *serviceEnd != "" {
+		end, err := parseDate(*serviceEnd)
+		if err != nil {
+			return r.usageErrorf(fs, "--service-end: %v", err)
+		}
+		invoice.ServiceEndDate = &end
+	}
+	if invoice.ServiceStartDate.After(*invoice.ServiceEndDate) {
+		return r.usageErrorf(fs, "the service period ends before it starts")
+	}
+	for _, line := range lines {
+		invoice.AddLineItem(line)
+	}
+
+	var entries []models.TimeEntry
+	if *billTime {
+		all, err := r.storage.GetAllTimeEntries()
+		if err != nil {
+			return fmt.Errorf("failed to load time entries: %w", err)
+		}
+		entries = models.UnbilledTime(all, client.ID, invoice.ServiceStartDate, invoice.ServiceEndDate)
+		for _, line := range models.BillTime(entries, client.DefaultHourlyRate) {
+			invoice.AddLineItem(line.Item)
+		}
+	}
+	var expenses []models.Expense
+	if *billExpenses {
+		all, err := r.storage.GetAllExpenses()
+		if err != nil {
+			return fmt.Errorf("failed to load expenses: %w", err)
+		}
+		expenses = models.UnbilledExpenses(all, client.ID, invoice.ServiceStartDate, invoice.ServiceEndDate)
+		for _, e := range expenses {
+			invoice.AddLineItem(*e.LineItem(invoice.Currency))
+		}
+	}
+	if len(invoice.LineItems) == 0 {
+		return fmt.Errorf("nothing to invoice for %s: give --item, or --time or --expenses with unbilled work in the service period", client.Name)
+	}
+
+	if err := r.storage.SaveInvoice(invoice); err != nil {
+		return fmt.Errorf("failed to save invoice: %w", err)
+	}
+	for i := range entries {
+		entries[i].MarkBilled(invoice.ID, invoice.Number)
+		if err := r.storage.UpdateTimeEntry(&entries[i]); err != nil {
+			return fmt.Errorf("invoice %s created but time not marked as billed: %w", invoice.Number, err)
+		}
+	}
+	for i := range expenses {
+		expenses[i].MarkBilled(invoice.ID, invoice.Number)
+		if err := r.storage.UpdateExpense(&expenses[i]); err != nil {
+			return fmt.Errorf("invoice %s created but expenses not marked as billed: %w", invoice.Number, err)
+		}
+	}
+
+	if *asJSON {
+		return r.printJSON(invoice)
+	}
+	fmt.Fprintf(r.stdout, "Created invoice %s for %s: %s\n", invoice.Number, client.Name, invoice.Currency.Format(invoice.Total))
+	return nil
+}
+
+func (r *runner) invoiceShow(args []string) error {
+	fs := r.newFlags("invoice show", "NUMBER [flags]")
+	asJSON := fs.Bool("json", false, "Print the invoice as JSON")
+	positional, err := r.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	invoice, err := r.findInvoice(positional[0])
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return r.printJSON(invoice)
+	}
+
+	currency := invoice.Currency.Code()
+	tw := r.table()
+	fmt.Fprintf(tw, "Invoice:\t%s\n", invoice.Number)
+	fmt.Fprintf(tw, "Client:\t%s\n", invoice.ClientName)
+	fmt.Fprintf(tw, "Status:\t%s\n", invoice.Status)
+	fmt.Fprintf(tw, "Date:\t%s\n", invoice.Date.Format("2006-01-02"))
+	fmt.Fprintf(tw, "Due:\t%s\n", invoice.DueDate.Format("2006-01-02"))
+	if invoice.ServiceStartDate != nil && invoice.ServiceEndDate != nil {
+		fmt.Fprintf(tw, "Service period:\t%s to %s\n", invoice.ServiceStartDate.Format("2006-01-02"), invoice.ServiceEndDate.Format("2006-01-02"))
+	}
+	tw.Flush()
+
+	fmt.Fprintln(r.stdout)
+	tw = r.table()
+	fmt.Fprintln(tw, "DESCRIPTION\tQTY\tUNIT PRICE\tTOTAL")
+	for _, item := range invoice.LineItems {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", item.Description, models.FormatQuantity(item.Quantity),
+			currency.Format(item.UnitPrice), currency.Format(item.Total))
+	}
+	tw.Flush()
+
+	fmt.Fprintln(r.stdout)
+	tw = r.table()
+	fmt.Fprintf(tw, "Subtotal:\t%s\n", currency.Format(invoice.Subtotal))
+	if !invoice.Discount.IsZero() {
+		fmt.Fprintf(tw, "Discount:\t%s\n", currency.Format(invoice.Discount.Neg()))
+	}
+	for _, t := range invoice.TaxBreakdown() {
+		fmt.Fprintf(tw, "%s (%s%%):\t%s\n", t.Name, models.FormatPercent(t.Rate), currency.Format(t.Amount))
+	}
+	fmt.Fprintf(tw, "Total:\t%s\n", currency.Format(invoice.Total))
+	if invoice.AmountCredited.IsPositive() {
+		fmt.Fprintf(tw, "Credited:\t%s\n", currency.Format(invoice.AmountCredited.Neg()))
+	}
+	if invoice.AmountPaid.IsPositive() {
+		fmt.Fprintf(tw, "Paid:\t%s\n", currency.Format(invoice.AmountPaid.Neg()))
+	}
+	fmt.Fprintf(tw, "Balance due:\t%s\n", currency.Format(invoice.BalanceDue()))
+	return tw.Flush()
+}
+
+func (r *runner) invoiceStatus(args []string) error {
+	fs := r.newFlags("invoice status", "NUMBER STATUS [flags]")
+	asJSON := fs.Bool("json", false, "Print the updated invoice as JSON")
+	reason := fs.String("reason", "", "Reason for the change, required for voiding and reversals")
+	positional, err := r.parse(fs, args, 2)
+	if err != nil {
+		return err
+	}
+	status, err := models.ParseInvoiceStatus(positional[1])
+	if err != nil {
+		return r.usageErrorf(fs, "%v", err)
+	}
+
+	invoice, err := r.findInvoice(positional[0])
+	if err != nil {
+		return err
+	}
+	oldStatus := invoice.Status
+	if err := audit.NewService(r.storage).ChangeStatus(invoice, status, *reason, ChangedBy); err != nil {
+		if invoice.Status != status {
+			return fmt.Errorf("invoice %s: %w", invoice.Number, err)
+		}
+		// Saved, but the audit entry could not be written
+		fmt.Fprintf(r.stderr, "invoicer: warning: %v\n", err)
+	}
+
+	if *asJSON {
+		return r.printJSON(invoice)
+	}
+	fmt.Fprintf(r.stdout, "%s: %s -> %s\n", invoice.Number, oldStatus, invoice.Status)
+	return nil
+}
+
+func (r *runner) invoiceExport(args []string) error {
+	fs := r.newFlags("invoice export", "NUMBER [flags]")
+	dir := fs.String("dir", ".", "Directory to save the PDF in")
+	receipts := fs.Bool("receipts", false, "Append the receipts of the expenses billed on the invoice")
+	rendererName := fs.String("renderer", "", "PDF renderer: latex, go or auto (default: from the config)")
+	templateName := fs.String("template", "", "Invoice template, see templates list (default: the client's or the config's)")
+	positional, err := r.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	renderer, err := export.ParseRenderer(*rendererName)
+	if err != nil {
+		return r.usageErrorf(fs, "%v", err)
+	}
+	chosen, err := export.ParseInvoiceTemplateName(*templateName)
+	if err != nil {
+		return r.usageErrorf(fs, "%v", err)
+	}
+
+	invoice, err := r.findInvoice(positional[0])
+	if err != nil {
+		return err
+	}
+	client, err := r.storage.GetClient(invoice.ClientID)
+	if err != nil {
+		return fmt.Errorf("failed to load client: %w", err)
+	}
+	var attached []export.Receipt
+	if *receipts {
+		if attached, err = export.InvoiceReceipts(r.storage, r.config, invoice.ID); err != nil {
+			return fmt.Errorf("failed to load receipts: %w", err)
+		}
+	}
+
+	exportPath, err := filepath.Abs(*dir)
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(exportPath); err != nil || !info.IsDir() {
+		return fmt.Errorf("directory does not exist: %s", exportPath)
+	}
+	templatePath, err := export.InvoiceTemplatePath(r.config, export.InvoiceTemplateName(r.config, client, chosen))
+	if err != nil {
+		return err
+	}
+	timesheet, err := export.InvoiceTimeEntries(r.storage, invoice.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load time entries: %w", err)
+	}
+	if err := export.ExportInvoiceToPDF(invoice, client, r.config, exportPath, templatePath, attached, timesheet, renderer); err != nil {
+		return err
+	}
+	fmt.Fprintln(r.stdout, export.GetExportPath(invoice, exportPath))
+	return nil
+}
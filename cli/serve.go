@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/user/invoicer/api"
+)
+
+func (r *runner) serve(args []string) error {
+	fs := r.newFlags("serve", "[flags]")
+	addr := fs.String("addr", "127.0.0.1:8080", "Address to listen on")
+	if _, err := r.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	handler, err := api.NewServer(r.storage, r.config)
+	if err != nil {
+		return err
+	}
+	// Bodies are small, but a PDF export can run LaTeX several times
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      2 * time.Minute,
+		IdleTimeout:       2 * time.Minute,
+	}
+
+	// Finish the requests in flight on Ctrl-C or a service manager's stop,
+	// so that no data file is left half written
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	errc := make(chan error, 1)
+	go func() {
+		errc <- server.ListenAndServe()
+	}()
+	fmt.Fprintf(r.stdout, "Serving the API on http://%s%s/\n", *addr, api.Prefix)
+
+	select {
+	case err := <-errc:
+		return fmt.Errorf("failed to serve: %w", err)
+	case <-ctx.Done():
+	}
+	shutdown, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdown); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to shut down: %w", err)
+	}
+	return nil
+}
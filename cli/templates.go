@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/user/invoicer/export"
+)
+
+func (r *runner) templatesList(args []string) error {
+	fs := r.newFlags("templates list", "[flags]")
+	asJSON := fs.Bool("json", false, "Print the templates as JSON")
+	if _, err := r.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	templates, err := export.InvoiceTemplates(r.config)
+	if err != nil {
+		return err
+	}
+	defaultName := export.InvoiceTemplateName(r.config, nil, "")
+
+	type listedTemplate struct {
+		Name    string `json:"name"`
+		Path    string `json:"path"`
+		Source  string `json:"source"`
+		Default bool   `json:"default"`
+	}
+	listed := []listedTemplate{}
+	found := false
+	for _, t := range templates {
+		listed = append(listed, listedTemplate{Name: t.Name, Path: t.Path, Source: string(t.Source), Default: t.Name == defaultName})
+		found = found || t.Name == defaultName
+	}
+	if !found {
+		fmt.Fprintf(r.stderr, "invoicer: warning: the default template %q is not in %s\n", defaultName, r.config.TemplatesDir())
+	}
+
+	if *asJSON {
+		return r.printJSON(listed)
+	}
+	tw := r.table()
+	fmt.Fprintln(tw, "NAME\tDEFAULT\tSOURCE\tPATH")
+	for _, t := range listed {
+		isDefault := ""
+		if t.Default {
+			isDefault = "yes"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", t.Name, isDefault, t.Source, t.Path)
+	}
+	return tw.Flush()
+}
@@ -5,13 +5,57 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/user/invoicer/templates"
+)
+
+// Storage backends selectable through Config.StorageBackend.
+const (
+	BackendJSON   = "json"
+	BackendSQLite = "sqlite"
 )
 
 type Config struct {
 	DataPath       string `json:"data_path"`
+	StorageBackend string `json:"storage_backend,omitempty"`
+	// JSONGenerations is how many rollback copies of each JSON data file
+	// to keep. Zero means the default; a negative value disables them.
+	JSONGenerations int `json:"json_generations,omitempty"`
+	// RoundingMode is "half_up" (the default) or "half_even"/"bankers", and
+	// RoundingScope is "invoice" (the default) to round each tax total or
+	// "line" to round each line's tax
+	RoundingMode   string `json:"rounding_mode,omitempty"`
+	RoundingScope  string `json:"rounding_scope,omitempty"`
+	// PDFRenderer is "latex" to render invoices with the LaTeX engine and
+	// invoice.tex, "go" for the built-in renderer, or empty for LaTeX only
+	// where latex_engine is installed
+	PDFRenderer    string `json:"pdf_renderer,omitempty"`
+	// LaTeXEngine is the program that compiles the LaTeX templates:
+	// "pdflatex" (the default), "xelatex", "lualatex", "tectonic" or
+	// "latexmk"
+	LaTeXEngine    string `json:"latex_engine,omitempty"`
+	// InvoiceTemplate names the LaTeX template invoices are rendered with
+	// unless their client has one of its own; empty means invoice.tex
+	InvoiceTemplate string `json:"invoice_template,omitempty"`
+	// FacturXProfile is "minimum", "basic" or "en16931" to embed the
+	// invoice as Factur-X XML of that profile in invoice PDFs, making them
+	// PDF/A-3 hybrids, or empty for plain PDFs. Needs the LaTeX renderer.
+	FacturXProfile string `json:"facturx_profile,omitempty"`
+	// APIToken is the bearer token clients of `invoicer serve` must send.
+	// The API refuses to start without one.
+	APIToken       string `json:"api_token,omitempty"`
 	CompanyName    string `json:"company_name"`
 	CompanyAddress string `json:"company_address"`
 	CompanyEmail   string `json:"company_email"`
+	// Seller details for e-invoices: the VAT ID, the address split into
+	// parts with an ISO 3166-1 alpha-2 country code, and the Peppol
+	// participant ID as SCHEME:ID
+	CompanyVATID      string `json:"company_vat_id,omitempty"`
+	CompanyStreet     string `json:"company_street,omitempty"`
+	CompanyCity       string `json:"company_city,omitempty"`
+	CompanyPostalCode string `json:"company_postal_code,omitempty"`
+	CompanyCountry    string `json:"company_country,omitempty"`
+	CompanyPeppolID   string `json:"company_peppol_id,omitempty"`
 	// Payment information
 	ZelleAccount   string `json:"zelle_account,omitempty"`
 	VenmoAccount   string `json:"venmo_account,omitempty"`
@@ -86,9 +130,14 @@ func (c *Config) Save() error {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	if err := os.WriteFile(configPath, data, 0644); err != nil {
+	// The file holds the API token, so only its owner may read it. WriteFile
+	// keeps the mode of an existing file, which may predate the token.
+	if err := os.WriteFile(configPath, data, 0600); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
+	if err := os.Chmod(configPath, 0600); err != nil {
+		return fmt.Errorf("failed to restrict config file permissions: %w", err)
+	}
 
 	return nil
 }
@@ -97,6 +146,17 @@ func (c *Config) DataDir() string {
 	return filepath.Join(c.DataPath, "data")
 }
 
+// DatabasePath is the SQLite database used when StorageBackend is "sqlite".
+func (c *Config) DatabasePath() string {
+	return filepath.Join(c.DataDir(), "invoicer.db")
+}
+
+// ReceiptsDir holds the copies of expense receipts, inside DataDir so that
+// they move and back up with the rest of the data.
+func (c *Config) ReceiptsDir() string {
+	return filepath.Join(c.DataDir(), "receipts")
+}
+
 func (c *Config) TemplatesDir() string {
 	return filepath.Join(c.DataPath, "templates")
 }
@@ -114,14 +174,15 @@ func (c *Config) EnsureDirectories() error {
 		}
 	}
 
-	// Check if template exists, if not copy from the working directory
-	templatePath := filepath.Join(c.TemplatesDir(), "invoice.tex")
-	if _, err := os.Stat(templatePath); os.IsNotExist(err) {
-		// Try to copy from local templates directory
-		localTemplate := "./templates/invoice.tex"
-		if _, err := os.Stat(localTemplate); err == nil {
-			// Copy the template
-			if err := copyTemplateFile(localTemplate, templatePath); err != nil {
+	// Copy the bundled templates that are missing, leaving edited ones be
+	for _, name := range templates.Names() {
+		templatePath := filepath.Join(c.TemplatesDir(), name)
+		if _, err := os.Stat(templatePath); os.IsNotExist(err) {
+			content, err := templates.Read(name)
+			if err != nil {
+				return fmt.Errorf("failed to read bundled template %s: %w", name, err)
+			}
+			if err := os.WriteFile(templatePath, content, 0644); err != nil {
 				return fmt.Errorf("failed to copy template: %w", err)
 			}
 		}
@@ -130,10 +191,39 @@ func (c *Config) EnsureDirectories() error {
 	return nil
 }
 
-func copyTemplateFile(src, dst string) error {
-	input, err := os.ReadFile(src)
+// UpgradeTemplates replaces copies of earlier versions of the bundled
+// templates that were never edited with the current ones, returning their
+// names as upgraded. Edited templates that still format amounts the way
+// the first templates did, bundled or not, are left alone and returned as
+// outdated, to be updated by hand.
+func (c *Config) UpgradeTemplates() (upgraded, outdated []string, err error) {
+	entries, err := os.ReadDir(c.TemplatesDir())
 	if err != nil {
-		return err
+		return nil, nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		templatePath := filepath.Join(c.TemplatesDir(), name)
+		content, err := os.ReadFile(templatePath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read template: %w", err)
+		}
+		switch {
+		case templates.IsEarlierVersion(name, content):
+			current, err := templates.Read(name)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to read bundled template %s: %w", name, err)
+			}
+			if err := os.WriteFile(templatePath, current, 0644); err != nil {
+				return nil, nil, fmt.Errorf("failed to upgrade template: %w", err)
+			}
+			upgraded = append(upgraded, name)
+		case templates.IsOutdated(content):
+			outdated = append(outdated, name)
+		}
 	}
-	return os.WriteFile(dst, input, 0644)
-}
\ No newline at end of file
+	return upgraded, outdated, nil
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/user/invoicer/templates"
+)
+
+func TestUpgradeTemplates(t *testing.T) {
+	cfg := &Config{DataPath: t.TempDir()}
+	if err := cfg.EnsureDirectories(); err != nil {
+		t.Fatal(err)
+	}
+	// The invoice.tex of the first release, never edited
+	v1, err := os.ReadFile(filepath.Join("testdata", "invoice-v1.tex"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	write := func(name string, content []byte) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(cfg.TemplatesDir(), name), content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("invoice.tex", v1)
+	// An edited copy of it, and a template of the user's own made from it
+	edited := append([]byte("% Our letterhead\n"), v1...)
+	write("estimate.tex", edited)
+	write("invoice-letterhead.tex", edited)
+
+	upgraded, outdated, err := cfg.UpgradeTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(upgraded, []string{"invoice.tex"}) {
+		t.Errorf("upgraded = %v, want [invoice.tex]", upgraded)
+	}
+	if !reflect.DeepEqual(outdated, []string{"estimate.tex", "invoice-letterhead.tex"}) {
+		t.Errorf("outdated = %v, want [estimate.tex invoice-letterhead.tex]", outdated)
+	}
+
+	current, _ := templates.Read("invoice.tex")
+	got, _ := os.ReadFile(filepath.Join(cfg.TemplatesDir(), "invoice.tex"))
+	if !bytes.Equal(got, current) {
+		t.Error("invoice.tex was not replaced with the current version")
+	}
+	got, _ = os.ReadFile(filepath.Join(cfg.TemplatesDir(), "estimate.tex"))
+	if !bytes.Equal(got, edited) {
+		t.Error("the edited estimate.tex was overwritten")
+	}
+
+	// Nothing left to upgrade
+	if upgraded, _, _ := cfg.UpgradeTemplates(); len(upgraded) != 0 {
+		t.Errorf("second run upgraded %v", upgraded)
+	}
+}
+
+func TestCurrentTemplatesAreNotEarlierVersions(t *testing.T) {
+	for _, name := range templates.Names() {
+		content, err := templates.Read(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if templates.IsEarlierVersion(name, content) || templates.IsOutdated(content) {
+			t.Errorf("the bundled %s counts as an earlier version", name)
+		}
+	}
+}
+
+func TestSaveKeepsTheTokenPrivate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not enforced on Windows")
+	}
+	t.Setenv("HOME", t.TempDir())
+	path, err := ConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Written world-readable by an earlier version
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.APIToken = "secret"
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("config file mode = %o, want 600", perm)
+	}
+}
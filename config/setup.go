@@ -86,18 +86,20 @@ func RunSetup() (*Config, error) {
 
 	// Bank routing input
 	m.inputs[7] = textinput.New()
-	m.inputs[7].Placeholder = "9-digit routing number (optional)"
-	m.inputs[7].CharLimit = 9
+	m.inputs[7].Placeholder = "Routing number or BIC (optional)"
+	m.inputs[7].CharLimit = 11
 	m.inputs[7].Width = 50
 	m.inputs[7].Prompt = "Bank routing: "
 
 	// Bank account input
 	m.inputs[8] = textinput.New()
-	m.inputs[8].Placeholder = "Account number (optional)"
-	m.inputs[8].CharLimit = 20
+	m.inputs[8].Placeholder = "Account number or IBAN (optional)"
+	m.inputs[8].CharLimit = 34
 	m.inputs[8].Width = 50
 	m.inputs[8].Prompt = "Bank account: "
 
+	m.inputs = append(m.inputs, eInvoicingInputs(config)...)
+
 	p := tea.NewProgram(m)
 	finalModel, err := p.Run()
 	if err != nil {
@@ -218,6 +220,14 @@ func (m *setupModel) saveConfig() error {
 	m.config.BankRouting = strings.TrimSpace(m.inputs[7].Value())
 	m.config.BankAccount = strings.TrimSpace(m.inputs[8].Value())
 
+	// E-invoicing details
+	m.config.CompanyVATID = strings.TrimSpace(m.inputs[9].Value())
+	m.config.CompanyStreet = strings.TrimSpace(m.inputs[10].Value())
+	m.config.CompanyCity = strings.TrimSpace(m.inputs[11].Value())
+	m.config.CompanyPostalCode = strings.TrimSpace(m.inputs[12].Value())
+	m.config.CompanyCountry = strings.ToUpper(strings.TrimSpace(m.inputs[13].Value()))
+	m.config.CompanyPeppolID = strings.TrimSpace(m.inputs[14].Value())
+
 	// Create directories
 	if err := m.config.EnsureDirectories(); err != nil {
 		return err
@@ -246,7 +256,14 @@ func (m setupModel) View() string {
 
 	// Payment information section
 	s.WriteString("\n" + blurredStyle.Render("Payment Information (optional)") + "\n")
-	for i := 4; i < len(m.inputs); i++ {
+	for i := 4; i < 9; i++ {
+		s.WriteString(m.inputs[i].View())
+		s.WriteString("\n")
+	}
+
+	// E-invoicing section
+	s.WriteString("\n" + blurredStyle.Render("E-invoicing (optional)") + "\n")
+	for i := 9; i < len(m.inputs); i++ {
 		s.WriteString(m.inputs[i].View())
 		if i < len(m.inputs)-1 {
 			s.WriteString("\n")
@@ -322,22 +339,50 @@ func NewSettingsEditor(cfg *Config) tea.Model {
 	// Bank routing input
 	m.inputs[7] = textinput.New()
 	m.inputs[7].SetValue(cfg.BankRouting)
-	m.inputs[7].Placeholder = "9-digit routing number (optional)"
-	m.inputs[7].CharLimit = 9
+	m.inputs[7].Placeholder = "Routing number or BIC (optional)"
+	m.inputs[7].CharLimit = 11
 	m.inputs[7].Width = 50
 	m.inputs[7].Prompt = "Bank routing: "
 
 	// Bank account input
 	m.inputs[8] = textinput.New()
 	m.inputs[8].SetValue(cfg.BankAccount)
-	m.inputs[8].Placeholder = "Account number (optional)"
-	m.inputs[8].CharLimit = 20
+	m.inputs[8].Placeholder = "Account number or IBAN (optional)"
+	m.inputs[8].CharLimit = 34
 	m.inputs[8].Width = 50
 	m.inputs[8].Prompt = "Bank account: "
 
+	m.inputs = append(m.inputs, eInvoicingInputs(cfg)...)
+
 	return settingsEditorModel{setupModel: m}
 }
 
+// eInvoicingInputs are the seller details only needed for e-invoices,
+// filled in from cfg.
+func eInvoicingInputs(cfg *Config) []textinput.Model {
+	fields := []struct {
+		prompt, placeholder, value string
+		limit                      int
+	}{
+		{"VAT ID: ", "e.g. DE123456789 (optional)", cfg.CompanyVATID, 20},
+		{"Street: ", "Street and number (optional)", cfg.CompanyStreet, 100},
+		{"City: ", "City (optional)", cfg.CompanyCity, 100},
+		{"Postal code: ", "Postal code (optional)", cfg.CompanyPostalCode, 20},
+		{"Country: ", "Two-letter code, e.g. DE (optional)", cfg.CompanyCountry, 2},
+		{"Peppol ID: ", "SCHEME:ID, e.g. 0088:5790000435968 (optional)", cfg.CompanyPeppolID, 60},
+	}
+	inputs := make([]textinput.Model, len(fields))
+	for i, f := range fields {
+		inputs[i] = textinput.New()
+		inputs[i].SetValue(f.value)
+		inputs[i].Placeholder = f.placeholder
+		inputs[i].CharLimit = f.limit
+		inputs[i].Width = 50
+		inputs[i].Prompt = f.prompt
+	}
+	return inputs
+}
+
 // settingsEditorModel wraps setupModel for settings editing
 type settingsEditorModel struct {
 	setupModel setupModel
@@ -402,7 +447,14 @@ func (m settingsEditorModel) View() string {
 
 	// Payment information section
 	s.WriteString("\n" + blurredStyle.Render("Payment Information (optional)") + "\n")
-	for i := 4; i < len(m.setupModel.inputs); i++ {
+	for i := 4; i < 9; i++ {
+		s.WriteString(m.setupModel.inputs[i].View())
+		s.WriteString("\n")
+	}
+
+	// E-invoicing section
+	s.WriteString("\n" + blurredStyle.Render("E-invoicing (optional)") + "\n")
+	for i := 9; i < len(m.setupModel.inputs); i++ {
 		s.WriteString(m.setupModel.inputs[i].View())
 		if i < len(m.setupModel.inputs)-1 {
 			s.WriteString("\n")
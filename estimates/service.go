@@ -0,0 +1,51 @@
+package estimates
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/user/invoicer/models"
+)
+
+type Service struct {
+	storage models.Storage
+}
+
+func NewService(storage models.Storage) *Service {
+	return &Service{storage: storage}
+}
+
+// ConvertToInvoice creates a draft invoice from estimate, numbered from the
+// invoice sequence, and saves the estimate as accepted and linked to it. If
+// the estimate cannot be saved the invoice is removed again, so an estimate
+// is never converted twice.
+func (s *Service) ConvertToInvoice(estimate *models.Estimate) (*models.Invoice, error) {
+	if err := estimate.CanConvert(); err != nil {
+		return nil, err
+	}
+
+	year := time.Now().Year()
+	seq, err := s.storage.GetNextInvoiceNumber(year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next invoice number: %w", err)
+	}
+
+	original := *estimate
+	invoice, err := estimate.ConvertToInvoice(models.GenerateInvoiceNumber(year, seq))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.SaveInvoice(invoice); err != nil {
+		*estimate = original
+		return nil, fmt.Errorf("failed to save invoice: %w", err)
+	}
+
+	if err := s.storage.UpdateEstimate(estimate); err != nil {
+		*estimate = original
+		s.storage.DeleteInvoice(invoice.ID)
+		return nil, fmt.Errorf("failed to update estimate: %w", err)
+	}
+
+	return invoice, nil
+}
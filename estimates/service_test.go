@@ -0,0 +1,84 @@
+package estimates
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/user/invoicer/models"
+	"github.com/user/invoicer/storage"
+)
+
+func newEstimate(t *testing.T, store models.Storage) *models.Estimate {
+	t.Helper()
+	estimate := models.NewEstimate("c1", "Acme", "EST-2026-01")
+	estimate.AddLineItem(*models.NewLineItem("Design", decimal.NewFromInt(2), decimal.NewFromInt(100)))
+	estimate.Status = models.EstimateSent
+	if err := store.SaveEstimate(estimate); err != nil {
+		t.Fatal(err)
+	}
+	return estimate
+}
+
+func TestConvertToInvoice(t *testing.T) {
+	store, err := storage.NewJSONStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	estimate := newEstimate(t, store)
+
+	invoice, err := NewService(store).ConvertToInvoice(estimate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, err := store.GetEstimate(estimate.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Status != models.EstimateAccepted || saved.InvoiceID != invoice.ID {
+		t.Errorf("saved estimate is %s, linked to %q", saved.Status, saved.InvoiceID)
+	}
+	stored, err := store.GetInvoice(invoice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.Total.Equal(estimate.Total) || stored.EstimateID != estimate.ID {
+		t.Errorf("stored invoice total %s from %q", stored.Total, stored.EstimateID)
+	}
+
+	if _, err := NewService(store).ConvertToInvoice(saved); err == nil {
+		t.Error("converted the estimate twice")
+	}
+}
+
+// failingEstimates is storage whose estimates cannot be updated.
+type failingEstimates struct {
+	models.Storage
+}
+
+func (failingEstimates) UpdateEstimate(*models.Estimate) error {
+	return errors.New("disk full")
+}
+
+func TestConvertRollsBack(t *testing.T) {
+	store, err := storage.NewJSONStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	estimate := newEstimate(t, store)
+
+	if _, err := NewService(failingEstimates{store}).ConvertToInvoice(estimate); err == nil {
+		t.Fatal("ConvertToInvoice() succeeded without saving the estimate")
+	}
+	if estimate.Status != models.EstimateSent || estimate.InvoiceID != "" {
+		t.Errorf("estimate left %s, linked to %q", estimate.Status, estimate.InvoiceID)
+	}
+	// The invoice is removed, so the estimate can be converted again
+	if invoices, _ := store.GetAllInvoices(); len(invoices) != 0 {
+		t.Errorf("%d invoices left behind", len(invoices))
+	}
+	if _, err := NewService(store).ConvertToInvoice(estimate); err != nil {
+		t.Errorf("converting again: %v", err)
+	}
+}
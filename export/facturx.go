@@ -0,0 +1,343 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/models"
+)
+
+// FacturXProfile is the Factur-X profile of the XML embedded in invoice
+// PDFs, or FacturXNone for plain PDFs.
+type FacturXProfile string
+
+const (
+	FacturXNone FacturXProfile = ""
+	// FacturXMinimum carries only the parties and totals
+	FacturXMinimum FacturXProfile = "minimum"
+	// FacturXBasic and FacturXEN16931 carry the lines, taxes and payment
+	// details as well
+	FacturXBasic   FacturXProfile = "basic"
+	FacturXEN16931 FacturXProfile = "en16931"
+)
+
+// ParseFacturXProfile reads a profile name such as "EN 16931"; "" and
+// "none" give FacturXNone.
+func ParseFacturXProfile(name string) (FacturXProfile, error) {
+	switch p := FacturXProfile(strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), " ", ""))); p {
+	case "none", FacturXNone:
+		return FacturXNone, nil
+	case FacturXMinimum, FacturXBasic, FacturXEN16931:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown Factur-X profile %q (use minimum, basic, en16931 or none)", name)
+}
+
+// guideline identifies the profile inside the XML.
+func (p FacturXProfile) guideline() string {
+	switch p {
+	case FacturXMinimum:
+		return "urn:factur-x.eu:1p0:minimum"
+	case FacturXBasic:
+		return "urn:cen.eu:en16931:2017#compliant#urn:factur-x.eu:1p0:basic"
+	}
+	return "urn:cen.eu:en16931:2017"
+}
+
+// conformanceLevel names the profile in the PDF's metadata.
+func (p FacturXProfile) conformanceLevel() string {
+	if p == FacturXEN16931 {
+		return "EN 16931"
+	}
+	return strings.ToUpper(string(p))
+}
+
+// relationship is how the XML relates to the visible invoice: MINIMUM is
+// not a full invoice, so it is only supporting data.
+func (p FacturXProfile) relationship() string {
+	if p == FacturXMinimum {
+		return "Data"
+	}
+	return "Alternative"
+}
+
+// Namespaces of UN/CEFACT Cross Industry Invoices.
+const (
+	ciiRSMNamespace = "urn:un:unece:uncefact:data:standard:CrossIndustryInvoice:100"
+	ciiRAMNamespace = "urn:un:unece:uncefact:data:standard:ReusableAggregateBusinessInformationEntity:100"
+	ciiUDTNamespace = "urn:un:unece:uncefact:data:standard:UnqualifiedDataType:100"
+)
+
+// The CII document, with the elements Factur-X profiles up to EN 16931
+// use, in the order the schema requires them. Elements outside MINIMUM are
+// pointers or omitted when empty.
+type ciiInvoice struct {
+	XMLName      xml.Name       `xml:"rsm:CrossIndustryInvoice"`
+	RSMNamespace string         `xml:"xmlns:rsm,attr"`
+	RAMNamespace string         `xml:"xmlns:ram,attr"`
+	UDTNamespace string         `xml:"xmlns:udt,attr"`
+	GuidelineID  string         `xml:"rsm:ExchangedDocumentContext>ram:GuidelineSpecifiedDocumentContextParameter>ram:ID"`
+	Document     ciiDocument    `xml:"rsm:ExchangedDocument"`
+	Transaction  ciiTransaction `xml:"rsm:SupplyChainTradeTransaction"`
+}
+
+type ciiDocument struct {
+	ID        string  `xml:"ram:ID"`
+	TypeCode  string  `xml:"ram:TypeCode"`
+	IssueDate ciiDate `xml:"ram:IssueDateTime"`
+}
+
+type ciiDate struct {
+	Value ciiDateString `xml:"udt:DateTimeString"`
+}
+
+type ciiDateString struct {
+	Format string `xml:"format,attr"`
+	Value  string `xml:",chardata"`
+}
+
+type ciiTransaction struct {
+	Lines      []ciiLine     `xml:"ram:IncludedSupplyChainTradeLineItem"`
+	Agreement  ciiAgreement  `xml:"ram:ApplicableHeaderTradeAgreement"`
+	Delivery   struct{}      `xml:"ram:ApplicableHeaderTradeDelivery"`
+	Settlement ciiSettlement `xml:"ram:ApplicableHeaderTradeSettlement"`
+}
+
+type ciiLine struct {
+	LineID     string            `xml:"ram:AssociatedDocumentLineDocument>ram:LineID"`
+	Name       string            `xml:"ram:SpecifiedTradeProduct>ram:Name"`
+	NetPrice   string            `xml:"ram:SpecifiedLineTradeAgreement>ram:NetPriceProductTradePrice>ram:ChargeAmount"`
+	Quantity   ublQuantity       `xml:"ram:SpecifiedLineTradeDelivery>ram:BilledQuantity"`
+	Settlement ciiLineSettlement `xml:"ram:SpecifiedLineTradeSettlement"`
+}
+
+type ciiLineSettlement struct {
+	Tax              ciiTax               `xml:"ram:ApplicableTradeTax"`
+	AllowanceCharges []ciiAllowanceCharge `xml:"ram:SpecifiedTradeAllowanceCharge"`
+	Total            string               `xml:"ram:SpecifiedTradeSettlementLineMonetarySummation>ram:LineTotalAmount"`
+}
+
+type ciiTax struct {
+	CalculatedAmount string `xml:"ram:CalculatedAmount,omitempty"`
+	TypeCode         string `xml:"ram:TypeCode"`
+	BasisAmount      string `xml:"ram:BasisAmount,omitempty"`
+	CategoryCode     string `xml:"ram:CategoryCode"`
+	Percent          string `xml:"ram:RateApplicablePercent"`
+}
+
+type ciiAllowanceCharge struct {
+	ChargeIndicator bool    `xml:"ram:ChargeIndicator>udt:Indicator"`
+	Amount          string  `xml:"ram:ActualAmount"`
+	ReasonCode      string  `xml:"ram:ReasonCode"`
+	Reason          string  `xml:"ram:Reason"`
+	Tax             *ciiTax `xml:"ram:CategoryTradeTax"`
+}
+
+type ciiAgreement struct {
+	BuyerReference string   `xml:"ram:BuyerReference,omitempty"`
+	Seller         ciiParty `xml:"ram:SellerTradeParty"`
+	Buyer          ciiParty `xml:"ram:BuyerTradeParty"`
+}
+
+type ciiParty struct {
+	Name            string      `xml:"ram:Name"`
+	Address         *ciiAddress `xml:"ram:PostalTradeAddress"`
+	URI             *ublID      `xml:"ram:URIUniversalCommunication>ram:URIID"`
+	TaxRegistration *ublID      `xml:"ram:SpecifiedTaxRegistration>ram:ID"`
+}
+
+type ciiAddress struct {
+	PostalCode string `xml:"ram:PostcodeCode,omitempty"`
+	Street     string `xml:"ram:LineOne,omitempty"`
+	City       string `xml:"ram:CityName,omitempty"`
+	Country    string `xml:"ram:CountryID"`
+}
+
+type ciiSettlement struct {
+	PaymentReference string               `xml:"ram:PaymentReference,omitempty"`
+	Currency         string               `xml:"ram:InvoiceCurrencyCode"`
+	PaymentMeans     *ciiPaymentMeans     `xml:"ram:SpecifiedTradeSettlementPaymentMeans"`
+	Taxes            []ciiTax             `xml:"ram:ApplicableTradeTax"`
+	Period           *ciiPeriod           `xml:"ram:BillingSpecifiedPeriod"`
+	AllowanceCharges []ciiAllowanceCharge `xml:"ram:SpecifiedTradeAllowanceCharge"`
+	DueDate          *ciiDate             `xml:"ram:SpecifiedTradePaymentTerms>ram:DueDateDateTime"`
+	Totals           ciiTotals            `xml:"ram:SpecifiedTradeSettlementHeaderMonetarySummation"`
+}
+
+type ciiPaymentMeans struct {
+	TypeCode string     `xml:"ram:TypeCode"`
+	Account  ciiAccount `xml:"ram:PayeePartyCreditorFinancialAccount"`
+}
+
+type ciiAccount struct {
+	IBAN          string `xml:"ram:IBANID,omitempty"`
+	ProprietaryID string `xml:"ram:ProprietaryID,omitempty"`
+}
+
+type ciiPeriod struct {
+	Start ciiDate `xml:"ram:StartDateTime"`
+	End   ciiDate `xml:"ram:EndDateTime"`
+}
+
+type ciiTotals struct {
+	LineTotal      string    `xml:"ram:LineTotalAmount,omitempty"`
+	AllowanceTotal string    `xml:"ram:AllowanceTotalAmount,omitempty"`
+	TaxBasisTotal  string    `xml:"ram:TaxBasisTotalAmount"`
+	TaxTotal       ublAmount `xml:"ram:TaxTotalAmount"`
+	GrandTotal     string    `xml:"ram:GrandTotalAmount"`
+	TotalPrepaid   string    `xml:"ram:TotalPrepaidAmount,omitempty"`
+	DuePayable     string    `xml:"ram:DuePayableAmount"`
+}
+
+// buildFacturX encodes invoice as CII XML in profile. The document is
+// mapped from the Peppol one, so it needs the same details and both
+// e-invoices agree to the cent.
+func buildFacturX(invoice *models.Invoice, client *models.Client, cfg *config.Config, profile FacturXProfile) ([]byte, error) {
+	doc, err := buildUBL(invoice, client, cfg)
+	if err != nil {
+		return nil, err
+	}
+	data, err := xml.MarshalIndent(ciiFromUBL(doc, profile), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode Factur-X XML: %w", err)
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// ciiFromUBL maps a Peppol document onto CII, leaving out what profile
+// does not allow.
+func ciiFromUBL(doc *ublInvoice, profile FacturXProfile) *ciiInvoice {
+	full := profile != FacturXMinimum
+	// UBL dates are YYYY-MM-DD and CII ones YYYYMMDD
+	date := func(d string) ciiDate {
+		return ciiDate{Value: ciiDateString{Format: "102", Value: strings.ReplaceAll(d, "-", "")}}
+	}
+	party := func(p ublParty, minimum ciiParty) ciiParty {
+		if !full {
+			return minimum
+		}
+		minimum.Address = &ciiAddress{
+			PostalCode: p.Address.PostalZone,
+			Street:     p.Address.Street,
+			City:       p.Address.City,
+			Country:    p.Address.Country,
+		}
+		endpoint := p.EndpointID
+		minimum.URI = &endpoint
+		if p.TaxScheme != nil {
+			minimum.TaxRegistration = &ublID{Scheme: "VA", Value: p.TaxScheme.CompanyID}
+		}
+		return minimum
+	}
+
+	// MINIMUM names the seller with its country and VAT ID, and only names
+	// the buyer
+	seller := ciiParty{
+		Name:            doc.Supplier.Name,
+		Address:         &ciiAddress{Country: doc.Supplier.Address.Country},
+		TaxRegistration: &ublID{Scheme: "VA", Value: doc.Supplier.TaxScheme.CompanyID},
+	}
+	totals := doc.MonetaryTotal
+	cii := &ciiInvoice{
+		RSMNamespace: ciiRSMNamespace,
+		RAMNamespace: ciiRAMNamespace,
+		UDTNamespace: ciiUDTNamespace,
+		GuidelineID:  profile.guideline(),
+		Document: ciiDocument{
+			ID:        doc.ID,
+			TypeCode:  doc.InvoiceTypeCode,
+			IssueDate: date(doc.IssueDate),
+		},
+		Transaction: ciiTransaction{
+			Agreement: ciiAgreement{
+				BuyerReference: doc.BuyerReference,
+				Seller:         party(doc.Supplier, seller),
+				Buyer:          party(doc.Customer, ciiParty{Name: doc.Customer.Name}),
+			},
+			Settlement: ciiSettlement{
+				Currency: doc.DocumentCurrencyCode,
+				Totals: ciiTotals{
+					TaxBasisTotal: totals.TaxExclusiveAmount.Value,
+					TaxTotal:      doc.TaxTotal.TaxAmount,
+					GrandTotal:    totals.TaxInclusiveAmount.Value,
+					DuePayable:    totals.PayableAmount.Value,
+				},
+			},
+		},
+	}
+	if !full {
+		return cii
+	}
+
+	ciiTaxOf := func(c ublTaxCategory) *ciiTax {
+		return &ciiTax{TypeCode: c.TaxScheme, CategoryCode: c.ID, Percent: c.Percent}
+	}
+	allowances := func(charges []ublAllowanceCharge) []ciiAllowanceCharge {
+		var out []ciiAllowanceCharge
+		for _, c := range charges {
+			a := ciiAllowanceCharge{
+				ChargeIndicator: c.ChargeIndicator,
+				Amount:          c.Amount.Value,
+				ReasonCode:      c.ReasonCode,
+				Reason:          c.Reason,
+			}
+			if c.TaxCategory != nil {
+				a.Tax = ciiTaxOf(*c.TaxCategory)
+			}
+			out = append(out, a)
+		}
+		return out
+	}
+
+	transaction := &cii.Transaction
+	for _, l := range doc.Lines {
+		transaction.Lines = append(transaction.Lines, ciiLine{
+			LineID:   l.ID,
+			Name:     l.Item.Name,
+			NetPrice: l.Price.Value,
+			Quantity: l.Quantity,
+			Settlement: ciiLineSettlement{
+				Tax:              *ciiTaxOf(l.Item.TaxCategory),
+				AllowanceCharges: allowances(l.AllowanceCharges),
+				Total:            l.LineExtensionAmount.Value,
+			},
+		})
+	}
+
+	settlement := &transaction.Settlement
+	if means := doc.PaymentMeans; means != nil {
+		settlement.PaymentReference = means.PaymentID
+		settlement.PaymentMeans = &ciiPaymentMeans{TypeCode: means.Code}
+		if means.Code == "58" {
+			settlement.PaymentMeans.Account.IBAN = means.Account.ID
+		} else {
+			settlement.PaymentMeans.Account.ProprietaryID = means.Account.ID
+		}
+	}
+	for _, s := range doc.TaxTotal.Subtotals {
+		tax := ciiTaxOf(s.Category)
+		tax.CalculatedAmount = s.TaxAmount.Value
+		tax.BasisAmount = s.TaxableAmount.Value
+		settlement.Taxes = append(settlement.Taxes, *tax)
+	}
+	if doc.InvoicePeriod != nil {
+		settlement.Period = &ciiPeriod{Start: date(doc.InvoicePeriod.StartDate), End: date(doc.InvoicePeriod.EndDate)}
+	}
+	settlement.AllowanceCharges = allowances(doc.AllowanceCharges)
+	dueDate := date(doc.DueDate)
+	settlement.DueDate = &dueDate
+
+	settlement.Totals.LineTotal = totals.LineExtensionAmount.Value
+	if totals.AllowanceTotalAmount != nil {
+		settlement.Totals.AllowanceTotal = totals.AllowanceTotalAmount.Value
+	}
+	if totals.PrepaidAmount != nil {
+		settlement.Totals.TotalPrepaid = totals.PrepaidAmount.Value
+	}
+	return cii
+}
+
+// facturXFileName is the name Factur-X readers look for.
+const facturXFileName = "factur-x.xml"
@@ -0,0 +1,367 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/user/invoicer/models"
+)
+
+// Layout of the built-in renderer, in millimetres, following the default
+// invoice.tex: A4 with one inch margins and 12pt text.
+const (
+	goPDFMargin     = 25.4
+	goPDFLineHeight = 6.0
+	goPDFFontSize   = 12.0
+	goPDFFont       = "Helvetica"
+)
+
+// goPDF wraps gofpdf with the translation of UTF-8 text to the encoding of
+// its standard fonts, which covers every supported currency symbol.
+type goPDF struct {
+	*gofpdf.Fpdf
+	tr func(string) string
+}
+
+func (p *goPDF) contentWidth() float64 {
+	width, _ := p.GetPageSize()
+	return width - 2*goPDFMargin
+}
+
+func (p *goPDF) font(style string, size float64) {
+	p.SetFont(goPDFFont, style, size)
+}
+
+// text writes a line of text across the content width, or the given width
+// starting at the current position.
+func (p *goPDF) text(width float64, s, align string) {
+	p.CellFormat(width, goPDFLineHeight, p.tr(s), "", 1, align, false, 0, "")
+}
+
+// paragraph writes s wrapped to the content width, keeping its line breaks.
+func (p *goPDF) paragraph(s string) {
+	p.MultiCell(0, goPDFLineHeight, p.tr(s), "", "L", false)
+}
+
+// rule draws a horizontal line from x across width at the current position.
+func (p *goPDF) rule(x, width, lineWidth float64) {
+	p.SetLineWidth(lineWidth)
+	p.Line(x, p.GetY(), x+width, p.GetY())
+}
+
+// fits reports whether height more millimetres fit above the bottom margin.
+func (p *goPDF) fits(height float64) bool {
+	_, pageHeight := p.GetPageSize()
+	return p.GetY()+height <= pageHeight-goPDFMargin
+}
+
+// renderInvoiceGo draws the invoice in data in Go, with the layout of the
+// default invoice.tex, saving baseName.pdf in exportPath dated created.
+// Image receipts get a page each after the invoice. The pages of PDF
+// receipts cannot be copied without LaTeX, so they are attached to the file
+// instead.
+func renderInvoiceGo(data InvoiceTemplateData, exportPath, baseName string, receipts []Receipt, created time.Time) error {
+	if err := os.MkdirAll(exportPath, 0755); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	invoice := data.Invoice
+	f := gofpdf.New("P", "mm", "A4", "")
+	p := &goPDF{Fpdf: f, tr: f.UnicodeTranslatorFromDescriptor("")}
+	p.SetMargins(goPDFMargin, goPDFMargin, goPDFMargin)
+	p.SetAutoPageBreak(true, goPDFMargin)
+	p.SetTitle("Invoice "+invoice.Number, true)
+	p.SetAuthor(data.FromName, true)
+	p.SetCreator("invoicer", true)
+	// Fixed dates and sorted resources make the same invoice give the same
+	// file
+	p.SetCreationDate(created)
+	p.SetModificationDate(created)
+	p.SetCatalogSort(true)
+
+	p.SetHeaderFuncMode(func() {
+		p.SetY(goPDFMargin - 12)
+		p.font("", 10)
+		half := p.contentWidth() / 2
+		p.CellFormat(half, 5, p.tr(data.FromName), "", 0, "L", false, 0, "")
+		p.CellFormat(half, 5, p.tr("Invoice #"+invoice.Number), "", 1, "R", false, 0, "")
+		p.rule(goPDFMargin, p.contentWidth(), 0.15)
+	}, true)
+
+	p.AddPage()
+	p.font("B", 25)
+	p.CellFormat(0, 12, "Invoice", "", 1, "C", false, 0, "")
+	p.Ln(8)
+
+	p.font("B", goPDFFontSize)
+	p.text(0, "From:", "L")
+	p.font("", goPDFFontSize)
+	p.paragraph(strings.Join(nonEmpty(data.FromName, data.FromAddress, data.FromEmail), "\n"))
+	p.Ln(3)
+
+	p.font("B", goPDFFontSize)
+	p.text(0, "To:", "L")
+	p.font("", goPDFFontSize)
+	p.paragraph(strings.Join(nonEmpty(append([]string{invoice.ClientName, data.ClientAddress}, data.ClientEmails...)...), "\n"))
+	p.Ln(3)
+
+	details := [][2]string{
+		{"Invoice Number:", invoice.Number},
+		{"Date:", data.InvoiceDate},
+		{"Due Date:", data.DueDate},
+	}
+	if data.ServicePeriod != "" {
+		details = append(details, [2]string{"Service Period:", data.ServicePeriod})
+	}
+	for _, d := range details {
+		p.font("B", goPDFFontSize)
+		label := p.tr(d[0]) + " "
+		p.CellFormat(p.GetStringWidth(label), goPDFLineHeight, label, "", 0, "L", false, 0, "")
+		p.font("", goPDFFontSize)
+		p.text(0, d[1], "L")
+	}
+	p.Ln(8)
+
+	p.lineItems(data)
+	p.Ln(5)
+	p.summary(data)
+	p.Ln(8)
+
+	p.font("B", goPDFFontSize)
+	p.text(0, "Payment Instructions:", "L")
+	p.font("", goPDFFontSize)
+	if len(data.PaymentMethods) == 0 {
+		p.paragraph("Please make payment to the account details provided separately.")
+	} else {
+		p.paragraph("Please send payment using one of the following methods:")
+		for _, method := range data.PaymentMethods {
+			p.font("B", goPDFFontSize)
+			label := p.tr(method.Type + ": ")
+			p.CellFormat(p.GetStringWidth(label), goPDFLineHeight, label, "", 0, "L", false, 0, "")
+			p.font("", goPDFFontSize)
+			p.paragraph(method.Details)
+		}
+	}
+
+	// At the foot of the last page, like \vfill in the template
+	if !p.fits(2 * goPDFLineHeight) {
+		p.AddPage()
+	}
+	_, pageHeight := p.GetPageSize()
+	p.SetY(pageHeight - goPDFMargin - goPDFLineHeight)
+	p.font("I", goPDFFontSize)
+	p.text(0, "Thank you for your business!", "C")
+
+	if err := p.receipts(receipts); err != nil {
+		return err
+	}
+
+	finalPDF := filepath.Join(exportPath, baseName+".pdf")
+	if err := p.OutputFileAndClose(finalPDF); err != nil {
+		return fmt.Errorf("failed to write PDF: %w", err)
+	}
+	return nil
+}
+
+// lineItems draws the table of line items with shaded alternate rows,
+// repeating the header on every page it runs onto.
+func (p *goPDF) lineItems(data InvoiceTemplateData) {
+	invoice := data.Invoice
+	currency := invoice.Currency
+	widths := []float64{0, 24, 32, 32}
+	widths[0] = p.contentWidth() - widths[1] - widths[2] - widths[3]
+	aligns := []string{"L", "R", "R", "R"}
+	const pad = 1.5
+
+	header := func() {
+		p.rule(goPDFMargin, p.contentWidth(), 0.3)
+		p.font("B", goPDFFontSize)
+		p.SetX(goPDFMargin + pad)
+		for i, title := range []string{"Description", "Quantity", "Unit Price", "Amount"} {
+			p.CellFormat(widths[i]-2*pad, goPDFLineHeight+2, title, "", 0, aligns[i], false, 0, "")
+			p.SetX(p.GetX() + 2*pad)
+		}
+		p.Ln(-1)
+		p.rule(goPDFMargin, p.contentWidth(), 0.15)
+	}
+	header()
+
+	p.font("", goPDFFontSize)
+	p.SetFillColor(242, 242, 242)
+	for i, item := range invoice.LineItems {
+		description := item.Description
+		if item.HasDiscount() {
+			description += fmt.Sprintf(" (less %s)", currency.Format(item.Discount()))
+		}
+		if len(item.Taxes) > 0 {
+			description += fmt.Sprintf(" (%s)", models.TaxNames(item.Taxes))
+		}
+		// SplitLines, unlike SplitText, works on the translated single-byte text
+		lines := p.SplitLines([]byte(p.tr(description)), widths[0]-2*pad)
+		height := float64(len(lines))*goPDFLineHeight + 2
+
+		if !p.fits(height) {
+			p.rule(goPDFMargin, p.contentWidth(), 0.3)
+			p.AddPage()
+			header()
+			p.font("", goPDFFontSize)
+		}
+
+		x, y := p.GetX(), p.GetY()
+		if i%2 == 1 {
+			p.Rect(x, y, p.contentWidth(), height, "F")
+		}
+		for j, line := range lines {
+			p.SetXY(x+pad, y+1+float64(j)*goPDFLineHeight)
+			p.CellFormat(widths[0]-2*pad, goPDFLineHeight, string(line), "", 0, "L", false, 0, "")
+		}
+		cells := []string{models.FormatQuantity(item.Quantity), currency.Format(item.UnitPrice), currency.Format(item.Total)}
+		p.SetXY(x+widths[0]+pad, y+1)
+		for j, cell := range cells {
+			p.CellFormat(widths[j+1]-2*pad, goPDFLineHeight, p.tr(cell), "", 0, aligns[j+1], false, 0, "")
+			p.SetX(p.GetX() + 2*pad)
+		}
+		p.SetXY(x, y+height)
+	}
+	p.rule(goPDFMargin, p.contentWidth(), 0.3)
+}
+
+// summary draws the totals flush right, as the template's summary table.
+func (p *goPDF) summary(data InvoiceTemplateData) {
+	invoice := data.Invoice
+	currency := invoice.Currency
+	type row struct {
+		label, amount string
+		bold          bool
+	}
+	rows := []row{{"Subtotal:", currency.Format(invoice.Subtotal), false}}
+	if data.HasDiscount {
+		var parts []string
+		if invoice.DiscountRate.IsPositive() {
+			parts = append(parts, models.FormatPercent(invoice.DiscountRate)+"%")
+		}
+		if invoice.FixedDiscount.IsPositive() {
+			parts = append(parts, currency.Format(invoice.FixedDiscount))
+		}
+		rows = append(rows, row{fmt.Sprintf("Discount (%s):", strings.Join(parts, " + ")), currency.Format(invoice.Discount.Neg()), false})
+	}
+	for _, t := range data.Taxes {
+		label := fmt.Sprintf("%s (%s%%", t.Name, models.FormatPercent(t.Rate))
+		if t.Inclusive {
+			label += ", incl."
+		}
+		rows = append(rows, row{label + "):", currency.Format(t.Amount), false})
+	}
+	rows = append(rows, row{})
+	if data.HasPayments || data.HasCredits {
+		rows = append(rows, row{"Total:", currency.Format(invoice.Total), false})
+		if data.HasCredits {
+			rows = append(rows, row{"Credited:", currency.Format(invoice.AmountCredited.Neg()), false})
+		}
+		if data.HasPayments {
+			rows = append(rows, row{"Amount Paid:", currency.Format(invoice.AmountPaid.Neg()), false})
+		}
+		rows = append(rows, row{})
+		rows = append(rows, row{"Balance Due:", currency.Format(invoice.BalanceDue()), true})
+	} else {
+		rows = append(rows, row{"Total Due:", currency.Format(invoice.Total), true})
+	}
+
+	// Size the columns to their widest entries
+	labelWidth, amountWidth := 0.0, 0.0
+	for _, r := range rows {
+		p.font("B", goPDFFontSize)
+		labelWidth = max(labelWidth, p.GetStringWidth(p.tr(r.label)))
+		amountWidth = max(amountWidth, p.GetStringWidth(p.tr(r.amount)))
+	}
+	labelWidth += 6
+	amountWidth += 2
+	x := goPDFMargin + p.contentWidth() - labelWidth - amountWidth
+
+	if !p.fits(float64(len(rows)) * goPDFLineHeight) {
+		p.AddPage()
+	}
+	for _, r := range rows {
+		if r.label == "" {
+			p.Ln(1)
+			p.rule(x, labelWidth+amountWidth, 0.15)
+			p.Ln(1)
+			continue
+		}
+		p.SetX(x)
+		p.font("B", goPDFFontSize)
+		p.CellFormat(labelWidth, goPDFLineHeight, p.tr(r.label), "", 0, "L", false, 0, "")
+		if !r.bold {
+			p.font("", goPDFFontSize)
+		}
+		p.CellFormat(amountWidth, goPDFLineHeight, p.tr(r.amount), "", 1, "R", false, 0, "")
+	}
+}
+
+// receipts adds a page for each image receipt, scaled to fit under its
+// title, and attaches PDF receipts to the document.
+func (p *goPDF) receipts(receipts []Receipt) error {
+	var attachments []gofpdf.Attachment
+	for i, receipt := range receipts {
+		ext := strings.ToLower(filepath.Ext(receipt.Path))
+		if ext == ".pdf" {
+			content, err := os.ReadFile(receipt.Path)
+			if err != nil {
+				return fmt.Errorf("failed to read receipt %s: %w", receipt.Title, err)
+			}
+			attachments = append(attachments, gofpdf.Attachment{
+				Content:     content,
+				Filename:    fmt.Sprintf("receipt-%d.pdf", i+1),
+				Description: receipt.Title,
+			})
+			continue
+		}
+		if ext != ".png" && ext != ".jpg" && ext != ".jpeg" {
+			return fmt.Errorf("receipt %s is not a PDF, PNG or JPEG file", receipt.Title)
+		}
+
+		p.AddPage()
+		p.font("B", goPDFFontSize)
+		p.paragraph(receipt.Title)
+		p.Ln(4)
+
+		info := p.RegisterImageOptions(receipt.Path, gofpdf.ImageOptions{ImageType: strings.TrimPrefix(ext, "."), ReadDpi: true})
+		if p.Err() {
+			return fmt.Errorf("failed to read receipt %s: %w", receipt.Title, p.Error())
+		}
+		_, pageHeight := p.GetPageSize()
+		maxWidth, maxHeight := p.contentWidth(), pageHeight-goPDFMargin-p.GetY()
+		width, height := info.Extent()
+		scale := min(maxWidth/width, maxHeight/height)
+		width, height = width*scale, height*scale
+		p.ImageOptions(receipt.Path, goPDFMargin+(maxWidth-width)/2, p.GetY(), width, height, false, gofpdf.ImageOptions{}, 0, "")
+	}
+
+	if len(attachments) == 0 {
+		return nil
+	}
+	p.SetAttachments(attachments)
+	p.AddPage()
+	p.font("B", goPDFFontSize)
+	p.text(0, "Receipts", "L")
+	p.font("", goPDFFontSize)
+	p.paragraph("The following receipts are attached to this PDF:")
+	for _, a := range attachments {
+		p.paragraph(fmt.Sprintf("%s: %s", a.Filename, a.Description))
+	}
+	return nil
+}
+
+func nonEmpty(values ...string) []string {
+	var kept []string
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,131 @@
+package export
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/models"
+)
+
+func goInvoice() (*models.Invoice, *models.Client) {
+	client := &models.Client{Name: "Café Müller", Address: "Hauptstraße 1\nBerlin", Emails: []string{"ap@cafe.test"}}
+	invoice := models.NewInvoice("c1", client.Name, "2026-03")
+	for i := range 40 {
+		item := models.NewLineItem("Espresso machine servicing, visit "+string(rune('A'+i%26)), decimal.NewFromInt(1), decimal.NewFromInt(45))
+		invoice.AddLineItem(*item)
+	}
+	invoice.SetTaxRate(decimal.NewFromInt(19))
+	return invoice, client
+}
+
+// writePNG saves a small image receipt.
+func writePNG(t *testing.T, path string) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 40, 60))); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGoRenderer(t *testing.T) {
+	dir := t.TempDir()
+	image := filepath.Join(dir, "taxi.png")
+	writePNG(t, image)
+	pdf := filepath.Join(dir, "hotel.pdf")
+	if err := os.WriteFile(pdf, []byte("%PDF-1.4 hotel bill"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	receipts := []Receipt{{Title: "Taxi", Path: image}, {Title: "Hotel", Path: pdf}}
+
+	invoice, client := goInvoice()
+	cfg := &config.Config{CompanyName: "Example GmbH"}
+	out := filepath.Join(dir, "out")
+	if err := ExportInvoiceToPDF(invoice, client, cfg, out, "", receipts, nil, RendererGo); err != nil {
+		t.Fatal(err)
+	}
+	first, err := os.ReadFile(GetExportPath(invoice, out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(first, []byte("%PDF-")) {
+		t.Fatal("the export is not a PDF")
+	}
+	if !bytes.Contains(first, []byte("/EmbeddedFile")) {
+		t.Error("the PDF receipt is not attached")
+	}
+
+	// The image and the list of attachments get a page each
+	bare := filepath.Join(dir, "bare")
+	if err := ExportInvoiceToPDF(invoice, client, cfg, bare, "", nil, nil, RendererGo); err != nil {
+		t.Fatal(err)
+	}
+	withoutReceipts, err := os.ReadFile(GetExportPath(invoice, bare))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pages := bytes.Count(first, []byte("/Type /Page\n"))
+	invoicePages := bytes.Count(withoutReceipts, []byte("/Type /Page\n"))
+	if invoicePages < 2 || pages != invoicePages+2 {
+		t.Errorf("%d pages with receipts and %d without, want 40 lines over several pages and 2 more with receipts", pages, invoicePages)
+	}
+
+	// The same invoice gives the same file
+	if err := ExportInvoiceToPDF(invoice, client, cfg, out, "", receipts, nil, RendererGo); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := os.ReadFile(GetExportPath(invoice, out)); !bytes.Equal(first, again) {
+		t.Error("exporting twice gave different files")
+	}
+}
+
+func TestGoRendererRefuses(t *testing.T) {
+	dir := t.TempDir()
+	invoice, client := goInvoice()
+
+	notes := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(notes, []byte("lunch"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := ExportInvoiceToPDF(invoice, client, &config.Config{}, dir, "", []Receipt{{Title: "Lunch", Path: notes}}, nil, RendererGo)
+	if err == nil || !strings.Contains(err.Error(), "not a PDF, PNG or JPEG") {
+		t.Errorf("text receipt: %v", err)
+	}
+
+	err = ExportInvoiceToPDF(invoice, client, &config.Config{FacturXProfile: "basic"}, dir, "", nil, nil, RendererGo)
+	if err == nil || !strings.Contains(err.Error(), "need the LaTeX renderer") {
+		t.Errorf("Factur-X with the Go renderer: %v", err)
+	}
+}
+
+func TestResolveRenderer(t *testing.T) {
+	tests := []struct {
+		chosen   Renderer
+		inConfig string
+		want     Renderer
+	}{
+		{RendererGo, "latex", RendererGo},
+		{RendererLaTeX, "go", RendererLaTeX},
+		{RendererAuto, "go", RendererGo},
+		{RendererAuto, " LaTeX ", RendererLaTeX},
+	}
+	for _, tt := range tests {
+		got, err := resolveRenderer(tt.chosen, &config.Config{PDFRenderer: tt.inConfig})
+		if err != nil || got != tt.want {
+			t.Errorf("resolveRenderer(%q, %q) = %q, %v, want %q", tt.chosen, tt.inConfig, got, err, tt.want)
+		}
+	}
+	if _, err := resolveRenderer(RendererAuto, &config.Config{PDFRenderer: "word"}); err == nil {
+		t.Error("an unknown renderer in the config was accepted")
+	}
+}
@@ -0,0 +1,91 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/models"
+)
+
+// htmlFuncs are the functions available to HTML templates, the counterparts
+// of those given to the LaTeX templates without the escaping, which
+// html/template does itself.
+var htmlFuncs = template.FuncMap{
+	"formatDecimal": func(d decimal.Decimal) string {
+		return d.StringFixed(2)
+	},
+	"money": func(c models.CurrencyCode, d decimal.Decimal) string {
+		return c.Format(d)
+	},
+	"taxNames": models.TaxNames,
+	"quantity": models.FormatQuantity,
+	"percent":  models.FormatPercent,
+	// discount describes a document discount, e.g. "10.0% + $200.00"
+	"discount": func(c models.CurrencyCode, rate, fixed decimal.Decimal) string {
+		var parts []string
+		if rate.IsPositive() {
+			parts = append(parts, models.FormatPercent(rate)+"%")
+		}
+		if fixed.IsPositive() {
+			parts = append(parts, c.Format(fixed))
+		}
+		return strings.Join(parts, " + ")
+	},
+	// lines splits a multi-line address so that each line can be
+	// followed by <br>
+	"lines": func(s string) []string {
+		return nonEmpty(strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")...)
+	},
+	// odd picks out alternate table rows, for shading them inline
+	"odd": func(i int) bool {
+		return i%2 == 1
+	},
+}
+
+// ExportInvoiceToHTML renders invoice through the html/template at
+// templatePath, saving it as a single self-contained HTML file in
+// exportPath.
+func ExportInvoiceToHTML(invoice *models.Invoice, client *models.Client, cfg *config.Config, exportPath, templatePath string) error {
+	data := newInvoiceTemplateData(invoice, client, cfg, func(s string) string { return s })
+	return renderHTML(data, templatePath, exportPath, fmt.Sprintf("invoice_%s", invoice.Number))
+}
+
+// renderHTML executes the HTML template at templatePath with data, saving
+// baseName.html in exportPath.
+func renderHTML(data any, templatePath, exportPath, baseName string) error {
+	if err := os.MkdirAll(exportPath, 0755); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	tmplContent, err := os.ReadFile(templatePath)
+	if err != nil {
+		return fmt.Errorf("failed to read template: %w", err)
+	}
+	tmpl, err := template.New(baseName).Funcs(htmlFuncs).Parse(string(tmplContent))
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	// Render fully before writing, so that a template error leaves no
+	// half-written file behind
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	finalHTML := filepath.Join(exportPath, baseName+".html")
+	if err := os.WriteFile(finalHTML, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write HTML: %w", err)
+	}
+	return nil
+}
+
+func GetHTMLExportPath(invoice *models.Invoice, exportPath string) string {
+	return filepath.Join(exportPath, fmt.Sprintf("invoice_%s.html", invoice.Number))
+}
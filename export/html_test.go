@@ -0,0 +1,78 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/models"
+	"github.com/user/invoicer/templates"
+)
+
+// bundledHTMLTemplate copies the bundled invoice.html into a directory of
+// its own.
+func bundledHTMLTemplate(t *testing.T) string {
+	t.Helper()
+	content, err := templates.Read("invoice.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "invoice.html")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestExportInvoiceToHTML(t *testing.T) {
+	client := &models.Client{Name: `Acme <script>alert("x")</script>`, Address: "1 Main St\r\nSpringfield", Emails: []string{"ap@acme.test"}}
+	invoice := models.NewInvoice("c1", client.Name, "2026-04")
+	invoice.AddLineItem(*models.NewLineItem("Design & build", decimal.NewFromInt(2), decimal.RequireFromString("612.50")))
+	invoice.SetDiscountRate(decimal.NewFromInt(10))
+	invoice.SetFixedDiscount(decimal.NewFromInt(25))
+	cfg := &config.Config{CompanyName: "Example LLC", CompanyEmail: "billing@example.test"}
+
+	dir := t.TempDir()
+	if err := ExportInvoiceToHTML(invoice, client, cfg, dir, bundledHTMLTemplate(t)); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(GetHTMLExportPath(invoice, dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(data)
+
+	for _, want := range []string{"2026-04", "Design &amp; build", "1 Main St<br>", "Springfield", "Discount (10.0% &#43; $25.00)", "-$147.50", "$1077.50", "mailto:ap@acme.test"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("the HTML has no %q", want)
+		}
+	}
+	if strings.Contains(html, "<script>") {
+		t.Error("the client name was not escaped")
+	}
+	// Self-contained: nothing is loaded from elsewhere
+	if external := regexp.MustCompile(`(?i)<(link|script|img)\b|url\(`).FindString(html); external != "" {
+		t.Errorf("the HTML loads %s", external)
+	}
+}
+
+func TestHTMLTemplateErrorWritesNothing(t *testing.T) {
+	broken := filepath.Join(t.TempDir(), "broken.html")
+	if err := os.WriteFile(broken, []byte("<p>{{.Invoice.NoSuchField}}</p>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	invoice := models.NewInvoice("c1", "Acme", "2026-05")
+	err := ExportInvoiceToHTML(invoice, &models.Client{Name: "Acme"}, &config.Config{}, dir, broken)
+	if err == nil || !strings.Contains(err.Error(), "failed to execute template") {
+		t.Errorf("ExportInvoiceToHTML() error = %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("files written for a broken template: %v", entries)
+	}
+}
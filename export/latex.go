@@ -0,0 +1,151 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/user/invoicer/config"
+)
+
+// LaTeXEngine is the program that turns the LaTeX templates into PDFs.
+type LaTeXEngine string
+
+const (
+	EnginePDFLaTeX LaTeXEngine = "pdflatex"
+	// EngineXeLaTeX and EngineLuaLaTeX handle any Unicode text and system
+	// fonts
+	EngineXeLaTeX  LaTeXEngine = "xelatex"
+	EngineLuaLaTeX LaTeXEngine = "lualatex"
+	// EngineTectonic and EngineLatexmk decide on reruns themselves
+	EngineTectonic LaTeXEngine = "tectonic"
+	EngineLatexmk  LaTeXEngine = "latexmk"
+)
+
+// ParseLaTeXEngine reads an engine name; "" gives pdflatex.
+func ParseLaTeXEngine(name string) (LaTeXEngine, error) {
+	switch e := LaTeXEngine(strings.ToLower(strings.TrimSpace(name))); e {
+	case "":
+		return EnginePDFLaTeX, nil
+	case EnginePDFLaTeX, EngineXeLaTeX, EngineLuaLaTeX, EngineTectonic, EngineLatexmk:
+		return e, nil
+	}
+	return "", fmt.Errorf("unknown LaTeX engine %q (use pdflatex, xelatex, lualatex, tectonic or latexmk)", name)
+}
+
+// configuredEngine is the engine chosen in cfg.
+func configuredEngine(cfg *config.Config) (LaTeXEngine, error) {
+	engine, err := ParseLaTeXEngine(cfg.LaTeXEngine)
+	if err != nil {
+		return "", fmt.Errorf("invalid latex_engine in config: %w", err)
+	}
+	return engine, nil
+}
+
+// args are the engine's arguments for compiling texFile in the working
+// directory, finding other files in searchPath too.
+func (e LaTeXEngine) args(texFile, searchPath string) []string {
+	switch e {
+	case EngineTectonic:
+		// Tectonic does not read TEXINPUTS
+		return []string{"-Z", "search-path=" + searchPath, texFile}
+	case EngineLatexmk:
+		return []string{"-pdf", "-interaction=nonstopmode", texFile}
+	}
+	return []string{"-interaction=nonstopmode", texFile}
+}
+
+func (e LaTeXEngine) rerunsItself() bool {
+	return e == EngineTectonic || e == EngineLatexmk
+}
+
+// plainObjectsPreamble is the TeX that, put before \documentclass, keeps
+// the engine from compressing the PDF's objects into streams.
+func (e LaTeXEngine) plainObjectsPreamble() string {
+	switch e {
+	case EngineLuaLaTeX:
+		return `\pdfvariable objcompresslevel=0`
+	case EngineXeLaTeX, EngineTectonic:
+		// xdvipdfmx only uses object streams from PDF 1.5 on
+		return `\AtBeginDvi{\special{dvipdfmx:config V 4}}`
+	}
+	return `\pdfobjcompresslevel=0`
+}
+
+// latexOptions are how renderPDF runs LaTeX.
+type latexOptions struct {
+	engine LaTeXEngine
+	// sourceDate is given to the engine as SOURCE_DATE_EPOCH, so that
+	// compiling the same document again gives the same file
+	sourceDate time.Time
+	// plainObjects keeps the PDF's objects uncompressed, so that
+	// embedFacturX can update them
+	plainObjects bool
+}
+
+// newLaTeXOptions runs the engine in cfg, dating the PDF from modified.
+func newLaTeXOptions(cfg *config.Config, modified time.Time) (latexOptions, error) {
+	engine, err := configuredEngine(cfg)
+	if err != nil {
+		return latexOptions{}, err
+	}
+	return latexOptions{engine: engine, sourceDate: sourceDate(modified)}, nil
+}
+
+// sourceDate is when a document last changed, for dating its PDF: the
+// time in SOURCE_DATE_EPOCH if that is set, else modified, else now.
+func sourceDate(modified time.Time) time.Time {
+	if epoch, err := strconv.ParseInt(os.Getenv("SOURCE_DATE_EPOCH"), 10, 64); err == nil {
+		return time.Unix(epoch, 0).UTC()
+	}
+	if modified.IsZero() {
+		return time.Now().UTC()
+	}
+	return modified.UTC().Truncate(time.Second)
+}
+
+// maxLaTeXRuns bounds the passes made for cross-references and page
+// numbers to settle.
+const maxLaTeXRuns = 4
+
+var rerunPattern = regexp.MustCompile(`(?i)rerun to get|label\(s\) may have changed|please rerun|rerun latex`)
+
+// runLaTeX compiles texFile in dir, running the engine again for as long
+// as its log asks for it. Files are also looked up in the current working
+// directory, so that templates can refer to files next to where invoicer
+// runs. The output of the last run is returned.
+func runLaTeX(opts latexOptions, dir, texFile string) ([]byte, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
+	}
+	env := append(os.Environ(),
+		"TEXINPUTS="+cwd+string(os.PathListSeparator)+os.Getenv("TEXINPUTS"),
+		"SOURCE_DATE_EPOCH="+strconv.FormatInt(opts.sourceDate.Unix(), 10),
+		// Date \today and the like from SOURCE_DATE_EPOCH too
+		"FORCE_SOURCE_DATE=1",
+	)
+	logFile := filepath.Join(dir, strings.TrimSuffix(texFile, filepath.Ext(texFile))+".log")
+
+	for run := 1; ; run++ {
+		cmd := exec.Command(string(opts.engine), opts.engine.args(texFile, cwd)...)
+		cmd.Dir = dir
+		cmd.Env = env
+		output, err := cmd.CombinedOutput()
+		if err != nil || opts.engine.rerunsItself() || run == maxLaTeXRuns {
+			return output, err
+		}
+		log, err := os.ReadFile(logFile)
+		if err != nil {
+			log = output
+		}
+		if !rerunPattern.Match(log) {
+			return output, nil
+		}
+	}
+}
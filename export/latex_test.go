@@ -0,0 +1,117 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/models"
+	"github.com/user/invoicer/templates"
+)
+
+func TestParseLaTeXEngine(t *testing.T) {
+	for name, want := range map[string]LaTeXEngine{
+		"":          EnginePDFLaTeX,
+		"pdflatex":  EnginePDFLaTeX,
+		" XeLaTeX ": EngineXeLaTeX,
+		"lualatex":  EngineLuaLaTeX,
+		"tectonic":  EngineTectonic,
+		"latexmk":   EngineLatexmk,
+	} {
+		if got, err := ParseLaTeXEngine(name); err != nil || got != want {
+			t.Errorf("ParseLaTeXEngine(%q) = %q, %v, want %q", name, got, err, want)
+		}
+	}
+	if _, err := configuredEngine(&config.Config{LaTeXEngine: "context"}); err == nil || !strings.Contains(err.Error(), "latex_engine") {
+		t.Errorf("configuredEngine(context) error = %v", err)
+	}
+
+	if args := EngineTectonic.args("doc.tex", "/templates"); !slices.Equal(args, []string{"-Z", "search-path=/templates", "doc.tex"}) {
+		t.Errorf("tectonic args = %q", args)
+	}
+	if args := EngineLatexmk.args("doc.tex", "/templates"); !slices.Equal(args, []string{"-pdf", "-interaction=nonstopmode", "doc.tex"}) {
+		t.Errorf("latexmk args = %q", args)
+	}
+	if !EngineTectonic.rerunsItself() || EngineXeLaTeX.rerunsItself() {
+		t.Error("only tectonic and latexmk rerun themselves")
+	}
+}
+
+// fakeEngine puts a program called engine on an otherwise empty PATH. It
+// records its arguments in args.txt and writes a PDF for the .tex file it
+// is given.
+func fakeEngine(t *testing.T, engine string) (bin string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake engine is a shell script")
+	}
+	bin = t.TempDir()
+	script := "#!/bin/sh\n" +
+		"echo \"$@\" > " + filepath.Join(bin, "args.txt") + "\n" +
+		"for last; do :; done\n" +
+		"printf '%%PDF-1.4 fake' > \"${last%.tex}.pdf\"\n"
+	if err := os.WriteFile(filepath.Join(bin, engine), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+	return bin
+}
+
+func latexInvoice(t *testing.T) (*models.Invoice, *models.Client, string) {
+	t.Helper()
+	content, err := templates.Read("invoice.tex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	templatePath := filepath.Join(t.TempDir(), "invoice.tex")
+	if err := os.WriteFile(templatePath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	invoice, client := goInvoice()
+	return invoice, client, templatePath
+}
+
+func TestConfiguredEngineRuns(t *testing.T) {
+	bin := fakeEngine(t, "xelatex")
+	invoice, client, templatePath := latexInvoice(t)
+	cfg := &config.Config{LaTeXEngine: "xelatex"}
+
+	// The engine is installed, so LaTeX is chosen
+	dir := t.TempDir()
+	if err := ExportInvoiceToPDF(invoice, client, cfg, dir, templatePath, nil, nil, RendererAuto); err != nil {
+		t.Fatal(err)
+	}
+	args, err := os.ReadFile(filepath.Join(bin, "args.txt"))
+	if err != nil {
+		t.Fatal("xelatex was not run")
+	}
+	if got := strings.TrimSpace(string(args)); got != "-interaction=nonstopmode invoice_2026-03.tex" {
+		t.Errorf("xelatex ran with %q", got)
+	}
+	if pdf, err := os.ReadFile(GetExportPath(invoice, dir)); err != nil || string(pdf) != "%PDF-1.4 fake" {
+		t.Errorf("exported %q, %v, want the engine's PDF", pdf, err)
+	}
+}
+
+func TestMissingEngine(t *testing.T) {
+	fakeEngine(t, "pdflatex")
+	invoice, client, templatePath := latexInvoice(t)
+	cfg := &config.Config{LaTeXEngine: "lualatex"}
+
+	// pdflatex being installed does not help when lualatex is configured
+	dir := t.TempDir()
+	err := ExportInvoiceToPDF(invoice, client, cfg, dir, templatePath, nil, nil, RendererLaTeX)
+	if err == nil || !strings.Contains(err.Error(), "lualatex not found in PATH") {
+		t.Errorf("ExportInvoiceToPDF(latex) error = %v", err)
+	}
+	if renderer, err := resolveRenderer(RendererAuto, cfg); err != nil || renderer != RendererGo {
+		t.Errorf("resolveRenderer(auto) = %q, %v, want the built-in renderer", renderer, err)
+	}
+	if err := ExportInvoiceToPDF(invoice, client, cfg, dir, templatePath, nil, nil, RendererAuto); err != nil {
+		t.Errorf("ExportInvoiceToPDF(auto) error = %v", err)
+	}
+}
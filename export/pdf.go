@@ -7,8 +7,10 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/shopspring/decimal"
 	"github.com/user/invoicer/config"
@@ -49,70 +51,352 @@ type InvoiceTemplateData struct {
 	ServicePeriod  string
 	HasDiscount    bool
 	HasTax         bool
+	Taxes          []models.TaxAmount
+	HasPayments    bool
+	HasCredits     bool
 	PaymentMethods []PaymentMethod
+	// Timesheet lists the time entries billed on the invoice, for templates
+	// that show the hours worked; TotalHours adds them up
+	Timesheet  []TimesheetEntry
+	TotalHours decimal.Decimal
 }
 
-func ExportInvoiceToPDF(invoice *models.Invoice, client *models.Client, cfg *config.Config, exportPath, templatePath string) error {
-	// Check if pdflatex is available
-	_, err := exec.LookPath("pdflatex")
+// TimesheetEntry is a time entry billed on an invoice.
+type TimesheetEntry struct {
+	Date        string
+	Project     string
+	Description string
+	Hours       decimal.Decimal
+}
+
+// Receipt is a receipt to append to an exported invoice, after its last
+// page. Path must be a PDF, PNG or JPEG file.
+type Receipt struct {
+	Title string
+	Path  string
+}
+
+// InvoiceReceipts returns the receipts of the expenses billed on the
+// invoice, oldest first.
+func InvoiceReceipts(storage models.Storage, cfg *config.Config, invoiceID string) ([]Receipt, error) {
+	expenses, err := storage.GetAllExpenses()
 	if err != nil {
-		return fmt.Errorf("pdflatex not found in PATH. Please install LaTeX (e.g., TeX Live, MiKTeX) to export PDFs")
+		return nil, err
 	}
+	sort.SliceStable(expenses, func(i, j int) bool {
+		return expenses[i].Date.Before(expenses[j].Date)
+	})
+
+	var receipts []Receipt
+	for _, e := range expenses {
+		if e.InvoiceID != invoiceID || !e.HasReceipt() {
+			continue
+		}
+		receipts = append(receipts, Receipt{
+			Title: fmt.Sprintf("Receipt: %s, %s", e.Label(), e.Date.Format("January 2, 2006")),
+			Path:  filepath.Join(cfg.DataDir(), filepath.FromSlash(e.ReceiptPath)),
+		})
+	}
+	return receipts, nil
+}
 
-	// Ensure export directory exists
-	if err := os.MkdirAll(exportPath, 0755); err != nil {
-		return fmt.Errorf("failed to create export directory: %w", err)
+// InvoiceTimeEntries returns the time entries billed on the invoice, oldest
+// first.
+func InvoiceTimeEntries(storage models.Storage, invoiceID string) ([]models.TimeEntry, error) {
+	entries, err := storage.GetAllTimeEntries()
+	if err != nil {
+		return nil, err
+	}
+	var billed []models.TimeEntry
+	for _, e := range entries {
+		if e.InvoiceID == invoiceID {
+			billed = append(billed, e)
+		}
+	}
+	sort.SliceStable(billed, func(i, j int) bool {
+		return billed[i].Start.Before(billed[j].Start)
+	})
+	return billed, nil
+}
+
+// newTimesheet prepares time entries for rendering, passing their text
+// through escape.
+func newTimesheet(entries []models.TimeEntry, escape func(string) string) ([]TimesheetEntry, decimal.Decimal) {
+	var (
+		timesheet []TimesheetEntry
+		seconds   int64
+	)
+	for _, e := range entries {
+		timesheet = append(timesheet, TimesheetEntry{
+			Date:        e.Start.Format("Jan 2, 2006"),
+			Project:     escape(e.Project),
+			Description: escape(e.Description),
+			Hours:       decimal.NewFromInt(int64(e.Duration() / time.Second)).Div(decimal.NewFromInt(3600)).Round(2),
+		})
+		seconds += int64(e.Duration() / time.Second)
+	}
+	return timesheet, decimal.NewFromInt(seconds).Div(decimal.NewFromInt(3600)).Round(2)
+}
+
+// Renderer selects how invoice PDFs are made. Estimates and credit notes
+// are always rendered with LaTeX.
+type Renderer string
+
+const (
+	// RendererAuto uses the renderer in the config, or LaTeX if the
+	// configured engine is installed and the built-in renderer if not
+	RendererAuto  Renderer = ""
+	RendererLaTeX Renderer = "latex"
+	// RendererGo draws the invoice in Go with the layout of the default
+	// template, ignoring the invoice templates
+	RendererGo Renderer = "go"
+)
+
+// ParseRenderer reads a renderer name; "auto" and "" give RendererAuto.
+func ParseRenderer(name string) (Renderer, error) {
+	switch r := Renderer(strings.ToLower(strings.TrimSpace(name))); r {
+	case "auto", RendererAuto:
+		return RendererAuto, nil
+	case RendererLaTeX, RendererGo:
+		return r, nil
+	}
+	return "", fmt.Errorf("unknown PDF renderer %q (use latex, go or auto)", name)
+}
+
+// resolveRenderer picks the renderer for an export: renderer if one was
+// chosen, else the one in cfg, else LaTeX where its engine is installed.
+func resolveRenderer(renderer Renderer, cfg *config.Config) (Renderer, error) {
+	if renderer == RendererAuto {
+		var err error
+		if renderer, err = ParseRenderer(cfg.PDFRenderer); err != nil {
+			return "", fmt.Errorf("invalid pdf_renderer in config: %w", err)
+		}
+	}
+	if renderer != RendererAuto {
+		return renderer, nil
+	}
+	engine, err := configuredEngine(cfg)
+	if err != nil {
+		return "", err
+	}
+	if _, err := exec.LookPath(string(engine)); err != nil {
+		return RendererGo, nil
+	}
+	return RendererLaTeX, nil
+}
+
+// ExportInvoiceToPDF renders invoice, followed by a page for each of
+// receipts, which may be empty. templatePath and timesheet, the time
+// entries billed on the invoice, are only used by the LaTeX renderer.
+// With a Factur-X profile in cfg the invoice is checked as an e-invoice
+// first and its XML embedded in the PDF, which needs the LaTeX renderer.
+func ExportInvoiceToPDF(invoice *models.Invoice, client *models.Client, cfg *config.Config, exportPath, templatePath string, receipts []Receipt, timesheet []models.TimeEntry, renderer Renderer) error {
+	renderer, err := resolveRenderer(renderer, cfg)
+	if err != nil {
+		return err
+	}
+	profile, err := ParseFacturXProfile(cfg.FacturXProfile)
+	if err != nil {
+		return fmt.Errorf("invalid facturx_profile in config: %w", err)
+	}
+	var facturX []byte
+	if profile != FacturXNone {
+		// PDF/A needs every font embedded, and the built-in renderer uses
+		// the standard fonts that viewers supply
+		if renderer == RendererGo {
+			return fmt.Errorf("Factur-X invoices need the LaTeX renderer, as the built-in renderer does not embed its fonts as PDF/A requires")
+		}
+		if facturX, err = buildFacturX(invoice, client, cfg, profile); err != nil {
+			return err
+		}
+	}
+
+	baseName := fmt.Sprintf("invoice_%s", invoice.Number)
+	created := sourceDate(invoice.UpdatedAt)
+	if renderer == RendererGo {
+		data := newInvoiceTemplateData(invoice, client, cfg, func(s string) string { return s })
+		err = renderInvoiceGo(data, exportPath, baseName, receipts, created)
+	} else {
+		var opts latexOptions
+		if opts, err = newLaTeXOptions(cfg, invoice.UpdatedAt); err != nil {
+			return err
+		}
+		opts.plainObjects = facturX != nil
+		data := newInvoiceTemplateData(invoice, client, cfg, escapeLatex)
+		data.Timesheet, data.TotalHours = newTimesheet(timesheet, escapeLatex)
+		err = renderPDF(data, templatePath, exportPath, baseName, receipts, opts)
+	}
+	if err != nil || facturX == nil {
+		return err
+	}
+
+	finalPDF := GetExportPath(invoice, exportPath)
+	if err := embedFacturX(finalPDF, facturX, profile, pdfADocument{
+		title:   "Invoice " + invoice.Number,
+		author:  cfg.CompanyName,
+		created: created,
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// newInvoiceTemplateData prepares invoice for rendering, passing the text
+// taken from the config and client through escape. Client emails are left
+// for the template to escape.
+func newInvoiceTemplateData(invoice *models.Invoice, client *models.Client, cfg *config.Config, escape func(string) string) InvoiceTemplateData {
+	// Format service period if available
+	servicePeriod := ""
+	if invoice.ServiceStartDate != nil && invoice.ServiceEndDate != nil {
+		servicePeriod = fmt.Sprintf("%s - %s",
+			invoice.ServiceStartDate.Format("January 2, 2006"),
+			invoice.ServiceEndDate.Format("January 2, 2006"))
+	}
+
+	taxes := invoice.TaxBreakdown()
+
+	return InvoiceTemplateData{
+		Invoice:        invoice,
+		FromName:       escape(cfg.CompanyName),
+		FromAddress:    escape(cfg.CompanyAddress),
+		FromEmail:      escape(cfg.CompanyEmail),
+		ClientAddress:  escape(client.Address),
+		ClientEmails:   client.Emails,
+		InvoiceDate:    invoice.Date.Format("January 2, 2006"),
+		DueDate:        invoice.DueDate.Format("January 2, 2006"),
+		ServicePeriod:  servicePeriod,
+		HasDiscount:    !invoice.Discount.IsZero(),
+		HasTax:         len(taxes) > 0,
+		Taxes:          taxes,
+		HasPayments:    invoice.AmountPaid.GreaterThan(decimal.Zero),
+		HasCredits:     invoice.AmountCredited.GreaterThan(decimal.Zero),
+		PaymentMethods: buildPaymentMethods(cfg, escape),
+	}
+}
+
+type EstimateTemplateData struct {
+	Estimate      *models.Estimate
+	FromName      string
+	FromAddress   string
+	FromEmail     string
+	ClientAddress string
+	ClientEmails  []string
+	EstimateDate  string
+	ValidUntil    string
+	Notes         string
+	HasDiscount   bool
+	HasTax        bool
+	Taxes         []models.TaxAmount
+}
+
+func ExportEstimateToPDF(estimate *models.Estimate, client *models.Client, cfg *config.Config, exportPath, templatePath string) error {
+	taxes := estimate.TaxBreakdown()
+	data := EstimateTemplateData{
+		Estimate:      estimate,
+		FromName:      escapeLatex(cfg.CompanyName),
+		FromAddress:   escapeLatex(cfg.CompanyAddress),
+		FromEmail:     escapeLatex(cfg.CompanyEmail),
+		ClientAddress: escapeLatex(client.Address),
+		ClientEmails:  client.Emails,
+		EstimateDate:  estimate.Date.Format("January 2, 2006"),
+		ValidUntil:    estimate.ValidUntil.Format("January 2, 2006"),
+		Notes:         escapeLatex(estimate.Notes),
+		HasDiscount:   !estimate.Discount.IsZero(),
+		HasTax:        len(taxes) > 0,
+		Taxes:         taxes,
+	}
+
+	opts, err := newLaTeXOptions(cfg, estimate.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	return renderPDF(data, templatePath, exportPath, fmt.Sprintf("estimate_%s", estimate.Number), nil, opts)
+}
+
+type CreditNoteTemplateData struct {
+	CreditNote     *models.CreditNote
+	FromName       string
+	FromAddress    string
+	FromEmail      string
+	ClientAddress  string
+	ClientEmails   []string
+	CreditNoteDate string
+	InvoiceDate    string
+	Reason         string
+	HasDiscount    bool
+	HasTax         bool
+	Taxes          []models.TaxAmount
+}
+
+// ExportCreditNoteToPDF renders note; invoice is the invoice it credits and
+// is only used for its date.
+func ExportCreditNoteToPDF(note *models.CreditNote, invoice *models.Invoice, client *models.Client, cfg *config.Config, exportPath, templatePath string) error {
+	taxes := note.TaxBreakdown()
+	data := CreditNoteTemplateData{
+		CreditNote:     note,
+		FromName:       escapeLatex(cfg.CompanyName),
+		FromAddress:    escapeLatex(cfg.CompanyAddress),
+		FromEmail:      escapeLatex(cfg.CompanyEmail),
+		ClientAddress:  escapeLatex(client.Address),
+		ClientEmails:   client.Emails,
+		CreditNoteDate: note.Date.Format("January 2, 2006"),
+		InvoiceDate:    invoice.Date.Format("January 2, 2006"),
+		Reason:         escapeLatex(note.Reason),
+		HasDiscount:    !note.Discount.IsZero(),
+		HasTax:         len(taxes) > 0,
+		Taxes:          taxes,
 	}
 
-	// Log where we're saving the file
-	fmt.Printf("Saving PDF to directory: %s\n", exportPath)
+	// Credit notes never change once issued
+	opts, err := newLaTeXOptions(cfg, note.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return renderPDF(data, templatePath, exportPath, fmt.Sprintf("credit_note_%s", note.Number), nil, opts)
+}
 
-	// Build payment methods from config
+// buildPaymentMethods lists the payment options configured in cfg, with
+// their details passed through escape.
+func buildPaymentMethods(cfg *config.Config, escape func(string) string) []PaymentMethod {
 	var paymentMethods []PaymentMethod
 
 	if cfg.ZelleAccount != "" {
 		paymentMethods = append(paymentMethods, PaymentMethod{
 			Type:    "Zelle",
-			Details: escapeLatex(cfg.ZelleAccount),
+			Details: escape(cfg.ZelleAccount),
 		})
 	}
 
 	if cfg.VenmoAccount != "" {
 		paymentMethods = append(paymentMethods, PaymentMethod{
 			Type:    "Venmo",
-			Details: escapeLatex(cfg.VenmoAccount),
+			Details: escape(cfg.VenmoAccount),
 		})
 	}
 
 	if cfg.BankName != "" && cfg.BankRouting != "" && cfg.BankAccount != "" {
 		paymentMethods = append(paymentMethods, PaymentMethod{
 			Type:    "Bank Wire",
-			Details: escapeLatex(fmt.Sprintf("%s, Routing: %s, Account: %s", cfg.BankName, cfg.BankRouting, cfg.BankAccount)),
+			Details: escape(fmt.Sprintf("%s, Routing: %s, Account: %s", cfg.BankName, cfg.BankRouting, cfg.BankAccount)),
 		})
 	}
 
-	// Format service period if available
-	servicePeriod := ""
-	if invoice.ServiceStartDate != nil && invoice.ServiceEndDate != nil {
-		servicePeriod = fmt.Sprintf("%s - %s",
-			invoice.ServiceStartDate.Format("January 2, 2006"),
-			invoice.ServiceEndDate.Format("January 2, 2006"))
+	return paymentMethods
+}
+
+// renderPDF executes the LaTeX template at templatePath with data and runs
+// the engine in opts on the result, saving baseName.pdf in exportPath. Any
+// receipts are appended after the document's last page.
+func renderPDF(data any, templatePath, exportPath, baseName string, receipts []Receipt, opts latexOptions) error {
+	// Check if the engine is available
+	_, err := exec.LookPath(string(opts.engine))
+	if err != nil {
+		return fmt.Errorf("%s not found in PATH. Please install LaTeX (e.g., TeX Live, MiKTeX) or set latex_engine to export PDFs", opts.engine)
 	}
 
-	// Prepare template data with escaped strings
-	data := InvoiceTemplateData{
-		Invoice:        invoice,
-		FromName:       escapeLatex(cfg.CompanyName),
-		FromAddress:    escapeLatex(cfg.CompanyAddress),
-		FromEmail:      escapeLatex(cfg.CompanyEmail),
-		ClientAddress:  escapeLatex(client.Address),
-		ClientEmails:   client.Emails, // Will handle escaping in template
-		InvoiceDate:    invoice.Date.Format("January 2, 2006"),
-		DueDate:        invoice.DueDate.Format("January 2, 2006"),
-		ServicePeriod:  servicePeriod,
-		HasDiscount:    invoice.DiscountRate.GreaterThan(decimal.Zero),
-		HasTax:         invoice.TaxRate.GreaterThan(decimal.Zero),
-		PaymentMethods: paymentMethods,
+	// Ensure export directory exists
+	if err := os.MkdirAll(exportPath, 0755); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
 	}
 
 	// Read template
@@ -127,9 +411,26 @@ func ExportInvoiceToPDF(invoice *models.Invoice, client *models.Client, cfg *con
 			return d.StringFixed(2)
 		},
 		"escapeLatex": escapeLatex,
+		"money": func(c models.CurrencyCode, d decimal.Decimal) string {
+			return c.FormatLatex(d)
+		},
+		"taxNames": models.TaxNames,
+		"quantity": models.FormatQuantity,
+		"percent":  models.FormatPercent,
+		// discount describes a document discount, e.g. "10.0\% + \$200.00"
+		"discount": func(c models.CurrencyCode, rate, fixed decimal.Decimal) string {
+			var parts []string
+			if rate.IsPositive() {
+				parts = append(parts, models.FormatPercent(rate)+`\%`)
+			}
+			if fixed.IsPositive() {
+				parts = append(parts, c.FormatLatex(fixed))
+			}
+			return strings.Join(parts, " + ")
+		},
 	}
 
-	tmpl, err := template.New("invoice").Funcs(funcMap).Parse(string(tmplContent))
+	tmpl, err := template.New(baseName).Funcs(funcMap).Parse(string(tmplContent))
 	if err != nil {
 		return fmt.Errorf("failed to parse template: %w", err)
 	}
@@ -140,23 +441,47 @@ func ExportInvoiceToPDF(invoice *models.Invoice, client *models.Client, cfg *con
 		return fmt.Errorf("failed to execute template: %w", err)
 	}
 
-	// Create temporary LaTeX file
-	tempDir := os.TempDir()
-	tempFile := filepath.Join(tempDir, fmt.Sprintf("invoice_%s.tex", invoice.Number))
-	if err := os.WriteFile(tempFile, buf.Bytes(), 0644); err != nil {
+	// Compile in a directory of our own, so that concurrent exports and
+	// other users of the temp directory cannot clash over file names
+	tempDir, err := os.MkdirTemp("", "invoicer-latex-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Copy receipts next to the document, so that their file names need no
+	// escaping
+	if len(receipts) > 0 {
+		pages, err := receiptPages(receipts, tempDir)
+		if err != nil {
+			return err
+		}
+		tex, err := appendPages(buf.Bytes(), pages)
+		if err != nil {
+			return err
+		}
+		buf.Reset()
+		buf.Write(tex)
+	}
+	if opts.plainObjects {
+		tex := append([]byte(opts.engine.plainObjectsPreamble()+"\n"), buf.Bytes()...)
+		buf.Reset()
+		buf.Write(tex)
+	}
+
+	texFile := baseName + ".tex"
+	if err := os.WriteFile(filepath.Join(tempDir, texFile), buf.Bytes(), 0644); err != nil {
 		return fmt.Errorf("failed to write temp file: %w", err)
 	}
-	defer os.Remove(tempFile)
 
-	// Run pdflatex with better error handling
-	cmd := exec.Command("pdflatex", "-interaction=nonstopmode", "-output-directory", tempDir, tempFile)
-	output, err := cmd.CombinedOutput()
+	// Run LaTeX with better error handling
+	output, err := runLaTeX(opts, tempDir, texFile)
 	if err != nil {
 		// Save the generated .tex file for debugging
-		debugFile := filepath.Join(exportPath, fmt.Sprintf("debug_invoice_%s.tex", invoice.Number))
-		debugErr := os.WriteFile(debugFile, buf.Bytes(), 0644)
-		if debugErr == nil {
-			fmt.Printf("DEBUG: Saved generated .tex file to: %s\n", debugFile)
+		debugFile := filepath.Join(exportPath, "debug_"+baseName+".tex")
+		debugNote := ""
+		if os.WriteFile(debugFile, buf.Bytes(), 0644) == nil {
+			debugNote = "\nThe generated .tex file was saved to " + debugFile
 		}
 
 		// Parse LaTeX errors from output
@@ -166,37 +491,60 @@ func ExportInvoiceToPDF(invoice *models.Invoice, client *models.Client, cfg *con
 			lines := strings.Split(outputStr, "\n")
 			for i, line := range lines {
 				if strings.Contains(line, "! LaTeX Error:") && i+1 < len(lines) {
-					return fmt.Errorf("LaTeX error: %s\nFull output saved to debug file", lines[i])
+					return fmt.Errorf("LaTeX error: %s%s", lines[i], debugNote)
 				}
 			}
 		}
 
-		return fmt.Errorf("failed to run pdflatex: %w\nOutput excerpt: %.500s", err, outputStr)
+		return fmt.Errorf("failed to run %s: %w\nOutput excerpt: %.500s%s", opts.engine, err, outputStr, debugNote)
 	}
 
 	// Move PDF to exports directory
-	tempPDF := filepath.Join(tempDir, fmt.Sprintf("invoice_%s.pdf", invoice.Number))
-	finalPDF := filepath.Join(exportPath, fmt.Sprintf("invoice_%s.pdf", invoice.Number))
-	defer os.Remove(tempPDF)
-
-	fmt.Printf("Moving PDF from: %s\n", tempPDF)
-	fmt.Printf("Moving PDF to: %s\n", finalPDF)
+	tempPDF := filepath.Join(tempDir, baseName+".pdf")
+	finalPDF := filepath.Join(exportPath, baseName+".pdf")
 
 	// Copy the file
 	if err := copyFile(tempPDF, finalPDF); err != nil {
 		return fmt.Errorf("failed to copy PDF: %w", err)
 	}
+	return nil
+}
 
-	fmt.Printf("Successfully saved PDF to: %s\n", finalPDF)
+// receiptPages copies receipts into dir and returns the LaTeX for the pages
+// showing them. Images get a page each under their title; PDFs are included
+// page by page as they are.
+func receiptPages(receipts []Receipt, dir string) (string, error) {
+	var pages strings.Builder
+	for i, receipt := range receipts {
+		ext := strings.ToLower(filepath.Ext(receipt.Path))
+		if ext != ".pdf" && ext != ".png" && ext != ".jpg" && ext != ".jpeg" {
+			return "", fmt.Errorf("receipt %s is not a PDF, PNG or JPEG file", receipt.Title)
+		}
+		name := fmt.Sprintf("invoicer-receipt-%d%s", i+1, ext)
+		if err := copyFile(receipt.Path, filepath.Join(dir, name)); err != nil {
+			return "", fmt.Errorf("failed to copy receipt %s: %w", receipt.Title, err)
+		}
 
-	// Clean up auxiliary files
-	auxFiles := []string{".aux", ".log", ".out"}
-	for _, ext := range auxFiles {
-		auxFile := filepath.Join(tempDir, fmt.Sprintf("invoice_%s%s", invoice.Number, ext))
-		os.Remove(auxFile)
+		if ext == ".pdf" {
+			fmt.Fprintf(&pages, "\\includepdf[pages=-]{%s}\n", name)
+			continue
+		}
+		fmt.Fprintf(&pages, "\\clearpage\n\\noindent\\textbf{%s}\\par\\vspace{1em}\n", escapeLatex(receipt.Title))
+		fmt.Fprintf(&pages, "\\begin{center}\\includegraphics[width=\\textwidth,height=0.85\\textheight,keepaspectratio]{%s}\\end{center}\n", name)
 	}
+	return pages.String(), nil
+}
 
-	return nil
+// appendPages adds pages to the end of a rendered LaTeX document, loading
+// pdfpages for them.
+func appendPages(tex []byte, pages string) ([]byte, error) {
+	doc := string(tex)
+	begin := strings.Index(doc, `\begin{document}`)
+	end := strings.LastIndex(doc, `\end{document}`)
+	if begin < 0 || end < begin {
+		return nil, fmt.Errorf("template has no document body to append receipts to")
+	}
+	return []byte(doc[:begin] + "\\usepackage{pdfpages}\n" + doc[begin:end] + pages + doc[end:]), nil
 }
 
 func copyFile(src, dst string) error {
@@ -219,3 +567,11 @@ func copyFile(src, dst string) error {
 func GetExportPath(invoice *models.Invoice, exportPath string) string {
 	return filepath.Join(exportPath, fmt.Sprintf("invoice_%s.pdf", invoice.Number))
 }
+
+func GetEstimateExportPath(estimate *models.Estimate, exportPath string) string {
+	return filepath.Join(exportPath, fmt.Sprintf("estimate_%s.pdf", estimate.Number))
+}
+
+func GetCreditNoteExportPath(note *models.CreditNote, exportPath string) string {
+	return filepath.Join(exportPath, fmt.Sprintf("credit_note_%s.pdf", note.Number))
+}
@@ -0,0 +1,637 @@
+package export
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// pdfADocument is what the PDF/A metadata says about a document.
+type pdfADocument struct {
+	title, author string
+	created       time.Time
+}
+
+// pdfEntry is a dictionary entry, its value kept as the PDF text it was
+// read from.
+type pdfEntry struct {
+	key, value string
+}
+
+var (
+	pdfObjectPattern = regexp.MustCompile(`^(\d+)\s+(\d+)\s+obj\s*`)
+	pdfRefPattern    = regexp.MustCompile(`^(\d+)\s+(\d+)\s+R$`)
+	pdfRefTail       = regexp.MustCompile(`^\s+\d+\s+R`)
+	pdfHexPattern    = regexp.MustCompile(`<([0-9A-Fa-f]+)>`)
+)
+
+// embedFacturX turns the PDF at path into a Factur-X hybrid by appending an
+// incremental update that attaches ciiXML as factur-x.xml and adds the XMP
+// metadata and output intent PDF/A-3 asks for. Only PDFs with a classic
+// cross-reference table and uncompressed dictionaries, as the LaTeX
+// renderer writes them, and with every font embedded can be updated.
+func embedFacturX(path string, ciiXML []byte, profile FacturXProfile, doc pdfADocument) error {
+	pdf, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read PDF: %w", err)
+	}
+	updated, err := appendFacturX(pdf, ciiXML, profile, doc)
+	if err != nil {
+		return fmt.Errorf("failed to embed Factur-X XML: %w", err)
+	}
+	if err := os.WriteFile(path, updated, 0644); err != nil {
+		return fmt.Errorf("failed to write PDF: %w", err)
+	}
+	return nil
+}
+
+func appendFacturX(pdf, ciiXML []byte, profile FacturXProfile, doc pdfADocument) ([]byte, error) {
+	xrefOffset, err := lastXrefOffset(pdf)
+	if err != nil {
+		return nil, err
+	}
+	offsets, trailer, err := readXrefTable(pdf, xrefOffset)
+	if err != nil {
+		return nil, err
+	}
+	size, err := strconv.Atoi(dictValue(trailer, "Size"))
+	if err != nil {
+		return nil, fmt.Errorf("trailer has no valid /Size")
+	}
+	rootNum, ok := pdfRef(dictValue(trailer, "Root"))
+	if !ok {
+		return nil, fmt.Errorf("trailer has no /Root")
+	}
+	catalog, err := readObjectDict(pdf, offsets, rootNum)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkFontsEmbedded(pdf, offsets); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.Write(pdf)
+	if !bytes.HasSuffix(pdf, []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+	written := map[int]int{}
+	next := size
+	newObject := func() int {
+		next++
+		return next - 1
+	}
+	writeObject := func(num int, body string) {
+		written[num] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", num, body)
+	}
+	writeStream := func(num int, dict string, data []byte) {
+		written[num] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n<< %s /Length %d >>\nstream\n", num, dict, len(data))
+		buf.Write(data)
+		buf.WriteString("\nendstream\nendobj\n")
+	}
+
+	date := pdfDate(doc.created)
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	zw.Write(ciiXML)
+	zw.Close()
+
+	fileNum := newObject()
+	writeStream(fileNum, fmt.Sprintf("/Type /EmbeddedFile /Subtype /text#2Fxml /Filter /FlateDecode /Params << /Size %d /ModDate %s >>", len(ciiXML), date), compressed.Bytes())
+	specNum := newObject()
+	writeObject(specNum, fmt.Sprintf("<< /Type /Filespec /F (%[1]s) /UF (%[1]s) /Desc (Factur-X invoice) /AFRelationship /%[2]s /EF << /F %[3]d 0 R /UF %[3]d 0 R >> >>",
+		facturXFileName, profile.relationship(), fileNum))
+	metadataNum := newObject()
+	writeStream(metadataNum, "/Type /Metadata /Subtype /XML", facturXMetadata(profile, doc))
+	iccNum := newObject()
+	writeStream(iccNum, "/N 3", sRGBProfile())
+	infoNum := newObject()
+	writeObject(infoNum, fmt.Sprintf("<< /Title %s /Author %s /Producer (invoicer) /CreationDate %s /ModDate %s >>",
+		pdfText(doc.title), pdfText(doc.author), date, date))
+
+	// The attachment goes in the catalog's name tree, which LaTeX may keep
+	// in an object of its own
+	embeddedFiles := fmt.Sprintf("<< /Names [(%s) %d 0 R] >>", facturXFileName, specNum)
+	names := dictValue(catalog, "Names")
+	if num, ok := pdfRef(names); ok {
+		dict, err := readObjectDict(pdf, offsets, num)
+		if err != nil {
+			return nil, err
+		}
+		if hasAttachments(dict) {
+			return nil, fmt.Errorf("the PDF already has attachments")
+		}
+		writeObject(num, formatDict(setDictValue(dict, "EmbeddedFiles", embeddedFiles)))
+	} else {
+		var dict []pdfEntry
+		if names != "" {
+			if dict, _, err = parseDict([]byte(names), 0); err != nil {
+				return nil, err
+			}
+		}
+		if hasAttachments(dict) {
+			return nil, fmt.Errorf("the PDF already has attachments")
+		}
+		catalog = setDictValue(catalog, "Names", formatDict(setDictValue(dict, "EmbeddedFiles", embeddedFiles)))
+	}
+
+	// PDF/A-3 is based on PDF 1.7, whatever the header says
+	catalog = setDictValue(catalog, "Version", "/1.7")
+	catalog = setDictValue(catalog, "Metadata", fmt.Sprintf("%d 0 R", metadataNum))
+	catalog = setDictValue(catalog, "OutputIntents", fmt.Sprintf(
+		"[<< /Type /OutputIntent /S /GTS_PDFA1 /OutputConditionIdentifier (sRGB IEC61966-2.1) /Info (sRGB IEC61966-2.1) /DestOutputProfile %d 0 R >>]", iccNum))
+	catalog = setDictValue(catalog, "AF", fmt.Sprintf("[%d 0 R]", specNum))
+	writeObject(rootNum, formatDict(catalog))
+
+	// The first ID stays the same across updates; the second changes
+	sum := md5.Sum(buf.Bytes())
+	newID := fmt.Sprintf("<%X>", sum)
+	firstID := newID
+	if match := pdfHexPattern.FindString(dictValue(trailer, "ID")); match != "" {
+		firstID = match
+	}
+
+	xrefStart := buf.Len()
+	buf.WriteString("xref\n")
+	nums := make([]int, 0, len(written))
+	for num := range written {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	for i := 0; i < len(nums); {
+		j := i + 1
+		for j < len(nums) && nums[j] == nums[j-1]+1 {
+			j++
+		}
+		fmt.Fprintf(&buf, "%d %d\n", nums[i], j-i)
+		for _, num := range nums[i:j] {
+			fmt.Fprintf(&buf, "%010d 00000 n \n", written[num])
+		}
+		i = j
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R /Prev %d /ID [%s %s] >>\nstartxref\n%d\n%%%%EOF\n",
+		next, rootNum, infoNum, xrefOffset, firstID, newID, xrefStart)
+	return buf.Bytes(), nil
+}
+
+// hasAttachments reports whether the name dictionary names lists embedded
+// files. Some writers give an empty list when there are none.
+func hasAttachments(names []pdfEntry) bool {
+	tree := dictValue(names, "EmbeddedFiles")
+	if tree == "" {
+		return false
+	}
+	entries, _, err := parseDict([]byte(tree), 0)
+	if err != nil {
+		return true
+	}
+	return strings.Trim(dictValue(entries, "Names"), "[] \t\r\n") != "" || dictValue(entries, "Kids") != ""
+}
+
+// checkFontsEmbedded makes sure that the PDF embeds every font it uses, as
+// PDF/A requires. Type 0 fonts are checked through their descendant fonts,
+// which are objects of their own, and Type 3 fonts are drawn by the PDF
+// itself.
+func checkFontsEmbedded(pdf []byte, offsets map[int]int) error {
+	nums := make([]int, 0, len(offsets))
+	for num := range offsets {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	for _, num := range nums {
+		dict, err := readObjectDict(pdf, offsets, num)
+		if err != nil || dictValue(dict, "Type") != "/Font" {
+			// Not a dictionary, or not a font
+			continue
+		}
+		if subtype := dictValue(dict, "Subtype"); subtype == "/Type0" || subtype == "/Type3" {
+			continue
+		}
+		name := strings.TrimPrefix(dictValue(dict, "BaseFont"), "/")
+		descriptorNum, ok := pdfRef(dictValue(dict, "FontDescriptor"))
+		if !ok {
+			return fmt.Errorf("font %s is not embedded, which PDF/A does not allow", name)
+		}
+		descriptor, err := readObjectDict(pdf, offsets, descriptorNum)
+		if err != nil {
+			return err
+		}
+		if dictValue(descriptor, "FontFile") == "" && dictValue(descriptor, "FontFile2") == "" && dictValue(descriptor, "FontFile3") == "" {
+			return fmt.Errorf("font %s is not embedded, which PDF/A does not allow", name)
+		}
+	}
+	return nil
+}
+
+// lastXrefOffset is the offset of the PDF's newest cross-reference section.
+func lastXrefOffset(pdf []byte) (int, error) {
+	i := bytes.LastIndex(pdf, []byte("startxref"))
+	if i < 0 {
+		return 0, fmt.Errorf("not a PDF file")
+	}
+	fields := strings.Fields(string(pdf[i+len("startxref"):]))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("not a PDF file")
+	}
+	offset, err := strconv.Atoi(fields[0])
+	if err != nil || offset < 0 || offset >= len(pdf) {
+		return 0, fmt.Errorf("invalid startxref")
+	}
+	return offset, nil
+}
+
+// readXrefTable reads the classic cross-reference table at offset, giving
+// the offsets of the objects in use and the trailer that follows it.
+func readXrefTable(pdf []byte, offset int) (map[int]int, []pdfEntry, error) {
+	if !bytes.HasPrefix(pdf[offset:], []byte("xref")) {
+		return nil, nil, fmt.Errorf("the PDF has a compressed cross-reference stream")
+	}
+	end := bytes.Index(pdf[offset:], []byte("trailer"))
+	if end < 0 {
+		return nil, nil, fmt.Errorf("the PDF has no trailer")
+	}
+	fields := strings.Fields(string(pdf[offset+len("xref") : offset+end]))
+	offsets := map[int]int{}
+	for i := 0; i+1 < len(fields); {
+		start, err1 := strconv.Atoi(fields[i])
+		count, err2 := strconv.Atoi(fields[i+1])
+		if err1 != nil || err2 != nil || i+2+3*count > len(fields) {
+			return nil, nil, fmt.Errorf("invalid cross-reference table")
+		}
+		for n := 0; n < count; n++ {
+			entry := fields[i+2+3*n:]
+			if entry[2] == "n" {
+				objOffset, err := strconv.Atoi(entry[0])
+				if err != nil {
+					return nil, nil, fmt.Errorf("invalid cross-reference table")
+				}
+				offsets[start+n] = objOffset
+			}
+		}
+		i += 2 + 3*count
+	}
+
+	dictStart := bytes.Index(pdf[offset+end:], []byte("<<"))
+	if dictStart < 0 {
+		return nil, nil, fmt.Errorf("the PDF has no trailer")
+	}
+	trailer, _, err := parseDict(pdf, offset+end+dictStart)
+	if err != nil {
+		return nil, nil, err
+	}
+	return offsets, trailer, nil
+}
+
+// readObjectDict reads the dictionary of object num.
+func readObjectDict(pdf []byte, offsets map[int]int, num int) ([]pdfEntry, error) {
+	offset, ok := offsets[num]
+	if !ok || offset >= len(pdf) {
+		return nil, fmt.Errorf("object %d is not in the newest cross-reference table", num)
+	}
+	match := pdfObjectPattern.FindSubmatch(pdf[offset:])
+	if match == nil || string(match[1]) != strconv.Itoa(num) {
+		return nil, fmt.Errorf("object %d is not where the cross-reference table says", num)
+	}
+	dict, _, err := parseDict(pdf, offset+len(match[0]))
+	if err != nil {
+		return nil, fmt.Errorf("object %d: %w", num, err)
+	}
+	return dict, nil
+}
+
+// parseDict reads the dictionary starting at pos, returning its entries
+// and the position after it.
+func parseDict(b []byte, pos int) ([]pdfEntry, int, error) {
+	if !bytes.HasPrefix(b[pos:], []byte("<<")) {
+		return nil, 0, fmt.Errorf("expected a dictionary")
+	}
+	pos += 2
+	var entries []pdfEntry
+	for {
+		pos = skipSpace(b, pos)
+		if pos >= len(b) {
+			return nil, 0, fmt.Errorf("unterminated dictionary")
+		}
+		if bytes.HasPrefix(b[pos:], []byte(">>")) {
+			return entries, pos + 2, nil
+		}
+		if b[pos] != '/' {
+			return nil, 0, fmt.Errorf("expected a name in dictionary")
+		}
+		keyEnd := skipToken(b, pos+1)
+		key := string(b[pos+1 : keyEnd])
+		valueStart := skipSpace(b, keyEnd)
+		valueEnd, err := skipValue(b, valueStart)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, pdfEntry{key, string(b[valueStart:valueEnd])})
+		pos = valueEnd
+	}
+}
+
+// skipValue returns the position after the value starting at pos.
+func skipValue(b []byte, pos int) (int, error) {
+	if pos >= len(b) {
+		return 0, fmt.Errorf("unexpected end of PDF")
+	}
+	switch {
+	case bytes.HasPrefix(b[pos:], []byte("<<")):
+		_, end, err := parseDict(b, pos)
+		return end, err
+	case b[pos] == '<':
+		end := bytes.IndexByte(b[pos:], '>')
+		if end < 0 {
+			return 0, fmt.Errorf("unterminated hex string")
+		}
+		return pos + end + 1, nil
+	case b[pos] == '[':
+		pos++
+		for {
+			pos = skipSpace(b, pos)
+			if pos >= len(b) {
+				return 0, fmt.Errorf("unterminated array")
+			}
+			if b[pos] == ']' {
+				return pos + 1, nil
+			}
+			var err error
+			if pos, err = skipValue(b, pos); err != nil {
+				return 0, err
+			}
+		}
+	case b[pos] == '(':
+		depth := 0
+		for i := pos; i < len(b); i++ {
+			switch b[i] {
+			case '\\':
+				i++
+			case '(':
+				depth++
+			case ')':
+				if depth--; depth == 0 {
+					return i + 1, nil
+				}
+			}
+		}
+		return 0, fmt.Errorf("unterminated string")
+	case b[pos] == '/':
+		return skipToken(b, pos+1), nil
+	}
+	end := skipToken(b, pos)
+	if end == pos {
+		return 0, fmt.Errorf("unexpected %q in PDF", b[pos])
+	}
+	// An object number may be the start of a reference such as 12 0 R
+	if tail := pdfRefTail.Find(b[end:]); tail != nil {
+		end += len(tail)
+	}
+	return end, nil
+}
+
+func skipSpace(b []byte, pos int) int {
+	for pos < len(b) {
+		switch b[pos] {
+		case ' ', '\t', '\r', '\n', '\f', 0:
+			pos++
+		case '%':
+			for pos < len(b) && b[pos] != '\n' && b[pos] != '\r' {
+				pos++
+			}
+		default:
+			return pos
+		}
+	}
+	return pos
+}
+
+// skipToken returns the position of the next delimiter after pos.
+func skipToken(b []byte, pos int) int {
+	for pos < len(b) && !strings.ContainsRune(" \t\r\n\f\x00()<>[]{}/%", rune(b[pos])) {
+		pos++
+	}
+	return pos
+}
+
+func dictValue(entries []pdfEntry, key string) string {
+	for _, e := range entries {
+		if e.key == key {
+			return e.value
+		}
+	}
+	return ""
+}
+
+func setDictValue(entries []pdfEntry, key, value string) []pdfEntry {
+	for i, e := range entries {
+		if e.key == key {
+			entries[i].value = value
+			return entries
+		}
+	}
+	return append(entries, pdfEntry{key, value})
+}
+
+func formatDict(entries []pdfEntry) string {
+	var s strings.Builder
+	s.WriteString("<<")
+	for _, e := range entries {
+		fmt.Fprintf(&s, " /%s %s", e.key, e.value)
+	}
+	s.WriteString(" >>")
+	return s.String()
+}
+
+// pdfRef reads the number of the object value refers to.
+func pdfRef(value string) (int, bool) {
+	match := pdfRefPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return 0, false
+	}
+	num, err := strconv.Atoi(match[1])
+	return num, err == nil
+}
+
+func pdfDate(t time.Time) string {
+	return "(D:" + t.UTC().Format("20060102150405") + "Z)"
+}
+
+// pdfText encodes s as a PDF text string, in UTF-16 unless it is plain
+// ASCII.
+func pdfText(s string) string {
+	ascii := true
+	for _, r := range s {
+		if r < 0x20 || r > 0x7e {
+			ascii = false
+		}
+	}
+	if ascii {
+		return "(" + strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(s) + ")"
+	}
+	var text strings.Builder
+	text.WriteString("<FEFF")
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&text, "%04X", u)
+	}
+	text.WriteString(">")
+	return text.String()
+}
+
+// facturXMetadata is the XMP metadata of a PDF/A-3 Factur-X document,
+// declaring the fx schema as PDF/A requires of extension schemas.
+func facturXMetadata(profile FacturXProfile, doc pdfADocument) []byte {
+	escape := func(s string) string {
+		var b strings.Builder
+		xml.EscapeText(&b, []byte(s))
+		return b.String()
+	}
+	property := func(name, description string) string {
+		return fmt.Sprintf(`<rdf:li rdf:parseType="Resource"><pdfaProperty:name>%s</pdfaProperty:name><pdfaProperty:valueType>Text</pdfaProperty:valueType><pdfaProperty:category>external</pdfaProperty:category><pdfaProperty:description>%s</pdfaProperty:description></rdf:li>`, name, description)
+	}
+	created := doc.created.UTC().Format(time.RFC3339)
+	return []byte(`<?xpacket begin="` + "\ufeff" + `" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+<rdf:Description rdf:about="" xmlns:pdfaid="http://www.aiim.org/pdfa/ns/id/">
+<pdfaid:part>3</pdfaid:part>
+<pdfaid:conformance>B</pdfaid:conformance>
+</rdf:Description>
+<rdf:Description rdf:about="" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<dc:title><rdf:Alt><rdf:li xml:lang="x-default">` + escape(doc.title) + `</rdf:li></rdf:Alt></dc:title>
+<dc:creator><rdf:Seq><rdf:li>` + escape(doc.author) + `</rdf:li></rdf:Seq></dc:creator>
+</rdf:Description>
+<rdf:Description rdf:about="" xmlns:pdf="http://ns.adobe.com/pdf/1.3/">
+<pdf:Producer>invoicer</pdf:Producer>
+</rdf:Description>
+<rdf:Description rdf:about="" xmlns:xmp="http://ns.adobe.com/xap/1.0/">
+<xmp:CreateDate>` + created + `</xmp:CreateDate>
+<xmp:ModifyDate>` + created + `</xmp:ModifyDate>
+</rdf:Description>
+<rdf:Description rdf:about="" xmlns:pdfaExtension="http://www.aiim.org/pdfa/ns/extension/" xmlns:pdfaSchema="http://www.aiim.org/pdfa/ns/schema#" xmlns:pdfaProperty="http://www.aiim.org/pdfa/ns/property#">
+<pdfaExtension:schemas><rdf:Bag><rdf:li rdf:parseType="Resource">
+<pdfaSchema:schema>Factur-X PDFA Extension Schema</pdfaSchema:schema>
+<pdfaSchema:namespaceURI>urn:factur-x:pdfa:CrossIndustryDocument:invoice:1p0#</pdfaSchema:namespaceURI>
+<pdfaSchema:prefix>fx</pdfaSchema:prefix>
+<pdfaSchema:property><rdf:Seq>
+` + property("DocumentFileName", "The name of the embedded XML document") + `
+` + property("DocumentType", "The type of the hybrid document in capital letters, e.g. INVOICE or ORDER") + `
+` + property("Version", "The actual version of the standard applying to the embedded XML document") + `
+` + property("ConformanceLevel", "The conformance level of the embedded XML document") + `
+</rdf:Seq></pdfaSchema:property>
+</rdf:li></rdf:Bag></pdfaExtension:schemas>
+</rdf:Description>
+<rdf:Description rdf:about="" xmlns:fx="urn:factur-x:pdfa:CrossIndustryDocument:invoice:1p0#">
+<fx:DocumentType>INVOICE</fx:DocumentType>
+<fx:DocumentFileName>` + facturXFileName + `</fx:DocumentFileName>
+<fx:Version>1.0</fx:Version>
+<fx:ConformanceLevel>` + profile.conformanceLevel() + `</fx:ConformanceLevel>
+</rdf:Description>
+</rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="w"?>`)
+}
+
+// sRGBProfile builds an ICC version 2 display profile for sRGB, the output
+// intent PDF/A needs for device colours.
+func sRGBProfile() []byte {
+	s15 := func(v float64) uint32 {
+		return uint32(int32(math.Round(v * 65536)))
+	}
+	xyz := func(x, y, z float64) []byte {
+		b := []byte("XYZ \x00\x00\x00\x00")
+		for _, v := range []float64{x, y, z} {
+			b = binary.BigEndian.AppendUint32(b, s15(v))
+		}
+		return b
+	}
+	desc := func(s string) []byte {
+		b := []byte("desc\x00\x00\x00\x00")
+		b = binary.BigEndian.AppendUint32(b, uint32(len(s)+1))
+		b = append(b, s...)
+		b = append(b, 0)
+		// Empty Unicode and ScriptCode descriptions
+		return append(b, make([]byte, 4+4+2+1+67)...)
+	}
+	text := func(s string) []byte {
+		return append(append([]byte("text\x00\x00\x00\x00"), s...), 0)
+	}
+	// The sRGB transfer function, sampled
+	curve := []byte("curv\x00\x00\x00\x00")
+	const samples = 1024
+	curve = binary.BigEndian.AppendUint32(curve, samples)
+	for i := 0; i < samples; i++ {
+		v := float64(i) / (samples - 1)
+		if v <= 0.04045 {
+			v /= 12.92
+		} else {
+			v = math.Pow((v+0.055)/1.055, 2.4)
+		}
+		curve = binary.BigEndian.AppendUint16(curve, uint16(math.Round(v*65535)))
+	}
+
+	tags := []struct {
+		sig  string
+		data []byte
+	}{
+		{"desc", desc("sRGB IEC61966-2.1")},
+		{"cprt", text("No copyright, use freely")},
+		{"wtpt", xyz(0.9642, 1.0, 0.8249)},
+		{"rXYZ", xyz(0.4361, 0.2225, 0.0139)},
+		{"gXYZ", xyz(0.3851, 0.7169, 0.0971)},
+		{"bXYZ", xyz(0.1431, 0.0606, 0.7141)},
+		{"rTRC", curve},
+		{"gTRC", curve},
+		{"bTRC", curve},
+	}
+
+	var table, data []byte
+	offset := 128 + 4 + 12*len(tags)
+	curveOffset := 0
+	for _, tag := range tags {
+		tagOffset := offset + len(data)
+		// The three curves share their data
+		if tag.sig == "gTRC" || tag.sig == "bTRC" {
+			tagOffset = curveOffset
+		} else {
+			if tag.sig == "rTRC" {
+				curveOffset = tagOffset
+			}
+			data = append(data, tag.data...)
+			for len(data)%4 != 0 {
+				data = append(data, 0)
+			}
+		}
+		table = append(table, tag.sig...)
+		table = binary.BigEndian.AppendUint32(table, uint32(tagOffset))
+		table = binary.BigEndian.AppendUint32(table, uint32(len(tag.data)))
+	}
+
+	header := make([]byte, 128)
+	binary.BigEndian.PutUint32(header[0:], uint32(offset+len(data)))
+	binary.BigEndian.PutUint32(header[8:], 0x02100000)
+	copy(header[12:], "mntrRGB XYZ ")
+	for i, v := range []uint16{1998, 2, 9, 6, 49, 0} {
+		binary.BigEndian.PutUint16(header[24+2*i:], v)
+	}
+	copy(header[36:], "acsp")
+	for i, v := range []float64{0.9642, 1.0, 0.8249} {
+		binary.BigEndian.PutUint32(header[68+4*i:], s15(v))
+	}
+
+	profile := append(header, binary.BigEndian.AppendUint32(nil, uint32(len(tags)))...)
+	profile = append(profile, table...)
+	return append(profile, data...)
+}
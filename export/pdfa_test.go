@@ -0,0 +1,207 @@
+package export
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/models"
+)
+
+// buildPDF writes a PDF with objects numbered from 1 and a classic
+// cross-reference table, the catalog being object 1.
+func buildPDF(objects ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.5\n")
+	offsets := make([]int, len(objects))
+	for i, body := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, body)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /ID [<0123ABCD> <0123ABCD>] >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+// pdfWithFont is a one-page PDF whose font is described by descriptor.
+func pdfWithFont(font string, extra ...string) []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Resources << /Font << /F1 4 0 R >> >> >>",
+		font,
+	}
+	return buildPDF(append(objects, extra...)...)
+}
+
+var testDocument = pdfADocument{
+	title:   "Invoice 2026-01",
+	author:  "Example GmbH",
+	created: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+}
+
+func TestAppendFacturX(t *testing.T) {
+	original := pdfWithFont(
+		"<< /Type /Font /Subtype /Type1 /BaseFont /LMRoman10-Regular /FontDescriptor 5 0 R >>",
+		"<< /Type /FontDescriptor /FontName /LMRoman10-Regular /FontFile 6 0 R >>",
+		"<< /Length 0 >>\nstream\n\nendstream",
+	)
+	xml := []byte(`<?xml version="1.0" encoding="UTF-8"?><rsm:CrossIndustryInvoice/>`)
+
+	updated, err := appendFacturX(original, xml, FacturXEN16931, testDocument)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(updated, original) {
+		t.Fatal("the update does not leave the original PDF in place")
+	}
+
+	xrefOffset, err := lastXrefOffset(updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offsets, trailer, err := readXrefTable(updated, xrefOffset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldXref, _ := lastXrefOffset(original)
+	if got := dictValue(trailer, "Prev"); got != fmt.Sprint(oldXref) {
+		t.Errorf("/Prev = %s, want %d", got, oldXref)
+	}
+	if got := dictValue(trailer, "Size"); got != "12" {
+		t.Errorf("/Size = %s, want 12", got)
+	}
+	if id := dictValue(trailer, "ID"); !strings.HasPrefix(id, "[<0123ABCD> <") || strings.Count(id, "0123ABCD") != 1 {
+		t.Errorf("/ID = %s, want the original first ID and a new second one", id)
+	}
+	// Every object in the new section is where the table says
+	for num := range offsets {
+		if _, err := readObjectDict(updated, offsets, num); err != nil {
+			t.Errorf("object %d: %v", num, err)
+		}
+	}
+
+	catalog, err := readObjectDict(updated, offsets, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := dictValue(catalog, "Pages"); got != "2 0 R" {
+		t.Errorf("/Pages = %q, want it kept", got)
+	}
+	if got := dictValue(catalog, "Version"); got != "/1.7" {
+		t.Errorf("/Version = %q, want /1.7", got)
+	}
+	for _, key := range []string{"Metadata", "OutputIntents", "AF"} {
+		if dictValue(catalog, key) == "" {
+			t.Errorf("catalog has no /%s", key)
+		}
+	}
+
+	// The attachment is named in the catalog and holds the XML
+	names, _, err := parseDict([]byte(dictValue(catalog, "Names")), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, _, err := parseDict([]byte(dictValue(names, "EmbeddedFiles")), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := strings.Fields(strings.Trim(dictValue(tree, "Names"), "[]"))
+	if len(list) != 4 || list[0] != "(factur-x.xml)" {
+		t.Fatalf("EmbeddedFiles names = %v", list)
+	}
+	specNum, _ := pdfRef(strings.Join(list[1:], " "))
+	spec, err := readObjectDict(updated, offsets, specNum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := dictValue(spec, "AFRelationship"); got != "/Alternative" {
+		t.Errorf("/AFRelationship = %s, want /Alternative", got)
+	}
+	ef, _, err := parseDict([]byte(dictValue(spec, "EF")), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileNum, _ := pdfRef(dictValue(ef, "F"))
+	offset := offsets[fileNum]
+	start := bytes.Index(updated[offset:], []byte("stream\n")) + offset + len("stream\n")
+	end := bytes.Index(updated[start:], []byte("\nendstream")) + start
+	zr, err := zlib.NewReader(bytes.NewReader(updated[start:end]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	attached, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(attached, xml) {
+		t.Errorf("attached XML = %q, want %q", attached, xml)
+	}
+
+	// The metadata declares the PDF/A part and the Factur-X profile
+	metadataNum, _ := pdfRef(dictValue(catalog, "Metadata"))
+	metadata := updated[offsets[metadataNum]:]
+	metadata = metadata[:bytes.Index(metadata, []byte("endstream"))]
+	for _, want := range []string{"<pdfaid:part>3</pdfaid:part>", "<fx:ConformanceLevel>EN 16931</fx:ConformanceLevel>", "Invoice 2026-01"} {
+		if !bytes.Contains(metadata, []byte(want)) {
+			t.Errorf("metadata lacks %s", want)
+		}
+	}
+}
+
+func TestAppendFacturXRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		pdf  []byte
+		want string
+	}{
+		{
+			"standard font",
+			pdfWithFont("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>"),
+			"font Helvetica is not embedded",
+		},
+		{
+			"descriptor without a font file",
+			pdfWithFont(
+				"<< /Type /Font /Subtype /TrueType /BaseFont /Arial /FontDescriptor 5 0 R >>",
+				"<< /Type /FontDescriptor /FontName /Arial /Flags 32 >>",
+			),
+			"font Arial is not embedded",
+		},
+		{
+			"existing attachments",
+			buildPDF(
+				"<< /Type /Catalog /Pages 2 0 R /Names << /EmbeddedFiles << /Names [(a.txt) 3 0 R] >> >> >>",
+				"<< /Type /Pages /Kids [] /Count 0 >>",
+				"<< /Type /Filespec /F (a.txt) >>",
+			),
+			"already has attachments",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := appendFacturX(tt.pdf, []byte("<x/>"), FacturXBasic, testDocument)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("appendFacturX() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFacturXNeedsLaTeX(t *testing.T) {
+	cfg := &config.Config{FacturXProfile: "basic", PDFRenderer: "go"}
+	invoice := models.NewInvoice("c1", "Acme", "2026-01")
+	err := ExportInvoiceToPDF(invoice, &models.Client{Name: "Acme"}, cfg, t.TempDir(), "", nil, nil, RendererAuto)
+	if err == nil || !strings.Contains(err.Error(), "need the LaTeX renderer") {
+		t.Errorf("ExportInvoiceToPDF() error = %v, want the built-in renderer refused", err)
+	}
+}
@@ -0,0 +1,140 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/models"
+	"github.com/user/invoicer/templates"
+)
+
+// DefaultInvoiceTemplate is the name of the template in invoice.tex. The
+// other invoice templates are the invoice-<name>.tex files next to it.
+const DefaultInvoiceTemplate = "default"
+
+// TemplateSource says where an invoice template in the templates directory
+// came from.
+type TemplateSource string
+
+const (
+	// TemplateBundled is a template shipped with invoicer, unchanged
+	TemplateBundled TemplateSource = "bundled"
+	// TemplateCustomized is a bundled template that has been edited since
+	TemplateCustomized TemplateSource = "bundled, customized"
+	// TemplateUser is a template invoicer does not ship
+	TemplateUser TemplateSource = "user"
+)
+
+// InvoiceTemplate is a LaTeX invoice template that can be chosen by name.
+type InvoiceTemplate struct {
+	Name   string
+	Path   string
+	Source TemplateSource
+}
+
+var templateNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ParseInvoiceTemplateName reads a template name; "" is kept, for leaving
+// the choice to the client or config.
+func ParseInvoiceTemplateName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name != "" && !templateNamePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid template name %q (use letters, digits, - and _)", name)
+	}
+	return name, nil
+}
+
+// invoiceTemplateFile is the file name of the template called name.
+func invoiceTemplateFile(name string) string {
+	if name == DefaultInvoiceTemplate {
+		return "invoice.tex"
+	}
+	return "invoice-" + name + ".tex"
+}
+
+// InvoiceTemplates lists the invoice templates in the templates directory,
+// the default first and the rest by name.
+func InvoiceTemplates(cfg *config.Config) ([]InvoiceTemplate, error) {
+	paths, err := filepath.Glob(filepath.Join(cfg.TemplatesDir(), "invoice-*.tex"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	names := []string{}
+	for _, path := range paths {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "invoice-"), ".tex")
+		if templateNamePattern.MatchString(name) && name != DefaultInvoiceTemplate {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if _, err := os.Stat(filepath.Join(cfg.TemplatesDir(), "invoice.tex")); err == nil {
+		names = append([]string{DefaultInvoiceTemplate}, names...)
+	}
+
+	list := make([]InvoiceTemplate, 0, len(names))
+	for _, name := range names {
+		path := filepath.Join(cfg.TemplatesDir(), invoiceTemplateFile(name))
+		source, err := templateSource(path)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, InvoiceTemplate{Name: name, Path: path, Source: source})
+	}
+	return list, nil
+}
+
+// templateSource compares the template at path with the bundled one of
+// the same name.
+func templateSource(path string) (TemplateSource, error) {
+	bundled, err := templates.Read(filepath.Base(path))
+	if err != nil {
+		return TemplateUser, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read template: %w", err)
+	}
+	if bytes.Equal(content, bundled) {
+		return TemplateBundled, nil
+	}
+	return TemplateCustomized, nil
+}
+
+// InvoiceTemplateName is the template to render an invoice for client
+// with: chosen if that is set, else the client's, else the one in cfg,
+// else the default. client may be nil.
+func InvoiceTemplateName(cfg *config.Config, client *models.Client, chosen string) string {
+	names := []string{chosen, cfg.InvoiceTemplate}
+	if client != nil {
+		names = []string{chosen, client.InvoiceTemplate, cfg.InvoiceTemplate}
+	}
+	for _, name := range names {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			return name
+		}
+	}
+	return DefaultInvoiceTemplate
+}
+
+// InvoiceTemplatePath is the path of the template called name, which must
+// exist.
+func InvoiceTemplatePath(cfg *config.Config, name string) (string, error) {
+	name, err := ParseInvoiceTemplateName(name)
+	if err != nil {
+		return "", err
+	}
+	if name == "" {
+		name = DefaultInvoiceTemplate
+	}
+	path := filepath.Join(cfg.TemplatesDir(), invoiceTemplateFile(name))
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("unknown invoice template %q: %s not found", name, path)
+	}
+	return path, nil
+}
@@ -0,0 +1,107 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/models"
+)
+
+// newTemplatesConfig sets up a data directory with the bundled templates,
+// from a working directory outside the source tree like an installed
+// invoicer.
+func newTemplatesConfig(t *testing.T) *config.Config {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	cfg := &config.Config{DataPath: t.TempDir()}
+	if err := cfg.EnsureDirectories(); err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestInvoiceTemplatesSources(t *testing.T) {
+	cfg := newTemplatesConfig(t)
+	dir := cfg.TemplatesDir()
+	if err := os.WriteFile(filepath.Join(dir, "invoice-minimal.tex"), []byte(`\documentclass{article}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "invoice-letterhead.tex"), []byte(`\documentclass{letter}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Not a valid template name, so not listed
+	if err := os.WriteFile(filepath.Join(dir, "invoice-Old Copy.tex"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := InvoiceTemplates(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		name   string
+		source TemplateSource
+	}{
+		{"default", TemplateBundled},
+		{"letterhead", TemplateUser},
+		{"minimal", TemplateCustomized},
+		{"timesheet", TemplateBundled},
+	}
+	if len(list) != len(want) {
+		t.Fatalf("got %d templates, want %d: %v", len(list), len(want), list)
+	}
+	for i, w := range want {
+		if list[i].Name != w.name || list[i].Source != w.source {
+			t.Errorf("template %d = %s (%s), want %s (%s)", i, list[i].Name, list[i].Source, w.name, w.source)
+		}
+	}
+	if list[0].Path != filepath.Join(dir, "invoice.tex") {
+		t.Errorf("default template path = %s", list[0].Path)
+	}
+}
+
+func TestInvoiceTemplateName(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		client   *models.Client
+		chosen   string
+		expected string
+	}{
+		{"nothing set", "", nil, "", DefaultInvoiceTemplate},
+		{"config", "minimal", nil, "", "minimal"},
+		{"client over config", "minimal", &models.Client{InvoiceTemplate: "timesheet"}, "", "timesheet"},
+		{"client without a template", "minimal", &models.Client{}, "", "minimal"},
+		{"chosen over client", "minimal", &models.Client{InvoiceTemplate: "timesheet"}, "Default", "default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{InvoiceTemplate: tt.config}
+			if got := InvoiceTemplateName(cfg, tt.client, tt.chosen); got != tt.expected {
+				t.Errorf("InvoiceTemplateName() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestInvoiceTemplatePath(t *testing.T) {
+	cfg := newTemplatesConfig(t)
+
+	path, err := InvoiceTemplatePath(cfg, "")
+	if err != nil || path != filepath.Join(cfg.TemplatesDir(), "invoice.tex") {
+		t.Errorf("InvoiceTemplatePath(\"\") = %q, %v", path, err)
+	}
+	path, err = InvoiceTemplatePath(cfg, " Timesheet ")
+	if err != nil || path != filepath.Join(cfg.TemplatesDir(), "invoice-timesheet.tex") {
+		t.Errorf("InvoiceTemplatePath(Timesheet) = %q, %v", path, err)
+	}
+	if _, err := InvoiceTemplatePath(cfg, "missing"); err == nil || !strings.Contains(err.Error(), `unknown invoice template "missing"`) {
+		t.Errorf("InvoiceTemplatePath(missing) error = %v", err)
+	}
+	if _, err := InvoiceTemplatePath(cfg, "../invoice"); err == nil || !strings.Contains(err.Error(), "invalid template name") {
+		t.Errorf("InvoiceTemplatePath(../invoice) error = %v", err)
+	}
+}
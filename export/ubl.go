@@ -0,0 +1,529 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/models"
+)
+
+// Identifiers of UBL 2.1 invoices following Peppol BIS Billing 3.0.
+const (
+	ublNamespace          = "urn:oasis:names:specification:ubl:schema:xsd:Invoice-2"
+	ublCACNamespace       = "urn:oasis:names:specification:ubl:schema:xsd:CommonAggregateComponents-2"
+	ublCBCNamespace       = "urn:oasis:names:specification:ubl:schema:xsd:CommonBasicComponents-2"
+	peppolCustomizationID = "urn:cen.eu:en16931:2017#compliant#urn:fdc:peppol.eu:2017:poacc:billing:3.0"
+	peppolProfileID       = "urn:fdc:peppol.eu:2017:poacc:billing:01:1.0"
+)
+
+// EInvoiceError lists everything that keeps an invoice from being exported
+// as a Peppol e-invoice, so that it can all be fixed in one go.
+type EInvoiceError struct {
+	Problems []string
+}
+
+func (e *EInvoiceError) Error() string {
+	return "invoice cannot be exported as an e-invoice: " + strings.Join(e.Problems, "; ")
+}
+
+// The UBL document, with only the elements Peppol BIS needs, in the order
+// the schema requires them.
+type ublInvoice struct {
+	XMLName              xml.Name             `xml:"Invoice"`
+	Namespace            string               `xml:"xmlns,attr"`
+	CACNamespace         string               `xml:"xmlns:cac,attr"`
+	CBCNamespace         string               `xml:"xmlns:cbc,attr"`
+	CustomizationID      string               `xml:"cbc:CustomizationID"`
+	ProfileID            string               `xml:"cbc:ProfileID"`
+	ID                   string               `xml:"cbc:ID"`
+	IssueDate            string               `xml:"cbc:IssueDate"`
+	DueDate              string               `xml:"cbc:DueDate"`
+	InvoiceTypeCode      string               `xml:"cbc:InvoiceTypeCode"`
+	DocumentCurrencyCode string               `xml:"cbc:DocumentCurrencyCode"`
+	BuyerReference       string               `xml:"cbc:BuyerReference"`
+	InvoicePeriod        *ublPeriod           `xml:"cac:InvoicePeriod"`
+	Supplier             ublParty             `xml:"cac:AccountingSupplierParty>cac:Party"`
+	Customer             ublParty             `xml:"cac:AccountingCustomerParty>cac:Party"`
+	PaymentMeans         *ublPaymentMeans     `xml:"cac:PaymentMeans"`
+	AllowanceCharges     []ublAllowanceCharge `xml:"cac:AllowanceCharge"`
+	TaxTotal             ublTaxTotal          `xml:"cac:TaxTotal"`
+	MonetaryTotal        ublMonetaryTotal     `xml:"cac:LegalMonetaryTotal"`
+	Lines                []ublLine            `xml:"cac:InvoiceLine"`
+}
+
+type ublPeriod struct {
+	StartDate string `xml:"cbc:StartDate"`
+	EndDate   string `xml:"cbc:EndDate"`
+}
+
+type ublID struct {
+	Scheme string `xml:"schemeID,attr,omitempty"`
+	Value  string `xml:",chardata"`
+}
+
+// Optional elements nested in others are pointers, as encoding/xml would
+// still write the empty parent of an omitted string.
+type ublParty struct {
+	EndpointID ublID              `xml:"cbc:EndpointID"`
+	Name       string             `xml:"cac:PartyName>cbc:Name"`
+	Address    ublAddress         `xml:"cac:PostalAddress"`
+	TaxScheme  *ublPartyTaxScheme `xml:"cac:PartyTaxScheme"`
+	LegalName  string             `xml:"cac:PartyLegalEntity>cbc:RegistrationName"`
+	Email      *string            `xml:"cac:Contact>cbc:ElectronicMail"`
+}
+
+type ublAddress struct {
+	Street     string `xml:"cbc:StreetName,omitempty"`
+	City       string `xml:"cbc:CityName,omitempty"`
+	PostalZone string `xml:"cbc:PostalZone,omitempty"`
+	Country    string `xml:"cac:Country>cbc:IdentificationCode"`
+}
+
+type ublPartyTaxScheme struct {
+	CompanyID string `xml:"cbc:CompanyID"`
+	TaxScheme string `xml:"cac:TaxScheme>cbc:ID"`
+}
+
+type ublPaymentMeans struct {
+	Code      string     `xml:"cbc:PaymentMeansCode"`
+	PaymentID string     `xml:"cbc:PaymentID"`
+	Account   ublAccount `xml:"cac:PayeeFinancialAccount"`
+}
+
+type ublAccount struct {
+	ID     string  `xml:"cbc:ID"`
+	Name   string  `xml:"cbc:Name,omitempty"`
+	Branch *string `xml:"cac:FinancialInstitutionBranch>cbc:ID"`
+}
+
+type ublAmount struct {
+	Currency string `xml:"currencyID,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type ublQuantity struct {
+	UnitCode string `xml:"unitCode,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type ublTaxCategory struct {
+	ID        string `xml:"cbc:ID"`
+	Percent   string `xml:"cbc:Percent"`
+	TaxScheme string `xml:"cac:TaxScheme>cbc:ID"`
+}
+
+type ublAllowanceCharge struct {
+	ChargeIndicator bool            `xml:"cbc:ChargeIndicator"`
+	ReasonCode      string          `xml:"cbc:AllowanceChargeReasonCode"`
+	Reason          string          `xml:"cbc:AllowanceChargeReason"`
+	Amount          ublAmount       `xml:"cbc:Amount"`
+	TaxCategory     *ublTaxCategory `xml:"cac:TaxCategory"`
+}
+
+type ublTaxTotal struct {
+	TaxAmount ublAmount        `xml:"cbc:TaxAmount"`
+	Subtotals []ublTaxSubtotal `xml:"cac:TaxSubtotal"`
+}
+
+type ublTaxSubtotal struct {
+	TaxableAmount ublAmount      `xml:"cbc:TaxableAmount"`
+	TaxAmount     ublAmount      `xml:"cbc:TaxAmount"`
+	Category      ublTaxCategory `xml:"cac:TaxCategory"`
+}
+
+type ublMonetaryTotal struct {
+	LineExtensionAmount  ublAmount  `xml:"cbc:LineExtensionAmount"`
+	TaxExclusiveAmount   ublAmount  `xml:"cbc:TaxExclusiveAmount"`
+	TaxInclusiveAmount   ublAmount  `xml:"cbc:TaxInclusiveAmount"`
+	AllowanceTotalAmount *ublAmount `xml:"cbc:AllowanceTotalAmount"`
+	PrepaidAmount        *ublAmount `xml:"cbc:PrepaidAmount"`
+	PayableAmount        ublAmount  `xml:"cbc:PayableAmount"`
+}
+
+type ublLine struct {
+	ID                  string               `xml:"cbc:ID"`
+	Quantity            ublQuantity          `xml:"cbc:InvoicedQuantity"`
+	LineExtensionAmount ublAmount            `xml:"cbc:LineExtensionAmount"`
+	AllowanceCharges    []ublAllowanceCharge `xml:"cac:AllowanceCharge"`
+	Item                ublItem              `xml:"cac:Item"`
+	Price               ublAmount            `xml:"cac:Price>cbc:PriceAmount"`
+}
+
+type ublItem struct {
+	Name        string         `xml:"cbc:Name"`
+	TaxCategory ublTaxCategory `xml:"cac:ClassifiedTaxCategory"`
+}
+
+// vatGroup is the lines charged at one VAT rate, which Peppol reports
+// together.
+type vatGroup struct {
+	category ublTaxCategory
+	rate     decimal.Decimal
+	total    decimal.Decimal
+}
+
+var (
+	countryCodePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+	peppolIDPattern    = regexp.MustCompile(`^([0-9]{4}):(\S+)$`)
+	ibanPattern        = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{11,30}$`)
+)
+
+// ValidateUBL checks that invoice, client and the seller details in cfg
+// have everything a Peppol e-invoice needs. The error is an
+// *EInvoiceError listing every problem found.
+func ValidateUBL(invoice *models.Invoice, client *models.Client, cfg *config.Config) error {
+	_, err := buildUBL(invoice, client, cfg)
+	return err
+}
+
+// ExportInvoiceToUBL validates invoice and saves it in exportPath as a UBL
+// 2.1 invoice following Peppol BIS Billing 3.0. Nothing is written if
+// validation fails.
+func ExportInvoiceToUBL(invoice *models.Invoice, client *models.Client, cfg *config.Config, exportPath string) error {
+	doc, err := buildUBL(invoice, client, cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(exportPath, 0755); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode e-invoice: %w", err)
+	}
+	data = append([]byte(xml.Header), append(data, '\n')...)
+
+	finalXML := GetUBLExportPath(invoice, exportPath)
+	if err := os.WriteFile(finalXML, data, 0644); err != nil {
+		return fmt.Errorf("failed to write e-invoice: %w", err)
+	}
+	return nil
+}
+
+func GetUBLExportPath(invoice *models.Invoice, exportPath string) string {
+	return filepath.Join(exportPath, fmt.Sprintf("invoice_%s.xml", invoice.Number))
+}
+
+// buildUBL maps the invoice onto a UBL document, collecting the problems
+// that would make it invalid.
+func buildUBL(invoice *models.Invoice, client *models.Client, cfg *config.Config) (*ublInvoice, error) {
+	var problems []string
+	currency := string(invoice.Currency.Code())
+	amount := func(d decimal.Decimal) ublAmount {
+		return ublAmount{Currency: currency, Value: d.StringFixed(2)}
+	}
+	round := func(d decimal.Decimal) decimal.Decimal {
+		return models.CurrentRoundingPolicy().Round(d, invoice.Currency)
+	}
+
+	seller, sellerProblems := ublPartyFor("seller", partyDetails{
+		name:       cfg.CompanyName,
+		vatID:      cfg.CompanyVATID,
+		street:     cfg.CompanyStreet,
+		city:       cfg.CompanyCity,
+		postalCode: cfg.CompanyPostalCode,
+		country:    cfg.CompanyCountry,
+		peppolID:   cfg.CompanyPeppolID,
+		email:      cfg.CompanyEmail,
+	}, true)
+	problems = append(problems, sellerProblems...)
+
+	var buyerEmail string
+	if len(client.Emails) > 0 {
+		buyerEmail = client.Emails[0]
+	}
+	buyer, buyerProblems := ublPartyFor("buyer", partyDetails{
+		name:       client.Name,
+		vatID:      client.VATID,
+		street:     client.Street,
+		city:       client.City,
+		postalCode: client.PostalCode,
+		country:    client.Country,
+		peppolID:   client.PeppolID,
+		email:      buyerEmail,
+	}, false)
+	problems = append(problems, buyerProblems...)
+
+	if len(invoice.LineItems) == 0 {
+		problems = append(problems, "the invoice has no line items")
+	}
+
+	// Peppol allows a single VAT rate per line, so each line must carry
+	// either the invoice's tax rate or one tax of its own
+	var groups []*vatGroup
+	var lines []ublLine
+	for i, item := range invoice.LineItems {
+		rate, problem := lineVATRate(invoice, item)
+		if problem != "" {
+			problems = append(problems, fmt.Sprintf("line %d (%s) %s", i+1, item.Description, problem))
+			continue
+		}
+		if strings.TrimSpace(item.Description) == "" {
+			problems = append(problems, fmt.Sprintf("line %d has no description", i+1))
+		}
+
+		var group *vatGroup
+		for _, g := range groups {
+			if g.rate.Equal(rate) {
+				group = g
+			}
+		}
+		if group == nil {
+			group = &vatGroup{category: vatCategory(rate), rate: rate}
+			groups = append(groups, group)
+		}
+		group.total = group.total.Add(item.Total)
+
+		// Prices cannot be negative, so credits are negative quantities
+		quantity, price := item.Quantity, item.UnitPrice
+		if price.IsNegative() {
+			quantity, price = quantity.Neg(), price.Neg()
+		}
+		line := ublLine{
+			ID:                  fmt.Sprint(i + 1),
+			Quantity:            ublQuantity{UnitCode: "C62", Value: quantity.String()},
+			LineExtensionAmount: amount(item.Total),
+			Item:                ublItem{Name: item.Description, TaxCategory: group.category},
+			// Unit prices may have more decimals than amounts
+			Price: ublAmount{Currency: currency, Value: price.String()},
+		}
+		if item.HasDiscount() {
+			line.AllowanceCharges = []ublAllowanceCharge{{
+				ReasonCode: "95",
+				Reason:     "Discount",
+				Amount:     amount(round(item.Gross()).Sub(item.Total)),
+			}}
+		}
+		lines = append(lines, line)
+	}
+
+	if len(problems) > 0 {
+		return nil, &EInvoiceError{Problems: problems}
+	}
+
+	// The document discount is spread over the VAT rates in proportion to
+	// their lines, as the tax breakdown does
+	var allowances []ublAllowanceCharge
+	remaining := invoice.Discount
+	taxable := make([]decimal.Decimal, len(groups))
+	for i, g := range groups {
+		share := remaining
+		if i < len(groups)-1 && !invoice.Subtotal.IsZero() {
+			share = round(invoice.Discount.Mul(g.total).Div(invoice.Subtotal))
+		}
+		remaining = remaining.Sub(share)
+		taxable[i] = g.total.Sub(share)
+		if share.IsZero() {
+			continue
+		}
+		category := g.category
+		allowances = append(allowances, ublAllowanceCharge{
+			ReasonCode:  "95",
+			Reason:      "Discount",
+			Amount:      amount(share),
+			TaxCategory: &category,
+		})
+	}
+
+	// Tax amounts come from the invoice's own breakdown, so that the
+	// e-invoice totals match the PDF to the cent
+	breakdown := invoice.TaxBreakdown()
+	taxTotal := ublTaxTotal{TaxAmount: amount(invoice.Tax)}
+	for i, g := range groups {
+		tax := decimal.Zero
+		for _, t := range breakdown {
+			if t.Rate.Equal(g.rate) {
+				tax = tax.Add(t.Amount)
+			}
+		}
+		taxTotal.Subtotals = append(taxTotal.Subtotals, ublTaxSubtotal{
+			TaxableAmount: amount(taxable[i]),
+			TaxAmount:     amount(tax),
+			Category:      g.category,
+		})
+	}
+
+	totals := ublMonetaryTotal{
+		LineExtensionAmount: amount(invoice.Subtotal),
+		TaxExclusiveAmount:  amount(invoice.Subtotal.Sub(invoice.Discount)),
+		TaxInclusiveAmount:  amount(invoice.Total),
+		PayableAmount:       amount(invoice.BalanceDue()),
+	}
+	if !invoice.Discount.IsZero() {
+		discount := amount(invoice.Discount)
+		totals.AllowanceTotalAmount = &discount
+	}
+	// Credit notes already issued reduce what is payable like payments do
+	if prepaid := invoice.AmountPaid.Add(invoice.AmountCredited); !prepaid.IsZero() {
+		paid := amount(prepaid)
+		totals.PrepaidAmount = &paid
+	}
+
+	buyerReference := strings.TrimSpace(client.BuyerReference)
+	if buyerReference == "" {
+		buyerReference = invoice.Number
+	}
+
+	doc := &ublInvoice{
+		Namespace:            ublNamespace,
+		CACNamespace:         ublCACNamespace,
+		CBCNamespace:         ublCBCNamespace,
+		CustomizationID:      peppolCustomizationID,
+		ProfileID:            peppolProfileID,
+		ID:                   invoice.Number,
+		IssueDate:            invoice.Date.Format("2006-01-02"),
+		DueDate:              invoice.DueDate.Format("2006-01-02"),
+		InvoiceTypeCode:      "380",
+		DocumentCurrencyCode: currency,
+		BuyerReference:       buyerReference,
+		Supplier:             seller,
+		Customer:             buyer,
+		PaymentMeans:         ublPaymentMeansFor(invoice, cfg),
+		AllowanceCharges:     allowances,
+		TaxTotal:             taxTotal,
+		MonetaryTotal:        totals,
+		Lines:                lines,
+	}
+	if invoice.ServiceStartDate != nil && invoice.ServiceEndDate != nil {
+		doc.InvoicePeriod = &ublPeriod{
+			StartDate: invoice.ServiceStartDate.Format("2006-01-02"),
+			EndDate:   invoice.ServiceEndDate.Format("2006-01-02"),
+		}
+	}
+	return doc, nil
+}
+
+// partyDetails are the seller's or buyer's details wherever they come from.
+type partyDetails struct {
+	name, vatID, street, city, postalCode, country, peppolID, email string
+}
+
+// ublPartyFor builds the party in role, "seller" or "buyer", and lists the
+// mandatory details it is missing. The seller needs a full address; the
+// buyer only a country. Without a Peppol ID the email address is used as
+// the electronic address.
+func ublPartyFor(role string, d partyDetails, fullAddress bool) (ublParty, []string) {
+	var problems []string
+	missing := func(what string) {
+		problems = append(problems, fmt.Sprintf("%s %s is missing", role, what))
+	}
+
+	name := strings.TrimSpace(d.name)
+	if name == "" {
+		missing("name")
+	}
+	vatID := strings.ToUpper(strings.ReplaceAll(d.vatID, " ", ""))
+	if vatID == "" {
+		missing("VAT ID")
+	}
+	street, city, postalCode := strings.TrimSpace(d.street), strings.TrimSpace(d.city), strings.TrimSpace(d.postalCode)
+	if fullAddress {
+		if street == "" {
+			missing("street")
+		}
+		if city == "" {
+			missing("city")
+		}
+		if postalCode == "" {
+			missing("postal code")
+		}
+	}
+	country := strings.ToUpper(strings.TrimSpace(d.country))
+	if country == "" {
+		missing("country")
+	} else if !countryCodePattern.MatchString(country) {
+		problems = append(problems, fmt.Sprintf("%s country %q is not a two-letter ISO 3166 code", role, d.country))
+	}
+
+	var endpoint ublID
+	email := strings.TrimSpace(d.email)
+	if peppolID := strings.TrimSpace(d.peppolID); peppolID != "" {
+		match := peppolIDPattern.FindStringSubmatch(peppolID)
+		if match == nil {
+			problems = append(problems, fmt.Sprintf("%s Peppol ID %q is not written SCHEME:ID, e.g. 0088:5790000435968", role, peppolID))
+		} else {
+			endpoint = ublID{Scheme: match[1], Value: match[2]}
+		}
+	} else if email != "" {
+		endpoint = ublID{Scheme: "EM", Value: email}
+	} else {
+		missing("Peppol ID or email address")
+	}
+
+	party := ublParty{
+		EndpointID: endpoint,
+		Name:       name,
+		Address:    ublAddress{Street: street, City: city, PostalZone: postalCode, Country: country},
+		LegalName:  name,
+	}
+	if email != "" {
+		party.Email = &email
+	}
+	if vatID != "" {
+		party.TaxScheme = &ublPartyTaxScheme{CompanyID: vatID, TaxScheme: "VAT"}
+	}
+	return party, problems
+}
+
+// lineVATRate is the single VAT rate charged on item, or a description of
+// why its taxes cannot be expressed as one.
+func lineVATRate(invoice *models.Invoice, item models.LineItem) (decimal.Decimal, string) {
+	for _, t := range item.Taxes {
+		if t.Compound {
+			return decimal.Zero, fmt.Sprintf("has the compound tax %s, which e-invoices cannot express", t.Name)
+		}
+		if t.Inclusive {
+			return decimal.Zero, fmt.Sprintf("has the inclusive tax %s; e-invoice prices must exclude VAT", t.Name)
+		}
+	}
+	switch {
+	case invoice.TaxRate.IsPositive() && len(item.Taxes) > 0:
+		return decimal.Zero, "has taxes of its own on top of the invoice's tax rate; only one VAT rate is allowed per line"
+	case invoice.TaxRate.IsPositive():
+		return invoice.TaxRate, ""
+	case len(item.Taxes) > 1:
+		return decimal.Zero, fmt.Sprintf("has several taxes (%s); only one VAT rate is allowed per line", models.TaxNames(item.Taxes))
+	case len(item.Taxes) == 1:
+		return item.Taxes[0].Rate, ""
+	}
+	return decimal.Zero, ""
+}
+
+// vatCategory is standard rated (S) for a positive rate and zero rated (Z)
+// otherwise.
+func vatCategory(rate decimal.Decimal) ublTaxCategory {
+	id := "Z"
+	if rate.IsPositive() {
+		id = "S"
+	}
+	return ublTaxCategory{ID: id, Percent: rate.StringFixed(2), TaxScheme: "VAT"}
+}
+
+// ublPaymentMeansFor is a credit transfer to the configured bank account,
+// marked as SEPA when the account is an IBAN, or nil without one.
+func ublPaymentMeansFor(invoice *models.Invoice, cfg *config.Config) *ublPaymentMeans {
+	account := strings.ToUpper(strings.ReplaceAll(cfg.BankAccount, " ", ""))
+	if account == "" {
+		return nil
+	}
+	code := "30"
+	if ibanPattern.MatchString(account) {
+		code = "58"
+	}
+	means := &ublPaymentMeans{
+		Code:      code,
+		PaymentID: invoice.Number,
+		Account:   ublAccount{ID: account, Name: cfg.CompanyName},
+	}
+	if branch := strings.TrimSpace(cfg.BankRouting); branch != "" {
+		means.Account.Branch = &branch
+	}
+	return means
+}
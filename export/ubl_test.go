@@ -0,0 +1,222 @@
+package export
+
+import (
+	"encoding/xml"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/models"
+)
+
+func ublSeller() *config.Config {
+	return &config.Config{
+		CompanyName:       "Example SARL",
+		CompanyEmail:      "billing@example.fr",
+		CompanyVATID:      "FR 12 345678901",
+		CompanyStreet:     "1 Rue de la Paix",
+		CompanyCity:       "Paris",
+		CompanyPostalCode: "75002",
+		CompanyCountry:    "fr",
+		BankAccount:       "FR76 3000 6000 0112 3456 7890 189",
+		BankRouting:       "AGRIFRPP",
+	}
+}
+
+func ublBuyer() *models.Client {
+	return &models.Client{Name: "Acme GmbH", Emails: []string{"ap@acme.de"}, VATID: "DE999999999", Country: "DE", PeppolID: "9930:DE999999999"}
+}
+
+// ublInvoiceAtTwoRates has a line at 20% with its own discount, one at
+// 5.5% and one without VAT, and 5% off the whole invoice.
+func ublInvoiceAtTwoRates() *models.Invoice {
+	d := decimal.RequireFromString
+	standard := models.Tax{ID: "vat", Name: "VAT", Rate: d("20")}
+	reduced := models.Tax{ID: "reduced", Name: "VAT reduced", Rate: d("5.5")}
+
+	invoice := models.NewInvoice("c1", "Acme GmbH", "2026-05")
+	invoice.Currency = "EUR"
+	first := models.NewLineItem("Consulting", d("2"), d("95.555"))
+	first.SetDiscount(d("10"), decimal.Zero)
+	first.Taxes = []models.Tax{standard}
+	second := models.NewLineItem("Books", d("3"), d("12.40"))
+	second.Taxes = []models.Tax{reduced}
+	invoice.AddLineItem(*first)
+	invoice.AddLineItem(*second)
+	invoice.AddLineItem(*models.NewLineItem("Exported goods", d("1"), d("40")))
+	invoice.SetDiscountRate(d("5"))
+	invoice.AmountPaid = d("50")
+	return invoice
+}
+
+func TestBuildUBL(t *testing.T) {
+	invoice := ublInvoiceAtTwoRates()
+	doc, err := buildUBL(invoice, ublBuyer(), ublSeller())
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := decimal.RequireFromString
+
+	if doc.CustomizationID != peppolCustomizationID || doc.ID != "2026-05" || doc.DocumentCurrencyCode != "EUR" {
+		t.Errorf("header = %s %s %s", doc.CustomizationID, doc.ID, doc.DocumentCurrencyCode)
+	}
+	if doc.BuyerReference != "2026-05" {
+		t.Errorf("buyer reference = %q, want the invoice number", doc.BuyerReference)
+	}
+	if got := doc.Supplier.EndpointID; got.Scheme != "EM" || got.Value != "billing@example.fr" {
+		t.Errorf("seller endpoint = %+v, want the email address", got)
+	}
+	if got := doc.Customer.EndpointID; got.Scheme != "9930" || got.Value != "DE999999999" {
+		t.Errorf("buyer endpoint = %+v, want the Peppol ID", got)
+	}
+	if doc.Supplier.TaxScheme.CompanyID != "FR12345678901" || doc.Supplier.Address.Country != "FR" {
+		t.Errorf("seller VAT ID %s and country %s are not normalised", doc.Supplier.TaxScheme.CompanyID, doc.Supplier.Address.Country)
+	}
+	if doc.PaymentMeans == nil || doc.PaymentMeans.Code != "58" || doc.PaymentMeans.Account.ID != "FR7630006000011234567890189" {
+		t.Errorf("payment means = %+v, want a SEPA transfer", doc.PaymentMeans)
+	}
+
+	// One tax subtotal per rate, adding up to the document's amounts
+	if len(doc.TaxTotal.Subtotals) != 3 {
+		t.Fatalf("tax subtotals = %+v, want 3", doc.TaxTotal.Subtotals)
+	}
+	taxable, tax := decimal.Zero, decimal.Zero
+	for _, sub := range doc.TaxTotal.Subtotals {
+		taxable = taxable.Add(d(sub.TaxableAmount.Value))
+		tax = tax.Add(d(sub.TaxAmount.Value))
+	}
+	if doc.TaxTotal.Subtotals[2].Category.ID != "Z" {
+		t.Errorf("the line without VAT is in category %s, want Z", doc.TaxTotal.Subtotals[2].Category.ID)
+	}
+	totals := doc.MonetaryTotal
+	if !taxable.Equal(d(totals.TaxExclusiveAmount.Value)) {
+		t.Errorf("taxable amounts add up to %s, not %s", taxable, totals.TaxExclusiveAmount.Value)
+	}
+	if !tax.Equal(d(doc.TaxTotal.TaxAmount.Value)) || !tax.Equal(invoice.Tax) {
+		t.Errorf("tax subtotals add up to %s, want %s", tax, invoice.Tax)
+	}
+	allowances := decimal.Zero
+	for _, a := range doc.AllowanceCharges {
+		allowances = allowances.Add(d(a.Amount.Value))
+	}
+	if totals.AllowanceTotalAmount == nil || !allowances.Equal(d(totals.AllowanceTotalAmount.Value)) || !allowances.Equal(invoice.Discount) {
+		t.Errorf("document allowances add up to %s, want %s", allowances, invoice.Discount)
+	}
+	if got := d(totals.TaxExclusiveAmount.Value).Add(tax); !got.Equal(d(totals.TaxInclusiveAmount.Value)) {
+		t.Errorf("tax exclusive %s + tax %s != tax inclusive %s", totals.TaxExclusiveAmount.Value, tax, totals.TaxInclusiveAmount.Value)
+	}
+	if totals.PrepaidAmount == nil || totals.PrepaidAmount.Value != "50.00" || !d(totals.PayableAmount.Value).Equal(invoice.Total.Sub(d("50"))) {
+		t.Errorf("prepaid %v and payable %s do not account for the payment", totals.PrepaidAmount, totals.PayableAmount.Value)
+	}
+
+	// The line discount is an allowance on the line, at the unrounded price
+	line := doc.Lines[0]
+	if len(line.AllowanceCharges) != 1 || line.AllowanceCharges[0].Amount.Value != "19.11" || line.Price.Value != "95.555" {
+		t.Errorf("first line = %+v", line)
+	}
+}
+
+func TestBuildUBLProblems(t *testing.T) {
+	d := decimal.RequireFromString
+	compound := models.Tax{Name: "QST", Rate: d("9.975"), Compound: true}
+	inclusive := models.Tax{Name: "VAT incl.", Rate: d("20"), Inclusive: true}
+	vat := models.Tax{Name: "VAT", Rate: d("20")}
+	levy := models.Tax{Name: "Levy", Rate: d("1")}
+
+	invoice := models.NewInvoice("c1", "Acme", "2026-06")
+	for _, taxes := range [][]models.Tax{{compound}, {inclusive}, {vat, levy}} {
+		item := models.NewLineItem("Work", d("1"), d("10"))
+		item.Taxes = taxes
+		invoice.AddLineItem(*item)
+	}
+	invoice.AddLineItem(*models.NewLineItem(" ", d("1"), d("10")))
+
+	client := &models.Client{Name: "Acme", Country: "Germany", PeppolID: "DE123"}
+	_, err := buildUBL(invoice, client, &config.Config{CompanyName: "Seller"})
+	var e *EInvoiceError
+	if !errors.As(err, &e) {
+		t.Fatalf("buildUBL() error = %v, want an EInvoiceError", err)
+	}
+	want := []string{
+		"seller VAT ID is missing",
+		"seller street is missing",
+		"seller city is missing",
+		"seller postal code is missing",
+		"seller country is missing",
+		"seller Peppol ID or email address is missing",
+		"buyer VAT ID is missing",
+		`buyer country "Germany" is not a two-letter ISO 3166 code`,
+		`buyer Peppol ID "DE123" is not written SCHEME:ID, e.g. 0088:5790000435968`,
+		"line 1 (Work) has the compound tax QST, which e-invoices cannot express",
+		"line 2 (Work) has the inclusive tax VAT incl.; e-invoice prices must exclude VAT",
+		"line 3 (Work) has several taxes (VAT, Levy); only one VAT rate is allowed per line",
+		"line 4 has no description",
+	}
+	if !slices.Equal(e.Problems, want) {
+		t.Errorf("problems:\n%s\nwant:\n%s", strings.Join(e.Problems, "\n"), strings.Join(want, "\n"))
+	}
+
+	// The invoice's tax rate cannot be combined with a line's own tax
+	mixed := models.NewInvoice("c1", "Acme", "2026-07")
+	item := models.NewLineItem("Work", d("1"), d("10"))
+	item.Taxes = []models.Tax{vat}
+	mixed.AddLineItem(*item)
+	mixed.SetTaxRate(d("19"))
+	err = ValidateUBL(mixed, ublBuyer(), ublSeller())
+	if !errors.As(err, &e) || len(e.Problems) != 1 || !strings.Contains(e.Problems[0], "on top of the invoice's tax rate") {
+		t.Errorf("ValidateUBL() error = %v, want the mixed rates reported", err)
+	}
+
+	empty := models.NewInvoice("c1", "Acme", "2026-08")
+	if err := ValidateUBL(empty, ublBuyer(), ublSeller()); err == nil || !strings.Contains(err.Error(), "the invoice has no line items") {
+		t.Errorf("ValidateUBL(no lines) error = %v", err)
+	}
+}
+
+func TestExportInvoiceToUBL(t *testing.T) {
+	dir := t.TempDir()
+	invoice := ublInvoiceAtTwoRates()
+	if err := ExportInvoiceToUBL(invoice, ublBuyer(), ublSeller(), dir); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(GetUBLExportPath(invoice, dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), xml.Header) {
+		t.Error("the e-invoice has no XML declaration")
+	}
+	var parsed struct {
+		XMLName xml.Name
+		ID      string `xml:"ID"`
+		Lines   []struct {
+			ID string `xml:"ID"`
+		} `xml:"InvoiceLine"`
+	}
+	if err := xml.Unmarshal(data, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if parsed.XMLName.Space != ublNamespace || parsed.XMLName.Local != "Invoice" || parsed.ID != "2026-05" || len(parsed.Lines) != 3 {
+		t.Errorf("parsed e-invoice = %+v", parsed)
+	}
+	// Empty optional parents are left out
+	for _, empty := range []string{"<cac:Contact></cac:Contact>", "<cac:FinancialInstitutionBranch></cac:FinancialInstitutionBranch>"} {
+		if strings.Contains(string(data), empty) {
+			t.Errorf("the e-invoice has an empty %s", empty)
+		}
+	}
+
+	// Nothing is written for an invalid invoice
+	invalid := t.TempDir()
+	if err := ExportInvoiceToUBL(models.NewInvoice("c1", "Acme", "2026-09"), ublBuyer(), ublSeller(), invalid); err == nil {
+		t.Fatal("an invoice without lines was exported")
+	}
+	if entries, _ := os.ReadDir(invalid); len(entries) != 0 {
+		t.Errorf("files written for an invalid invoice: %v", entries)
+	}
+}
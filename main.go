@@ -1,26 +1,52 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/user/invoicer/backup"
+	"github.com/user/invoicer/cli"
 	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/models"
+	"github.com/user/invoicer/overdue"
+	"github.com/user/invoicer/recurring"
 	"github.com/user/invoicer/storage"
+	"github.com/user/invoicer/timeimport"
 	"github.com/user/invoicer/ui"
 )
 
 func main() {
 	var (
-		backupFlag  = flag.Bool("backup", false, "Create a backup of all data")
-		backupPath  = flag.String("backup-path", "", "Path to save backup (optional)")
-		restoreFlag = flag.String("restore", "", "Restore from a backup file")
+		backupFlag    = flag.Bool("backup", false, "Create a backup of all data")
+		backupPath    = flag.String("backup-path", "", "Path to save backup (optional)")
+		restoreFlag   = flag.String("restore", "", "Restore from a backup file")
+		importJSON    = flag.Bool("import-json", false, "Import JSON data files into the SQLite database")
+		sweepFlag     = flag.Bool("sweep-overdue", false, "Mark sent invoices past their due date as overdue and exit")
+		recurringFlag = flag.Bool("generate-recurring", false, "Create draft invoices for due recurring schedules and exit")
+		importTime    = flag.String("import-time", "", "Import time entries from a Toggl or Clockify CSV or Timewarrior JSON export and exit")
+		timeFormat    = flag.String("time-format", "", "Format of the -import-time file: toggl, clockify or timewarrior (detected if empty)")
+		noDrafts      = flag.Bool("no-drafts", false, "With -import-time, import the time without creating draft invoices")
 	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: invoicer [flags]\n       invoicer <command> <action> [flags]\n\nFlags:\n")
+		flag.PrintDefaults()
+		fmt.Fprintln(flag.CommandLine.Output(), "\nRun 'invoicer help' for the commands.")
+	}
 	flag.Parse()
 
+	// Subcommands are for scripts, so they never prompt
+	if flag.NArg() > 0 {
+		os.Exit(runCommand(flag.Args()))
+	}
+
 	if *backupFlag {
 		if err := backup.CreateBackup(*backupPath); err != nil {
 			log.Fatal("Backup failed:", err)
@@ -49,6 +75,12 @@ func main() {
 		fmt.Println("\nConfiguration saved successfully!")
 	}
 
+	rounding, err := models.ParseRoundingPolicy(cfg.RoundingMode, cfg.RoundingScope)
+	if err != nil {
+		log.Fatal("Invalid configuration:", err)
+	}
+	models.SetRoundingPolicy(rounding)
+
 	// Ensure directories exist
 	if err := cfg.EnsureDirectories(); err != nil {
 		log.Fatal("Failed to create directories:", err)
@@ -59,17 +91,277 @@ func main() {
 		log.Fatal("Migration failed:", err)
 	}
 
-	// Initialize storage with configured data directory
-	store, err := storage.NewJSONStorage(cfg.DataDir())
+	if *importJSON {
+		result, err := storage.ImportJSON(cfg.DataDir(), cfg.DatabasePath())
+		if err != nil {
+			log.Fatal("Import failed:", err)
+		}
+		fmt.Printf("Imported %d clients, %d invoices, %d payments, %d recurring invoices, %d estimates, %d credit notes, %d taxes, %d catalog items, %d time entries, %d client mappings, %d expenses and %d audit entries into %s\n",
+			result.Clients, result.Invoices, result.Payments, result.Recurring, result.Estimates, result.CreditNotes,
+			result.Taxes, result.CatalogItems, result.TimeEntries, result.Mappings, result.Expenses, result.AuditEntries, cfg.DatabasePath())
+		if cfg.StorageBackend != config.BackendSQLite {
+			fmt.Printf("Set \"storage_backend\": \"%s\" in your config to start using it.\n", config.BackendSQLite)
+		}
+		os.Exit(0)
+	}
+
+	// Initialize storage with the configured backend, offering to roll back
+	// any data file that was left corrupt by an interrupted write
+	store, err := storage.Open(cfg)
+	var corrupt *storage.CorruptFileError
+	for errors.As(err, &corrupt) {
+		if !offerRecovery(corrupt) {
+			log.Fatal("Failed to initialize storage:", err)
+		}
+		store, err = storage.Open(cfg)
+	}
 	if err != nil {
 		log.Fatal("Failed to initialize storage:", err)
 	}
-	
+	if closer, ok := store.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	if *importTime != "" {
+		succeeded := runTimeImport(store, *importTime, *timeFormat, !*noDrafts)
+		if closer, ok := store.(io.Closer); ok {
+			closer.Close()
+		}
+		if !succeeded {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// Cron entry points: run the requested tasks and exit
+	now := time.Now()
+	if *recurringFlag || *sweepFlag {
+		succeeded := true
+		if *recurringFlag {
+			succeeded = printRecurring(recurring.NewGenerator(store).Run(now)) && succeeded
+		}
+		if *sweepFlag {
+			succeeded = printOverdue(overdue.NewSweeper(store).Run(now)) && succeeded
+		}
+		if closer, ok := store.(io.Closer); ok {
+			closer.Close()
+		}
+		if !succeeded {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// Generate due recurring invoices and move invoices past their due date
+	// to overdue before anyone looks at them
+	notices := upgradeTemplates(cfg)
+	noticeIsError := false
+	generated, err := recurring.NewGenerator(store).Run(now)
+	if err != nil {
+		notices = append(notices, fmt.Sprintf("Recurring invoice generation failed: %v", err))
+		noticeIsError = true
+	} else if summary := generated.Summary(); summary != "" {
+		notices = append(notices, summary)
+		noticeIsError = noticeIsError || len(generated.Failed) > 0
+	}
+	swept, err := overdue.NewSweeper(store).Run(now)
+	if err != nil {
+		notices = append(notices, fmt.Sprintf("Overdue sweep failed: %v", err))
+		noticeIsError = true
+	} else if summary := swept.Summary(); summary != "" {
+		notices = append(notices, summary)
+		noticeIsError = noticeIsError || len(swept.Failed) > 0
+	}
+
+	menu := ui.NewMainMenuModel(store, cfg)
+	if len(notices) > 0 {
+		menu = menu.WithNotice(strings.Join(notices, "\n"), noticeIsError)
+	}
+
 	// Pass config to UI
-	p := tea.NewProgram(ui.NewMainMenuModel(store, cfg), tea.WithAltScreen())
-	
+	p := tea.NewProgram(menu, tea.WithAltScreen())
+
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Error running program: %v", err)
+		if closer, ok := store.(io.Closer); ok {
+			closer.Close()
+		}
 		os.Exit(1)
 	}
-}
\ No newline at end of file
+}
+
+// printRecurring reports the invoices created by a recurring run, returning
+// false if anything failed.
+func printRecurring(result *recurring.Result, err error) bool {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Recurring invoice generation failed: %v\n", err)
+		return false
+	}
+	for _, invoice := range result.Created {
+		fmt.Printf("%s  %s  %s  draft created\n", invoice.Number, invoice.ClientName, invoice.Date.Format("2006-01-02"))
+	}
+	for _, failure := range result.Failed {
+		fmt.Fprintf(os.Stderr, "%s (%s): %v\n", failure.Schedule.Name, failure.Schedule.ClientName, failure.Err)
+	}
+	return len(result.Failed) == 0
+}
+
+// printOverdue reports the invoices moved to overdue by a sweep, returning
+// false if anything failed.
+func printOverdue(result *overdue.Result, err error) bool {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Overdue sweep failed: %v\n", err)
+		return false
+	}
+	for _, invoice := range result.Marked {
+		fmt.Printf("%s  %s  due %s  marked overdue\n", invoice.Number, invoice.ClientName, invoice.DueDate.Format("2006-01-02"))
+	}
+	for _, failure := range result.Failed {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", failure.Invoice.Number, failure.Err)
+	}
+	return len(result.Failed) == 0
+}
+
+// runTimeImport imports the time in a time tracker's export, asking about
+// client names it does not know, and drafts invoices for the billable time
+// if drafts is set. It returns false if anything failed.
+func runTimeImport(store models.Storage, path, formatName string, drafts bool) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Time import failed: %v\n", err)
+		return false
+	}
+
+	var format timeimport.Format
+	if formatName != "" {
+		format, err = timeimport.ParseFormat(formatName)
+	} else {
+		format, err = timeimport.DetectFormat(path, data)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Time import failed: %v\n", err)
+		return false
+	}
+
+	records, err := timeimport.Parse(format, bytes.NewReader(data))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Time import failed: %v\n", err)
+		return false
+	}
+
+	importer := timeimport.NewImporter(store, timeimport.NewPromptResolver(os.Stdin, os.Stdout))
+	result, err := importer.Run(records)
+	if result != nil {
+		for _, client := range result.NewClients {
+			fmt.Printf("Created client %s; set its hourly rate before sending invoices\n", client.Name)
+		}
+		fmt.Printf("Imported %d time entries from %s (%d already imported, %d skipped)\n",
+			len(result.Imported), format, result.Duplicates, result.Skipped)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Time import failed: %v\n", err)
+		return false
+	}
+
+	if !drafts {
+		return true
+	}
+	invoices, err := importer.DraftInvoices(result.Imported)
+	for _, invoice := range invoices {
+		fmt.Printf("%s  %s  %s  draft created\n", invoice.Number, invoice.ClientName, invoice.Currency.Format(invoice.Total))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Drafting invoices failed: %v\n", err)
+		return false
+	}
+	return true
+}
+
+// runCommand runs a non-interactive subcommand and returns its exit code.
+// Unlike the interface it does not run the setup wizard, offer to migrate
+// old data or recover corrupt files; it fails instead.
+func runCommand(args []string) int {
+	if !cli.IsCommand(args[0]) {
+		// Help and mistyped commands need no data
+		return cli.Run(nil, nil, args, os.Stdout, os.Stderr)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invoicer: failed to load configuration: %v\n", err)
+		return cli.ExitError
+	}
+	if cfg == nil {
+		fmt.Fprintln(os.Stderr, "invoicer: not configured yet; run invoicer without arguments once to set it up")
+		return cli.ExitError
+	}
+	rounding, err := models.ParseRoundingPolicy(cfg.RoundingMode, cfg.RoundingScope)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invoicer: invalid configuration: %v\n", err)
+		return cli.ExitError
+	}
+	models.SetRoundingPolicy(rounding)
+	if err := cfg.EnsureDirectories(); err != nil {
+		fmt.Fprintf(os.Stderr, "invoicer: failed to create directories: %v\n", err)
+		return cli.ExitError
+	}
+	for _, notice := range upgradeTemplates(cfg) {
+		fmt.Fprintf(os.Stderr, "invoicer: %s\n", notice)
+	}
+
+	store, err := storage.Open(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invoicer: failed to initialize storage: %v\n", err)
+		return cli.ExitError
+	}
+	if closer, ok := store.(io.Closer); ok {
+		defer closer.Close()
+	}
+	return cli.Run(store, cfg, args, os.Stdout, os.Stderr)
+}
+
+// upgradeTemplates brings the templates that were never edited up to date,
+// returning notices of what it did and of edited templates that need
+// updating by hand.
+func upgradeTemplates(cfg *config.Config) []string {
+	upgraded, outdated, err := cfg.UpgradeTemplates()
+	if err != nil {
+		return []string{fmt.Sprintf("Template upgrade failed: %v", err)}
+	}
+	var notices []string
+	if len(upgraded) > 0 {
+		notices = append(notices, fmt.Sprintf("Updated the templates %s to the current version", strings.Join(upgraded, ", ")))
+	}
+	if len(outdated) > 0 {
+		notices = append(notices, fmt.Sprintf("The edited templates %s in %s print every amount in dollars; update them as described under \"Upgrading Templates\" in the README",
+			strings.Join(outdated, ", "), cfg.TemplatesDir()))
+	}
+	return notices
+}
+
+// offerRecovery asks the user whether to restore a corrupt data file from
+// its newest good generation, returning true if the file was recovered.
+func offerRecovery(corrupt *storage.CorruptFileError) bool {
+	fmt.Printf("%v\n", corrupt)
+	if corrupt.Generation == "" {
+		fmt.Println("No earlier generation of this file could be read.")
+		return false
+	}
+
+	if info, err := os.Stat(corrupt.Generation); err == nil {
+		fmt.Printf("Newest good copy: %s (saved %s)\n", corrupt.Generation, info.ModTime().Format("2006-01-02 15:04:05"))
+	}
+	fmt.Print("Recover from this copy? (y/N): ")
+	var response string
+	fmt.Scanln(&response)
+	if strings.ToLower(response) != "y" {
+		return false
+	}
+
+	if err := corrupt.Recover(); err != nil {
+		fmt.Printf("Recovery failed: %v\n", err)
+		return false
+	}
+	fmt.Printf("Recovered %s\n", corrupt.Path)
+	return true
+}
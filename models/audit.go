@@ -1,15 +1,29 @@
 package models
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// ChangedBySystem is recorded for status changes made from the TUI.
+const ChangedBySystem = "system"
+
+// AuditAction says what an audit entry records. Entries written before
+// credit notes existed have no action and are all status changes.
+type AuditAction string
+
+const (
+	AuditStatusChange AuditAction = "status_change"
+	AuditCreditNote   AuditAction = "credit_note"
+)
+
 type AuditEntry struct {
 	ID            string        `json:"id"`
 	InvoiceID     string        `json:"invoice_id"`
 	InvoiceNumber string        `json:"invoice_number"`
+	Action        AuditAction   `json:"action,omitempty"`
 	OldStatus     InvoiceStatus `json:"old_status"`
 	NewStatus     InvoiceStatus `json:"new_status"`
 	ChangedBy     string        `json:"changed_by"`
@@ -22,10 +36,24 @@ func NewAuditEntry(invoiceID, invoiceNumber string, oldStatus, newStatus Invoice
 		ID:            uuid.New().String(),
 		InvoiceID:     invoiceID,
 		InvoiceNumber: invoiceNumber,
+		Action:        AuditStatusChange,
 		OldStatus:     oldStatus,
 		NewStatus:     newStatus,
-		ChangedBy:     "system",
+		ChangedBy:     ChangedBySystem,
 		ChangedAt:     time.Now(),
 		Reason:        reason,
 	}
-}
\ No newline at end of file
+}
+
+// NewCreditNoteAuditEntry records that note was issued against invoice. The
+// invoice status is recorded as both the old and new status.
+func NewCreditNoteAuditEntry(invoice *Invoice, note *CreditNote) *AuditEntry {
+	entry := NewAuditEntry(invoice.ID, invoice.Number, invoice.Status, invoice.Status,
+		fmt.Sprintf("Credit note %s for %s: %s", note.Number, note.Currency.Format(note.Amount()), note.Reason))
+	entry.Action = AuditCreditNote
+	return entry
+}
+
+func (e *AuditEntry) IsStatusChange() bool {
+	return e.Action == "" || e.Action == AuditStatusChange
+}
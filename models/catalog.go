@@ -0,0 +1,92 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// CatalogItem is a product or service that can be added to documents without
+// typing it in again. Line items copy what they need from it, so changing the
+// catalog does not alter existing documents.
+type CatalogItem struct {
+	ID          string `json:"id"`
+	SKU         string `json:"sku"`
+	Description string `json:"description"`
+	// Unit is what the price is per, e.g. "hour", "day" or "item"
+	Unit      string          `json:"unit,omitempty"`
+	UnitPrice decimal.Decimal `json:"unit_price"`
+	// TaxIDs are the taxes from the tax catalogue charged by default
+	TaxIDs    []string  `json:"tax_ids,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func NewCatalogItem(sku, description, unit string, unitPrice decimal.Decimal, taxIDs []string) *CatalogItem {
+	now := time.Now()
+	return &CatalogItem{
+		ID:          uuid.New().String(),
+		SKU:         sku,
+		Description: description,
+		Unit:        unit,
+		UnitPrice:   unitPrice,
+		TaxIDs:      taxIDs,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+func (c *CatalogItem) Update(sku, description, unit string, unitPrice decimal.Decimal, taxIDs []string) {
+	c.SKU = sku
+	c.Description = description
+	c.Unit = unit
+	c.UnitPrice = unitPrice
+	c.TaxIDs = taxIDs
+	c.UpdatedAt = time.Now()
+}
+
+func (c *CatalogItem) Validate() error {
+	if strings.TrimSpace(c.Description) == "" {
+		return errors.New("description is required")
+	}
+	if c.UnitPrice.IsNegative() {
+		return errors.New("unit price cannot be negative")
+	}
+	return nil
+}
+
+// IsHourly reports whether the item is billed by the hour.
+func (c *CatalogItem) IsHourly() bool {
+	switch strings.ToLower(strings.TrimSpace(c.Unit)) {
+	case "h", "hr", "hrs", "hour", "hours":
+		return true
+	}
+	return false
+}
+
+// PriceFor is the unit price to bill client for the item. Hourly items
+// without a price of their own use the client's hourly rate.
+func (c *CatalogItem) PriceFor(client *Client) decimal.Decimal {
+	if c.UnitPrice.IsZero() && c.IsHourly() && client != nil {
+		return client.DefaultHourlyRate
+	}
+	return c.UnitPrice
+}
+
+// Taxes looks up the item's default taxes in the tax catalogue. Taxes that
+// have since been deleted are left out.
+func (c *CatalogItem) Taxes(catalogue []Tax) []Tax {
+	var taxes []Tax
+	for _, id := range c.TaxIDs {
+		for _, t := range catalogue {
+			if t.ID == id {
+				taxes = append(taxes, t)
+				break
+			}
+		}
+	}
+	return taxes
+}
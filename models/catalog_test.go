@@ -0,0 +1,47 @@
+package models
+
+import "testing"
+
+func TestCatalogItemPrice(t *testing.T) {
+	client := &Client{DefaultHourlyRate: dec("95")}
+	tests := []struct {
+		unit  string
+		price string
+		want  string
+	}{
+		{"hour", "0", "95"},
+		{" Hrs ", "0", "95"},
+		{"hour", "120", "120"},
+		{"day", "0", "0"},
+		{"", "49.99", "49.99"},
+	}
+	for _, tt := range tests {
+		item := NewCatalogItem("", "Work", tt.unit, dec(tt.price), nil)
+		checkAmount(t, "price per "+tt.unit, item.PriceFor(client), tt.want)
+	}
+	hourly := NewCatalogItem("", "Work", "h", dec("0"), nil)
+	checkAmount(t, "price without a client", hourly.PriceFor(nil), "0")
+}
+
+func TestCatalogItemTaxes(t *testing.T) {
+	vat := NewTax("VAT", dec("20"), false, false)
+	levy := NewTax("Levy", dec("1"), false, false)
+	item := NewCatalogItem("SKU-1", "Widget", "item", dec("10"), []string{levy.ID, "deleted", vat.ID})
+
+	taxes := item.Taxes([]Tax{*vat, *levy})
+	if TaxNames(taxes) != "Levy, VAT" {
+		t.Errorf("taxes = %s, want Levy, VAT in the item's order", TaxNames(taxes))
+	}
+}
+
+func TestCatalogItemValidate(t *testing.T) {
+	if err := NewCatalogItem("", " ", "", dec("1"), nil).Validate(); err == nil {
+		t.Error("an item without a description is valid")
+	}
+	if err := NewCatalogItem("", "Refund", "", dec("-1"), nil).Validate(); err == nil {
+		t.Error("an item with a negative price is valid")
+	}
+	if err := NewCatalogItem("", "Free sample", "", dec("0"), nil).Validate(); err != nil {
+		t.Errorf("a free item is invalid: %v", err)
+	}
+}
@@ -13,6 +13,27 @@ type Client struct {
 	Address          string          `json:"address"`
 	Emails           []string        `json:"emails"`
 	DefaultHourlyRate decimal.Decimal `json:"default_hourly_rate"`
+	// DefaultCurrency is used for new invoices and estimates for the client
+	DefaultCurrency  CurrencyCode    `json:"default_currency,omitempty"`
+	// VATID and the address split into parts identify the client on
+	// e-invoices; Address is still what printed documents show
+	VATID            string          `json:"vat_id,omitempty"`
+	Street           string          `json:"street,omitempty"`
+	City             string          `json:"city,omitempty"`
+	PostalCode       string          `json:"postal_code,omitempty"`
+	// Country is an ISO 3166-1 alpha-2 code such as "DE"
+	Country          string          `json:"country,omitempty"`
+	// PeppolID is the client's Peppol participant ID as SCHEME:ID, e.g.
+	// "0088:5790000435968"
+	PeppolID         string          `json:"peppol_id,omitempty"`
+	// BuyerReference is the reference the client wants quoted on their
+	// e-invoices, e.g. a purchase order or Leitweg-ID
+	BuyerReference   string          `json:"buyer_reference,omitempty"`
+	// InvoiceTemplate names the template the client's invoices are
+	// rendered with, overriding the default in the config
+	InvoiceTemplate  string          `json:"invoice_template,omitempty"`
+	// Version is bumped by storage on every update to detect concurrent edits
+	Version          int             `json:"version"`
 	CreatedAt        time.Time       `json:"created_at"`
 	UpdatedAt        time.Time       `json:"updated_at"`
 }
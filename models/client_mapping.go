@@ -0,0 +1,42 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ClientMapping remembers which client a client name used in another time
+// tracker refers to, so that imports only ask about it once. A mapping
+// without a ClientID means time recorded under that name is ignored.
+type ClientMapping struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	ClientID  string    `json:"client_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewClientMapping(name, clientID string) *ClientMapping {
+	return &ClientMapping{
+		ID:        uuid.New().String(),
+		Name:      name,
+		ClientID:  clientID,
+		CreatedAt: time.Now(),
+	}
+}
+
+func (m *ClientMapping) Ignored() bool {
+	return m.ClientID == ""
+}
+
+// FindClientMapping returns the mapping for name, ignoring case, or nil if
+// there is none.
+func FindClientMapping(mappings []ClientMapping, name string) *ClientMapping {
+	for i := range mappings {
+		if strings.EqualFold(mappings[i].Name, name) {
+			return &mappings[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// CreditNote reduces what is owed on an issued invoice without changing the
+// invoice itself. Its line items carry negative amounts and it uses the
+// invoice's discount and tax rates, so its Total is negative too. A fixed
+// discount on the invoice is shared out by subtotal, so crediting every
+// line takes off exactly the invoice's total.
+type CreditNote struct {
+	ID            string          `json:"id"`
+	Number        string          `json:"number"`
+	InvoiceID     string          `json:"invoice_id"`
+	InvoiceNumber string          `json:"invoice_number"`
+	ClientID      string          `json:"client_id"`
+	ClientName    string          `json:"client_name"`
+	Date          time.Time       `json:"date"`
+	Reason        string          `json:"reason"`
+	LineItems     []LineItem      `json:"line_items"`
+	Subtotal      decimal.Decimal `json:"subtotal"`
+	DiscountRate  decimal.Decimal `json:"discount_rate"`
+	// FixedDiscountRate is the invoice's fixed discount as a percentage of
+	// its subtotal. FixedDiscount is the share of it for the credited lines.
+	FixedDiscountRate decimal.Decimal `json:"fixed_discount_rate"`
+	FixedDiscount     decimal.Decimal `json:"fixed_discount"`
+	Discount          decimal.Decimal `json:"discount"`
+	TaxRate           decimal.Decimal `json:"tax_rate"`
+	Tax               decimal.Decimal `json:"tax"`
+	Total             decimal.Decimal `json:"total"`
+	Currency          CurrencyCode    `json:"currency,omitempty"`
+	CreatedAt         time.Time       `json:"created_at"`
+}
+
+func NewCreditNote(invoice *Invoice, number, reason string) *CreditNote {
+	now := time.Now()
+	fixedRate := decimal.Zero
+	if invoice.FixedDiscount.IsPositive() && invoice.Subtotal.IsPositive() {
+		fixedRate = invoice.FixedDiscount.Div(invoice.Subtotal).Mul(decimal.NewFromInt(100))
+	}
+	return &CreditNote{
+		ID:                uuid.New().String(),
+		Number:            number,
+		InvoiceID:         invoice.ID,
+		InvoiceNumber:     invoice.Number,
+		ClientID:          invoice.ClientID,
+		ClientName:        invoice.ClientName,
+		Date:              now,
+		Reason:            reason,
+		LineItems:         []LineItem{},
+		Subtotal:          decimal.Zero,
+		DiscountRate:      invoice.DiscountRate,
+		FixedDiscountRate: fixedRate,
+		FixedDiscount:     decimal.Zero,
+		Discount:          decimal.Zero,
+		TaxRate:           invoice.TaxRate,
+		Tax:               decimal.Zero,
+		Total:             decimal.Zero,
+		Currency:          invoice.Currency,
+		CreatedAt:         now,
+	}
+}
+
+// AddCredit adds a line crediting quantity units at unitPrice, reversing the
+// given taxes with it. The price is stored negated whatever its sign.
+func (c *CreditNote) AddCredit(description string, quantity, unitPrice decimal.Decimal, taxes []Tax) {
+	item := NewLineItem(description, quantity.Abs(), unitPrice.Abs().Neg())
+	item.Taxes = taxes
+	c.LineItems = append(c.LineItems, *item)
+	c.CalculateTotals()
+}
+
+func (c *CreditNote) RemoveLineItem(itemID string) {
+	newItems := []LineItem{}
+	for _, item := range c.LineItems {
+		if item.ID != itemID {
+			newItems = append(newItems, item)
+		}
+	}
+	c.LineItems = newItems
+	c.CalculateTotals()
+}
+
+func (c *CreditNote) CalculateTotals() {
+	subtotal, _, _, _ := calculateTotals(c.LineItems, decimal.Zero, decimal.Zero, decimal.Zero, c.Currency)
+	c.FixedDiscount = CurrentRoundingPolicy().Round(subtotal.Abs().Mul(c.FixedDiscountRate.Div(decimal.NewFromInt(100))), c.Currency)
+	c.Subtotal, c.Discount, c.Tax, c.Total = calculateTotals(c.LineItems, c.DiscountRate, c.FixedDiscount, c.TaxRate, c.Currency)
+}
+
+// TaxBreakdown lists each tax reversed by the credit note. The amounts are
+// negative.
+func (c *CreditNote) TaxBreakdown() []TaxAmount {
+	return taxBreakdown(c.LineItems, c.Discount, c.TaxRate, c.Currency)
+}
+
+// Amount is the positive amount the credit note takes off the invoice.
+func (c *CreditNote) Amount() decimal.Decimal {
+	return c.Total.Neg()
+}
+
+func GenerateCreditNoteNumber(year int, sequence int) string {
+	return fmt.Sprintf("CN-%d-%02d", year, sequence)
+}
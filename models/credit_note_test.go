@@ -0,0 +1,31 @@
+package models
+
+import "testing"
+
+func TestCreditNoteSharesFixedDiscount(t *testing.T) {
+	vat := NewTax("VAT", dec("20"), false, false)
+	invoice := NewInvoice("c1", "Acme", "2026-01")
+	design := NewLineItem("Design", dec("3"), dec("100"))
+	design.Taxes = []Tax{*vat}
+	invoice.AddLineItem(*design)
+	invoice.AddLineItem(*NewLineItem("Hosting", dec("1"), dec("33.33")))
+	invoice.SetDiscountRate(dec("5"))
+	invoice.SetFixedDiscount(dec("25"))
+
+	// Crediting every line takes off exactly what the invoice charged
+	full := NewCreditNote(invoice, "CN-2026-01", "Cancelled")
+	for _, item := range invoice.LineItems {
+		full.AddCredit(item.Description, item.Quantity, item.UnitPrice, item.Taxes)
+	}
+	checkAmount(t, "fixed discount", full.FixedDiscount, "25")
+	checkAmount(t, "discount", full.Discount, invoice.Discount.Neg().String())
+	checkAmount(t, "amount", full.Amount(), invoice.CreditableAmount().String())
+
+	// Crediting the hosting takes its share of the 25, 33.33 of 333.33
+	part := NewCreditNote(invoice, "CN-2026-02", "Hosting was down")
+	part.AddCredit("Hosting", dec("1"), dec("33.33"), nil)
+	checkAmount(t, "fixed discount", part.FixedDiscount, "2.5")
+	// 5% of 33.33 is 1.6665, plus the 2.50
+	checkAmount(t, "discount", part.Discount, "-4.17")
+	checkAmount(t, "amount", part.Amount(), "29.16")
+}
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// CurrencyCode is an ISO 4217 code. The empty code is treated as
+// DefaultCurrency so that records saved before currencies existed keep
+// reading as US dollars.
+type CurrencyCode string
+
+const DefaultCurrency CurrencyCode = "USD"
+
+type currencyInfo struct {
+	symbol string
+	// latex is the symbol as pdflatex needs it
+	latex      string
+	minorUnits int32
+}
+
+var currencies = map[CurrencyCode]currencyInfo{
+	"USD": {"$", `\$`, 2},
+	"EUR": {"€", `\texteuro{}`, 2},
+	"GBP": {"£", `\pounds{}`, 2},
+	"CHF": {"CHF ", "CHF ", 2},
+	"CAD": {"CA$", `CA\$`, 2},
+	"AUD": {"A$", `A\$`, 2},
+	"JPY": {"¥", `\textyen{}`, 0},
+	"SEK": {"SEK ", "SEK ", 2},
+	"NOK": {"NOK ", "NOK ", 2},
+	"DKK": {"DKK ", "DKK ", 2},
+	"PLN": {"PLN ", "PLN ", 2},
+}
+
+// SupportedCurrencies lists the known currency codes in alphabetical order.
+func SupportedCurrencies() []CurrencyCode {
+	codes := make([]CurrencyCode, 0, len(currencies))
+	for code := range currencies {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+	return codes
+}
+
+// ParseCurrency accepts a supported code in any case. An empty string gives
+// DefaultCurrency.
+func ParseCurrency(s string) (CurrencyCode, error) {
+	code := CurrencyCode(strings.ToUpper(strings.TrimSpace(s)))
+	if code == "" {
+		return DefaultCurrency, nil
+	}
+	if _, ok := currencies[code]; !ok {
+		return "", fmt.Errorf("unsupported currency %q", s)
+	}
+	return code, nil
+}
+
+// Code returns c, or DefaultCurrency if c is empty.
+func (c CurrencyCode) Code() CurrencyCode {
+	if c == "" {
+		return DefaultCurrency
+	}
+	return c
+}
+
+func (c CurrencyCode) info() currencyInfo {
+	if info, ok := currencies[c.Code()]; ok {
+		return info
+	}
+	return currencyInfo{symbol: string(c) + " ", latex: string(c) + " ", minorUnits: 2}
+}
+
+func (c CurrencyCode) Symbol() string {
+	return c.info().symbol
+}
+
+// MinorUnits is the number of decimal places amounts are shown with, e.g. 2
+// for cents and 0 for yen.
+func (c CurrencyCode) MinorUnits() int32 {
+	return c.info().minorUnits
+}
+
+// Format renders amount with the currency symbol, e.g. "€1250.00" or
+// "-$5.00".
+func (c CurrencyCode) Format(amount decimal.Decimal) string {
+	return formatMoney(c.info().symbol, c.MinorUnits(), amount)
+}
+
+// FormatLatex is like Format but with the symbol escaped for LaTeX.
+func (c CurrencyCode) FormatLatex(amount decimal.Decimal) string {
+	return formatMoney(c.info().latex, c.MinorUnits(), amount)
+}
+
+func formatMoney(symbol string, places int32, amount decimal.Decimal) string {
+	if amount.IsNegative() {
+		return "-" + symbol + amount.Neg().StringFixed(places)
+	}
+	return symbol + amount.StringFixed(places)
+}
+
+// Amounts holds totals per currency. Amounts in different currencies are
+// never added together.
+type Amounts map[CurrencyCode]decimal.Decimal
+
+func (a Amounts) Add(currency CurrencyCode, amount decimal.Decimal) {
+	code := currency.Code()
+	a[code] = a[code].Add(amount)
+}
+
+// String lists each currency's total in code order, e.g. "€300.00 · $1200.00".
+func (a Amounts) String() string {
+	codes := make([]CurrencyCode, 0, len(a))
+	for code := range a {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+
+	parts := make([]string, len(codes))
+	for i, code := range codes {
+		parts[i] = code.Format(a[code])
+	}
+	return strings.Join(parts, " · ")
+}
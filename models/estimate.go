@@ -0,0 +1,167 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type EstimateStatus string
+
+const (
+	EstimateDraft    EstimateStatus = "draft"
+	EstimateSent     EstimateStatus = "sent"
+	EstimateAccepted EstimateStatus = "accepted"
+	EstimateDeclined EstimateStatus = "declined"
+	EstimateExpired  EstimateStatus = "expired"
+)
+
+// estimateTransitions lists the legal moves out of each estimate status. An
+// expired estimate can be sent again, e.g. with a new validity date.
+var estimateTransitions = map[EstimateStatus][]EstimateStatus{
+	EstimateDraft:    {EstimateSent},
+	EstimateSent:     {EstimateAccepted, EstimateDeclined, EstimateExpired},
+	EstimateExpired:  {EstimateSent},
+	EstimateAccepted: {},
+	EstimateDeclined: {},
+}
+
+// Estimate is a quote sent before work starts. It has its own number
+// sequence and becomes an invoice through ConvertToInvoice.
+type Estimate struct {
+	ID           string          `json:"id"`
+	Number       string          `json:"number"`
+	ClientID     string          `json:"client_id"`
+	ClientName   string          `json:"client_name"`
+	Date         time.Time       `json:"date"`
+	ValidUntil   time.Time       `json:"valid_until"`
+	LineItems    []LineItem      `json:"line_items"`
+	Subtotal     decimal.Decimal `json:"subtotal"`
+	DiscountRate decimal.Decimal `json:"discount_rate"`
+	// FixedDiscount is taken off the subtotal on top of DiscountRate
+	FixedDiscount decimal.Decimal `json:"fixed_discount"`
+	Discount      decimal.Decimal `json:"discount"`
+	TaxRate       decimal.Decimal `json:"tax_rate"`
+	Tax           decimal.Decimal `json:"tax"`
+	Total         decimal.Decimal `json:"total"`
+	Currency      CurrencyCode    `json:"currency,omitempty"`
+	Notes         string          `json:"notes,omitempty"`
+	Status        EstimateStatus  `json:"status"`
+	// InvoiceID is set once the estimate has been converted
+	InvoiceID string    `json:"invoice_id,omitempty"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func NewEstimate(clientID, clientName, number string) *Estimate {
+	now := time.Now()
+	return &Estimate{
+		ID:           uuid.New().String(),
+		Number:       number,
+		ClientID:     clientID,
+		ClientName:   clientName,
+		Date:         now,
+		ValidUntil:   now.AddDate(0, 0, 30),
+		LineItems:    []LineItem{},
+		Subtotal:     decimal.Zero,
+		DiscountRate: decimal.Zero,
+		Discount:     decimal.Zero,
+		TaxRate:      decimal.Zero,
+		Tax:          decimal.Zero,
+		Total:        decimal.Zero,
+		Currency:     DefaultCurrency,
+		Status:       EstimateDraft,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+func (e *Estimate) AddLineItem(item LineItem) {
+	e.LineItems = append(e.LineItems, item)
+	e.CalculateTotals()
+}
+
+func (e *Estimate) RemoveLineItem(itemID string) {
+	newItems := []LineItem{}
+	for _, item := range e.LineItems {
+		if item.ID != itemID {
+			newItems = append(newItems, item)
+		}
+	}
+	e.LineItems = newItems
+	e.CalculateTotals()
+}
+
+func (e *Estimate) CalculateTotals() {
+	e.Subtotal, e.Discount, e.Tax, e.Total = calculateTotals(e.LineItems, e.DiscountRate, e.FixedDiscount, e.TaxRate, e.Currency)
+	e.UpdatedAt = time.Now()
+}
+
+// TaxBreakdown lists each tax charged on the estimate with its amount.
+func (e *Estimate) TaxBreakdown() []TaxAmount {
+	return taxBreakdown(e.LineItems, e.Discount, e.TaxRate, e.Currency)
+}
+
+// AllowedEstimateTransitions returns the statuses an estimate in status from
+// may move to.
+func AllowedEstimateTransitions(from EstimateStatus) []EstimateStatus {
+	return estimateTransitions[from]
+}
+
+func (e *Estimate) UpdateStatus(newStatus EstimateStatus) error {
+	if e.Status == newStatus {
+		return fmt.Errorf("estimate already has status %s", newStatus)
+	}
+	for _, to := range estimateTransitions[e.Status] {
+		if to == newStatus {
+			e.Status = newStatus
+			e.UpdatedAt = time.Now()
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot change estimate status from %s to %s: %w", e.Status, newStatus, ErrInvalidTransition)
+}
+
+// CanConvert reports whether the estimate can still become an invoice.
+func (e *Estimate) CanConvert() error {
+	if e.InvoiceID != "" {
+		return fmt.Errorf("estimate %s has already been converted to an invoice", e.Number)
+	}
+	switch e.Status {
+	case EstimateDraft, EstimateSent, EstimateAccepted:
+		return nil
+	}
+	return fmt.Errorf("a %s estimate cannot be converted to an invoice", e.Status)
+}
+
+// ConvertToInvoice builds a draft invoice with the estimate's line items,
+// discount and tax, links the two and marks the estimate accepted. The
+// caller saves both.
+func (e *Estimate) ConvertToInvoice(number string) (*Invoice, error) {
+	if err := e.CanConvert(); err != nil {
+		return nil, err
+	}
+
+	invoice := NewInvoice(e.ClientID, e.ClientName, number)
+	for _, item := range e.LineItems {
+		invoice.LineItems = append(invoice.LineItems, item.Copy())
+	}
+	invoice.DiscountRate = e.DiscountRate
+	invoice.FixedDiscount = e.FixedDiscount
+	invoice.TaxRate = e.TaxRate
+	invoice.Currency = e.Currency
+	invoice.EstimateID = e.ID
+	invoice.CalculateTotals()
+
+	e.InvoiceID = invoice.ID
+	e.Status = EstimateAccepted
+	e.UpdatedAt = time.Now()
+	return invoice, nil
+}
+
+func GenerateEstimateNumber(year int, sequence int) string {
+	return fmt.Sprintf("EST-%d-%02d", year, sequence)
+}
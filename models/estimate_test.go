@@ -0,0 +1,49 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEstimateStatus(t *testing.T) {
+	estimate := NewEstimate("c1", "Acme", "EST-2026-01")
+	if err := estimate.UpdateStatus(EstimateAccepted); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("draft to accepted: %v, want ErrInvalidTransition", err)
+	}
+	for _, status := range []EstimateStatus{EstimateSent, EstimateExpired, EstimateSent, EstimateDeclined} {
+		if err := estimate.UpdateStatus(status); err != nil {
+			t.Fatalf("to %s: %v", status, err)
+		}
+	}
+	if len(AllowedEstimateTransitions(EstimateDeclined)) != 0 {
+		t.Error("a declined estimate can change status")
+	}
+	if err := estimate.CanConvert(); err == nil {
+		t.Error("a declined estimate can be converted")
+	}
+}
+
+func TestEstimateConvertsOnce(t *testing.T) {
+	estimate := NewEstimate("c1", "Acme", "EST-2026-01")
+	estimate.AddLineItem(*NewLineItem("Design", dec("2"), dec("100")))
+
+	invoice, err := estimate.ConvertToInvoice("2026-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if invoice.Status != StatusDraft || invoice.EstimateID != estimate.ID || estimate.InvoiceID != invoice.ID {
+		t.Errorf("invoice %s (%s) and estimate are not linked", invoice.Number, invoice.Status)
+	}
+	if estimate.Status != EstimateAccepted {
+		t.Errorf("estimate status = %s, want accepted", estimate.Status)
+	}
+	if _, err := estimate.ConvertToInvoice("2026-02"); err == nil {
+		t.Error("converted the estimate twice")
+	}
+}
+
+func TestGenerateEstimateNumber(t *testing.T) {
+	if got := GenerateEstimateNumber(2026, 7); got != "EST-2026-07" {
+		t.Errorf("GenerateEstimateNumber() = %s", got)
+	}
+}
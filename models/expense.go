@@ -0,0 +1,138 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Expense is a cost paid on a client's behalf, such as travel or hardware,
+// that is passed through to them on an invoice. Amounts are in the client's
+// currency.
+type Expense struct {
+	ID          string          `json:"id"`
+	ClientID    string          `json:"client_id"`
+	ClientName  string          `json:"client_name"`
+	Date        time.Time       `json:"date"`
+	Vendor      string          `json:"vendor"`
+	Category    string          `json:"category,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Amount      decimal.Decimal `json:"amount"`
+	// MarkupRate is the percentage added on top of Amount when billing
+	MarkupRate decimal.Decimal `json:"markup_rate"`
+	// ReceiptPath is the copy of the receipt kept in the data directory,
+	// relative to it, e.g. "receipts/<id>.pdf"
+	ReceiptPath string `json:"receipt_path,omitempty"`
+	// InvoiceID and InvoiceNumber are set once the expense has been billed
+	InvoiceID     string    `json:"invoice_id,omitempty"`
+	InvoiceNumber string    `json:"invoice_number,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func NewExpense(clientID, clientName string, date time.Time, vendor, category, description string, amount, markupRate decimal.Decimal) *Expense {
+	now := time.Now()
+	return &Expense{
+		ID:          uuid.New().String(),
+		ClientID:    clientID,
+		ClientName:  clientName,
+		Date:        date,
+		Vendor:      vendor,
+		Category:    category,
+		Description: description,
+		Amount:      amount,
+		MarkupRate:  markupRate,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+func (e *Expense) Update(clientID, clientName string, date time.Time, vendor, category, description string, amount, markupRate decimal.Decimal) {
+	e.ClientID = clientID
+	e.ClientName = clientName
+	e.Date = date
+	e.Vendor = vendor
+	e.Category = category
+	e.Description = description
+	e.Amount = amount
+	e.MarkupRate = markupRate
+	e.UpdatedAt = time.Now()
+}
+
+func (e *Expense) Validate() error {
+	if e.ClientID == "" {
+		return errors.New("client is required")
+	}
+	if strings.TrimSpace(e.Vendor) == "" {
+		return errors.New("vendor is required")
+	}
+	if !e.Amount.IsPositive() {
+		return errors.New("amount must be positive")
+	}
+	if e.MarkupRate.IsNegative() {
+		return errors.New("markup cannot be negative")
+	}
+	return nil
+}
+
+// BilledAmount is the amount with the markup added, rounded to currency's
+// minor units.
+func (e *Expense) BilledAmount(currency CurrencyCode) decimal.Decimal {
+	markup := e.Amount.Mul(e.MarkupRate).Div(decimal.NewFromInt(100))
+	return CurrentRoundingPolicy().Round(e.Amount.Add(markup), currency)
+}
+
+func (e *Expense) HasReceipt() bool {
+	return e.ReceiptPath != ""
+}
+
+func (e *Expense) IsBilled() bool {
+	return e.InvoiceID != ""
+}
+
+func (e *Expense) MarkBilled(invoiceID, invoiceNumber string) {
+	e.InvoiceID = invoiceID
+	e.InvoiceNumber = invoiceNumber
+	e.UpdatedAt = time.Now()
+}
+
+// Label describes the expense on an invoice, e.g. "Travel: Amtrak - tickets
+// to the client site".
+func (e *Expense) Label() string {
+	label := e.Vendor
+	if e.Description != "" {
+		label += " - " + e.Description
+	}
+	if e.Category != "" {
+		label = e.Category + ": " + label
+	}
+	return label
+}
+
+// LineItem bills the expense as a single line at its billed amount.
+func (e *Expense) LineItem(currency CurrencyCode) *LineItem {
+	return NewLineItem(e.Label(), decimal.NewFromInt(1), e.BilledAmount(currency))
+}
+
+// UnbilledExpenses picks the expenses for clientID that have not been
+// invoiced yet and fall within the service period from..to, both days
+// included. A nil bound leaves that end of the period open.
+func UnbilledExpenses(expenses []Expense, clientID string, from, to *time.Time) []Expense {
+	var unbilled []Expense
+	for _, e := range expenses {
+		if e.ClientID != clientID || e.IsBilled() {
+			continue
+		}
+		if from != nil && e.Date.Before(startOfDay(*from)) {
+			continue
+		}
+		if to != nil && !e.Date.Before(startOfDay(*to).AddDate(0, 0, 1)) {
+			continue
+		}
+		unbilled = append(unbilled, e)
+	}
+	return unbilled
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpenseBilledAmount(t *testing.T) {
+	defer SetRoundingPolicy(DefaultRoundingPolicy)
+	tests := []struct {
+		amount, markup string
+		currency       CurrencyCode
+		want           string
+	}{
+		{"100", "0", "USD", "100"},
+		{"100", "15", "USD", "115"},
+		// 38.3295 rounds to the cent
+		{"33.33", "15", "USD", "38.33"},
+		{"10.10", "5", "USD", "10.61"},
+		{"1234", "10", "JPY", "1357"},
+	}
+	for _, tt := range tests {
+		e := NewExpense("c1", "Acme", time.Now(), "Vendor", "", "", dec(tt.amount), dec(tt.markup))
+		checkAmount(t, tt.amount+" plus "+tt.markup+"% in "+string(tt.currency), e.BilledAmount(tt.currency), tt.want)
+	}
+
+	// The rounding mode in use applies to the markup too
+	SetRoundingPolicy(RoundingPolicy{RoundHalfEven, RoundPerInvoice})
+	e := NewExpense("c1", "Acme", time.Now(), "Vendor", "", "", dec("10.10"), dec("5"))
+	checkAmount(t, "half even", e.BilledAmount("USD"), "10.6")
+}
+
+func TestExpenseLineItem(t *testing.T) {
+	e := NewExpense("c1", "Acme", time.Now(), "Amtrak", "Travel", "tickets to the client site", dec("80"), dec("10"))
+	item := e.LineItem("USD")
+	if item.Description != "Travel: Amtrak - tickets to the client site" {
+		t.Errorf("description = %q", item.Description)
+	}
+	checkAmount(t, "quantity", item.Quantity, "1")
+	checkAmount(t, "total", item.Total, "88")
+
+	if label := NewExpense("c1", "Acme", time.Now(), "Amtrak", "", "", dec("1"), dec("0")).Label(); label != "Amtrak" {
+		t.Errorf("bare label = %q", label)
+	}
+}
+
+func TestUnbilledExpensesPeriod(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	expense := func(vendor string, month time.Month, day int) Expense {
+		return *NewExpense("c1", "Acme", time.Date(2026, month, day, 0, 0, 0, 0, time.Local), vendor, "", "", dec("10"), dec("0"))
+	}
+	billed := expense("Billed", 3, 10)
+	billed.MarkBilled("i1", "2026-01")
+	other := expense("Other client", 3, 10)
+	other.ClientID = "c2"
+	expenses := []Expense{
+		expense("February", 2, 28),
+		expense("First of March", 3, 1),
+		expense("Last of March", 3, 31),
+		expense("April", 4, 1),
+		billed, other,
+	}
+
+	var got []string
+	for _, e := range UnbilledExpenses(expenses, "c1", &from, &to) {
+		got = append(got, e.Vendor)
+	}
+	if len(got) != 2 || got[0] != "First of March" || got[1] != "Last of March" {
+		t.Errorf("unbilled in March = %v", got)
+	}
+	if open := UnbilledExpenses(expenses, "c1", &from, nil); len(open) != 3 {
+		t.Errorf("%d unbilled from March on, want 3", len(open))
+	}
+}
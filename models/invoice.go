@@ -15,14 +15,23 @@ const (
 	StatusSent  InvoiceStatus = "sent"
 	StatusPaid  InvoiceStatus = "paid"
 	StatusOverdue InvoiceStatus = "overdue"
+	StatusVoid    InvoiceStatus = "void"
 )
 
 type LineItem struct {
-	ID          string          `json:"id"`
-	Description string          `json:"description"`
-	Quantity    decimal.Decimal `json:"quantity"`
-	UnitPrice   decimal.Decimal `json:"unit_price"`
-	Total       decimal.Decimal `json:"total"`
+	ID            string          `json:"id"`
+	Description   string          `json:"description"`
+	Quantity      decimal.Decimal `json:"quantity"`
+	UnitPrice     decimal.Decimal `json:"unit_price"`
+	// DiscountRate (a percentage) and FixedDiscount are the line's own
+	// discount, taken off before the document's discount
+	DiscountRate  decimal.Decimal `json:"discount_rate"`
+	FixedDiscount decimal.Decimal `json:"fixed_discount"`
+	// Total is the line amount after its own discount
+	Total         decimal.Decimal `json:"total"`
+	// Taxes are the taxes charged on this line on top of the invoice's
+	// TaxRate, copied from the tax catalogue when the line was entered
+	Taxes         []Tax           `json:"taxes,omitempty"`
 }
 
 func NewLineItem(description string, quantity, unitPrice decimal.Decimal) *LineItem {
@@ -35,8 +44,37 @@ func NewLineItem(description string, quantity, unitPrice decimal.Decimal) *LineI
 	}
 }
 
+// Copy returns the line with a new ID, for carrying it over to another
+// document.
+func (li LineItem) Copy() LineItem {
+	li.ID = uuid.New().String()
+	li.Taxes = append([]Tax(nil), li.Taxes...)
+	return li
+}
+
+func (li *LineItem) SetDiscount(rate, fixed decimal.Decimal) {
+	li.DiscountRate = rate
+	li.FixedDiscount = fixed
+	li.UpdateTotal()
+}
+
 func (li *LineItem) UpdateTotal() {
-	li.Total = li.Quantity.Mul(li.UnitPrice)
+	gross := li.Gross()
+	li.Total = gross.Sub(applyDiscount(gross, li.DiscountRate, li.FixedDiscount))
+}
+
+// Gross is the line amount before its own discount.
+func (li LineItem) Gross() decimal.Decimal {
+	return li.Quantity.Mul(li.UnitPrice)
+}
+
+// Discount is what the line's own discount takes off its Gross amount.
+func (li LineItem) Discount() decimal.Decimal {
+	return li.Gross().Sub(li.Total)
+}
+
+func (li LineItem) HasDiscount() bool {
+	return li.DiscountRate.IsPositive() || li.FixedDiscount.IsPositive()
 }
 
 type Invoice struct {
@@ -51,11 +89,21 @@ type Invoice struct {
 	LineItems        []LineItem      `json:"line_items"`
 	Subtotal         decimal.Decimal `json:"subtotal"`
 	DiscountRate     decimal.Decimal `json:"discount_rate"`
+	// FixedDiscount is taken off the subtotal on top of DiscountRate
+	FixedDiscount    decimal.Decimal `json:"fixed_discount"`
 	Discount         decimal.Decimal `json:"discount"`
 	TaxRate          decimal.Decimal `json:"tax_rate"`
 	Tax              decimal.Decimal `json:"tax"`
 	Total            decimal.Decimal `json:"total"`
+	Currency         CurrencyCode    `json:"currency,omitempty"`
+	AmountPaid       decimal.Decimal `json:"amount_paid"`
+	// AmountCredited is the sum of the credit notes issued against the invoice
+	AmountCredited   decimal.Decimal `json:"amount_credited"`
 	Status           InvoiceStatus   `json:"status"`
+	// EstimateID links an invoice to the estimate it was converted from
+	EstimateID       string          `json:"estimate_id,omitempty"`
+	// Version is bumped by storage on every update to detect concurrent edits
+	Version          int             `json:"version"`
 	CreatedAt        time.Time       `json:"created_at"`
 	UpdatedAt        time.Time       `json:"updated_at"`
 }
@@ -78,10 +126,14 @@ func NewInvoice(clientID, clientName, number string) *Invoice {
 		LineItems:        []LineItem{},
 		Subtotal:         decimal.Zero,
 		DiscountRate:     decimal.Zero,
+		FixedDiscount:    decimal.Zero,
 		Discount:         decimal.Zero,
 		TaxRate:          decimal.Zero,
 		Tax:              decimal.Zero,
 		Total:            decimal.Zero,
+		AmountPaid:       decimal.Zero,
+		AmountCredited:   decimal.Zero,
+		Currency:         DefaultCurrency,
 		Status:           StatusDraft,
 		CreatedAt:        now,
 		UpdatedAt:        now,
@@ -105,30 +157,110 @@ func (i *Invoice) RemoveLineItem(itemID string) {
 }
 
 func (i *Invoice) CalculateTotals() {
-	subtotal := decimal.Zero
-	for _, item := range i.LineItems {
-		subtotal = subtotal.Add(item.Total)
-	}
-	i.Subtotal = subtotal
+	i.Subtotal, i.Discount, i.Tax, i.Total = calculateTotals(i.LineItems, i.DiscountRate, i.FixedDiscount, i.TaxRate, i.Currency)
+	i.UpdatedAt = time.Now()
+}
+
+// TaxBreakdown lists each tax charged on the invoice with its amount.
+func (i *Invoice) TaxBreakdown() []TaxAmount {
+	return taxBreakdown(i.LineItems, i.Discount, i.TaxRate, i.Currency)
+}
+
+// calculateTotals is shared by every document with line items. Discounts
+// always come before tax:
+//  1. each line's own discount is already taken off its Total
+//  2. the document discount, discountRate percent of the subtotal plus
+//     fixedDiscount, is taken off the subtotal
+//  3. the taxes in taxBreakdown are charged on what remains; inclusive taxes
+//     are already in the subtotal and are not added
+// Line totals, the discount and the taxes are rounded to currency's minor
+// units under the rounding policy as they are worked out, so the total is
+// exactly the sum of the amounts shown. Line totals are updated in place.
+func calculateTotals(items []LineItem, discountRate, fixedDiscount, taxRate decimal.Decimal, currency CurrencyCode) (subtotal, discount, tax, total decimal.Decimal) {
+	policy := CurrentRoundingPolicy()
 	
-	if i.DiscountRate.GreaterThan(decimal.Zero) {
-		i.Discount = i.Subtotal.Mul(i.DiscountRate.Div(decimal.NewFromInt(100)))
-	} else {
-		i.Discount = decimal.Zero
+	subtotal = decimal.Zero
+	for i := range items {
+		items[i].UpdateTotal()
+		items[i].Total = policy.Round(items[i].Total, currency)
+		subtotal = subtotal.Add(items[i].Total)
 	}
 	
-	afterDiscount := i.Subtotal.Sub(i.Discount)
+	discount = policy.Round(applyDiscount(subtotal, discountRate, fixedDiscount), currency)
+	afterDiscount := subtotal.Sub(discount)
 	
-	if i.TaxRate.GreaterThan(decimal.Zero) {
-		i.Tax = afterDiscount.Mul(i.TaxRate.Div(decimal.NewFromInt(100)))
-	} else {
-		i.Tax = decimal.Zero
+	tax = decimal.Zero
+	for _, t := range taxBreakdown(items, discount, taxRate, currency) {
+		if !t.Inclusive {
+			tax = tax.Add(t.Amount)
+		}
 	}
 	
-	i.Total = afterDiscount.Add(i.Tax)
+	total = afterDiscount.Add(tax)
+	return subtotal, discount, tax, total
+}
+
+// applyDiscount works out a discount of rate percent of amount plus fixed.
+// It has the sign of amount, so credits are discounted towards zero too, and
+// never takes off more than amount.
+func applyDiscount(amount, rate, fixed decimal.Decimal) decimal.Decimal {
+	discount := decimal.Zero
+	if rate.GreaterThan(decimal.Zero) {
+		discount = amount.Mul(rate.Div(decimal.NewFromInt(100)))
+	}
+	if fixed.GreaterThan(decimal.Zero) {
+		if amount.IsNegative() {
+			discount = discount.Sub(fixed)
+		} else {
+			discount = discount.Add(fixed)
+		}
+	}
+	if discount.Abs().GreaterThan(amount.Abs()) {
+		discount = amount
+	}
+	return discount
+}
+
+// ApplyPayments sets AmountPaid to the sum of the given payments, which
+// should be every payment recorded against this invoice.
+func (i *Invoice) ApplyPayments(payments []Payment) {
+	paid := decimal.Zero
+	for _, p := range payments {
+		paid = paid.Add(p.Amount)
+	}
+	i.AmountPaid = paid
+	i.UpdatedAt = time.Now()
+}
+
+// ApplyCreditNotes sets AmountCredited from the given credit notes, which
+// should be every credit note issued against this invoice.
+func (i *Invoice) ApplyCreditNotes(notes []CreditNote) {
+	credited := decimal.Zero
+	for _, n := range notes {
+		credited = credited.Add(n.Amount())
+	}
+	i.AmountCredited = credited
 	i.UpdatedAt = time.Now()
 }
 
+// BalanceDue is what remains to be paid on the invoice after payments and
+// credit notes. It is negative when more was paid than is still owed.
+func (i *Invoice) BalanceDue() decimal.Decimal {
+	return i.Total.Sub(i.AmountPaid).Sub(i.AmountCredited)
+}
+
+// CreditableAmount is how much of the invoice total has not been credited
+// yet.
+func (i *Invoice) CreditableAmount() decimal.Decimal {
+	return i.Total.Sub(i.AmountCredited)
+}
+
+// AcceptsPayments reports whether payments can be recorded against the
+// invoice: it must have been issued and not voided.
+func (i *Invoice) AcceptsPayments() bool {
+	return i.Status != StatusDraft && i.Status != StatusVoid
+}
+
 var DecimalZero = decimal.Zero
 
 func (i *Invoice) SetDiscountRate(rate decimal.Decimal) {
@@ -136,14 +268,21 @@ func (i *Invoice) SetDiscountRate(rate decimal.Decimal) {
 	i.CalculateTotals()
 }
 
+func (i *Invoice) SetFixedDiscount(amount decimal.Decimal) {
+	i.FixedDiscount = amount
+	i.CalculateTotals()
+}
+
 func (i *Invoice) SetTaxRate(rate decimal.Decimal) {
 	i.TaxRate = rate
 	i.CalculateTotals()
 }
 
+// UpdateStatus moves the invoice to newStatus if the transition is allowed.
+// Reversals and voiding need a non-empty reason. Errors are *TransitionError.
 func (i *Invoice) UpdateStatus(newStatus InvoiceStatus, reason string) error {
-	if i.Status == newStatus {
-		return fmt.Errorf("invoice already has status %s", newStatus)
+	if err := CheckTransition(i.Status, newStatus, reason); err != nil {
+		return err
 	}
 	
 	i.Status = newStatus
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+var dec = decimal.RequireFromString
+
+func checkAmount(t *testing.T, name string, got decimal.Decimal, want string) {
+	t.Helper()
+	if !got.Equal(dec(want)) {
+		t.Errorf("%s = %s, want %s", name, got, want)
+	}
+}
+
+func TestInvoiceTotalsWithDocumentTaxRate(t *testing.T) {
+	invoice := NewInvoice("c1", "Acme", "2026-01")
+	invoice.AddLineItem(*NewLineItem("Hours", dec("3"), dec("33")))
+	invoice.SetDiscountRate(dec("10"))
+	invoice.SetTaxRate(dec("7"))
+
+	checkAmount(t, "subtotal", invoice.Subtotal, "99")
+	checkAmount(t, "discount", invoice.Discount, "9.9")
+	// 7% of 89.10 is 6.237
+	checkAmount(t, "tax", invoice.Tax, "6.24")
+	checkAmount(t, "total", invoice.Total, "95.34")
+
+	breakdown := invoice.TaxBreakdown()
+	if len(breakdown) != 1 || breakdown[0].Name != "Tax" {
+		t.Fatalf("breakdown = %+v, want only the document rate", breakdown)
+	}
+	checkAmount(t, "breakdown", breakdown[0].Amount, "6.24")
+}
+
+func TestInvoiceLineTaxes(t *testing.T) {
+	state := NewTax("State", dec("6"), false, false)
+	county := NewTax("County", dec("2"), true, false)
+	vat := NewTax("VAT", dec("20"), false, true)
+
+	invoice := NewInvoice("c1", "Acme", "2026-01")
+	taxed := NewLineItem("Taxed", dec("1"), dec("100"))
+	taxed.Taxes = []Tax{*state, *county}
+	inclusive := NewLineItem("VAT included", dec("1"), dec("120"))
+	inclusive.Taxes = []Tax{*vat}
+	invoice.AddLineItem(*taxed)
+	invoice.AddLineItem(*NewLineItem("Exempt", dec("1"), dec("50")))
+	invoice.AddLineItem(*inclusive)
+
+	checkAmount(t, "subtotal", invoice.Subtotal, "270")
+	// State 6.00 on 100, County 2% on 106 compounded; the VAT is already
+	// in the price
+	checkAmount(t, "tax", invoice.Tax, "8.12")
+	checkAmount(t, "total", invoice.Total, "278.12")
+
+	want := []struct {
+		name      string
+		inclusive bool
+		amount    string
+	}{
+		{"State", false, "6"},
+		{"County", false, "2.12"},
+		{"VAT", true, "20"},
+	}
+	breakdown := invoice.TaxBreakdown()
+	if len(breakdown) != len(want) {
+		t.Fatalf("breakdown = %+v", breakdown)
+	}
+	for i, w := range want {
+		if breakdown[i].Name != w.name || breakdown[i].Inclusive != w.inclusive {
+			t.Errorf("row %d = %+v, want %s", i, breakdown[i], w.name)
+		}
+		checkAmount(t, w.name, breakdown[i].Amount, w.amount)
+	}
+}
+
+func TestInvoiceDiscounts(t *testing.T) {
+	vat := NewTax("VAT", dec("10"), false, false)
+	invoice := NewInvoice("c1", "Acme", "2026-01")
+	percent := NewLineItem("Ten percent off", dec("2"), dec("100"))
+	percent.SetDiscount(dec("10"), decimal.Zero)
+	percent.Taxes = []Tax{*vat}
+	fixed := NewLineItem("Twenty off", dec("1"), dec("120"))
+	fixed.SetDiscount(decimal.Zero, dec("20"))
+	invoice.AddLineItem(*percent)
+	invoice.AddLineItem(*fixed)
+
+	checkAmount(t, "line total", invoice.LineItems[0].Total, "180")
+	checkAmount(t, "line total", invoice.LineItems[1].Total, "100")
+
+	// 28 off 280 takes 10% off each line, so the VAT is charged on 162
+	invoice.SetFixedDiscount(dec("28"))
+	checkAmount(t, "subtotal", invoice.Subtotal, "280")
+	checkAmount(t, "discount", invoice.Discount, "28")
+	checkAmount(t, "tax", invoice.Tax, "16.2")
+	checkAmount(t, "total", invoice.Total, "268.2")
+
+	// A discount never takes off more than there is
+	invoice.SetFixedDiscount(dec("1000"))
+	checkAmount(t, "discount", invoice.Discount, "280")
+	checkAmount(t, "total", invoice.Total, "0")
+}
+
+func TestEstimateConvertsWithTotals(t *testing.T) {
+	vat := NewTax("VAT", dec("10"), false, false)
+	item := NewLineItem("Design", dec("2"), dec("100"))
+	item.Taxes = []Tax{*vat}
+	estimate := NewEstimate("c1", "Acme", "EST-2026-01")
+	estimate.LineItems = []LineItem{*item}
+	estimate.FixedDiscount = dec("5")
+	estimate.CalculateTotals()
+
+	invoice, err := estimate.ConvertToInvoice("2026-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkAmount(t, "invoice total", invoice.Total, estimate.Total.String())
+	if len(invoice.LineItems) != 1 || len(invoice.LineItems[0].Taxes) != 1 {
+		t.Fatalf("line items = %+v", invoice.LineItems)
+	}
+	if invoice.LineItems[0].ID == item.ID {
+		t.Error("the invoice shares line item IDs with the estimate")
+	}
+}
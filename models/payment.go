@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Payment is one installment received against an invoice.
+type Payment struct {
+	ID        string          `json:"id"`
+	InvoiceID string          `json:"invoice_id"`
+	Amount    decimal.Decimal `json:"amount"`
+	Date      time.Time       `json:"date"`
+	Method    string          `json:"method"`
+	Reference string          `json:"reference,omitempty"`
+	Note      string          `json:"note,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+func NewPayment(invoiceID string, amount decimal.Decimal, date time.Time, method, reference, note string) *Payment {
+	return &Payment{
+		ID:        uuid.New().String(),
+		InvoiceID: invoiceID,
+		Amount:    amount,
+		Date:      date,
+		Method:    method,
+		Reference: reference,
+		Note:      note,
+		CreatedAt: time.Now(),
+	}
+}
@@ -0,0 +1,155 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type Cadence string
+
+const (
+	CadenceWeekly    Cadence = "weekly"
+	CadenceMonthly   Cadence = "monthly"
+	CadenceQuarterly Cadence = "quarterly"
+)
+
+// RecurringInvoice is a template from which a draft invoice is generated at
+// the start of every billing period. Invoices are billed in advance: the run
+// on NextRunDate covers the period that starts on that day.
+type RecurringInvoice struct {
+	ID           string          `json:"id"`
+	Name         string          `json:"name"`
+	ClientID     string          `json:"client_id"`
+	ClientName   string          `json:"client_name"`
+	LineItems    []LineItem      `json:"line_items"`
+	DiscountRate decimal.Decimal `json:"discount_rate"`
+	// FixedDiscount is taken off the subtotal on top of DiscountRate
+	FixedDiscount decimal.Decimal `json:"fixed_discount"`
+	TaxRate       decimal.Decimal `json:"tax_rate"`
+	Currency      CurrencyCode    `json:"currency,omitempty"`
+	DueDays       int             `json:"due_days"`
+	Cadence       Cadence         `json:"cadence"`
+	// Interval is the number of cadence units between runs, e.g. 2 with
+	// CadenceWeekly for every other week
+	Interval int `json:"interval"`
+	// StartDate anchors the schedule so that monthly runs keep their day of
+	// the month after a short month
+	StartDate   time.Time `json:"start_date"`
+	NextRunDate time.Time `json:"next_run_date"`
+	Active      bool      `json:"active"`
+	Version     int       `json:"version"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func NewRecurringInvoice(clientID, clientName, name string, cadence Cadence, interval int, startDate time.Time) *RecurringInvoice {
+	now := time.Now()
+	return &RecurringInvoice{
+		ID:           uuid.New().String(),
+		Name:         name,
+		ClientID:     clientID,
+		ClientName:   clientName,
+		LineItems:    []LineItem{},
+		DiscountRate: decimal.Zero,
+		TaxRate:      decimal.Zero,
+		Currency:     DefaultCurrency,
+		DueDays:      30,
+		Cadence:      cadence,
+		Interval:     interval,
+		StartDate:    startDate,
+		NextRunDate:  startDate,
+		Active:       true,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+// Describe returns the cadence in words, e.g. "Every 2 weeks".
+func (r *RecurringInvoice) Describe() string {
+	n := r.interval()
+	switch r.Cadence {
+	case CadenceWeekly:
+		if n == 1 {
+			return "Weekly"
+		}
+		return fmt.Sprintf("Every %d weeks", n)
+	case CadenceQuarterly:
+		if n == 1 {
+			return "Quarterly"
+		}
+		return fmt.Sprintf("Every %d quarters", n)
+	default:
+		if n == 1 {
+			return "Monthly"
+		}
+		return fmt.Sprintf("Every %d months", n)
+	}
+}
+
+func (r *RecurringInvoice) interval() int {
+	if r.Interval < 1 {
+		return 1
+	}
+	return r.Interval
+}
+
+// NextPeriodStart returns the start of the period following the one that
+// starts on start.
+func (r *RecurringInvoice) NextPeriodStart(start time.Time) time.Time {
+	n := r.interval()
+	switch r.Cadence {
+	case CadenceWeekly:
+		return start.AddDate(0, 0, 7*n)
+	case CadenceQuarterly:
+		return r.addMonths(start, 3*n)
+	default:
+		return r.addMonths(start, n)
+	}
+}
+
+// addMonths moves start forward by months, landing on the schedule's anchor
+// day or the last day of the month if that month is shorter.
+func (r *RecurringInvoice) addMonths(start time.Time, months int) time.Time {
+	day := r.StartDate.Day()
+	if r.StartDate.IsZero() {
+		day = start.Day()
+	}
+	first := time.Date(start.Year(), start.Month()+time.Month(months), 1, 0, 0, 0, 0, start.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// GenerateInvoice builds the draft invoice for the period starting on
+// NextRunDate. It does not advance the schedule.
+func (r *RecurringInvoice) GenerateInvoice(number string) *Invoice {
+	start := r.NextRunDate
+	end := r.NextPeriodStart(start).AddDate(0, 0, -1)
+
+	invoice := NewInvoice(r.ClientID, r.ClientName, number)
+	invoice.Date = start
+	invoice.DueDate = start.AddDate(0, 0, r.DueDays)
+	invoice.ServiceStartDate = &start
+	invoice.ServiceEndDate = &end
+	for _, item := range r.LineItems {
+		invoice.LineItems = append(invoice.LineItems, item.Copy())
+	}
+	invoice.DiscountRate = r.DiscountRate
+	invoice.FixedDiscount = r.FixedDiscount
+	invoice.TaxRate = r.TaxRate
+	invoice.Currency = r.Currency
+	invoice.CalculateTotals()
+
+	return invoice
+}
+
+// Advance moves NextRunDate on to the following period.
+func (r *RecurringInvoice) Advance() {
+	r.NextRunDate = r.NextPeriodStart(r.NextRunDate)
+	r.UpdatedAt = time.Now()
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// RoundingMode decides which way amounts exactly half way between two
+// minor units go.
+type RoundingMode string
+
+const (
+	// RoundHalfUp rounds halves away from zero, so 0.125 becomes 0.13 and
+	// -0.125 becomes -0.13.
+	RoundHalfUp RoundingMode = "half_up"
+	// RoundHalfEven rounds halves to the nearest even digit, so 0.125
+	// becomes 0.12 and 0.135 becomes 0.14. Also known as bankers' rounding.
+	RoundHalfEven RoundingMode = "half_even"
+)
+
+// RoundingScope decides where taxes are rounded.
+type RoundingScope string
+
+const (
+	// RoundPerInvoice adds up each tax over all lines and rounds the sum.
+	RoundPerInvoice RoundingScope = "invoice"
+	// RoundPerLine rounds each line's tax before adding them up.
+	RoundPerLine RoundingScope = "line"
+)
+
+// RoundingPolicy is how document amounts are rounded to the currency's minor
+// units. Line totals, discounts and each row of the tax breakdown are always
+// rounded, so the printed amounts add up to the printed total.
+type RoundingPolicy struct {
+	Mode  RoundingMode
+	Scope RoundingScope
+}
+
+var DefaultRoundingPolicy = RoundingPolicy{Mode: RoundHalfUp, Scope: RoundPerInvoice}
+
+var roundingPolicy = DefaultRoundingPolicy
+
+// SetRoundingPolicy changes the policy used by every CalculateTotals from
+// now on. Totals already worked out are not touched until they are
+// recalculated.
+func SetRoundingPolicy(p RoundingPolicy) {
+	roundingPolicy = p
+}
+
+func CurrentRoundingPolicy() RoundingPolicy {
+	return roundingPolicy
+}
+
+// ParseRoundingPolicy reads a policy as written in the config file. Empty
+// values take the defaults; "bankers" is accepted for "half_even".
+func ParseRoundingPolicy(mode, scope string) (RoundingPolicy, error) {
+	p := DefaultRoundingPolicy
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "":
+	case string(RoundHalfUp):
+		p.Mode = RoundHalfUp
+	case string(RoundHalfEven), "bankers":
+		p.Mode = RoundHalfEven
+	default:
+		return p, fmt.Errorf("unknown rounding mode %q", mode)
+	}
+	switch strings.ToLower(strings.TrimSpace(scope)) {
+	case "":
+	case string(RoundPerInvoice):
+		p.Scope = RoundPerInvoice
+	case string(RoundPerLine):
+		p.Scope = RoundPerLine
+	default:
+		return p, fmt.Errorf("unknown rounding scope %q", scope)
+	}
+	return p, nil
+}
+
+// Round rounds amount to currency's minor units.
+func (p RoundingPolicy) Round(amount decimal.Decimal, currency CurrencyCode) decimal.Decimal {
+	places := currency.MinorUnits()
+	if p.Mode == RoundHalfEven {
+		return amount.RoundBank(places)
+	}
+	return amount.Round(places)
+}
+
+// FormatPercent renders a rate with one decimal place, or with as many as it
+// needs if that is more, e.g. "20.0" or "7.25".
+func FormatPercent(rate decimal.Decimal) string {
+	return formatAtLeast(rate, 1)
+}
+
+// FormatQuantity renders a quantity with two decimal places, or with as many
+// as it needs if that is more, e.g. "1.50" or "0.125".
+func FormatQuantity(quantity decimal.Decimal) string {
+	return formatAtLeast(quantity, 2)
+}
+
+func formatAtLeast(d decimal.Decimal, places int32) string {
+	if d.Equal(d.Truncate(places)) {
+		return d.StringFixed(places)
+	}
+	return d.String()
+}
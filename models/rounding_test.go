@@ -0,0 +1,91 @@
+package models
+
+import "testing"
+
+// threeLines is an invoice whose 10% tax is half a cent on every line.
+func threeLines() *Invoice {
+	invoice := NewInvoice("c1", "Acme", "2026-01")
+	for i := 0; i < 3; i++ {
+		invoice.AddLineItem(*NewLineItem("Stamp", dec("1"), dec("0.15")))
+	}
+	invoice.SetTaxRate(dec("10"))
+	return invoice
+}
+
+func TestRoundingPolicy(t *testing.T) {
+	defer SetRoundingPolicy(DefaultRoundingPolicy)
+
+	tests := []struct {
+		policy RoundingPolicy
+		tax    string
+	}{
+		// 0.045 on the whole invoice
+		{RoundingPolicy{RoundHalfUp, RoundPerInvoice}, "0.05"},
+		{RoundingPolicy{RoundHalfEven, RoundPerInvoice}, "0.04"},
+		// 0.015 on each line
+		{RoundingPolicy{RoundHalfUp, RoundPerLine}, "0.06"},
+		{RoundingPolicy{RoundHalfEven, RoundPerLine}, "0.06"},
+	}
+	for _, tt := range tests {
+		SetRoundingPolicy(tt.policy)
+		invoice := threeLines()
+		checkAmount(t, string(tt.policy.Mode)+" per "+string(tt.policy.Scope)+" tax", invoice.Tax, tt.tax)
+		checkAmount(t, "total", invoice.Total, dec("0.45").Add(dec(tt.tax)).String())
+		if breakdown := invoice.TaxBreakdown(); len(breakdown) != 1 || !breakdown[0].Amount.Equal(invoice.Tax) {
+			t.Errorf("breakdown %+v does not add up to the tax %s", breakdown, invoice.Tax)
+		}
+	}
+}
+
+func TestRoundToCurrency(t *testing.T) {
+	tests := []struct {
+		mode     RoundingMode
+		amount   string
+		currency CurrencyCode
+		want     string
+	}{
+		{RoundHalfUp, "0.125", "USD", "0.13"},
+		{RoundHalfUp, "-0.125", "USD", "-0.13"},
+		{RoundHalfEven, "0.125", "USD", "0.12"},
+		{RoundHalfEven, "0.135", "USD", "0.14"},
+		{RoundHalfUp, "100.5", "JPY", "101"},
+		{RoundHalfEven, "100.5", "JPY", "100"},
+	}
+	for _, tt := range tests {
+		got := RoundingPolicy{Mode: tt.mode}.Round(dec(tt.amount), tt.currency)
+		checkAmount(t, string(tt.mode)+" "+tt.amount+" "+string(tt.currency), got, tt.want)
+	}
+}
+
+func TestParseRoundingPolicy(t *testing.T) {
+	tests := []struct {
+		mode, scope string
+		want        RoundingPolicy
+		wantErr     bool
+	}{
+		{"", "", DefaultRoundingPolicy, false},
+		{"Bankers", "line", RoundingPolicy{RoundHalfEven, RoundPerLine}, false},
+		{"half_up", "invoice", RoundingPolicy{RoundHalfUp, RoundPerInvoice}, false},
+		{"truncate", "", RoundingPolicy{}, true},
+		{"", "document", RoundingPolicy{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRoundingPolicy(tt.mode, tt.scope)
+		if (err != nil) != tt.wantErr || (!tt.wantErr && got != tt.want) {
+			t.Errorf("ParseRoundingPolicy(%q, %q) = %+v, %v", tt.mode, tt.scope, got, err)
+		}
+	}
+}
+
+func TestFormatPercentAndQuantity(t *testing.T) {
+	for value, want := range map[string]string{"20": "20.0", "7.25": "7.25", "0": "0.0"} {
+		if got := FormatPercent(dec(value)); got != want {
+			t.Errorf("FormatPercent(%s) = %s, want %s", value, got, want)
+		}
+	}
+	for value, want := range map[string]string{"1.5": "1.50", "0.125": "0.125", "3": "3.00"} {
+		if got := FormatQuantity(dec(value)); got != want {
+			t.Errorf("FormatQuantity(%s) = %s, want %s", value, got, want)
+		}
+	}
+}
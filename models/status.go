@@ -0,0 +1,121 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrSameStatus        = errors.New("invoice already has this status")
+	ErrInvalidTransition = errors.New("status transition not allowed")
+	ErrReasonRequired    = errors.New("reason required for status change")
+)
+
+// TransitionError describes a rejected status change. Err is one of
+// ErrSameStatus, ErrInvalidTransition or ErrReasonRequired.
+type TransitionError struct {
+	From InvoiceStatus
+	To   InvoiceStatus
+	Err  error
+}
+
+func (e *TransitionError) Error() string {
+	switch e.Err {
+	case ErrSameStatus:
+		return fmt.Sprintf("invoice already has status %s", e.To)
+	case ErrReasonRequired:
+		return fmt.Sprintf("a reason is required to change status from %s to %s", e.From, e.To)
+	default:
+		return fmt.Sprintf("cannot change status from %s to %s", e.From, e.To)
+	}
+}
+
+func (e *TransitionError) Unwrap() error {
+	return e.Err
+}
+
+type transition struct {
+	to       InvoiceStatus
+	reversal bool
+}
+
+// statusTransitions lists the legal moves out of each status. Reversals undo
+// an earlier step (a payment that bounced, an invoice sent by mistake) and,
+// like voiding, must be explained. Void is final.
+var statusTransitions = map[InvoiceStatus][]transition{
+	StatusDraft: {
+		{to: StatusSent},
+		{to: StatusVoid},
+	},
+	StatusSent: {
+		{to: StatusPaid},
+		{to: StatusOverdue},
+		{to: StatusDraft, reversal: true},
+		{to: StatusVoid},
+	},
+	StatusOverdue: {
+		{to: StatusPaid},
+		{to: StatusSent, reversal: true},
+		{to: StatusVoid},
+	},
+	StatusPaid: {
+		{to: StatusSent, reversal: true},
+	},
+	StatusVoid: {},
+}
+
+// ParseInvoiceStatus reads a status name such as "sent".
+func ParseInvoiceStatus(name string) (InvoiceStatus, error) {
+	status := InvoiceStatus(strings.ToLower(strings.TrimSpace(name)))
+	if _, ok := statusTransitions[status]; !ok {
+		return "", fmt.Errorf("unknown invoice status %q (use draft, sent, paid, overdue or void)", name)
+	}
+	return status, nil
+}
+
+// AllowedTransitions returns the statuses an invoice in status from may move to.
+func AllowedTransitions(from InvoiceStatus) []InvoiceStatus {
+	var statuses []InvoiceStatus
+	for _, t := range statusTransitions[from] {
+		statuses = append(statuses, t.to)
+	}
+	return statuses
+}
+
+// RequiresReason reports whether moving from one status to another must be
+// accompanied by a reason for the audit log.
+func RequiresReason(from, to InvoiceStatus) bool {
+	if to == StatusVoid {
+		return true
+	}
+	for _, t := range statusTransitions[from] {
+		if t.to == to {
+			return t.reversal
+		}
+	}
+	return false
+}
+
+// CheckTransition validates a status change without applying it.
+func CheckTransition(from, to InvoiceStatus, reason string) error {
+	if from == to {
+		return &TransitionError{From: from, To: to, Err: ErrSameStatus}
+	}
+
+	allowed := false
+	for _, t := range statusTransitions[from] {
+		if t.to == to {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return &TransitionError{From: from, To: to, Err: ErrInvalidTransition}
+	}
+
+	if RequiresReason(from, to) && strings.TrimSpace(reason) == "" {
+		return &TransitionError{From: from, To: to, Err: ErrReasonRequired}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckTransition(t *testing.T) {
+	tests := []struct {
+		from, to InvoiceStatus
+		reason   string
+		want     error
+	}{
+		{StatusDraft, StatusSent, "", nil},
+		{StatusSent, StatusPaid, "", nil},
+		{StatusSent, StatusOverdue, "", nil},
+		{StatusOverdue, StatusPaid, "", nil},
+		{StatusDraft, StatusPaid, "", ErrInvalidTransition},
+		{StatusPaid, StatusOverdue, "", ErrInvalidTransition},
+		{StatusPaid, StatusVoid, "refunded", ErrInvalidTransition},
+		{StatusVoid, StatusDraft, "mistake", ErrInvalidTransition},
+		{StatusSent, StatusSent, "", ErrSameStatus},
+		// Reversals and voiding must be explained
+		{StatusSent, StatusDraft, "", ErrReasonRequired},
+		{StatusSent, StatusDraft, "  ", ErrReasonRequired},
+		{StatusSent, StatusDraft, "sent by mistake", nil},
+		{StatusPaid, StatusSent, "payment bounced", nil},
+		{StatusOverdue, StatusSent, "", ErrReasonRequired},
+		{StatusDraft, StatusVoid, "", ErrReasonRequired},
+		{StatusOverdue, StatusVoid, "written off", nil},
+	}
+	for _, tt := range tests {
+		err := CheckTransition(tt.from, tt.to, tt.reason)
+		if !errors.Is(err, tt.want) || (err == nil) != (tt.want == nil) {
+			t.Errorf("CheckTransition(%s, %s, %q) = %v, want %v", tt.from, tt.to, tt.reason, err, tt.want)
+		}
+		var te *TransitionError
+		if err != nil && (!errors.As(err, &te) || te.From != tt.from || te.To != tt.to) {
+			t.Errorf("CheckTransition(%s, %s) error %#v is not a TransitionError between them", tt.from, tt.to, err)
+		}
+	}
+}
+
+func TestAllowedTransitions(t *testing.T) {
+	if got := AllowedTransitions(StatusVoid); len(got) != 0 {
+		t.Errorf("AllowedTransitions(void) = %v, want none", got)
+	}
+	for from := range statusTransitions {
+		for _, to := range AllowedTransitions(from) {
+			reason := ""
+			if RequiresReason(from, to) {
+				reason = "because"
+			}
+			if err := CheckTransition(from, to, reason); err != nil {
+				t.Errorf("%s to %s is listed as allowed but %v", from, to, err)
+			}
+		}
+	}
+}
+
+func TestParseInvoiceStatus(t *testing.T) {
+	if got, err := ParseInvoiceStatus(" Overdue "); err != nil || got != StatusOverdue {
+		t.Errorf("ParseInvoiceStatus(Overdue) = %q, %v", got, err)
+	}
+	if _, err := ParseInvoiceStatus("cancelled"); err == nil {
+		t.Error("ParseInvoiceStatus(cancelled) succeeded")
+	}
+}
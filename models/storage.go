@@ -1,5 +1,35 @@
 package models
 
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrConflict matches any *ConflictError via errors.Is.
+var ErrConflict = errors.New("record was modified concurrently")
+
+// ConflictError is returned by the Update methods when the stored record has
+// a newer Version than the one being saved, meaning someone else
+// changed it after the caller loaded it.
+type ConflictError struct {
+	Entity  string
+	Name    string
+	Version int
+	Current int
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s %s was changed elsewhere (version %d, yours is %d); reload it and try again",
+		e.Entity, e.Name, e.Current, e.Version)
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// Storage holds invoicer's records. SaveInvoice, SaveEstimate and
+// SaveCreditNote give the document the next free number if its own was
+// taken since GetNext*Number allocated it.
 type Storage interface {
 	GetAllClients() ([]Client, error)
 	GetClient(id string) (*Client, error)
@@ -16,6 +46,57 @@ type Storage interface {
 	GetNextInvoiceNumber(year int) (int, error)
 	GetInvoicesByClient(clientID string) ([]Invoice, error)
 	
+	SavePayment(payment *Payment) error
+	GetPayments(invoiceID string) ([]Payment, error)
+	DeletePayment(id string) error
+	
 	SaveAuditEntry(entry *AuditEntry) error
 	GetAuditEntries(invoiceID string) ([]AuditEntry, error)
+	
+	GetAllRecurringInvoices() ([]RecurringInvoice, error)
+	GetRecurringInvoice(id string) (*RecurringInvoice, error)
+	SaveRecurringInvoice(recurring *RecurringInvoice) error
+	UpdateRecurringInvoice(recurring *RecurringInvoice) error
+	DeleteRecurringInvoice(id string) error
+	
+	GetAllEstimates() ([]Estimate, error)
+	GetEstimate(id string) (*Estimate, error)
+	SaveEstimate(estimate *Estimate) error
+	UpdateEstimate(estimate *Estimate) error
+	DeleteEstimate(id string) error
+	GetNextEstimateNumber(year int) (int, error)
+	
+	GetAllCreditNotes() ([]CreditNote, error)
+	GetCreditNotes(invoiceID string) ([]CreditNote, error)
+	SaveCreditNote(note *CreditNote) error
+	DeleteCreditNote(id string) error
+	GetNextCreditNoteNumber(year int) (int, error)
+	
+	GetAllTaxes() ([]Tax, error)
+	GetTax(id string) (*Tax, error)
+	SaveTax(tax *Tax) error
+	UpdateTax(tax *Tax) error
+	DeleteTax(id string) error
+	
+	GetAllCatalogItems() ([]CatalogItem, error)
+	GetCatalogItem(id string) (*CatalogItem, error)
+	SaveCatalogItem(item *CatalogItem) error
+	UpdateCatalogItem(item *CatalogItem) error
+	DeleteCatalogItem(id string) error
+	
+	GetAllTimeEntries() ([]TimeEntry, error)
+	GetTimeEntry(id string) (*TimeEntry, error)
+	SaveTimeEntry(entry *TimeEntry) error
+	UpdateTimeEntry(entry *TimeEntry) error
+	DeleteTimeEntry(id string) error
+	
+	GetAllExpenses() ([]Expense, error)
+	GetExpense(id string) (*Expense, error)
+	SaveExpense(expense *Expense) error
+	UpdateExpense(expense *Expense) error
+	DeleteExpense(id string) error
+	
+	GetAllClientMappings() ([]ClientMapping, error)
+	SaveClientMapping(mapping *ClientMapping) error
+	DeleteClientMapping(id string) error
 }
\ No newline at end of file
@@ -0,0 +1,168 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Tax is a named tax rate from the tax catalogue. Line items keep a copy of
+// the taxes charged on them, so changing or deleting a catalogue entry does
+// not alter documents that already use it.
+type Tax struct {
+	ID   string          `json:"id"`
+	Name string          `json:"name"`
+	Rate decimal.Decimal `json:"rate"`
+	// Compound taxes are charged on the line amount plus its non-compound
+	// taxes, e.g. a county tax levied on top of state tax.
+	Compound bool `json:"compound,omitempty"`
+	// Inclusive taxes are already part of the line's unit price and are
+	// shown in the breakdown without being added to the total.
+	Inclusive bool      `json:"inclusive,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func NewTax(name string, rate decimal.Decimal, compound, inclusive bool) *Tax {
+	now := time.Now()
+	return &Tax{
+		ID:        uuid.New().String(),
+		Name:      name,
+		Rate:      rate,
+		Compound:  compound,
+		Inclusive: inclusive,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func (t *Tax) Update(name string, rate decimal.Decimal, compound, inclusive bool) {
+	t.Name = name
+	t.Rate = rate
+	t.Compound = compound
+	t.Inclusive = inclusive
+	t.UpdatedAt = time.Now()
+}
+
+func (t *Tax) Validate() error {
+	if strings.TrimSpace(t.Name) == "" {
+		return errors.New("tax name is required")
+	}
+	if t.Rate.IsNegative() {
+		return errors.New("tax rate cannot be negative")
+	}
+	if t.Compound && t.Inclusive {
+		return errors.New("a tax cannot be both compound and inclusive")
+	}
+	return nil
+}
+
+// TaxNames lists the names of taxes separated by commas.
+func TaxNames(taxes []Tax) string {
+	names := make([]string, len(taxes))
+	for i, t := range taxes {
+		names[i] = t.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// TaxAmount is one row of a document's tax breakdown.
+type TaxAmount struct {
+	Name      string
+	Rate      decimal.Decimal
+	Inclusive bool
+	Amount    decimal.Decimal
+}
+
+// taxBreakdown works out every tax charged on items after the document
+// discount. Each line's share of the discount, in proportion to its Total, is
+// taken off first; inclusive taxes are then backed out of what remains to
+// find the line's taxable base, non-compound taxes are charged on the base
+// and compound taxes on the base plus the line's non-compound taxes. The
+// document-wide taxRate, if any, is charged on the sum of the bases and
+// listed first as "Tax". Amounts are rounded to currency's minor units per
+// line or per tax according to the rounding policy.
+func taxBreakdown(items []LineItem, discount, taxRate decimal.Decimal, currency CurrencyCode) []TaxAmount {
+	hundred := decimal.NewFromInt(100)
+	policy := CurrentRoundingPolicy()
+	lineRound := func(amount decimal.Decimal) decimal.Decimal {
+		if policy.Scope == RoundPerLine {
+			return policy.Round(amount, currency)
+		}
+		return amount
+	}
+	subtotal := decimal.Zero
+	for _, item := range items {
+		subtotal = subtotal.Add(item.Total)
+	}
+	keep := decimal.NewFromInt(1)
+	if !discount.IsZero() && !subtotal.IsZero() {
+		keep = subtotal.Sub(discount).Div(subtotal)
+	}
+
+	var order []string
+	amounts := map[string]*TaxAmount{}
+	add := func(id string, t TaxAmount) {
+		if existing, ok := amounts[id]; ok {
+			existing.Amount = existing.Amount.Add(t.Amount)
+			return
+		}
+		order = append(order, id)
+		amounts[id] = &t
+	}
+
+	totalBase, lineTax := decimal.Zero, decimal.Zero
+	for _, item := range items {
+		net := item.Total.Mul(keep)
+
+		inclusiveRate := decimal.Zero
+		for _, t := range item.Taxes {
+			if t.Inclusive {
+				inclusiveRate = inclusiveRate.Add(t.Rate)
+			}
+		}
+		base := net
+		if inclusiveRate.GreaterThan(decimal.Zero) {
+			base = net.Div(decimal.NewFromInt(1).Add(inclusiveRate.Div(hundred)))
+		}
+		totalBase = totalBase.Add(base)
+		lineTax = lineTax.Add(lineRound(base.Mul(taxRate.Div(hundred))))
+
+		simple := decimal.Zero
+		for _, t := range item.Taxes {
+			if t.Compound {
+				continue
+			}
+			amount := lineRound(base.Mul(t.Rate.Div(hundred)))
+			if !t.Inclusive {
+				simple = simple.Add(amount)
+			}
+			add(t.ID, TaxAmount{Name: t.Name, Rate: t.Rate, Inclusive: t.Inclusive, Amount: amount})
+		}
+		for _, t := range item.Taxes {
+			if t.Compound {
+				amount := lineRound(base.Add(simple).Mul(t.Rate.Div(hundred)))
+				add(t.ID, TaxAmount{Name: t.Name, Rate: t.Rate, Amount: amount})
+			}
+		}
+	}
+
+	breakdown := []TaxAmount{}
+	if taxRate.GreaterThan(decimal.Zero) {
+		amount := totalBase.Mul(taxRate.Div(hundred))
+		if policy.Scope == RoundPerLine {
+			amount = lineTax
+		}
+		breakdown = append(breakdown, TaxAmount{Name: "Tax", Rate: taxRate, Amount: amount})
+	}
+	for _, id := range order {
+		breakdown = append(breakdown, *amounts[id])
+	}
+	for i := range breakdown {
+		breakdown[i].Amount = policy.Round(breakdown[i].Amount, currency)
+	}
+	return breakdown
+}
@@ -0,0 +1,194 @@
+package models
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// TimeEntry is a stretch of time worked for a client, either typed in or
+// recorded with the timer.
+type TimeEntry struct {
+	ID          string    `json:"id"`
+	ClientID    string    `json:"client_id"`
+	ClientName  string    `json:"client_name"`
+	Project     string    `json:"project,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Start       time.Time `json:"start"`
+	// End is nil while the timer is running
+	End      *time.Time `json:"end,omitempty"`
+	Billable bool       `json:"billable"`
+	// InvoiceID and InvoiceNumber are set once the entry has been billed
+	InvoiceID     string `json:"invoice_id,omitempty"`
+	InvoiceNumber string `json:"invoice_number,omitempty"`
+	// ExternalID identifies entries imported from another time tracker, so
+	// that importing the same export twice does not duplicate them
+	ExternalID string    `json:"external_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func NewTimeEntry(clientID, clientName, project, description string, start, end time.Time, billable bool) *TimeEntry {
+	now := time.Now()
+	return &TimeEntry{
+		ID:          uuid.New().String(),
+		ClientID:    clientID,
+		ClientName:  clientName,
+		Project:     project,
+		Description: description,
+		Start:       start,
+		End:         &end,
+		Billable:    billable,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// StartTimer returns a running entry that started now.
+func StartTimer(clientID, clientName, project, description string, billable bool) *TimeEntry {
+	now := time.Now()
+	return &TimeEntry{
+		ID:          uuid.New().String(),
+		ClientID:    clientID,
+		ClientName:  clientName,
+		Project:     project,
+		Description: description,
+		Start:       now,
+		Billable:    billable,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+func (e *TimeEntry) Update(clientID, clientName, project, description string, start time.Time, end *time.Time, billable bool) {
+	e.ClientID = clientID
+	e.ClientName = clientName
+	e.Project = project
+	e.Description = description
+	e.Start = start
+	e.End = end
+	e.Billable = billable
+	e.UpdatedAt = time.Now()
+}
+
+func (e *TimeEntry) Validate() error {
+	if e.ClientID == "" {
+		return errors.New("client is required")
+	}
+	if e.End != nil && e.End.Before(e.Start) {
+		return errors.New("end time cannot be before start time")
+	}
+	return nil
+}
+
+func (e *TimeEntry) IsRunning() bool {
+	return e.End == nil
+}
+
+// Stop ends a running entry at the given time.
+func (e *TimeEntry) Stop(at time.Time) {
+	if at.Before(e.Start) {
+		at = e.Start
+	}
+	e.End = &at
+	e.UpdatedAt = time.Now()
+}
+
+// Duration is how long the entry lasted, or has lasted so far if the timer
+// is still running.
+func (e *TimeEntry) Duration() time.Duration {
+	if e.End == nil {
+		return time.Since(e.Start)
+	}
+	return e.End.Sub(e.Start)
+}
+
+func (e *TimeEntry) IsBilled() bool {
+	return e.InvoiceID != ""
+}
+
+func (e *TimeEntry) MarkBilled(invoiceID, invoiceNumber string) {
+	e.InvoiceID = invoiceID
+	e.InvoiceNumber = invoiceNumber
+	e.UpdatedAt = time.Now()
+}
+
+// UnbilledTime picks the finished, billable entries for clientID that have
+// not been invoiced yet and started within the service period from..to,
+// both days included. A nil bound leaves that end of the period open.
+func UnbilledTime(entries []TimeEntry, clientID string, from, to *time.Time) []TimeEntry {
+	var unbilled []TimeEntry
+	for _, e := range entries {
+		if e.ClientID != clientID || !e.Billable || e.IsBilled() || e.IsRunning() {
+			continue
+		}
+		if from != nil && e.Start.Before(startOfDay(*from)) {
+			continue
+		}
+		if to != nil && !e.Start.Before(startOfDay(*to).AddDate(0, 0, 1)) {
+			continue
+		}
+		unbilled = append(unbilled, e)
+	}
+	return unbilled
+}
+
+// startOfDay is local midnight on t's date. Service periods are calendar
+// dates, parsed without a time zone, while entries are recorded in local time.
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// TimeLineItem is a line item billing a group of time entries.
+type TimeLineItem struct {
+	Item     LineItem
+	EntryIDs []string
+}
+
+// BillTime groups entries into one line item per project, or per
+// description for entries without a project, priced at rate per hour.
+// Hours are added up before rounding to two decimal places, and lines are
+// ordered by when their first entry started.
+func BillTime(entries []TimeEntry, rate decimal.Decimal) []TimeLineItem {
+	sorted := append([]TimeEntry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+
+	var (
+		keys    []string
+		labels  = map[string]string{}
+		seconds = map[string]int64{}
+		ids     = map[string][]string{}
+	)
+	for _, e := range sorted {
+		label := strings.TrimSpace(e.Project)
+		if label == "" {
+			label = strings.TrimSpace(e.Description)
+		}
+		if label == "" {
+			label = "Time"
+		}
+		key := strings.ToLower(label)
+		if _, ok := labels[key]; !ok {
+			keys = append(keys, key)
+			labels[key] = label
+		}
+		seconds[key] += int64(e.Duration() / time.Second)
+		ids[key] = append(ids[key], e.ID)
+	}
+
+	items := make([]TimeLineItem, 0, len(keys))
+	for _, key := range keys {
+		hours := decimal.NewFromInt(seconds[key]).Div(decimal.NewFromInt(3600)).Round(2)
+		items = append(items, TimeLineItem{
+			Item:     *NewLineItem(labels[key], hours, rate),
+			EntryIDs: ids[key],
+		})
+	}
+	return items
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+// entry is a billable entry for c1 starting at start and lasting minutes.
+func entry(project, description string, start time.Time, minutes int) TimeEntry {
+	return *NewTimeEntry("c1", "Acme", project, description, start, start.Add(time.Duration(minutes)*time.Minute), true)
+}
+
+func TestBillTime(t *testing.T) {
+	day := time.Date(2026, 3, 2, 9, 0, 0, 0, time.Local)
+	entries := []TimeEntry{
+		entry("", "Support call", day.Add(4*time.Hour), 50),
+		entry("Website", "Layout", day, 20),
+		entry("website ", "Fonts", day.Add(time.Hour), 20),
+		entry("Website", "", day.Add(2*time.Hour), 20),
+		entry("", "", day.Add(5*time.Hour), 15),
+	}
+
+	items := BillTime(entries, dec("90"))
+	want := []struct {
+		description string
+		hours       string
+		total       string
+		entries     int
+	}{
+		// Three times 20 minutes is one hour, not 3 x 0.33
+		{"Website", "1", "90", 3},
+		{"Support call", "0.83", "74.7", 1},
+		{"Time", "0.25", "22.5", 1},
+	}
+	if len(items) != len(want) {
+		t.Fatalf("%d line items, want %d: %+v", len(items), len(want), items)
+	}
+	for i, w := range want {
+		item := items[i].Item
+		if item.Description != w.description || len(items[i].EntryIDs) != w.entries {
+			t.Errorf("line %d = %s with %d entries, want %s with %d", i, item.Description, len(items[i].EntryIDs), w.description, w.entries)
+		}
+		checkAmount(t, w.description+" hours", item.Quantity, w.hours)
+		checkAmount(t, w.description+" total", item.Total, w.total)
+	}
+	if items[0].EntryIDs[0] != entries[1].ID {
+		t.Error("the website entries are not in the order they started")
+	}
+}
+
+func TestUnbilledTimePeriod(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	at := func(month time.Month, day, hour int) time.Time {
+		return time.Date(2026, month, day, hour, 0, 0, 0, time.Local)
+	}
+
+	billed := entry("", "Billed", at(3, 5, 9), 60)
+	billed.MarkBilled("i1", "2026-01")
+	unbillable := entry("", "Unbillable", at(3, 5, 9), 60)
+	unbillable.Billable = false
+	running := *StartTimer("c1", "Acme", "", "Running", true)
+	running.Start = at(3, 5, 9)
+	other := entry("", "Other client", at(3, 5, 9), 60)
+	other.ClientID = "c2"
+
+	entries := []TimeEntry{
+		entry("", "Last of February", at(2, 28, 23), 60),
+		entry("", "First of March", at(3, 1, 0), 60),
+		entry("", "Last evening of March", at(3, 31, 23), 60),
+		entry("", "First of April", at(4, 1, 0), 60),
+		billed, unbillable, running, other,
+	}
+
+	var got []string
+	for _, e := range UnbilledTime(entries, "c1", &from, &to) {
+		got = append(got, e.Description)
+	}
+	if len(got) != 2 || got[0] != "First of March" || got[1] != "Last evening of March" {
+		t.Errorf("unbilled in March = %v", got)
+	}
+	if open := UnbilledTime(entries, "c1", nil, nil); len(open) != 4 {
+		t.Errorf("%d unbilled without a period, want 4", len(open))
+	}
+}
@@ -0,0 +1,101 @@
+package overdue
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/user/invoicer/audit"
+	"github.com/user/invoicer/models"
+)
+
+// ChangedBy identifies the sweeper in the audit log.
+const ChangedBy = "overdue-sweeper"
+
+type Sweeper struct {
+	storage models.Storage
+	audit   *audit.Service
+}
+
+func NewSweeper(storage models.Storage) *Sweeper {
+	return &Sweeper{
+		storage: storage,
+		audit:   audit.NewService(storage),
+	}
+}
+
+// Failure is an invoice the sweeper could not move to overdue, or whose
+// change could not be written to the audit log.
+type Failure struct {
+	Invoice models.Invoice
+	Err     error
+}
+
+// Result lists what a sweep changed.
+type Result struct {
+	Marked []models.Invoice
+	Failed []Failure
+}
+
+// Run moves every sent invoice with a balance due whose due date is before
+// today (relative to now) to overdue.
+func (s *Sweeper) Run(now time.Time) (*Result, error) {
+	invoices, err := s.storage.GetAllInvoices()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load invoices: %w", err)
+	}
+
+	today := startOfDay(now)
+	result := &Result{}
+	for i := range invoices {
+		invoice := &invoices[i]
+		if invoice.Status != models.StatusSent || !startOfDay(invoice.DueDate).Before(today) {
+			continue
+		}
+		// Nothing is owed, e.g. a paid invoice moved back to sent
+		if !invoice.BalanceDue().GreaterThan(decimal.Zero) {
+			continue
+		}
+
+		reason := fmt.Sprintf("Due date %s passed", invoice.DueDate.Format("Jan 2, 2006"))
+		err := s.audit.ChangeStatus(invoice, models.StatusOverdue, reason, ChangedBy)
+		if invoice.Status == models.StatusOverdue {
+			result.Marked = append(result.Marked, *invoice)
+		}
+		if err != nil {
+			result.Failed = append(result.Failed, Failure{Invoice: *invoice, Err: err})
+		}
+	}
+
+	return result, nil
+}
+
+// Summary describes the result in one line, or returns "" if nothing happened.
+func (r *Result) Summary() string {
+	var parts []string
+	if len(r.Marked) > 0 {
+		numbers := make([]string, len(r.Marked))
+		for i, invoice := range r.Marked {
+			numbers[i] = invoice.Number
+		}
+		parts = append(parts, fmt.Sprintf("%d %s marked overdue: %s",
+			len(r.Marked), plural(len(r.Marked)), strings.Join(numbers, ", ")))
+	}
+	if len(r.Failed) > 0 {
+		parts = append(parts, fmt.Sprintf("%d failed", len(r.Failed)))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return "invoice"
+	}
+	return "invoices"
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.Local()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
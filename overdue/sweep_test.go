@@ -0,0 +1,98 @@
+package overdue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/user/invoicer/models"
+	"github.com/user/invoicer/storage"
+)
+
+// saveInvoice saves a 100 invoice in status, due days after now.
+func saveInvoice(t *testing.T, store models.Storage, number string, status models.InvoiceStatus, now time.Time, days int) *models.Invoice {
+	t.Helper()
+	invoice := models.NewInvoice("c1", "Acme", number)
+	invoice.AddLineItem(*models.NewLineItem("Work", decimal.NewFromInt(1), decimal.NewFromInt(100)))
+	invoice.Status = status
+	invoice.DueDate = now.AddDate(0, 0, days)
+	if err := store.SaveInvoice(invoice); err != nil {
+		t.Fatal(err)
+	}
+	return invoice
+}
+
+func TestSweep(t *testing.T) {
+	store, err := storage.NewJSONStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.Local)
+	late := saveInvoice(t, store, "2026-01", models.StatusSent, now, -1)
+	saveInvoice(t, store, "2026-02", models.StatusSent, now, 0)
+	saveInvoice(t, store, "2026-03", models.StatusDraft, now, -30)
+	saveInvoice(t, store, "2026-04", models.StatusPaid, now, -30)
+	// Due earlier today is not late yet
+	saveInvoice(t, store, "2026-05", models.StatusSent, now.Add(-14*time.Hour), 0)
+
+	result, err := NewSweeper(store).Run(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Marked) != 1 || result.Marked[0].ID != late.ID || len(result.Failed) != 0 {
+		t.Fatalf("result = %+v, want only 2026-01 marked", result)
+	}
+	if got := result.Summary(); got != "1 invoice marked overdue: 2026-01" {
+		t.Errorf("summary = %q", got)
+	}
+
+	saved, err := store.GetInvoice(late.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Status != models.StatusOverdue {
+		t.Errorf("status = %s, want overdue", saved.Status)
+	}
+	entries, err := store.GetAuditEntries(late.ID)
+	if err != nil || len(entries) != 1 || entries[0].ChangedBy != ChangedBy || entries[0].Reason != "Due date Mar 9, 2026 passed" {
+		t.Errorf("audit entries = %+v, %v", entries, err)
+	}
+
+	// The next day, those due today are late; the overdue one is left be
+	result, err = NewSweeper(store).Run(now.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := result.Summary(); got != "2 invoices marked overdue: 2026-02, 2026-05" {
+		t.Errorf("the next day's summary = %q", got)
+	}
+	if got := (&Result{}).Summary(); got != "" {
+		t.Errorf("empty summary = %q", got)
+	}
+}
+
+func TestSweepSkipsInvoicesWithNothingDue(t *testing.T) {
+	store, err := storage.NewJSONStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	// Paid in full, then moved back to sent
+	invoice := models.NewInvoice("c1", "Acme", "2026-01")
+	invoice.AddLineItem(*models.NewLineItem("Work", decimal.NewFromInt(1), decimal.NewFromInt(100)))
+	invoice.Status = models.StatusSent
+	invoice.DueDate = now.AddDate(0, 0, -10)
+	invoice.AmountPaid = invoice.Total
+	if err := store.SaveInvoice(invoice); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewSweeper(store).Run(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Marked) != 0 {
+		t.Errorf("marked %s overdue with nothing due", result.Marked[0].Number)
+	}
+}
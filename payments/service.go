@@ -0,0 +1,169 @@
+package payments
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+	"github.com/user/invoicer/audit"
+	"github.com/user/invoicer/models"
+)
+
+type Service struct {
+	storage models.Storage
+	audit   *audit.Service
+}
+
+func NewService(storage models.Storage) *Service {
+	return &Service{
+		storage: storage,
+		audit:   audit.NewService(storage),
+	}
+}
+
+// RecordPayment stores payment against invoice, recomputes the invoice's
+// AmountPaid from the full ledger and marks it paid once nothing is left to
+// pay. It reports whether the invoice status changed.
+func (s *Service) RecordPayment(invoice *models.Invoice, payment *models.Payment) (bool, error) {
+	if !invoice.AcceptsPayments() {
+		return false, fmt.Errorf("cannot record a payment against a %s invoice", invoice.Status)
+	}
+	if !payment.Amount.GreaterThan(decimal.Zero) {
+		return false, fmt.Errorf("payment amount must be positive")
+	}
+	if payment.Amount.GreaterThan(invoice.BalanceDue()) {
+		return false, fmt.Errorf("payment of %s exceeds balance due of %s",
+			invoice.Currency.Format(payment.Amount), invoice.Currency.Format(invoice.BalanceDue()))
+	}
+
+	payment.InvoiceID = invoice.ID
+	if err := s.storage.SavePayment(payment); err != nil {
+		return false, fmt.Errorf("failed to save payment: %w", err)
+	}
+
+	changed, err := s.settle(invoice)
+	if err != nil && !changed {
+		// The invoice was not saved; keep the ledger consistent with it
+		s.storage.DeletePayment(payment.ID)
+	}
+	return changed, err
+}
+
+// RemovePayment deletes a payment recorded against invoice, such as one that
+// bounced, and recomputes the invoice's balance. A paid invoice that is owed
+// money again goes back to sent. It reports whether the invoice status
+// changed.
+func (s *Service) RemovePayment(invoice *models.Invoice, paymentID string) (bool, error) {
+	payments, err := s.storage.GetPayments(invoice.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to load payments: %w", err)
+	}
+	var payment *models.Payment
+	for i := range payments {
+		if payments[i].ID == paymentID {
+			payment = &payments[i]
+			break
+		}
+	}
+	if payment == nil {
+		return false, fmt.Errorf("payment %s is not recorded against invoice %s", paymentID, invoice.Number)
+	}
+
+	if err := s.storage.DeletePayment(payment.ID); err != nil {
+		return false, fmt.Errorf("failed to delete payment: %w", err)
+	}
+	oldStatus := invoice.Status
+	if changed, err := s.settle(invoice); err != nil {
+		if !changed {
+			s.storage.SavePayment(payment)
+		}
+		return changed, err
+	}
+
+	if invoice.Status == models.StatusPaid && invoice.BalanceDue().GreaterThan(decimal.Zero) {
+		reason := fmt.Sprintf("Payment of %s removed", invoice.Currency.Format(payment.Amount))
+		if err := s.audit.ChangeStatus(invoice, models.StatusSent, reason, models.ChangedBySystem); err != nil {
+			return invoice.Status != oldStatus, err
+		}
+	}
+	return invoice.Status != oldStatus, nil
+}
+
+// IssueCreditNote stores note against invoice, takes it off the invoice's
+// balance and records it in the audit log. An unpaid invoice whose balance
+// reaches zero is marked paid. It reports whether the invoice status changed.
+func (s *Service) IssueCreditNote(invoice *models.Invoice, note *models.CreditNote) (bool, error) {
+	if !invoice.AcceptsPayments() {
+		return false, fmt.Errorf("cannot issue a credit note against a %s invoice", invoice.Status)
+	}
+	if len(note.LineItems) == 0 {
+		return false, fmt.Errorf("credit note has no line items")
+	}
+	if !note.Amount().GreaterThan(decimal.Zero) {
+		return false, fmt.Errorf("credit note amount must be positive")
+	}
+	if note.Amount().GreaterThan(invoice.CreditableAmount()) {
+		return false, fmt.Errorf("credit of %s exceeds the %s left to credit on invoice %s",
+			invoice.Currency.Format(note.Amount()), invoice.Currency.Format(invoice.CreditableAmount()), invoice.Number)
+	}
+
+	note.InvoiceID = invoice.ID
+	note.InvoiceNumber = invoice.Number
+	if err := s.storage.SaveCreditNote(note); err != nil {
+		return false, fmt.Errorf("failed to save credit note: %w", err)
+	}
+
+	changed, err := s.settle(invoice)
+	if err != nil && !changed {
+		s.storage.DeleteCreditNote(note.ID)
+		return false, err
+	}
+
+	if auditErr := s.audit.LogCreditNote(invoice, note); auditErr != nil && err == nil {
+		err = fmt.Errorf("credit note issued but audit log failed: %w", auditErr)
+	}
+	return changed, err
+}
+
+// settle refreshes invoice.AmountPaid and invoice.AmountCredited from
+// storage, moves the invoice to paid when the balance reaches zero and saves
+// it.
+func (s *Service) settle(invoice *models.Invoice) (bool, error) {
+	payments, err := s.storage.GetPayments(invoice.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to load payments: %w", err)
+	}
+	notes, err := s.storage.GetCreditNotes(invoice.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to load credit notes: %w", err)
+	}
+
+	oldStatus := invoice.Status
+	oldPaid := invoice.AmountPaid
+	oldCredited := invoice.AmountCredited
+	invoice.ApplyPayments(payments)
+	invoice.ApplyCreditNotes(notes)
+
+	changed := false
+	if !invoice.BalanceDue().GreaterThan(decimal.Zero) && invoice.Status != models.StatusPaid {
+		if err := invoice.UpdateStatus(models.StatusPaid, "Paid in full"); err != nil {
+			invoice.AmountPaid = oldPaid
+			invoice.AmountCredited = oldCredited
+			return false, err
+		}
+		changed = true
+	}
+
+	if err := s.storage.UpdateInvoice(invoice); err != nil {
+		invoice.AmountPaid = oldPaid
+		invoice.AmountCredited = oldCredited
+		invoice.Status = oldStatus
+		return false, fmt.Errorf("failed to save invoice: %w", err)
+	}
+
+	if changed {
+		if err := s.audit.LogStatusChange(invoice, oldStatus, "Paid in full"); err != nil {
+			return true, fmt.Errorf("status updated but audit log failed: %w", err)
+		}
+	}
+	return changed, nil
+}
@@ -0,0 +1,63 @@
+package payments
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/user/invoicer/models"
+	"github.com/user/invoicer/storage"
+)
+
+func TestRemoveBouncedPayment(t *testing.T) {
+	store, err := storage.NewJSONStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	invoice := models.NewInvoice("c1", "Acme", "2026-01")
+	invoice.AddLineItem(*models.NewLineItem("Work", decimal.NewFromInt(1), decimal.NewFromInt(300)))
+	invoice.Status = models.StatusSent
+	if err := store.SaveInvoice(invoice); err != nil {
+		t.Fatal(err)
+	}
+
+	service := NewService(store)
+	deposit := models.NewPayment(invoice.ID, decimal.NewFromInt(100), time.Now(), "Bank transfer", "", "")
+	check := models.NewPayment(invoice.ID, decimal.NewFromInt(200), time.Now(), "Check", "1042", "")
+	for _, payment := range []*models.Payment{deposit, check} {
+		if _, err := service.RecordPayment(invoice, payment); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if invoice.Status != models.StatusPaid {
+		t.Fatalf("status = %s after paying in full", invoice.Status)
+	}
+
+	changed, err := service.RemovePayment(invoice, check.ID)
+	if err != nil || !changed {
+		t.Fatalf("RemovePayment() = %v, %v", changed, err)
+	}
+	saved, err := store.GetInvoice(invoice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Status != models.StatusSent || !saved.AmountPaid.Equal(decimal.NewFromInt(100)) || !saved.BalanceDue().Equal(decimal.NewFromInt(200)) {
+		t.Errorf("after removing the check: %s, paid %s, due %s", saved.Status, saved.AmountPaid, saved.BalanceDue())
+	}
+	if ledger, _ := store.GetPayments(invoice.ID); len(ledger) != 1 || ledger[0].ID != deposit.ID {
+		t.Errorf("ledger = %+v, want only the deposit", ledger)
+	}
+	entries, err := store.GetAuditEntries(invoice.ID)
+	if err != nil || len(entries) == 0 || entries[len(entries)-1].NewStatus != models.StatusSent {
+		t.Errorf("audit entries = %+v, %v, want the move back to sent", entries, err)
+	}
+
+	// A partial payment comes off without a status change
+	if changed, err := service.RemovePayment(invoice, deposit.ID); err != nil || changed {
+		t.Errorf("RemovePayment(deposit) = %v, %v", changed, err)
+	}
+	if _, err := service.RemovePayment(invoice, deposit.ID); err == nil {
+		t.Error("removed the same payment twice")
+	}
+}
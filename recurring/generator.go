@@ -0,0 +1,124 @@
+package recurring
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/user/invoicer/models"
+)
+
+type Generator struct {
+	storage models.Storage
+}
+
+func NewGenerator(storage models.Storage) *Generator {
+	return &Generator{storage: storage}
+}
+
+// Failure is a schedule whose invoices could not be generated.
+type Failure struct {
+	Schedule models.RecurringInvoice
+	Err      error
+}
+
+// Result lists the draft invoices a run created.
+type Result struct {
+	Created []models.Invoice
+	Failed  []Failure
+}
+
+// Run generates a draft invoice for every period of every active schedule
+// that has started on or before now. A schedule that was not run for a while
+// catches up with one invoice per missed period.
+func (g *Generator) Run(now time.Time) (*Result, error) {
+	schedules, err := g.storage.GetAllRecurringInvoices()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load recurring invoices: %w", err)
+	}
+
+	result := &Result{}
+	for i := range schedules {
+		schedule := &schedules[i]
+		if !schedule.Active {
+			continue
+		}
+		for !schedule.NextRunDate.After(now) {
+			invoice, err := g.generate(schedule)
+			if invoice != nil {
+				result.Created = append(result.Created, *invoice)
+			}
+			if errors.Is(err, models.ErrConflict) {
+				// Another invoicer is generating this schedule's invoices
+				break
+			}
+			if err != nil {
+				result.Failed = append(result.Failed, Failure{Schedule: *schedule, Err: err})
+				break
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// RunSchedule generates the invoice for schedule's next period immediately,
+// whether or not it is due yet, and advances the schedule.
+func (g *Generator) RunSchedule(schedule *models.RecurringInvoice) (*models.Invoice, error) {
+	return g.generate(schedule)
+}
+
+// generate moves the schedule on past its next period and then saves that
+// period's invoice. Claiming the period first means that of two invoicers
+// sharing the data, only the one whose schedule update wins the version
+// check creates the invoice. If the invoice cannot be saved the schedule is
+// moved back.
+func (g *Generator) generate(schedule *models.RecurringInvoice) (*models.Invoice, error) {
+	year := schedule.NextRunDate.Year()
+	seq, err := g.storage.GetNextInvoiceNumber(year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next invoice number: %w", err)
+	}
+
+	invoice := schedule.GenerateInvoice(models.GenerateInvoiceNumber(year, seq))
+	original := *schedule
+	schedule.Advance()
+	if err := g.storage.UpdateRecurringInvoice(schedule); err != nil {
+		*schedule = original
+		return nil, fmt.Errorf("failed to advance schedule: %w", err)
+	}
+
+	if err := g.storage.SaveInvoice(invoice); err != nil {
+		advanced := *schedule
+		*schedule = original
+		schedule.Version = advanced.Version
+		if rollbackErr := g.storage.UpdateRecurringInvoice(schedule); rollbackErr != nil {
+			*schedule = advanced
+			return nil, fmt.Errorf("failed to save invoice, and the schedule skipped its period: %w", err)
+		}
+		return nil, fmt.Errorf("failed to save invoice: %w", err)
+	}
+
+	return invoice, nil
+}
+
+// Summary describes the result in one line, or returns "" if nothing happened.
+func (r *Result) Summary() string {
+	var parts []string
+	if len(r.Created) > 0 {
+		numbers := make([]string, len(r.Created))
+		for i, invoice := range r.Created {
+			numbers[i] = invoice.Number
+		}
+		noun := "draft invoices"
+		if len(r.Created) == 1 {
+			noun = "draft invoice"
+		}
+		parts = append(parts, fmt.Sprintf("%d recurring %s created: %s", len(r.Created), noun, strings.Join(numbers, ", ")))
+	}
+	if len(r.Failed) > 0 {
+		parts = append(parts, fmt.Sprintf("%d failed", len(r.Failed)))
+	}
+	return strings.Join(parts, "; ")
+}
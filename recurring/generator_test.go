@@ -0,0 +1,113 @@
+package recurring
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/user/invoicer/models"
+	"github.com/user/invoicer/storage"
+)
+
+// newSchedule saves a monthly schedule that started two months before now.
+func newSchedule(t *testing.T, store models.Storage, now time.Time) *models.RecurringInvoice {
+	t.Helper()
+	start := time.Date(now.Year(), now.Month()-2, 1, 0, 0, 0, 0, time.Local)
+	schedule := models.NewRecurringInvoice("c1", "Acme", "Retainer", models.CadenceMonthly, 1, start)
+	schedule.LineItems = []models.LineItem{*models.NewLineItem("Retainer", decimal.NewFromInt(1), decimal.NewFromInt(500))}
+	if err := store.SaveRecurringInvoice(schedule); err != nil {
+		t.Fatal(err)
+	}
+	return schedule
+}
+
+func TestRunCatchesUp(t *testing.T) {
+	store, err := storage.NewJSONStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	newSchedule(t, store, now)
+
+	result, err := NewGenerator(store).Run(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Created) != 3 || len(result.Failed) != 0 {
+		t.Fatalf("created %d invoices with failures %v, want 3", len(result.Created), result.Failed)
+	}
+	// Nothing is due again until next month
+	if result, _ := NewGenerator(store).Run(now); len(result.Created) != 0 {
+		t.Errorf("a second run created %d invoices", len(result.Created))
+	}
+}
+
+func TestTwoProcessesGenerateOnce(t *testing.T) {
+	dir := t.TempDir()
+	first, err := storage.NewJSONStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := storage.NewJSONStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	schedule := newSchedule(t, first, now)
+
+	// The second process loaded the schedule before the first generated
+	stale, err := second.GetRecurringInvoice(schedule.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewGenerator(first).RunSchedule(schedule); err != nil {
+		t.Fatal(err)
+	}
+	invoice, err := NewGenerator(second).RunSchedule(stale)
+	if invoice != nil || !errors.Is(err, models.ErrConflict) {
+		t.Errorf("RunSchedule(stale) = %v, %v, want a conflict and no invoice", invoice, err)
+	}
+	invoices, err := second.GetAllInvoices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(invoices) != 1 {
+		t.Errorf("%d invoices for one period, want 1", len(invoices))
+	}
+}
+
+// failingSaves is storage whose invoices cannot be saved.
+type failingSaves struct {
+	models.Storage
+}
+
+func (failingSaves) SaveInvoice(*models.Invoice) error {
+	return errors.New("disk full")
+}
+
+func TestFailedSaveMovesScheduleBack(t *testing.T) {
+	store, err := storage.NewJSONStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	schedule := newSchedule(t, store, now)
+	runDate := schedule.NextRunDate
+
+	if _, err := NewGenerator(failingSaves{store}).RunSchedule(schedule); err == nil {
+		t.Fatal("RunSchedule() succeeded without saving the invoice")
+	}
+	saved, err := store.GetRecurringInvoice(schedule.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !saved.NextRunDate.Equal(runDate) || !schedule.NextRunDate.Equal(runDate) {
+		t.Errorf("next run = %s (saved %s), want it back at %s", schedule.NextRunDate, saved.NextRunDate, runDate)
+	}
+	// The schedule can still be run, so the period is not lost
+	if invoice, err := NewGenerator(store).RunSchedule(schedule); err != nil || invoice == nil {
+		t.Errorf("RunSchedule() after the failure = %v, %v", invoice, err)
+	}
+}
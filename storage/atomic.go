@@ -0,0 +1,211 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultGenerations is how many previous versions of each JSON data file
+// are kept as rollback points next to the live file (invoices.json.1 is the
+// newest, invoices.json.N the oldest).
+const DefaultGenerations = 5
+
+// CorruptFileError is returned by NewJSONStorage when a data file is missing
+// or cannot be parsed. Generation is the newest rollback point that still
+// parses, or empty if none is usable.
+type CorruptFileError struct {
+	Path       string
+	Generation string
+	Err        error
+}
+
+func (e *CorruptFileError) Error() string {
+	return fmt.Sprintf("data file %s is corrupt: %v", e.Path, e.Err)
+}
+
+func (e *CorruptFileError) Unwrap() error {
+	return e.Err
+}
+
+// Recover replaces the corrupt file with its newest good generation. The
+// corrupt file is kept alongside with a .corrupt-<timestamp> suffix so that
+// nothing is lost if the user wants to inspect it.
+func (e *CorruptFileError) Recover() error {
+	if e.Generation == "" {
+		return fmt.Errorf("no usable generation found for %s", e.Path)
+	}
+
+	if _, err := os.Stat(e.Path); err == nil {
+		keep := fmt.Sprintf("%s.corrupt-%s", e.Path, time.Now().Format("20060102_150405"))
+		if err := os.Rename(e.Path, keep); err != nil {
+			return fmt.Errorf("failed to set aside corrupt file: %w", err)
+		}
+	}
+
+	data, err := os.ReadFile(e.Generation)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(e.Path, data, 0644, 0)
+}
+
+func generationPath(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
+
+// validJSONArray reports whether data holds a JSON array, which is the shape
+// of every data file JSONStorage writes.
+func validJSONArray(data []byte) error {
+	var items []json.RawMessage
+	return json.Unmarshal(data, &items)
+}
+
+// checkFile verifies that path parses, returning a *CorruptFileError that
+// points at the newest good generation if it does not.
+func checkFile(path string, generations int) error {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		err = validJSONArray(data)
+	}
+	if err == nil {
+		return nil
+	}
+
+	corrupt := &CorruptFileError{Path: path, Err: err}
+	for n := 1; n <= generations; n++ {
+		gen := generationPath(path, n)
+		data, err := os.ReadFile(gen)
+		if err != nil {
+			continue
+		}
+		if validJSONArray(data) == nil {
+			corrupt.Generation = gen
+			break
+		}
+	}
+	return corrupt
+}
+
+// hasGenerations reports whether any rollback point exists for path.
+func hasGenerations(path string, generations int) bool {
+	for n := 1; n <= generations; n++ {
+		if _, err := os.Stat(generationPath(path, n)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// rotateGenerations shifts path.1..path.N-1 up by one and links the current
+// contents of path into path.1. Linking rather than renaming means the live
+// file is never absent, even if we crash before the new version lands.
+func rotateGenerations(path string, generations int) error {
+	if generations <= 0 {
+		return nil
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+
+	if err := os.Remove(generationPath(path, generations)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for n := generations - 1; n >= 1; n-- {
+		err := os.Rename(generationPath(path, n), generationPath(path, n+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	newest := generationPath(path, 1)
+	if err := os.Link(path, newest); err != nil {
+		// Some filesystems (and synced folders) do not support hard links.
+		return copyFile(path, newest)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory,
+// fsyncs it, rotates the existing generations and renames it over path, so a
+// reader only ever sees the old or the new contents.
+func writeFileAtomic(path string, data []byte, perm os.FileMode, generations int) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		return err
+	}
+
+	if err := rotateGenerations(path, generations); err != nil {
+		return fmt.Errorf("failed to rotate generations of %s: %w", path, err)
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return err
+	}
+
+	syncDir(dir)
+	return nil
+}
+
+// syncDir flushes directory metadata so the rename itself is durable. Not
+// every platform supports syncing a directory, so errors are ignored.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
+
+// removeStaleTempFiles deletes temporary files left behind by a write that
+// was interrupted before its rename.
+func removeStaleTempFiles(path string) {
+	matches, _ := filepath.Glob(filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*"))
+	for _, match := range matches {
+		os.Remove(match)
+	}
+}
+
+func copyFile(src, dst string) error {
+	source, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	destination, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(destination, source); err != nil {
+		destination.Close()
+		return err
+	}
+	if err := destination.Sync(); err != nil {
+		destination.Close()
+		return err
+	}
+	return destination.Close()
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerationsRotate(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewJSONStorageWithGenerations(dir, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 5; i++ {
+		newTestInvoice(t, store, fmt.Sprintf("2026-%02d", i))
+	}
+
+	path := filepath.Join(dir, "invoices.json")
+	for n := 1; n <= 3; n++ {
+		if _, err := os.Stat(generationPath(path, n)); err != nil {
+			t.Errorf("generation %d: %v", n, err)
+		}
+	}
+	if _, err := os.Stat(generationPath(path, 4)); !os.IsNotExist(err) {
+		t.Errorf("a fourth generation was kept: %v", err)
+	}
+	// Each generation is the file as it was one save earlier
+	for n, want := range map[int]int{1: 4, 2: 3, 3: 2} {
+		data, err := os.ReadFile(generationPath(path, n))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var invoices []json.RawMessage
+		if err := json.Unmarshal(data, &invoices); err != nil || len(invoices) != want {
+			t.Errorf("generation %d holds %d invoices (%v), want %d", n, len(invoices), err, want)
+		}
+	}
+}
+
+func TestRecoverTruncatedFile(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewJSONStorageWithGenerations(dir, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 3; i++ {
+		newTestInvoice(t, store, fmt.Sprintf("2026-%02d", i))
+	}
+
+	// A crash or a sync tool cut the file short, and spoilt its newest
+	// generation too
+	path := filepath.Join(dir, "invoices.json")
+	for _, file := range []string{path, generationPath(path, 1)} {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, data[:len(data)/2], 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err = NewJSONStorageWithGenerations(dir, 3)
+	var corrupt *CorruptFileError
+	if !errors.As(err, &corrupt) {
+		t.Fatalf("opening a truncated file: %v, want a CorruptFileError", err)
+	}
+	if corrupt.Path != path || corrupt.Generation != generationPath(path, 2) {
+		t.Fatalf("corrupt = %s with generation %s, want %s", corrupt.Path, corrupt.Generation, generationPath(path, 2))
+	}
+
+	if err := corrupt.Recover(); err != nil {
+		t.Fatal(err)
+	}
+	store, err = NewJSONStorageWithGenerations(dir, 3)
+	if err != nil {
+		t.Fatalf("opening the recovered file: %v", err)
+	}
+	// Generation 2 is from before the last two saves
+	invoices, err := store.GetAllInvoices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(invoices) != 1 || invoices[0].Number != "2026-01" {
+		t.Errorf("recovered %d invoices, want 2026-01 only", len(invoices))
+	}
+	if kept, _ := filepath.Glob(path + ".corrupt-*"); len(kept) != 1 {
+		t.Errorf("corrupt copies = %v, want the truncated file set aside", kept)
+	}
+
+	// Without a good generation there is nothing to recover
+	err = checkFile(filepath.Join(dir, "missing.json"), 3)
+	if !errors.As(err, &corrupt) || corrupt.Generation != "" || corrupt.Recover() == nil {
+		t.Errorf("checkFile(missing) = %v, want a CorruptFileError without a generation", err)
+	}
+}
@@ -6,106 +6,168 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 
 	"github.com/user/invoicer/models"
 )
 
 type JSONStorage struct {
-	dataDir      string
-	clientsFile  string
-	invoicesFile string
-	auditFile    string
-	mu           sync.RWMutex
+	dataDir       string
+	clientsFile   string
+	invoicesFile  string
+	auditFile     string
+	paymentsFile  string
+	recurringFile string
+	estimatesFile string
+	creditsFile   string
+	taxesFile     string
+	catalogFile   string
+	timeFile      string
+	mappingsFile  string
+	expensesFile  string
+	generations   int
+	lock          dirLock
+	mu            sync.RWMutex
 }
 
 func NewJSONStorage(dataDir string) (*JSONStorage, error) {
+	return NewJSONStorageWithGenerations(dataDir, DefaultGenerations)
+}
+
+// NewJSONStorageWithGenerations is like NewJSONStorage but keeps the given
+// number of rollback generations for each data file. Zero disables them.
+func NewJSONStorageWithGenerations(dataDir string, generations int) (*JSONStorage, error) {
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create data directory: %w", err)
 	}
-	
+
 	s := &JSONStorage{
-		dataDir:      dataDir,
-		clientsFile:  filepath.Join(dataDir, "clients.json"),
-		invoicesFile: filepath.Join(dataDir, "invoices.json"),
-		auditFile:    filepath.Join(dataDir, "audit.json"),
+		dataDir:       dataDir,
+		clientsFile:   filepath.Join(dataDir, "clients.json"),
+		invoicesFile:  filepath.Join(dataDir, "invoices.json"),
+		auditFile:     filepath.Join(dataDir, "audit.json"),
+		paymentsFile:  filepath.Join(dataDir, "payments.json"),
+		recurringFile: filepath.Join(dataDir, "recurring.json"),
+		estimatesFile: filepath.Join(dataDir, "estimates.json"),
+		creditsFile:   filepath.Join(dataDir, "credit_notes.json"),
+		taxesFile:     filepath.Join(dataDir, "taxes.json"),
+		catalogFile:   filepath.Join(dataDir, "catalog.json"),
+		timeFile:      filepath.Join(dataDir, "time_entries.json"),
+		mappingsFile:  filepath.Join(dataDir, "client_mappings.json"),
+		expensesFile:  filepath.Join(dataDir, "expenses.json"),
+		generations:   generations,
+		lock:          newDirLock(dataDir),
 	}
-	
+
 	if err := s.initFiles(); err != nil {
 		return nil, err
 	}
-	
+
 	return s, nil
 }
 
+// initFiles creates missing data files and checks that existing ones parse.
+// A file that is corrupt, or missing while older generations of it exist, is
+// reported as a *CorruptFileError so the caller can offer a recovery.
 func (s *JSONStorage) initFiles() error {
-	files := []string{s.clientsFile, s.invoicesFile, s.auditFile}
-	for _, file := range files {
-		if _, err := os.Stat(file); os.IsNotExist(err) {
-			if err := os.WriteFile(file, []byte("[]"), 0644); err != nil {
-				return fmt.Errorf("failed to create %s: %w", file, err)
+	return s.withLock(true, func() error {
+		files := []string{s.clientsFile, s.invoicesFile, s.auditFile, s.paymentsFile, s.recurringFile, s.estimatesFile, s.creditsFile, s.taxesFile, s.catalogFile, s.timeFile, s.mappingsFile, s.expensesFile}
+		for _, file := range files {
+			removeStaleTempFiles(file)
+			if _, err := os.Stat(file); os.IsNotExist(err) && !hasGenerations(file, s.generations) {
+				if err := writeFileAtomic(file, []byte("[]"), 0644, 0); err != nil {
+					return fmt.Errorf("failed to create %s: %w", file, err)
+				}
+				continue
+			}
+			if err := checkFile(file, s.generations); err != nil {
+				return err
 			}
 		}
-	}
-	return nil
+		return nil
+	})
 }
 
-func (s *JSONStorage) readClients() ([]models.Client, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	
-	data, err := os.ReadFile(s.clientsFile)
-	if err != nil {
-		return nil, err
+// withLock runs fn while holding both the in-process mutex and the advisory
+// lock on the data directory. Writers hold them exclusively for the whole
+// read-modify-write so another process cannot slip a write in between.
+func (s *JSONStorage) withLock(exclusive bool, fn func() error) error {
+	if exclusive {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	} else {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
 	}
-	
-	var clients []models.Client
-	if err := json.Unmarshal(data, &clients); err != nil {
-		return nil, err
-	}
-	
-	return clients, nil
-}
 
-func (s *JSONStorage) writeClients(clients []models.Client) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	
-	data, err := json.MarshalIndent(clients, "", "  ")
+	unlock, err := s.lock.acquire(exclusive)
 	if err != nil {
 		return err
 	}
-	
-	return os.WriteFile(s.clientsFile, data, 0644)
+	defer unlock()
+
+	return fn()
 }
 
-func (s *JSONStorage) readInvoices() ([]models.Invoice, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	
-	data, err := os.ReadFile(s.invoicesFile)
+func loadFile[T any](path string) ([]T, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
-	
-	var invoices []models.Invoice
-	if err := json.Unmarshal(data, &invoices); err != nil {
+
+	var items []T
+	if err := json.Unmarshal(data, &items); err != nil {
 		return nil, err
 	}
-	
-	return invoices, nil
+
+	return items, nil
 }
 
-func (s *JSONStorage) writeInvoices(invoices []models.Invoice) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	
-	data, err := json.MarshalIndent(invoices, "", "  ")
+func (s *JSONStorage) storeFile(path string, items any) error {
+	data, err := json.MarshalIndent(items, "", "  ")
 	if err != nil {
 		return err
 	}
-	
-	return os.WriteFile(s.invoicesFile, data, 0644)
+
+	return writeFileAtomic(path, data, 0644, s.generations)
+}
+
+// readFile loads every record in path under a shared lock.
+func readFile[T any](s *JSONStorage, path string) ([]T, error) {
+	var items []T
+	err := s.withLock(false, func() error {
+		var err error
+		items, err = loadFile[T](path)
+		return err
+	})
+	return items, err
+}
+
+// modifyFile loads the records in path, lets fn change them and writes the
+// result back, all under one exclusive lock.
+func modifyFile[T any](s *JSONStorage, path string, fn func([]T) ([]T, error)) error {
+	return s.withLock(true, func() error {
+		items, err := loadFile[T](path)
+		if err != nil {
+			return err
+		}
+
+		items, err = fn(items)
+		if err != nil {
+			return err
+		}
+
+		return s.storeFile(path, items)
+	})
+}
+
+func (s *JSONStorage) readClients() ([]models.Client, error) {
+	return readFile[models.Client](s, s.clientsFile)
+}
+
+func (s *JSONStorage) readInvoices() ([]models.Invoice, error) {
+	return readFile[models.Invoice](s, s.invoicesFile)
 }
 
 func (s *JSONStorage) GetAllClients() ([]models.Client, error) {
@@ -117,63 +179,68 @@ func (s *JSONStorage) GetClient(id string) (*models.Client, error) {
 	if err != nil {
 		return nil, err
 	}
-	
+
 	for _, client := range clients {
 		if client.ID == id {
 			return &client, nil
 		}
 	}
-	
+
 	return nil, errors.New("client not found")
 }
 
 func (s *JSONStorage) SaveClient(client *models.Client) error {
-	clients, err := s.readClients()
-	if err != nil {
-		return err
+	if client.Version == 0 {
+		client.Version = 1
 	}
-	
-	clients = append(clients, *client)
-	return s.writeClients(clients)
+
+	return modifyFile(s, s.clientsFile, func(clients []models.Client) ([]models.Client, error) {
+		return append(clients, *client), nil
+	})
 }
 
 func (s *JSONStorage) UpdateClient(client *models.Client) error {
-	clients, err := s.readClients()
+	next := client.Version + 1
+
+	err := modifyFile(s, s.clientsFile, func(clients []models.Client) ([]models.Client, error) {
+		for i, c := range clients {
+			if c.ID == client.ID {
+				if c.Version != client.Version {
+					return nil, &models.ConflictError{Entity: "client", Name: c.Name, Version: client.Version, Current: c.Version}
+				}
+				clients[i] = *client
+				clients[i].Version = next
+				return clients, nil
+			}
+		}
+		return nil, errors.New("client not found")
+	})
 	if err != nil {
 		return err
 	}
-	
-	for i, c := range clients {
-		if c.ID == client.ID {
-			clients[i] = *client
-			return s.writeClients(clients)
-		}
-	}
-	
-	return errors.New("client not found")
+
+	client.Version = next
+	return nil
 }
 
 func (s *JSONStorage) DeleteClient(id string) error {
-	clients, err := s.readClients()
-	if err != nil {
-		return err
-	}
-	
-	newClients := []models.Client{}
-	found := false
-	for _, c := range clients {
-		if c.ID != id {
-			newClients = append(newClients, c)
-		} else {
-			found = true
+	return modifyFile(s, s.clientsFile, func(clients []models.Client) ([]models.Client, error) {
+		newClients := []models.Client{}
+		found := false
+		for _, c := range clients {
+			if c.ID != id {
+				newClients = append(newClients, c)
+			} else {
+				found = true
+			}
 		}
-	}
-	
-	if !found {
-		return errors.New("client not found")
-	}
-	
-	return s.writeClients(newClients)
+
+		if !found {
+			return nil, errors.New("client not found")
+		}
+
+		return newClients, nil
+	})
 }
 
 func (s *JSONStorage) GetAllInvoices() ([]models.Invoice, error) {
@@ -185,13 +252,13 @@ func (s *JSONStorage) GetInvoice(id string) (*models.Invoice, error) {
 	if err != nil {
 		return nil, err
 	}
-	
+
 	for _, invoice := range invoices {
 		if invoice.ID == id {
 			return &invoice, nil
 		}
 	}
-	
+
 	return nil, errors.New("invoice not found")
 }
 
@@ -200,63 +267,73 @@ func (s *JSONStorage) GetInvoiceByNumber(number string) (*models.Invoice, error)
 	if err != nil {
 		return nil, err
 	}
-	
+
 	for _, invoice := range invoices {
 		if invoice.Number == number {
 			return &invoice, nil
 		}
 	}
-	
+
 	return nil, errors.New("invoice not found")
 }
 
 func (s *JSONStorage) SaveInvoice(invoice *models.Invoice) error {
-	invoices, err := s.readInvoices()
-	if err != nil {
-		return err
+	if invoice.Version == 0 {
+		invoice.Version = 1
 	}
-	
-	invoices = append(invoices, *invoice)
-	return s.writeInvoices(invoices)
+
+	return modifyFile(s, s.invoicesFile, func(invoices []models.Invoice) ([]models.Invoice, error) {
+		numbers := make([]string, len(invoices))
+		for i, inv := range invoices {
+			numbers[i] = inv.Number
+		}
+		invoice.Number = freeNumber(numbers, invoice.Number)
+		return append(invoices, *invoice), nil
+	})
 }
 
 func (s *JSONStorage) UpdateInvoice(invoice *models.Invoice) error {
-	invoices, err := s.readInvoices()
+	next := invoice.Version + 1
+
+	err := modifyFile(s, s.invoicesFile, func(invoices []models.Invoice) ([]models.Invoice, error) {
+		for i, inv := range invoices {
+			if inv.ID == invoice.ID {
+				if inv.Version != invoice.Version {
+					return nil, &models.ConflictError{Entity: "invoice", Name: inv.Number, Version: invoice.Version, Current: inv.Version}
+				}
+				invoices[i] = *invoice
+				invoices[i].Version = next
+				return invoices, nil
+			}
+		}
+		return nil, errors.New("invoice not found")
+	})
 	if err != nil {
 		return err
 	}
-	
-	for i, inv := range invoices {
-		if inv.ID == invoice.ID {
-			invoices[i] = *invoice
-			return s.writeInvoices(invoices)
-		}
-	}
-	
-	return errors.New("invoice not found")
+
+	invoice.Version = next
+	return nil
 }
 
 func (s *JSONStorage) DeleteInvoice(id string) error {
-	invoices, err := s.readInvoices()
-	if err != nil {
-		return err
-	}
-	
-	newInvoices := []models.Invoice{}
-	found := false
-	for _, inv := range invoices {
-		if inv.ID != id {
-			newInvoices = append(newInvoices, inv)
-		} else {
-			found = true
+	return modifyFile(s, s.invoicesFile, func(invoices []models.Invoice) ([]models.Invoice, error) {
+		newInvoices := []models.Invoice{}
+		found := false
+		for _, inv := range invoices {
+			if inv.ID != id {
+				newInvoices = append(newInvoices, inv)
+			} else {
+				found = true
+			}
 		}
-	}
-	
-	if !found {
-		return errors.New("invoice not found")
-	}
-	
-	return s.writeInvoices(newInvoices)
+
+		if !found {
+			return nil, errors.New("invoice not found")
+		}
+
+		return newInvoices, nil
+	})
 }
 
 func (s *JSONStorage) GetNextInvoiceNumber(year int) (int, error) {
@@ -264,22 +341,41 @@ func (s *JSONStorage) GetNextInvoiceNumber(year int) (int, error) {
 	if err != nil {
 		return 0, err
 	}
-	
+
+	numbers := make([]string, len(invoices))
+	for i, invoice := range invoices {
+		numbers[i] = invoice.Number
+	}
+	return nextSequence(numbers, fmt.Sprintf("%d-", year)), nil
+}
+
+// nextSequence returns one more than the highest sequence number among the
+// document numbers that start with prefix, e.g. 4 for "2025-03" and "2025-".
+func nextSequence(numbers []string, prefix string) int {
 	maxSequence := 0
-	yearPrefix := fmt.Sprintf("%d-", year)
-	
-	for _, invoice := range invoices {
-		if len(invoice.Number) >= 7 && invoice.Number[:5] == yearPrefix {
-			var seq int
-			if _, err := fmt.Sscanf(invoice.Number[5:], "%d", &seq); err == nil {
-				if seq > maxSequence {
-					maxSequence = seq
-				}
-			}
+	for _, number := range numbers {
+		if !strings.HasPrefix(number, prefix) {
+			continue
+		}
+		var seq int
+		if _, err := fmt.Sscanf(number[len(prefix):], "%d", &seq); err == nil && seq > maxSequence {
+			maxSequence = seq
+		}
+	}
+	return maxSequence + 1
+}
+
+// freeNumber returns number, or the next one after it if it is among
+// numbers already. Another process may have saved a document between this
+// one's number being allocated and it being saved.
+func freeNumber(numbers []string, number string) string {
+	for _, n := range numbers {
+		if n == number {
+			prefix := number[:strings.LastIndex(number, "-")+1]
+			return fmt.Sprintf("%s%02d", prefix, nextSequence(numbers, prefix))
 		}
 	}
-	
-	return maxSequence + 1, nil
+	return number
 }
 
 func (s *JSONStorage) GetInvoicesByClient(clientID string) ([]models.Invoice, error) {
@@ -287,68 +383,610 @@ func (s *JSONStorage) GetInvoicesByClient(clientID string) ([]models.Invoice, er
 	if err != nil {
 		return nil, err
 	}
-	
+
 	clientInvoices := []models.Invoice{}
 	for _, invoice := range invoices {
 		if invoice.ClientID == clientID {
 			clientInvoices = append(clientInvoices, invoice)
 		}
 	}
-	
+
 	return clientInvoices, nil
 }
 
 func (s *JSONStorage) readAuditEntries() ([]models.AuditEntry, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	
-	data, err := os.ReadFile(s.auditFile)
+	return readFile[models.AuditEntry](s, s.auditFile)
+}
+
+func (s *JSONStorage) SaveAuditEntry(entry *models.AuditEntry) error {
+	return modifyFile(s, s.auditFile, func(entries []models.AuditEntry) ([]models.AuditEntry, error) {
+		return append(entries, *entry), nil
+	})
+}
+
+func (s *JSONStorage) GetAuditEntries(invoiceID string) ([]models.AuditEntry, error) {
+	entries, err := s.readAuditEntries()
 	if err != nil {
 		return nil, err
 	}
-	
-	var entries []models.AuditEntry
-	if err := json.Unmarshal(data, &entries); err != nil {
+
+	invoiceEntries := []models.AuditEntry{}
+	for _, entry := range entries {
+		if entry.InvoiceID == invoiceID {
+			invoiceEntries = append(invoiceEntries, entry)
+		}
+	}
+
+	return invoiceEntries, nil
+}
+
+func (s *JSONStorage) readPayments() ([]models.Payment, error) {
+	return readFile[models.Payment](s, s.paymentsFile)
+}
+
+func (s *JSONStorage) SavePayment(payment *models.Payment) error {
+	return modifyFile(s, s.paymentsFile, func(payments []models.Payment) ([]models.Payment, error) {
+		return append(payments, *payment), nil
+	})
+}
+
+func (s *JSONStorage) GetPayments(invoiceID string) ([]models.Payment, error) {
+	payments, err := s.readPayments()
+	if err != nil {
 		return nil, err
 	}
-	
-	return entries, nil
+
+	invoicePayments := []models.Payment{}
+	for _, payment := range payments {
+		if payment.InvoiceID == invoiceID {
+			invoicePayments = append(invoicePayments, payment)
+		}
+	}
+
+	return invoicePayments, nil
 }
 
-func (s *JSONStorage) writeAuditEntries(entries []models.AuditEntry) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	
-	data, err := json.MarshalIndent(entries, "", "  ")
+func (s *JSONStorage) DeletePayment(id string) error {
+	return modifyFile(s, s.paymentsFile, func(payments []models.Payment) ([]models.Payment, error) {
+		newPayments := []models.Payment{}
+		found := false
+		for _, p := range payments {
+			if p.ID != id {
+				newPayments = append(newPayments, p)
+			} else {
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, errors.New("payment not found")
+		}
+
+		return newPayments, nil
+	})
+}
+
+func (s *JSONStorage) readRecurringInvoices() ([]models.RecurringInvoice, error) {
+	return readFile[models.RecurringInvoice](s, s.recurringFile)
+}
+
+func (s *JSONStorage) GetAllRecurringInvoices() ([]models.RecurringInvoice, error) {
+	return s.readRecurringInvoices()
+}
+
+func (s *JSONStorage) GetRecurringInvoice(id string) (*models.RecurringInvoice, error) {
+	schedules, err := s.readRecurringInvoices()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range schedules {
+		if r.ID == id {
+			return &r, nil
+		}
+	}
+
+	return nil, errors.New("recurring invoice not found")
+}
+
+func (s *JSONStorage) SaveRecurringInvoice(recurring *models.RecurringInvoice) error {
+	if recurring.Version == 0 {
+		recurring.Version = 1
+	}
+
+	return modifyFile(s, s.recurringFile, func(schedules []models.RecurringInvoice) ([]models.RecurringInvoice, error) {
+		return append(schedules, *recurring), nil
+	})
+}
+
+func (s *JSONStorage) UpdateRecurringInvoice(recurring *models.RecurringInvoice) error {
+	next := recurring.Version + 1
+
+	err := modifyFile(s, s.recurringFile, func(schedules []models.RecurringInvoice) ([]models.RecurringInvoice, error) {
+		for i, r := range schedules {
+			if r.ID == recurring.ID {
+				if r.Version != recurring.Version {
+					return nil, &models.ConflictError{Entity: "recurring invoice", Name: r.Name, Version: recurring.Version, Current: r.Version}
+				}
+				schedules[i] = *recurring
+				schedules[i].Version = next
+				return schedules, nil
+			}
+		}
+		return nil, errors.New("recurring invoice not found")
+	})
 	if err != nil {
 		return err
 	}
-	
-	return os.WriteFile(s.auditFile, data, 0644)
+
+	recurring.Version = next
+	return nil
 }
 
-func (s *JSONStorage) SaveAuditEntry(entry *models.AuditEntry) error {
-	entries, err := s.readAuditEntries()
+func (s *JSONStorage) DeleteRecurringInvoice(id string) error {
+	return modifyFile(s, s.recurringFile, func(schedules []models.RecurringInvoice) ([]models.RecurringInvoice, error) {
+		newSchedules := []models.RecurringInvoice{}
+		found := false
+		for _, r := range schedules {
+			if r.ID != id {
+				newSchedules = append(newSchedules, r)
+			} else {
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, errors.New("recurring invoice not found")
+		}
+
+		return newSchedules, nil
+	})
+}
+
+func (s *JSONStorage) readEstimates() ([]models.Estimate, error) {
+	return readFile[models.Estimate](s, s.estimatesFile)
+}
+
+func (s *JSONStorage) GetAllEstimates() ([]models.Estimate, error) {
+	return s.readEstimates()
+}
+
+func (s *JSONStorage) GetEstimate(id string) (*models.Estimate, error) {
+	estimates, err := s.readEstimates()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, estimate := range estimates {
+		if estimate.ID == id {
+			return &estimate, nil
+		}
+	}
+
+	return nil, errors.New("estimate not found")
+}
+
+func (s *JSONStorage) SaveEstimate(estimate *models.Estimate) error {
+	if estimate.Version == 0 {
+		estimate.Version = 1
+	}
+
+	return modifyFile(s, s.estimatesFile, func(estimates []models.Estimate) ([]models.Estimate, error) {
+		numbers := make([]string, len(estimates))
+		for i, e := range estimates {
+			numbers[i] = e.Number
+		}
+		estimate.Number = freeNumber(numbers, estimate.Number)
+		return append(estimates, *estimate), nil
+	})
+}
+
+func (s *JSONStorage) UpdateEstimate(estimate *models.Estimate) error {
+	next := estimate.Version + 1
+
+	err := modifyFile(s, s.estimatesFile, func(estimates []models.Estimate) ([]models.Estimate, error) {
+		for i, e := range estimates {
+			if e.ID == estimate.ID {
+				if e.Version != estimate.Version {
+					return nil, &models.ConflictError{Entity: "estimate", Name: e.Number, Version: estimate.Version, Current: e.Version}
+				}
+				estimates[i] = *estimate
+				estimates[i].Version = next
+				return estimates, nil
+			}
+		}
+		return nil, errors.New("estimate not found")
+	})
 	if err != nil {
 		return err
 	}
-	
-	entries = append(entries, *entry)
-	return s.writeAuditEntries(entries)
+
+	estimate.Version = next
+	return nil
 }
 
-func (s *JSONStorage) GetAuditEntries(invoiceID string) ([]models.AuditEntry, error) {
-	entries, err := s.readAuditEntries()
+func (s *JSONStorage) DeleteEstimate(id string) error {
+	return modifyFile(s, s.estimatesFile, func(estimates []models.Estimate) ([]models.Estimate, error) {
+		newEstimates := []models.Estimate{}
+		found := false
+		for _, e := range estimates {
+			if e.ID != id {
+				newEstimates = append(newEstimates, e)
+			} else {
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, errors.New("estimate not found")
+		}
+
+		return newEstimates, nil
+	})
+}
+
+func (s *JSONStorage) GetNextEstimateNumber(year int) (int, error) {
+	estimates, err := s.readEstimates()
+	if err != nil {
+		return 0, err
+	}
+
+	numbers := make([]string, len(estimates))
+	for i, estimate := range estimates {
+		numbers[i] = estimate.Number
+	}
+	return nextSequence(numbers, fmt.Sprintf("EST-%d-", year)), nil
+}
+
+func (s *JSONStorage) readCreditNotes() ([]models.CreditNote, error) {
+	return readFile[models.CreditNote](s, s.creditsFile)
+}
+
+func (s *JSONStorage) GetAllCreditNotes() ([]models.CreditNote, error) {
+	return s.readCreditNotes()
+}
+
+func (s *JSONStorage) GetCreditNotes(invoiceID string) ([]models.CreditNote, error) {
+	notes, err := s.readCreditNotes()
 	if err != nil {
 		return nil, err
 	}
-	
-	invoiceEntries := []models.AuditEntry{}
+
+	invoiceNotes := []models.CreditNote{}
+	for _, note := range notes {
+		if note.InvoiceID == invoiceID {
+			invoiceNotes = append(invoiceNotes, note)
+		}
+	}
+
+	return invoiceNotes, nil
+}
+
+func (s *JSONStorage) SaveCreditNote(note *models.CreditNote) error {
+	return modifyFile(s, s.creditsFile, func(notes []models.CreditNote) ([]models.CreditNote, error) {
+		numbers := make([]string, len(notes))
+		for i, n := range notes {
+			numbers[i] = n.Number
+		}
+		note.Number = freeNumber(numbers, note.Number)
+		return append(notes, *note), nil
+	})
+}
+
+func (s *JSONStorage) DeleteCreditNote(id string) error {
+	return modifyFile(s, s.creditsFile, func(notes []models.CreditNote) ([]models.CreditNote, error) {
+		newNotes := []models.CreditNote{}
+		found := false
+		for _, n := range notes {
+			if n.ID != id {
+				newNotes = append(newNotes, n)
+			} else {
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, errors.New("credit note not found")
+		}
+
+		return newNotes, nil
+	})
+}
+
+func (s *JSONStorage) GetNextCreditNoteNumber(year int) (int, error) {
+	notes, err := s.readCreditNotes()
+	if err != nil {
+		return 0, err
+	}
+
+	numbers := make([]string, len(notes))
+	for i, note := range notes {
+		numbers[i] = note.Number
+	}
+	return nextSequence(numbers, fmt.Sprintf("CN-%d-", year)), nil
+}
+
+func (s *JSONStorage) readTaxes() ([]models.Tax, error) {
+	return readFile[models.Tax](s, s.taxesFile)
+}
+
+func (s *JSONStorage) GetAllTaxes() ([]models.Tax, error) {
+	return s.readTaxes()
+}
+
+func (s *JSONStorage) GetTax(id string) (*models.Tax, error) {
+	taxes, err := s.readTaxes()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range taxes {
+		if t.ID == id {
+			return &t, nil
+		}
+	}
+
+	return nil, errors.New("tax not found")
+}
+
+func (s *JSONStorage) SaveTax(tax *models.Tax) error {
+	return modifyFile(s, s.taxesFile, func(taxes []models.Tax) ([]models.Tax, error) {
+		return append(taxes, *tax), nil
+	})
+}
+
+func (s *JSONStorage) UpdateTax(tax *models.Tax) error {
+	return modifyFile(s, s.taxesFile, func(taxes []models.Tax) ([]models.Tax, error) {
+		for i, t := range taxes {
+			if t.ID == tax.ID {
+				taxes[i] = *tax
+				return taxes, nil
+			}
+		}
+		return nil, errors.New("tax not found")
+	})
+}
+
+func (s *JSONStorage) DeleteTax(id string) error {
+	return modifyFile(s, s.taxesFile, func(taxes []models.Tax) ([]models.Tax, error) {
+		newTaxes := []models.Tax{}
+		found := false
+		for _, t := range taxes {
+			if t.ID != id {
+				newTaxes = append(newTaxes, t)
+			} else {
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, errors.New("tax not found")
+		}
+
+		return newTaxes, nil
+	})
+}
+
+func (s *JSONStorage) readCatalogItems() ([]models.CatalogItem, error) {
+	return readFile[models.CatalogItem](s, s.catalogFile)
+}
+
+func (s *JSONStorage) GetAllCatalogItems() ([]models.CatalogItem, error) {
+	return s.readCatalogItems()
+}
+
+func (s *JSONStorage) GetCatalogItem(id string) (*models.CatalogItem, error) {
+	items, err := s.readCatalogItems()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		if item.ID == id {
+			return &item, nil
+		}
+	}
+
+	return nil, errors.New("catalog item not found")
+}
+
+func (s *JSONStorage) SaveCatalogItem(item *models.CatalogItem) error {
+	return modifyFile(s, s.catalogFile, func(items []models.CatalogItem) ([]models.CatalogItem, error) {
+		return append(items, *item), nil
+	})
+}
+
+func (s *JSONStorage) UpdateCatalogItem(item *models.CatalogItem) error {
+	return modifyFile(s, s.catalogFile, func(items []models.CatalogItem) ([]models.CatalogItem, error) {
+		for i, existing := range items {
+			if existing.ID == item.ID {
+				items[i] = *item
+				return items, nil
+			}
+		}
+		return nil, errors.New("catalog item not found")
+	})
+}
+
+func (s *JSONStorage) DeleteCatalogItem(id string) error {
+	return modifyFile(s, s.catalogFile, func(items []models.CatalogItem) ([]models.CatalogItem, error) {
+		newItems := []models.CatalogItem{}
+		found := false
+		for _, item := range items {
+			if item.ID != id {
+				newItems = append(newItems, item)
+			} else {
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, errors.New("catalog item not found")
+		}
+
+		return newItems, nil
+	})
+}
+
+func (s *JSONStorage) readTimeEntries() ([]models.TimeEntry, error) {
+	return readFile[models.TimeEntry](s, s.timeFile)
+}
+
+func (s *JSONStorage) GetAllTimeEntries() ([]models.TimeEntry, error) {
+	return s.readTimeEntries()
+}
+
+func (s *JSONStorage) GetTimeEntry(id string) (*models.TimeEntry, error) {
+	entries, err := s.readTimeEntries()
+	if err != nil {
+		return nil, err
+	}
+
 	for _, entry := range entries {
-		if entry.InvoiceID == invoiceID {
-			invoiceEntries = append(invoiceEntries, entry)
+		if entry.ID == id {
+			return &entry, nil
 		}
 	}
-	
-	return invoiceEntries, nil
-}
\ No newline at end of file
+
+	return nil, errors.New("time entry not found")
+}
+
+func (s *JSONStorage) SaveTimeEntry(entry *models.TimeEntry) error {
+	return modifyFile(s, s.timeFile, func(entries []models.TimeEntry) ([]models.TimeEntry, error) {
+		return append(entries, *entry), nil
+	})
+}
+
+func (s *JSONStorage) UpdateTimeEntry(entry *models.TimeEntry) error {
+	return modifyFile(s, s.timeFile, func(entries []models.TimeEntry) ([]models.TimeEntry, error) {
+		for i, existing := range entries {
+			if existing.ID == entry.ID {
+				entries[i] = *entry
+				return entries, nil
+			}
+		}
+		return nil, errors.New("time entry not found")
+	})
+}
+
+func (s *JSONStorage) DeleteTimeEntry(id string) error {
+	return modifyFile(s, s.timeFile, func(entries []models.TimeEntry) ([]models.TimeEntry, error) {
+		newEntries := []models.TimeEntry{}
+		found := false
+		for _, entry := range entries {
+			if entry.ID != id {
+				newEntries = append(newEntries, entry)
+			} else {
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, errors.New("time entry not found")
+		}
+
+		return newEntries, nil
+	})
+}
+
+func (s *JSONStorage) readExpenses() ([]models.Expense, error) {
+	return readFile[models.Expense](s, s.expensesFile)
+}
+
+func (s *JSONStorage) GetAllExpenses() ([]models.Expense, error) {
+	return s.readExpenses()
+}
+
+func (s *JSONStorage) GetExpense(id string) (*models.Expense, error) {
+	expenses, err := s.readExpenses()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, expense := range expenses {
+		if expense.ID == id {
+			return &expense, nil
+		}
+	}
+
+	return nil, errors.New("expense not found")
+}
+
+func (s *JSONStorage) SaveExpense(expense *models.Expense) error {
+	return modifyFile(s, s.expensesFile, func(expenses []models.Expense) ([]models.Expense, error) {
+		return append(expenses, *expense), nil
+	})
+}
+
+func (s *JSONStorage) UpdateExpense(expense *models.Expense) error {
+	return modifyFile(s, s.expensesFile, func(expenses []models.Expense) ([]models.Expense, error) {
+		for i, existing := range expenses {
+			if existing.ID == expense.ID {
+				expenses[i] = *expense
+				return expenses, nil
+			}
+		}
+		return nil, errors.New("expense not found")
+	})
+}
+
+func (s *JSONStorage) DeleteExpense(id string) error {
+	return modifyFile(s, s.expensesFile, func(expenses []models.Expense) ([]models.Expense, error) {
+		newExpenses := []models.Expense{}
+		found := false
+		for _, expense := range expenses {
+			if expense.ID != id {
+				newExpenses = append(newExpenses, expense)
+			} else {
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, errors.New("expense not found")
+		}
+
+		return newExpenses, nil
+	})
+}
+
+func (s *JSONStorage) readClientMappings() ([]models.ClientMapping, error) {
+	return readFile[models.ClientMapping](s, s.mappingsFile)
+}
+
+func (s *JSONStorage) GetAllClientMappings() ([]models.ClientMapping, error) {
+	return s.readClientMappings()
+}
+
+// SaveClientMapping adds mapping, replacing any mapping for the same name.
+func (s *JSONStorage) SaveClientMapping(mapping *models.ClientMapping) error {
+	return modifyFile(s, s.mappingsFile, func(mappings []models.ClientMapping) ([]models.ClientMapping, error) {
+		for i, existing := range mappings {
+			if strings.EqualFold(existing.Name, mapping.Name) {
+				mappings[i] = *mapping
+				return mappings, nil
+			}
+		}
+		return append(mappings, *mapping), nil
+	})
+}
+
+func (s *JSONStorage) DeleteClientMapping(id string) error {
+	return modifyFile(s, s.mappingsFile, func(mappings []models.ClientMapping) ([]models.ClientMapping, error) {
+		newMappings := []models.ClientMapping{}
+		found := false
+		for _, mapping := range mappings {
+			if mapping.ID != id {
+				newMappings = append(newMappings, mapping)
+			} else {
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, errors.New("client mapping not found")
+		}
+
+		return newMappings, nil
+	})
+}
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// lockFileName is the advisory lock every invoicer process takes on the data
+// directory while it reads or rewrites the JSON files.
+const lockFileName = ".invoicer.lock"
+
+// dirLock serialises access to a data directory across processes. It is
+// advisory: it only protects against other invoicer processes.
+type dirLock struct {
+	path string
+}
+
+func newDirLock(dataDir string) dirLock {
+	return dirLock{path: filepath.Join(dataDir, lockFileName)}
+}
+
+// acquire blocks until the lock is held and returns a function releasing it.
+// Shared locks may be held by several readers at once.
+func (l dirLock) acquire(exclusive bool) (func(), error) {
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	if err := lockFile(f, exclusive); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock data directory: %w", err)
+	}
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/user/invoicer/models"
+)
+
+func TestDirLockExcludes(t *testing.T) {
+	dir := t.TempDir()
+	unlock, err := newDirLock(dir).acquire(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acquired := make(chan struct{})
+	go func() {
+		unlockOther, err := newDirLock(dir).acquire(false)
+		if err == nil {
+			unlockOther()
+		}
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("a reader got the lock while a writer held it")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	select {
+	case <-acquired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the reader never got the lock")
+	}
+}
+
+// Two stores on one directory stand in for two invoicer processes: they
+// share nothing but the lock file.
+func TestTwoStoresOnOneDirectory(t *testing.T) {
+	dir := t.TempDir()
+	first, err := NewJSONStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewJSONStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const perStore = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*perStore)
+	for s, store := range []*JSONStorage{first, second} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perStore {
+				client := models.NewClient(fmt.Sprintf("Client %d-%d", s, i), "", nil, decimal.NewFromInt(100))
+				errs <- store.SaveClient(client)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	clients, err := first.GetAllClients()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clients) != 2*perStore {
+		t.Errorf("%d clients saved, want %d", len(clients), 2*perStore)
+	}
+
+	// Both load the same invoice; the second to save must reload first
+	invoice := newTestInvoice(t, first, "2026-01")
+	mine, err := first.GetInvoice(invoice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	theirs, err := second.GetInvoice(invoice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mine.SetTaxRate(decimal.NewFromInt(7))
+	if err := first.UpdateInvoice(mine); err != nil {
+		t.Fatal(err)
+	}
+	theirs.SetDiscountRate(decimal.NewFromInt(10))
+	if err := second.UpdateInvoice(theirs); !errors.Is(err, models.ErrConflict) {
+		t.Fatalf("UpdateInvoice(stale) error = %v, want a conflict", err)
+	}
+	theirs, err = second.GetInvoice(invoice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	theirs.SetDiscountRate(decimal.NewFromInt(10))
+	if err := second.UpdateInvoice(theirs); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := first.GetInvoice(invoice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !saved.TaxRate.Equal(decimal.NewFromInt(7)) || !saved.DiscountRate.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("saved tax rate %s and discount %s, want both changes kept", saved.TaxRate, saved.DiscountRate)
+	}
+}
@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/models"
+)
+
+// Open returns the storage backend selected by cfg.StorageBackend. Callers
+// should close the result if it implements io.Closer.
+func Open(cfg *config.Config) (models.Storage, error) {
+	switch cfg.StorageBackend {
+	case "", config.BackendJSON:
+		generations := cfg.JSONGenerations
+		if generations == 0 {
+			generations = DefaultGenerations
+		} else if generations < 0 {
+			generations = 0
+		}
+		return NewJSONStorageWithGenerations(cfg.DataDir(), generations)
+	case config.BackendSQLite:
+		return NewSQLiteStorage(cfg.DatabasePath())
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.StorageBackend)
+	}
+}
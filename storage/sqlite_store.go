@@ -0,0 +1,1730 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/user/invoicer/models"
+
+	_ "modernc.org/sqlite"
+)
+
+// migrations are applied in order and tracked through PRAGMA user_version,
+// so new schema changes must only ever be appended.
+var migrations = []string{
+	`CREATE TABLE clients (
+		id                  TEXT PRIMARY KEY,
+		name                TEXT NOT NULL,
+		address             TEXT NOT NULL DEFAULT '',
+		emails              TEXT NOT NULL DEFAULT '[]',
+		default_hourly_rate TEXT NOT NULL DEFAULT '0',
+		created_at          TEXT NOT NULL,
+		updated_at          TEXT NOT NULL
+	);
+
+	CREATE TABLE invoices (
+		id                 TEXT PRIMARY KEY,
+		number             TEXT NOT NULL,
+		client_id          TEXT NOT NULL,
+		client_name        TEXT NOT NULL DEFAULT '',
+		date               TEXT NOT NULL,
+		due_date           TEXT NOT NULL,
+		service_start_date TEXT,
+		service_end_date   TEXT,
+		subtotal           TEXT NOT NULL DEFAULT '0',
+		discount_rate      TEXT NOT NULL DEFAULT '0',
+		discount           TEXT NOT NULL DEFAULT '0',
+		tax_rate           TEXT NOT NULL DEFAULT '0',
+		tax                TEXT NOT NULL DEFAULT '0',
+		total              TEXT NOT NULL DEFAULT '0',
+		status             TEXT NOT NULL,
+		created_at         TEXT NOT NULL,
+		updated_at         TEXT NOT NULL
+	);
+	CREATE INDEX idx_invoices_number ON invoices(number);
+	CREATE INDEX idx_invoices_client_id ON invoices(client_id);
+
+	CREATE TABLE line_items (
+		invoice_id  TEXT NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+		position    INTEGER NOT NULL,
+		id          TEXT NOT NULL,
+		description TEXT NOT NULL,
+		quantity    TEXT NOT NULL,
+		unit_price  TEXT NOT NULL,
+		total       TEXT NOT NULL,
+		PRIMARY KEY (invoice_id, position)
+	);
+
+	CREATE TABLE audit_entries (
+		id             TEXT PRIMARY KEY,
+		invoice_id     TEXT NOT NULL,
+		invoice_number TEXT NOT NULL,
+		old_status     TEXT NOT NULL,
+		new_status     TEXT NOT NULL,
+		changed_by     TEXT NOT NULL,
+		changed_at     TEXT NOT NULL,
+		reason         TEXT NOT NULL DEFAULT ''
+	);
+	CREATE INDEX idx_audit_entries_invoice_id ON audit_entries(invoice_id);`,
+
+	`ALTER TABLE clients ADD COLUMN version INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE invoices ADD COLUMN version INTEGER NOT NULL DEFAULT 0;`,
+
+	`ALTER TABLE invoices ADD COLUMN amount_paid TEXT NOT NULL DEFAULT '0';
+
+	CREATE TABLE payments (
+		id         TEXT PRIMARY KEY,
+		invoice_id TEXT NOT NULL,
+		amount     TEXT NOT NULL,
+		date       TEXT NOT NULL,
+		method     TEXT NOT NULL DEFAULT '',
+		reference  TEXT NOT NULL DEFAULT '',
+		note       TEXT NOT NULL DEFAULT '',
+		created_at TEXT NOT NULL
+	);
+	CREATE INDEX idx_payments_invoice_id ON payments(invoice_id);`,
+
+	`CREATE TABLE recurring_invoices (
+		id             TEXT PRIMARY KEY,
+		name           TEXT NOT NULL DEFAULT '',
+		client_id      TEXT NOT NULL,
+		client_name    TEXT NOT NULL DEFAULT '',
+		line_items     TEXT NOT NULL DEFAULT '[]',
+		discount_rate  TEXT NOT NULL DEFAULT '0',
+		tax_rate       TEXT NOT NULL DEFAULT '0',
+		due_days       INTEGER NOT NULL DEFAULT 30,
+		cadence        TEXT NOT NULL,
+		interval_count INTEGER NOT NULL DEFAULT 1,
+		start_date     TEXT NOT NULL,
+		next_run_date  TEXT NOT NULL,
+		active         INTEGER NOT NULL DEFAULT 1,
+		version        INTEGER NOT NULL DEFAULT 0,
+		created_at     TEXT NOT NULL,
+		updated_at     TEXT NOT NULL
+	);`,
+
+	`ALTER TABLE invoices ADD COLUMN estimate_id TEXT NOT NULL DEFAULT '';
+
+	CREATE TABLE estimates (
+		id            TEXT PRIMARY KEY,
+		number        TEXT NOT NULL,
+		client_id     TEXT NOT NULL,
+		client_name   TEXT NOT NULL DEFAULT '',
+		date          TEXT NOT NULL,
+		valid_until   TEXT NOT NULL,
+		line_items    TEXT NOT NULL DEFAULT '[]',
+		subtotal      TEXT NOT NULL DEFAULT '0',
+		discount_rate TEXT NOT NULL DEFAULT '0',
+		discount      TEXT NOT NULL DEFAULT '0',
+		tax_rate      TEXT NOT NULL DEFAULT '0',
+		tax           TEXT NOT NULL DEFAULT '0',
+		total         TEXT NOT NULL DEFAULT '0',
+		notes         TEXT NOT NULL DEFAULT '',
+		status        TEXT NOT NULL,
+		invoice_id    TEXT NOT NULL DEFAULT '',
+		version       INTEGER NOT NULL DEFAULT 0,
+		created_at    TEXT NOT NULL,
+		updated_at    TEXT NOT NULL
+	);
+	CREATE INDEX idx_estimates_number ON estimates(number);`,
+
+	`ALTER TABLE invoices ADD COLUMN amount_credited TEXT NOT NULL DEFAULT '0';
+	ALTER TABLE audit_entries ADD COLUMN action TEXT NOT NULL DEFAULT 'status_change';
+
+	CREATE TABLE credit_notes (
+		id             TEXT PRIMARY KEY,
+		number         TEXT NOT NULL,
+		invoice_id     TEXT NOT NULL,
+		invoice_number TEXT NOT NULL,
+		client_id      TEXT NOT NULL,
+		client_name    TEXT NOT NULL DEFAULT '',
+		date           TEXT NOT NULL,
+		reason         TEXT NOT NULL DEFAULT '',
+		line_items     TEXT NOT NULL DEFAULT '[]',
+		subtotal       TEXT NOT NULL DEFAULT '0',
+		discount_rate  TEXT NOT NULL DEFAULT '0',
+		discount       TEXT NOT NULL DEFAULT '0',
+		tax_rate       TEXT NOT NULL DEFAULT '0',
+		tax            TEXT NOT NULL DEFAULT '0',
+		total          TEXT NOT NULL DEFAULT '0',
+		created_at     TEXT NOT NULL
+	);
+	CREATE INDEX idx_credit_notes_invoice_id ON credit_notes(invoice_id);
+	CREATE INDEX idx_credit_notes_number ON credit_notes(number);`,
+
+	`ALTER TABLE clients ADD COLUMN default_currency TEXT NOT NULL DEFAULT '';
+	ALTER TABLE invoices ADD COLUMN currency TEXT NOT NULL DEFAULT '';
+	ALTER TABLE recurring_invoices ADD COLUMN currency TEXT NOT NULL DEFAULT '';
+	ALTER TABLE estimates ADD COLUMN currency TEXT NOT NULL DEFAULT '';
+	ALTER TABLE credit_notes ADD COLUMN currency TEXT NOT NULL DEFAULT '';`,
+
+	`ALTER TABLE line_items ADD COLUMN taxes TEXT NOT NULL DEFAULT '[]';
+
+	CREATE TABLE taxes (
+		id         TEXT PRIMARY KEY,
+		name       TEXT NOT NULL,
+		rate       TEXT NOT NULL,
+		compound   INTEGER NOT NULL DEFAULT 0,
+		inclusive  INTEGER NOT NULL DEFAULT 0,
+		created_at TEXT NOT NULL,
+		updated_at TEXT NOT NULL
+	);`,
+
+	`ALTER TABLE line_items ADD COLUMN discount_rate TEXT NOT NULL DEFAULT '0';
+	ALTER TABLE line_items ADD COLUMN fixed_discount TEXT NOT NULL DEFAULT '0';
+	ALTER TABLE invoices ADD COLUMN fixed_discount TEXT NOT NULL DEFAULT '0';
+	ALTER TABLE recurring_invoices ADD COLUMN fixed_discount TEXT NOT NULL DEFAULT '0';
+	ALTER TABLE estimates ADD COLUMN fixed_discount TEXT NOT NULL DEFAULT '0';`,
+
+	`CREATE TABLE catalog_items (
+		id          TEXT PRIMARY KEY,
+		sku         TEXT NOT NULL DEFAULT '',
+		description TEXT NOT NULL,
+		unit        TEXT NOT NULL DEFAULT '',
+		unit_price  TEXT NOT NULL DEFAULT '0',
+		tax_ids     TEXT NOT NULL DEFAULT '[]',
+		created_at  TEXT NOT NULL,
+		updated_at  TEXT NOT NULL
+	);`,
+
+	`CREATE TABLE time_entries (
+		id             TEXT PRIMARY KEY,
+		client_id      TEXT NOT NULL,
+		client_name    TEXT NOT NULL,
+		project        TEXT NOT NULL DEFAULT '',
+		description    TEXT NOT NULL DEFAULT '',
+		started_at     TEXT NOT NULL,
+		ended_at       TEXT,
+		billable       INTEGER NOT NULL DEFAULT 1,
+		invoice_id     TEXT NOT NULL DEFAULT '',
+		invoice_number TEXT NOT NULL DEFAULT '',
+		created_at     TEXT NOT NULL,
+		updated_at     TEXT NOT NULL
+	);
+	CREATE INDEX time_entries_client ON time_entries(client_id);`,
+
+	`ALTER TABLE time_entries ADD COLUMN external_id TEXT NOT NULL DEFAULT '';
+	CREATE TABLE client_mappings (
+		id         TEXT PRIMARY KEY,
+		name       TEXT NOT NULL UNIQUE COLLATE NOCASE,
+		client_id  TEXT NOT NULL DEFAULT '',
+		created_at TEXT NOT NULL
+	);`,
+
+	`CREATE TABLE expenses (
+		id             TEXT PRIMARY KEY,
+		client_id      TEXT NOT NULL,
+		client_name    TEXT NOT NULL,
+		date           TEXT NOT NULL,
+		vendor         TEXT NOT NULL,
+		category       TEXT NOT NULL DEFAULT '',
+		description    TEXT NOT NULL DEFAULT '',
+		amount         TEXT NOT NULL,
+		markup_rate    TEXT NOT NULL DEFAULT '0',
+		receipt_path   TEXT NOT NULL DEFAULT '',
+		invoice_id     TEXT NOT NULL DEFAULT '',
+		invoice_number TEXT NOT NULL DEFAULT '',
+		created_at     TEXT NOT NULL,
+		updated_at     TEXT NOT NULL
+	);
+	CREATE INDEX expenses_client ON expenses(client_id);`,
+
+	`ALTER TABLE clients ADD COLUMN vat_id TEXT NOT NULL DEFAULT '';
+	ALTER TABLE clients ADD COLUMN street TEXT NOT NULL DEFAULT '';
+	ALTER TABLE clients ADD COLUMN city TEXT NOT NULL DEFAULT '';
+	ALTER TABLE clients ADD COLUMN postal_code TEXT NOT NULL DEFAULT '';
+	ALTER TABLE clients ADD COLUMN country TEXT NOT NULL DEFAULT '';
+	ALTER TABLE clients ADD COLUMN peppol_id TEXT NOT NULL DEFAULT '';
+	ALTER TABLE clients ADD COLUMN buyer_reference TEXT NOT NULL DEFAULT '';`,
+
+	`ALTER TABLE clients ADD COLUMN invoice_template TEXT NOT NULL DEFAULT '';`,
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx so that the insert
+// helpers can be shared between normal writes and the JSON importer.
+type queryer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+type SQLiteStorage struct {
+	db   *sql.DB
+	path string
+}
+
+func NewSQLiteStorage(path string) (*SQLiteStorage, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	// Transactions take the write lock when they begin, so that a number
+	// read in one is still free when the row using it is inserted
+	dsn := "file:" + path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_txlock=immediate"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	// A single connection keeps pragmas consistent and avoids SQLITE_BUSY
+	// between our own connections; the busy timeout covers other processes.
+	db.SetMaxOpenConns(1)
+
+	s := &SQLiteStorage{db: db, path: path}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *SQLiteStorage) Close() error {
+	return s.db.Close()
+}
+
+// SnapshotSQLite writes a consistent copy of the database at path to dest,
+// which must not exist yet. Unlike copying the file, it never catches a
+// transaction half written by another process.
+func SnapshotSQLite(path, dest string) error {
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(`VACUUM INTO ?`, dest); err != nil {
+		return fmt.Errorf("failed to copy database: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) migrate() error {
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to apply migration %d: %w", i+1, err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %d: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// withTx runs fn inside a transaction, committing only if fn succeeds.
+func (s *SQLiteStorage) withTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
+
+func parseTime(s string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, s)
+}
+
+func formatNullTime(t *time.Time) sql.NullString {
+	if t == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: formatTime(*t), Valid: true}
+}
+
+func parseNullTime(s sql.NullString) (*time.Time, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	t, err := parseTime(s.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+const clientColumns = `id, name, address, emails, default_hourly_rate, default_currency, vat_id, street, city, postal_code,
+	country, peppol_id, buyer_reference, invoice_template, version, created_at, updated_at`
+
+func scanClient(row rowScanner) (*models.Client, error) {
+	var (
+		c                    models.Client
+		emails               string
+		createdAt, updatedAt string
+	)
+	if err := row.Scan(&c.ID, &c.Name, &c.Address, &emails, &c.DefaultHourlyRate, &c.DefaultCurrency, &c.VATID, &c.Street, &c.City,
+		&c.PostalCode, &c.Country, &c.PeppolID, &c.BuyerReference, &c.InvoiceTemplate, &c.Version, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(emails), &c.Emails); err != nil {
+		return nil, fmt.Errorf("invalid emails for client %s: %w", c.ID, err)
+	}
+	var err error
+	if c.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if c.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func insertClient(q queryer, client *models.Client) error {
+	emails, err := json.Marshal(client.Emails)
+	if err != nil {
+		return err
+	}
+	_, err = q.Exec(`INSERT INTO clients (`+clientColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		client.ID, client.Name, client.Address, string(emails), client.DefaultHourlyRate, client.DefaultCurrency,
+		client.VATID, client.Street, client.City, client.PostalCode, client.Country, client.PeppolID, client.BuyerReference,
+		client.InvoiceTemplate, client.Version, formatTime(client.CreatedAt), formatTime(client.UpdatedAt))
+	return err
+}
+
+func (s *SQLiteStorage) GetAllClients() ([]models.Client, error) {
+	rows, err := s.db.Query(`SELECT ` + clientColumns + ` FROM clients ORDER BY rowid`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	clients := []models.Client{}
+	for rows.Next() {
+		client, err := scanClient(rows)
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, *client)
+	}
+	return clients, rows.Err()
+}
+
+func (s *SQLiteStorage) GetClient(id string) (*models.Client, error) {
+	client, err := scanClient(s.db.QueryRow(`SELECT `+clientColumns+` FROM clients WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("client not found")
+	}
+	return client, err
+}
+
+func (s *SQLiteStorage) SaveClient(client *models.Client) error {
+	if client.Version == 0 {
+		client.Version = 1
+	}
+	return insertClient(s.db, client)
+}
+
+func (s *SQLiteStorage) UpdateClient(client *models.Client) error {
+	emails, err := json.Marshal(client.Emails)
+	if err != nil {
+		return err
+	}
+	err = s.withTx(func(tx *sql.Tx) error {
+		res, err := tx.Exec(`UPDATE clients SET name = ?, address = ?, emails = ?, default_hourly_rate = ?,
+			default_currency = ?, vat_id = ?, street = ?, city = ?, postal_code = ?, country = ?, peppol_id = ?,
+			buyer_reference = ?, invoice_template = ?, created_at = ?, updated_at = ?, version = version + 1 WHERE id = ? AND version = ?`,
+			client.Name, client.Address, string(emails), client.DefaultHourlyRate, client.DefaultCurrency,
+			client.VATID, client.Street, client.City, client.PostalCode, client.Country, client.PeppolID, client.BuyerReference,
+			client.InvoiceTemplate, formatTime(client.CreatedAt), formatTime(client.UpdatedAt), client.ID, client.Version)
+		if err != nil {
+			return err
+		}
+		return checkVersionedUpdate(tx, res, "clients", "client", client.ID, client.Name, client.Version)
+	})
+	if err != nil {
+		return err
+	}
+	client.Version++
+	return nil
+}
+
+func (s *SQLiteStorage) DeleteClient(id string) error {
+	res, err := s.db.Exec(`DELETE FROM clients WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, "client not found")
+}
+
+// checkVersionedUpdate turns an UPDATE ... WHERE version = ? that matched no
+// rows into either a not-found error or a *models.ConflictError.
+func checkVersionedUpdate(q queryer, res sql.Result, table, entity, id, name string, version int) error {
+	n, err := res.RowsAffected()
+	if err != nil || n > 0 {
+		return err
+	}
+
+	var current int
+	err = q.QueryRow(`SELECT version FROM `+table+` WHERE id = ?`, id).Scan(&current)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New(entity + " not found")
+	}
+	if err != nil {
+		return err
+	}
+	return &models.ConflictError{Entity: entity, Name: name, Version: version, Current: current}
+}
+
+func expectAffected(res sql.Result, notFound string) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errors.New(notFound)
+	}
+	return nil
+}
+
+const invoiceColumns = `id, number, client_id, client_name, date, due_date, service_start_date, service_end_date,
+	subtotal, discount_rate, fixed_discount, discount, tax_rate, tax, total, currency, amount_paid, amount_credited, status,
+	estimate_id, version, created_at, updated_at`
+
+func scanInvoice(row rowScanner) (*models.Invoice, error) {
+	var (
+		inv                             models.Invoice
+		date, dueDate, created, updated string
+		serviceStart, serviceEnd        sql.NullString
+	)
+	err := row.Scan(&inv.ID, &inv.Number, &inv.ClientID, &inv.ClientName, &date, &dueDate,
+		&serviceStart, &serviceEnd, &inv.Subtotal, &inv.DiscountRate, &inv.FixedDiscount, &inv.Discount,
+		&inv.TaxRate, &inv.Tax, &inv.Total, &inv.Currency, &inv.AmountPaid, &inv.AmountCredited, &inv.Status, &inv.EstimateID, &inv.Version, &created, &updated)
+	if err != nil {
+		return nil, err
+	}
+	if inv.Date, err = parseTime(date); err != nil {
+		return nil, err
+	}
+	if inv.DueDate, err = parseTime(dueDate); err != nil {
+		return nil, err
+	}
+	if inv.ServiceStartDate, err = parseNullTime(serviceStart); err != nil {
+		return nil, err
+	}
+	if inv.ServiceEndDate, err = parseNullTime(serviceEnd); err != nil {
+		return nil, err
+	}
+	if inv.CreatedAt, err = parseTime(created); err != nil {
+		return nil, err
+	}
+	if inv.UpdatedAt, err = parseTime(updated); err != nil {
+		return nil, err
+	}
+	inv.LineItems = []models.LineItem{}
+	return &inv, nil
+}
+
+// queryInvoices loads the invoices matching where (which may be empty)
+// together with their line items.
+func (s *SQLiteStorage) queryInvoices(where string, args ...any) ([]models.Invoice, error) {
+	rows, err := s.db.Query(`SELECT `+invoiceColumns+` FROM invoices `+where+` ORDER BY rowid`, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	invoices := []models.Invoice{}
+	index := map[string]int{}
+	for rows.Next() {
+		inv, err := scanInvoice(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		index[inv.ID] = len(invoices)
+		invoices = append(invoices, *inv)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(invoices) == 0 {
+		return invoices, nil
+	}
+
+	itemRows, err := s.db.Query(`SELECT invoice_id, id, description, quantity, unit_price, discount_rate, fixed_discount, total, taxes
+		FROM line_items WHERE invoice_id IN (SELECT id FROM invoices `+where+`)
+		ORDER BY invoice_id, position`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		var (
+			invoiceID, taxes string
+			item             models.LineItem
+		)
+		if err := itemRows.Scan(&invoiceID, &item.ID, &item.Description, &item.Quantity, &item.UnitPrice,
+			&item.DiscountRate, &item.FixedDiscount, &item.Total, &taxes); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(taxes), &item.Taxes); err != nil {
+			return nil, fmt.Errorf("invalid taxes for line item %s: %w", item.ID, err)
+		}
+		if i, ok := index[invoiceID]; ok {
+			invoices[i].LineItems = append(invoices[i].LineItems, item)
+		}
+	}
+	return invoices, itemRows.Err()
+}
+
+func (s *SQLiteStorage) queryInvoice(where string, args ...any) (*models.Invoice, error) {
+	invoices, err := s.queryInvoices(where, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(invoices) == 0 {
+		return nil, errors.New("invoice not found")
+	}
+	return &invoices[0], nil
+}
+
+func insertInvoice(q queryer, invoice *models.Invoice) error {
+	_, err := q.Exec(`INSERT INTO invoices (`+invoiceColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		invoice.ID, invoice.Number, invoice.ClientID, invoice.ClientName,
+		formatTime(invoice.Date), formatTime(invoice.DueDate),
+		formatNullTime(invoice.ServiceStartDate), formatNullTime(invoice.ServiceEndDate),
+		invoice.Subtotal, invoice.DiscountRate, invoice.FixedDiscount, invoice.Discount, invoice.TaxRate, invoice.Tax, invoice.Total,
+		invoice.Currency, invoice.AmountPaid, invoice.AmountCredited, invoice.Status, invoice.EstimateID, invoice.Version, formatTime(invoice.CreatedAt), formatTime(invoice.UpdatedAt))
+	if err != nil {
+		return err
+	}
+	return insertLineItems(q, invoice)
+}
+
+func insertLineItems(q queryer, invoice *models.Invoice) error {
+	for i, item := range invoice.LineItems {
+		taxes, err := json.Marshal(item.Taxes)
+		if err != nil {
+			return err
+		}
+		_, err = q.Exec(`INSERT INTO line_items (invoice_id, position, id, description, quantity, unit_price,
+			discount_rate, fixed_discount, total, taxes)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			invoice.ID, i, item.ID, item.Description, item.Quantity, item.UnitPrice,
+			item.DiscountRate, item.FixedDiscount, item.Total, string(taxes))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetAllInvoices() ([]models.Invoice, error) {
+	return s.queryInvoices("")
+}
+
+func (s *SQLiteStorage) GetInvoice(id string) (*models.Invoice, error) {
+	return s.queryInvoice("WHERE id = ?", id)
+}
+
+func (s *SQLiteStorage) GetInvoiceByNumber(number string) (*models.Invoice, error) {
+	return s.queryInvoice("WHERE number = ?", number)
+}
+
+func (s *SQLiteStorage) SaveInvoice(invoice *models.Invoice) error {
+	if invoice.Version == 0 {
+		invoice.Version = 1
+	}
+	return s.withTx(func(tx *sql.Tx) error {
+		if err := takeNumber(tx, "invoices", &invoice.Number); err != nil {
+			return err
+		}
+		return insertInvoice(tx, invoice)
+	})
+}
+
+func (s *SQLiteStorage) UpdateInvoice(invoice *models.Invoice) error {
+	err := s.withTx(func(tx *sql.Tx) error {
+		res, err := tx.Exec(`UPDATE invoices SET number = ?, client_id = ?, client_name = ?, date = ?, due_date = ?,
+			service_start_date = ?, service_end_date = ?, subtotal = ?, discount_rate = ?, fixed_discount = ?, discount = ?,
+			tax_rate = ?, tax = ?, total = ?, currency = ?, amount_paid = ?, amount_credited = ?, status = ?, estimate_id = ?,
+			created_at = ?, updated_at = ?,
+			version = version + 1
+			WHERE id = ? AND version = ?`,
+			invoice.Number, invoice.ClientID, invoice.ClientName,
+			formatTime(invoice.Date), formatTime(invoice.DueDate),
+			formatNullTime(invoice.ServiceStartDate), formatNullTime(invoice.ServiceEndDate),
+			invoice.Subtotal, invoice.DiscountRate, invoice.FixedDiscount, invoice.Discount, invoice.TaxRate, invoice.Tax, invoice.Total,
+			invoice.Currency, invoice.AmountPaid, invoice.AmountCredited, invoice.Status, invoice.EstimateID, formatTime(invoice.CreatedAt), formatTime(invoice.UpdatedAt),
+			invoice.ID, invoice.Version)
+		if err != nil {
+			return err
+		}
+		if err := checkVersionedUpdate(tx, res, "invoices", "invoice", invoice.ID, invoice.Number, invoice.Version); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM line_items WHERE invoice_id = ?`, invoice.ID); err != nil {
+			return err
+		}
+		return insertLineItems(tx, invoice)
+	})
+	if err != nil {
+		return err
+	}
+	invoice.Version++
+	return nil
+}
+
+func (s *SQLiteStorage) DeleteInvoice(id string) error {
+	res, err := s.db.Exec(`DELETE FROM invoices WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, "invoice not found")
+}
+
+func (s *SQLiteStorage) GetNextInvoiceNumber(year int) (int, error) {
+	return s.nextNumber("invoices", fmt.Sprintf("%d-", year))
+}
+
+// nextNumber returns the next sequence number for the numbers in table that
+// start with prefix.
+func (s *SQLiteStorage) nextNumber(table, prefix string) (int, error) {
+	numbers, err := numbersWithPrefix(s.db, table, prefix)
+	if err != nil {
+		return 0, err
+	}
+	return nextSequence(numbers, prefix), nil
+}
+
+// takeNumber moves *number on to the next free one if a row in table has
+// it already. Call it in the transaction inserting the row, so that no
+// other process can take the number in between.
+func takeNumber(tx *sql.Tx, table string, number *string) error {
+	numbers, err := numbersWithPrefix(tx, table, (*number)[:strings.LastIndex(*number, "-")+1])
+	if err != nil {
+		return err
+	}
+	*number = freeNumber(numbers, *number)
+	return nil
+}
+
+func numbersWithPrefix(q queryer, table, prefix string) ([]string, error) {
+	rows, err := q.Query(`SELECT number FROM `+table+` WHERE substr(number, 1, ?) = ?`, len(prefix), prefix)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var numbers []string
+	for rows.Next() {
+		var number string
+		if err := rows.Scan(&number); err != nil {
+			return nil, err
+		}
+		numbers = append(numbers, number)
+	}
+	return numbers, rows.Err()
+}
+
+func (s *SQLiteStorage) GetInvoicesByClient(clientID string) ([]models.Invoice, error) {
+	return s.queryInvoices("WHERE client_id = ?", clientID)
+}
+
+func insertAuditEntry(q queryer, entry *models.AuditEntry) error {
+	action := entry.Action
+	if action == "" {
+		action = models.AuditStatusChange
+	}
+	_, err := q.Exec(`INSERT INTO audit_entries (id, invoice_id, invoice_number, action, old_status, new_status, changed_by, changed_at, reason)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.ID, entry.InvoiceID, entry.InvoiceNumber, action, entry.OldStatus, entry.NewStatus,
+		entry.ChangedBy, formatTime(entry.ChangedAt), entry.Reason)
+	return err
+}
+
+func (s *SQLiteStorage) SaveAuditEntry(entry *models.AuditEntry) error {
+	return insertAuditEntry(s.db, entry)
+}
+
+func (s *SQLiteStorage) GetAuditEntries(invoiceID string) ([]models.AuditEntry, error) {
+	rows, err := s.db.Query(`SELECT id, invoice_id, invoice_number, action, old_status, new_status, changed_by, changed_at, reason
+		FROM audit_entries WHERE invoice_id = ? ORDER BY rowid`, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var (
+			entry     models.AuditEntry
+			changedAt string
+		)
+		if err := rows.Scan(&entry.ID, &entry.InvoiceID, &entry.InvoiceNumber, &entry.Action, &entry.OldStatus,
+			&entry.NewStatus, &entry.ChangedBy, &changedAt, &entry.Reason); err != nil {
+			return nil, err
+		}
+		if entry.ChangedAt, err = parseTime(changedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+func insertPayment(q queryer, payment *models.Payment) error {
+	_, err := q.Exec(`INSERT INTO payments (id, invoice_id, amount, date, method, reference, note, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		payment.ID, payment.InvoiceID, payment.Amount, formatTime(payment.Date),
+		payment.Method, payment.Reference, payment.Note, formatTime(payment.CreatedAt))
+	return err
+}
+
+func (s *SQLiteStorage) SavePayment(payment *models.Payment) error {
+	return insertPayment(s.db, payment)
+}
+
+func (s *SQLiteStorage) GetPayments(invoiceID string) ([]models.Payment, error) {
+	rows, err := s.db.Query(`SELECT id, invoice_id, amount, date, method, reference, note, created_at
+		FROM payments WHERE invoice_id = ? ORDER BY date, rowid`, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := []models.Payment{}
+	for rows.Next() {
+		var (
+			p               models.Payment
+			date, createdAt string
+		)
+		if err := rows.Scan(&p.ID, &p.InvoiceID, &p.Amount, &date, &p.Method, &p.Reference, &p.Note, &createdAt); err != nil {
+			return nil, err
+		}
+		if p.Date, err = parseTime(date); err != nil {
+			return nil, err
+		}
+		if p.CreatedAt, err = parseTime(createdAt); err != nil {
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+	return payments, rows.Err()
+}
+
+func (s *SQLiteStorage) DeletePayment(id string) error {
+	res, err := s.db.Exec(`DELETE FROM payments WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, "payment not found")
+}
+
+const recurringColumns = `id, name, client_id, client_name, line_items, discount_rate, fixed_discount, tax_rate, currency, due_days,
+	cadence, interval_count, start_date, next_run_date, active, version, created_at, updated_at`
+
+// Recurring invoices keep their line items as JSON: they are only ever read
+// and written as a whole and never queried individually.
+func scanRecurringInvoice(row rowScanner) (*models.RecurringInvoice, error) {
+	var (
+		r                                    models.RecurringInvoice
+		items, start, next, created, updated string
+	)
+	err := row.Scan(&r.ID, &r.Name, &r.ClientID, &r.ClientName, &items, &r.DiscountRate, &r.FixedDiscount, &r.TaxRate, &r.Currency, &r.DueDays,
+		&r.Cadence, &r.Interval, &start, &next, &r.Active, &r.Version, &created, &updated)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(items), &r.LineItems); err != nil {
+		return nil, fmt.Errorf("invalid line items for recurring invoice %s: %w", r.ID, err)
+	}
+	if r.StartDate, err = parseTime(start); err != nil {
+		return nil, err
+	}
+	if r.NextRunDate, err = parseTime(next); err != nil {
+		return nil, err
+	}
+	if r.CreatedAt, err = parseTime(created); err != nil {
+		return nil, err
+	}
+	if r.UpdatedAt, err = parseTime(updated); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func insertRecurringInvoice(q queryer, r *models.RecurringInvoice) error {
+	items, err := json.Marshal(r.LineItems)
+	if err != nil {
+		return err
+	}
+	_, err = q.Exec(`INSERT INTO recurring_invoices (`+recurringColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.ID, r.Name, r.ClientID, r.ClientName, string(items), r.DiscountRate, r.FixedDiscount, r.TaxRate, r.Currency, r.DueDays,
+		r.Cadence, r.Interval, formatTime(r.StartDate), formatTime(r.NextRunDate), r.Active, r.Version,
+		formatTime(r.CreatedAt), formatTime(r.UpdatedAt))
+	return err
+}
+
+func (s *SQLiteStorage) GetAllRecurringInvoices() ([]models.RecurringInvoice, error) {
+	rows, err := s.db.Query(`SELECT ` + recurringColumns + ` FROM recurring_invoices ORDER BY rowid`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules := []models.RecurringInvoice{}
+	for rows.Next() {
+		r, err := scanRecurringInvoice(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, *r)
+	}
+	return schedules, rows.Err()
+}
+
+func (s *SQLiteStorage) GetRecurringInvoice(id string) (*models.RecurringInvoice, error) {
+	r, err := scanRecurringInvoice(s.db.QueryRow(`SELECT `+recurringColumns+` FROM recurring_invoices WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("recurring invoice not found")
+	}
+	return r, err
+}
+
+func (s *SQLiteStorage) SaveRecurringInvoice(recurring *models.RecurringInvoice) error {
+	if recurring.Version == 0 {
+		recurring.Version = 1
+	}
+	return insertRecurringInvoice(s.db, recurring)
+}
+
+func (s *SQLiteStorage) UpdateRecurringInvoice(recurring *models.RecurringInvoice) error {
+	items, err := json.Marshal(recurring.LineItems)
+	if err != nil {
+		return err
+	}
+	err = s.withTx(func(tx *sql.Tx) error {
+		res, err := tx.Exec(`UPDATE recurring_invoices SET name = ?, client_id = ?, client_name = ?, line_items = ?,
+			discount_rate = ?, fixed_discount = ?, tax_rate = ?, currency = ?, due_days = ?, cadence = ?, interval_count = ?, start_date = ?,
+			next_run_date = ?, active = ?, created_at = ?, updated_at = ?, version = version + 1
+			WHERE id = ? AND version = ?`,
+			recurring.Name, recurring.ClientID, recurring.ClientName, string(items),
+			recurring.DiscountRate, recurring.FixedDiscount, recurring.TaxRate, recurring.Currency, recurring.DueDays, recurring.Cadence, recurring.Interval,
+			formatTime(recurring.StartDate), formatTime(recurring.NextRunDate), recurring.Active,
+			formatTime(recurring.CreatedAt), formatTime(recurring.UpdatedAt), recurring.ID, recurring.Version)
+		if err != nil {
+			return err
+		}
+		return checkVersionedUpdate(tx, res, "recurring_invoices", "recurring invoice", recurring.ID, recurring.Name, recurring.Version)
+	})
+	if err != nil {
+		return err
+	}
+	recurring.Version++
+	return nil
+}
+
+func (s *SQLiteStorage) DeleteRecurringInvoice(id string) error {
+	res, err := s.db.Exec(`DELETE FROM recurring_invoices WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, "recurring invoice not found")
+}
+
+const estimateColumns = `id, number, client_id, client_name, date, valid_until, line_items, subtotal,
+	discount_rate, fixed_discount, discount, tax_rate, tax, total, currency, notes, status, invoice_id, version, created_at,
+	updated_at`
+
+func scanEstimate(row rowScanner) (*models.Estimate, error) {
+	var (
+		e                                         models.Estimate
+		date, validUntil, items, created, updated string
+	)
+	err := row.Scan(&e.ID, &e.Number, &e.ClientID, &e.ClientName, &date, &validUntil, &items, &e.Subtotal,
+		&e.DiscountRate, &e.FixedDiscount, &e.Discount, &e.TaxRate, &e.Tax, &e.Total, &e.Currency, &e.Notes, &e.Status, &e.InvoiceID,
+		&e.Version, &created, &updated)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(items), &e.LineItems); err != nil {
+		return nil, fmt.Errorf("invalid line items for estimate %s: %w", e.Number, err)
+	}
+	if e.Date, err = parseTime(date); err != nil {
+		return nil, err
+	}
+	if e.ValidUntil, err = parseTime(validUntil); err != nil {
+		return nil, err
+	}
+	if e.CreatedAt, err = parseTime(created); err != nil {
+		return nil, err
+	}
+	if e.UpdatedAt, err = parseTime(updated); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func insertEstimate(q queryer, e *models.Estimate) error {
+	items, err := json.Marshal(e.LineItems)
+	if err != nil {
+		return err
+	}
+	_, err = q.Exec(`INSERT INTO estimates (`+estimateColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.ID, e.Number, e.ClientID, e.ClientName, formatTime(e.Date), formatTime(e.ValidUntil), string(items),
+		e.Subtotal, e.DiscountRate, e.FixedDiscount, e.Discount, e.TaxRate, e.Tax, e.Total, e.Currency, e.Notes, e.Status, e.InvoiceID,
+		e.Version, formatTime(e.CreatedAt), formatTime(e.UpdatedAt))
+	return err
+}
+
+func (s *SQLiteStorage) GetAllEstimates() ([]models.Estimate, error) {
+	rows, err := s.db.Query(`SELECT ` + estimateColumns + ` FROM estimates ORDER BY rowid`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	estimates := []models.Estimate{}
+	for rows.Next() {
+		e, err := scanEstimate(rows)
+		if err != nil {
+			return nil, err
+		}
+		estimates = append(estimates, *e)
+	}
+	return estimates, rows.Err()
+}
+
+func (s *SQLiteStorage) GetEstimate(id string) (*models.Estimate, error) {
+	e, err := scanEstimate(s.db.QueryRow(`SELECT `+estimateColumns+` FROM estimates WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("estimate not found")
+	}
+	return e, err
+}
+
+func (s *SQLiteStorage) SaveEstimate(estimate *models.Estimate) error {
+	if estimate.Version == 0 {
+		estimate.Version = 1
+	}
+	return s.withTx(func(tx *sql.Tx) error {
+		if err := takeNumber(tx, "estimates", &estimate.Number); err != nil {
+			return err
+		}
+		return insertEstimate(tx, estimate)
+	})
+}
+
+func (s *SQLiteStorage) UpdateEstimate(estimate *models.Estimate) error {
+	items, err := json.Marshal(estimate.LineItems)
+	if err != nil {
+		return err
+	}
+	err = s.withTx(func(tx *sql.Tx) error {
+		res, err := tx.Exec(`UPDATE estimates SET number = ?, client_id = ?, client_name = ?, date = ?, valid_until = ?,
+			line_items = ?, subtotal = ?, discount_rate = ?, fixed_discount = ?, discount = ?, tax_rate = ?, tax = ?, total = ?, currency = ?, notes = ?,
+			status = ?, invoice_id = ?, created_at = ?, updated_at = ?, version = version + 1
+			WHERE id = ? AND version = ?`,
+			estimate.Number, estimate.ClientID, estimate.ClientName, formatTime(estimate.Date), formatTime(estimate.ValidUntil),
+			string(items), estimate.Subtotal, estimate.DiscountRate, estimate.FixedDiscount, estimate.Discount, estimate.TaxRate, estimate.Tax,
+			estimate.Total, estimate.Currency, estimate.Notes, estimate.Status, estimate.InvoiceID,
+			formatTime(estimate.CreatedAt), formatTime(estimate.UpdatedAt), estimate.ID, estimate.Version)
+		if err != nil {
+			return err
+		}
+		return checkVersionedUpdate(tx, res, "estimates", "estimate", estimate.ID, estimate.Number, estimate.Version)
+	})
+	if err != nil {
+		return err
+	}
+	estimate.Version++
+	return nil
+}
+
+func (s *SQLiteStorage) DeleteEstimate(id string) error {
+	res, err := s.db.Exec(`DELETE FROM estimates WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, "estimate not found")
+}
+
+func (s *SQLiteStorage) GetNextEstimateNumber(year int) (int, error) {
+	return s.nextNumber("estimates", fmt.Sprintf("EST-%d-", year))
+}
+
+const creditNoteColumns = `id, number, invoice_id, invoice_number, client_id, client_name, date, reason, line_items,
+	subtotal, discount_rate, discount, tax_rate, tax, total, currency, created_at`
+
+func scanCreditNote(row rowScanner) (*models.CreditNote, error) {
+	var (
+		n                      models.CreditNote
+		date, items, createdAt string
+	)
+	err := row.Scan(&n.ID, &n.Number, &n.InvoiceID, &n.InvoiceNumber, &n.ClientID, &n.ClientName, &date, &n.Reason,
+		&items, &n.Subtotal, &n.DiscountRate, &n.Discount, &n.TaxRate, &n.Tax, &n.Total, &n.Currency, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(items), &n.LineItems); err != nil {
+		return nil, fmt.Errorf("invalid line items for credit note %s: %w", n.Number, err)
+	}
+	if n.Date, err = parseTime(date); err != nil {
+		return nil, err
+	}
+	if n.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+func insertCreditNote(q queryer, n *models.CreditNote) error {
+	items, err := json.Marshal(n.LineItems)
+	if err != nil {
+		return err
+	}
+	_, err = q.Exec(`INSERT INTO credit_notes (`+creditNoteColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		n.ID, n.Number, n.InvoiceID, n.InvoiceNumber, n.ClientID, n.ClientName, formatTime(n.Date), n.Reason,
+		string(items), n.Subtotal, n.DiscountRate, n.Discount, n.TaxRate, n.Tax, n.Total, n.Currency, formatTime(n.CreatedAt))
+	return err
+}
+
+func (s *SQLiteStorage) queryCreditNotes(where string, args ...any) ([]models.CreditNote, error) {
+	rows, err := s.db.Query(`SELECT `+creditNoteColumns+` FROM credit_notes `+where+` ORDER BY rowid`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := []models.CreditNote{}
+	for rows.Next() {
+		n, err := scanCreditNote(rows)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, *n)
+	}
+	return notes, rows.Err()
+}
+
+func (s *SQLiteStorage) GetAllCreditNotes() ([]models.CreditNote, error) {
+	return s.queryCreditNotes("")
+}
+
+func (s *SQLiteStorage) GetCreditNotes(invoiceID string) ([]models.CreditNote, error) {
+	return s.queryCreditNotes("WHERE invoice_id = ?", invoiceID)
+}
+
+func (s *SQLiteStorage) SaveCreditNote(note *models.CreditNote) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		if err := takeNumber(tx, "credit_notes", &note.Number); err != nil {
+			return err
+		}
+		return insertCreditNote(tx, note)
+	})
+}
+
+func (s *SQLiteStorage) DeleteCreditNote(id string) error {
+	res, err := s.db.Exec(`DELETE FROM credit_notes WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, "credit note not found")
+}
+
+func (s *SQLiteStorage) GetNextCreditNoteNumber(year int) (int, error) {
+	return s.nextNumber("credit_notes", fmt.Sprintf("CN-%d-", year))
+}
+
+const taxColumns = `id, name, rate, compound, inclusive, created_at, updated_at`
+
+func scanTax(row rowScanner) (*models.Tax, error) {
+	var (
+		t                  models.Tax
+		createdAt, updated string
+	)
+	if err := row.Scan(&t.ID, &t.Name, &t.Rate, &t.Compound, &t.Inclusive, &createdAt, &updated); err != nil {
+		return nil, err
+	}
+	var err error
+	if t.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if t.UpdatedAt, err = parseTime(updated); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func insertTax(q queryer, t *models.Tax) error {
+	_, err := q.Exec(`INSERT INTO taxes (`+taxColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		t.ID, t.Name, t.Rate, t.Compound, t.Inclusive, formatTime(t.CreatedAt), formatTime(t.UpdatedAt))
+	return err
+}
+
+func (s *SQLiteStorage) GetAllTaxes() ([]models.Tax, error) {
+	rows, err := s.db.Query(`SELECT ` + taxColumns + ` FROM taxes ORDER BY rowid`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	taxes := []models.Tax{}
+	for rows.Next() {
+		t, err := scanTax(rows)
+		if err != nil {
+			return nil, err
+		}
+		taxes = append(taxes, *t)
+	}
+	return taxes, rows.Err()
+}
+
+func (s *SQLiteStorage) GetTax(id string) (*models.Tax, error) {
+	t, err := scanTax(s.db.QueryRow(`SELECT `+taxColumns+` FROM taxes WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("tax not found")
+	}
+	return t, err
+}
+
+func (s *SQLiteStorage) SaveTax(tax *models.Tax) error {
+	return insertTax(s.db, tax)
+}
+
+func (s *SQLiteStorage) UpdateTax(tax *models.Tax) error {
+	res, err := s.db.Exec(`UPDATE taxes SET name = ?, rate = ?, compound = ?, inclusive = ?, created_at = ?, updated_at = ?
+		WHERE id = ?`,
+		tax.Name, tax.Rate, tax.Compound, tax.Inclusive, formatTime(tax.CreatedAt), formatTime(tax.UpdatedAt), tax.ID)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, "tax not found")
+}
+
+func (s *SQLiteStorage) DeleteTax(id string) error {
+	res, err := s.db.Exec(`DELETE FROM taxes WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, "tax not found")
+}
+
+const catalogColumns = `id, sku, description, unit, unit_price, tax_ids, created_at, updated_at`
+
+func scanCatalogItem(row rowScanner) (*models.CatalogItem, error) {
+	var (
+		c                          models.CatalogItem
+		taxIDs, createdAt, updated string
+	)
+	if err := row.Scan(&c.ID, &c.SKU, &c.Description, &c.Unit, &c.UnitPrice, &taxIDs, &createdAt, &updated); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(taxIDs), &c.TaxIDs); err != nil {
+		return nil, fmt.Errorf("invalid taxes for catalog item %s: %w", c.ID, err)
+	}
+	var err error
+	if c.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if c.UpdatedAt, err = parseTime(updated); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func insertCatalogItem(q queryer, c *models.CatalogItem) error {
+	taxIDs, err := json.Marshal(c.TaxIDs)
+	if err != nil {
+		return err
+	}
+	_, err = q.Exec(`INSERT INTO catalog_items (`+catalogColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		c.ID, c.SKU, c.Description, c.Unit, c.UnitPrice, string(taxIDs), formatTime(c.CreatedAt), formatTime(c.UpdatedAt))
+	return err
+}
+
+func (s *SQLiteStorage) GetAllCatalogItems() ([]models.CatalogItem, error) {
+	rows, err := s.db.Query(`SELECT ` + catalogColumns + ` FROM catalog_items ORDER BY rowid`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.CatalogItem{}
+	for rows.Next() {
+		c, err := scanCatalogItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *c)
+	}
+	return items, rows.Err()
+}
+
+func (s *SQLiteStorage) GetCatalogItem(id string) (*models.CatalogItem, error) {
+	c, err := scanCatalogItem(s.db.QueryRow(`SELECT `+catalogColumns+` FROM catalog_items WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("catalog item not found")
+	}
+	return c, err
+}
+
+func (s *SQLiteStorage) SaveCatalogItem(item *models.CatalogItem) error {
+	return insertCatalogItem(s.db, item)
+}
+
+func (s *SQLiteStorage) UpdateCatalogItem(item *models.CatalogItem) error {
+	taxIDs, err := json.Marshal(item.TaxIDs)
+	if err != nil {
+		return err
+	}
+	res, err := s.db.Exec(`UPDATE catalog_items SET sku = ?, description = ?, unit = ?, unit_price = ?, tax_ids = ?,
+		created_at = ?, updated_at = ? WHERE id = ?`,
+		item.SKU, item.Description, item.Unit, item.UnitPrice, string(taxIDs),
+		formatTime(item.CreatedAt), formatTime(item.UpdatedAt), item.ID)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, "catalog item not found")
+}
+
+func (s *SQLiteStorage) DeleteCatalogItem(id string) error {
+	res, err := s.db.Exec(`DELETE FROM catalog_items WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, "catalog item not found")
+}
+
+const timeEntryColumns = `id, client_id, client_name, project, description, started_at, ended_at, billable,
+	invoice_id, invoice_number, external_id, created_at, updated_at`
+
+func scanTimeEntry(row rowScanner) (*models.TimeEntry, error) {
+	var (
+		e                           models.TimeEntry
+		start, createdAt, updatedAt string
+		end                         sql.NullString
+	)
+	if err := row.Scan(&e.ID, &e.ClientID, &e.ClientName, &e.Project, &e.Description, &start, &end, &e.Billable,
+		&e.InvoiceID, &e.InvoiceNumber, &e.ExternalID, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	var err error
+	if e.Start, err = parseTime(start); err != nil {
+		return nil, err
+	}
+	if e.End, err = parseNullTime(end); err != nil {
+		return nil, err
+	}
+	if e.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if e.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func insertTimeEntry(q queryer, e *models.TimeEntry) error {
+	_, err := q.Exec(`INSERT INTO time_entries (`+timeEntryColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.ID, e.ClientID, e.ClientName, e.Project, e.Description, formatTime(e.Start), formatNullTime(e.End), e.Billable,
+		e.InvoiceID, e.InvoiceNumber, e.ExternalID, formatTime(e.CreatedAt), formatTime(e.UpdatedAt))
+	return err
+}
+
+func (s *SQLiteStorage) GetAllTimeEntries() ([]models.TimeEntry, error) {
+	rows, err := s.db.Query(`SELECT ` + timeEntryColumns + ` FROM time_entries ORDER BY rowid`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.TimeEntry{}
+	for rows.Next() {
+		e, err := scanTimeEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *e)
+	}
+	return entries, rows.Err()
+}
+
+func (s *SQLiteStorage) GetTimeEntry(id string) (*models.TimeEntry, error) {
+	e, err := scanTimeEntry(s.db.QueryRow(`SELECT `+timeEntryColumns+` FROM time_entries WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("time entry not found")
+	}
+	return e, err
+}
+
+func (s *SQLiteStorage) SaveTimeEntry(entry *models.TimeEntry) error {
+	return insertTimeEntry(s.db, entry)
+}
+
+func (s *SQLiteStorage) UpdateTimeEntry(entry *models.TimeEntry) error {
+	res, err := s.db.Exec(`UPDATE time_entries SET client_id = ?, client_name = ?, project = ?, description = ?,
+		started_at = ?, ended_at = ?, billable = ?, invoice_id = ?, invoice_number = ?, external_id = ?, created_at = ?,
+		updated_at = ? WHERE id = ?`,
+		entry.ClientID, entry.ClientName, entry.Project, entry.Description, formatTime(entry.Start), formatNullTime(entry.End),
+		entry.Billable, entry.InvoiceID, entry.InvoiceNumber, entry.ExternalID, formatTime(entry.CreatedAt),
+		formatTime(entry.UpdatedAt), entry.ID)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, "time entry not found")
+}
+
+func (s *SQLiteStorage) DeleteTimeEntry(id string) error {
+	res, err := s.db.Exec(`DELETE FROM time_entries WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, "time entry not found")
+}
+
+const expenseColumns = `id, client_id, client_name, date, vendor, category, description, amount, markup_rate,
+	receipt_path, invoice_id, invoice_number, created_at, updated_at`
+
+func scanExpense(row rowScanner) (*models.Expense, error) {
+	var (
+		e                          models.Expense
+		date, createdAt, updatedAt string
+	)
+	if err := row.Scan(&e.ID, &e.ClientID, &e.ClientName, &date, &e.Vendor, &e.Category, &e.Description, &e.Amount,
+		&e.MarkupRate, &e.ReceiptPath, &e.InvoiceID, &e.InvoiceNumber, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	var err error
+	if e.Date, err = parseTime(date); err != nil {
+		return nil, err
+	}
+	if e.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if e.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func insertExpense(q queryer, e *models.Expense) error {
+	_, err := q.Exec(`INSERT INTO expenses (`+expenseColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.ID, e.ClientID, e.ClientName, formatTime(e.Date), e.Vendor, e.Category, e.Description, e.Amount,
+		e.MarkupRate, e.ReceiptPath, e.InvoiceID, e.InvoiceNumber, formatTime(e.CreatedAt), formatTime(e.UpdatedAt))
+	return err
+}
+
+func (s *SQLiteStorage) GetAllExpenses() ([]models.Expense, error) {
+	rows, err := s.db.Query(`SELECT ` + expenseColumns + ` FROM expenses ORDER BY rowid`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	expenses := []models.Expense{}
+	for rows.Next() {
+		e, err := scanExpense(rows)
+		if err != nil {
+			return nil, err
+		}
+		expenses = append(expenses, *e)
+	}
+	return expenses, rows.Err()
+}
+
+func (s *SQLiteStorage) GetExpense(id string) (*models.Expense, error) {
+	e, err := scanExpense(s.db.QueryRow(`SELECT `+expenseColumns+` FROM expenses WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("expense not found")
+	}
+	return e, err
+}
+
+func (s *SQLiteStorage) SaveExpense(expense *models.Expense) error {
+	return insertExpense(s.db, expense)
+}
+
+func (s *SQLiteStorage) UpdateExpense(expense *models.Expense) error {
+	res, err := s.db.Exec(`UPDATE expenses SET client_id = ?, client_name = ?, date = ?, vendor = ?, category = ?,
+		description = ?, amount = ?, markup_rate = ?, receipt_path = ?, invoice_id = ?, invoice_number = ?,
+		created_at = ?, updated_at = ? WHERE id = ?`,
+		expense.ClientID, expense.ClientName, formatTime(expense.Date), expense.Vendor, expense.Category,
+		expense.Description, expense.Amount, expense.MarkupRate, expense.ReceiptPath, expense.InvoiceID,
+		expense.InvoiceNumber, formatTime(expense.CreatedAt), formatTime(expense.UpdatedAt), expense.ID)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, "expense not found")
+}
+
+func (s *SQLiteStorage) DeleteExpense(id string) error {
+	res, err := s.db.Exec(`DELETE FROM expenses WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, "expense not found")
+}
+
+const clientMappingColumns = `id, name, client_id, created_at`
+
+func scanClientMapping(row rowScanner) (*models.ClientMapping, error) {
+	var (
+		m         models.ClientMapping
+		createdAt string
+	)
+	if err := row.Scan(&m.ID, &m.Name, &m.ClientID, &createdAt); err != nil {
+		return nil, err
+	}
+	var err error
+	if m.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// insertClientMapping adds mapping, replacing any mapping for the same name.
+func insertClientMapping(q queryer, m *models.ClientMapping) error {
+	_, err := q.Exec(`INSERT INTO client_mappings (`+clientMappingColumns+`) VALUES (?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET id = excluded.id, name = excluded.name, client_id = excluded.client_id,
+		created_at = excluded.created_at`,
+		m.ID, m.Name, m.ClientID, formatTime(m.CreatedAt))
+	return err
+}
+
+func (s *SQLiteStorage) GetAllClientMappings() ([]models.ClientMapping, error) {
+	rows, err := s.db.Query(`SELECT ` + clientMappingColumns + ` FROM client_mappings ORDER BY rowid`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	mappings := []models.ClientMapping{}
+	for rows.Next() {
+		m, err := scanClientMapping(rows)
+		if err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, *m)
+	}
+	return mappings, rows.Err()
+}
+
+func (s *SQLiteStorage) SaveClientMapping(mapping *models.ClientMapping) error {
+	return insertClientMapping(s.db, mapping)
+}
+
+func (s *SQLiteStorage) DeleteClientMapping(id string) error {
+	res, err := s.db.Exec(`DELETE FROM client_mappings WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, "client mapping not found")
+}
+
+// ImportResult summarises what ImportJSON copied into the database.
+type ImportResult struct {
+	Clients      int
+	Invoices     int
+	AuditEntries int
+	Payments     int
+	Recurring    int
+	Estimates    int
+	CreditNotes  int
+	Taxes        int
+	CatalogItems int
+	TimeEntries  int
+	Mappings     int
+	Expenses     int
+}
+
+// ImportJSON copies the clients, invoices, payments, audit entries,
+// recurring invoices, estimates, credit notes, taxes, catalog items, time
+// entries, client mappings and expenses stored as JSON in dataDir into the
+// SQLite database at dbPath. The import runs in a single
+// transaction and refuses to touch a database that already holds data.
+func ImportJSON(dataDir, dbPath string) (*ImportResult, error) {
+	src, err := NewJSONStorage(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	clients, err := src.readClients()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read clients: %w", err)
+	}
+	invoices, err := src.readInvoices()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read invoices: %w", err)
+	}
+	entries, err := src.readAuditEntries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit entries: %w", err)
+	}
+	payments, err := src.readPayments()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read payments: %w", err)
+	}
+
+	schedules, err := src.readRecurringInvoices()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recurring invoices: %w", err)
+	}
+
+	estimates, err := src.readEstimates()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read estimates: %w", err)
+	}
+
+	creditNotes, err := src.readCreditNotes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credit notes: %w", err)
+	}
+
+	taxes, err := src.readTaxes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read taxes: %w", err)
+	}
+
+	catalog, err := src.readCatalogItems()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read catalog items: %w", err)
+	}
+
+	timeEntries, err := src.readTimeEntries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read time entries: %w", err)
+	}
+
+	mappings, err := src.readClientMappings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client mappings: %w", err)
+	}
+
+	expenses, err := src.readExpenses()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read expenses: %w", err)
+	}
+
+	dst, err := NewSQLiteStorage(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer dst.Close()
+
+	err = dst.withTx(func(tx *sql.Tx) error {
+		var count int
+		if err := tx.QueryRow(`SELECT (SELECT COUNT(*) FROM clients) + (SELECT COUNT(*) FROM invoices)`).Scan(&count); err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("database %s already contains data", dbPath)
+		}
+
+		for i := range clients {
+			if err := insertClient(tx, &clients[i]); err != nil {
+				return fmt.Errorf("failed to import client %s: %w", clients[i].Name, err)
+			}
+		}
+		for i := range invoices {
+			if err := insertInvoice(tx, &invoices[i]); err != nil {
+				return fmt.Errorf("failed to import invoice %s: %w", invoices[i].Number, err)
+			}
+		}
+		for i := range entries {
+			if err := insertAuditEntry(tx, &entries[i]); err != nil {
+				return fmt.Errorf("failed to import audit entry %s: %w", entries[i].ID, err)
+			}
+		}
+		for i := range payments {
+			if err := insertPayment(tx, &payments[i]); err != nil {
+				return fmt.Errorf("failed to import payment %s: %w", payments[i].ID, err)
+			}
+		}
+		for i := range schedules {
+			if err := insertRecurringInvoice(tx, &schedules[i]); err != nil {
+				return fmt.Errorf("failed to import recurring invoice %s: %w", schedules[i].Name, err)
+			}
+		}
+		for i := range estimates {
+			if err := insertEstimate(tx, &estimates[i]); err != nil {
+				return fmt.Errorf("failed to import estimate %s: %w", estimates[i].Number, err)
+			}
+		}
+		for i := range creditNotes {
+			if err := insertCreditNote(tx, &creditNotes[i]); err != nil {
+				return fmt.Errorf("failed to import credit note %s: %w", creditNotes[i].Number, err)
+			}
+		}
+		for i := range taxes {
+			if err := insertTax(tx, &taxes[i]); err != nil {
+				return fmt.Errorf("failed to import tax %s: %w", taxes[i].Name, err)
+			}
+		}
+		for i := range catalog {
+			if err := insertCatalogItem(tx, &catalog[i]); err != nil {
+				return fmt.Errorf("failed to import catalog item %s: %w", catalog[i].Description, err)
+			}
+		}
+		for i := range timeEntries {
+			if err := insertTimeEntry(tx, &timeEntries[i]); err != nil {
+				return fmt.Errorf("failed to import time entry %s: %w", timeEntries[i].ID, err)
+			}
+		}
+		for i := range mappings {
+			if err := insertClientMapping(tx, &mappings[i]); err != nil {
+				return fmt.Errorf("failed to import client mapping %s: %w", mappings[i].Name, err)
+			}
+		}
+		for i := range expenses {
+			if err := insertExpense(tx, &expenses[i]); err != nil {
+				return fmt.Errorf("failed to import expense %s: %w", expenses[i].ID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ImportResult{
+		Clients:      len(clients),
+		Invoices:     len(invoices),
+		AuditEntries: len(entries),
+		Payments:     len(payments),
+		Recurring:    len(schedules),
+		Estimates:    len(estimates),
+		CreditNotes:  len(creditNotes),
+		Taxes:        len(taxes),
+		CatalogItems: len(catalog),
+		TimeEntries:  len(timeEntries),
+		Mappings:     len(mappings),
+		Expenses:     len(expenses),
+	}, nil
+}
+
+var _ models.Storage = (*SQLiteStorage)(nil)
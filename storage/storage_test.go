@@ -0,0 +1,239 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/user/invoicer/models"
+)
+
+// backends runs test against a fresh store of each backend.
+func backends(t *testing.T, test func(t *testing.T, store models.Storage)) {
+	t.Run("json", func(t *testing.T) {
+		store, err := NewJSONStorage(t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		test(t, store)
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "invoicer.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { store.Close() })
+		test(t, store)
+	})
+}
+
+func newTestInvoice(t *testing.T, store models.Storage, number string) *models.Invoice {
+	t.Helper()
+	client := models.NewClient("Acme "+number, "1 Main St", []string{"billing@acme.test"}, decimal.NewFromInt(90))
+	if err := store.SaveClient(client); err != nil {
+		t.Fatal(err)
+	}
+	invoice := models.NewInvoice(client.ID, client.Name, number)
+	item := models.NewLineItem("Consulting", decimal.RequireFromString("1.5"), decimal.NewFromInt(120))
+	item.Taxes = []models.Tax{*models.NewTax("VAT", decimal.NewFromInt(20), false, false)}
+	invoice.AddLineItem(*item)
+	invoice.AddLineItem(*models.NewLineItem("Travel", decimal.NewFromInt(1), decimal.RequireFromString("45.50")))
+	if err := store.SaveInvoice(invoice); err != nil {
+		t.Fatal(err)
+	}
+	return invoice
+}
+
+// sameJSON reports whether a and b encode alike, which compares decimals
+// and times by value.
+func sameJSON(t *testing.T, a, b any) bool {
+	t.Helper()
+	ja, err := json.Marshal(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jb, err := json.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(ja) == string(jb)
+}
+
+func TestInvoiceRoundTrip(t *testing.T) {
+	backends(t, func(t *testing.T, store models.Storage) {
+		invoice := newTestInvoice(t, store, "2026-01")
+
+		got, err := store.GetInvoice(invoice.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sameJSON(t, got, invoice) {
+			t.Errorf("GetInvoice() = %+v, want %+v", got, invoice)
+		}
+		if byNumber, err := store.GetInvoiceByNumber("2026-01"); err != nil || byNumber.ID != invoice.ID {
+			t.Errorf("GetInvoiceByNumber() = %v, %v", byNumber, err)
+		}
+
+		got.RemoveLineItem(got.LineItems[1].ID)
+		got.Status = models.StatusSent
+		if err := store.UpdateInvoice(got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Version != invoice.Version+1 {
+			t.Errorf("version after update = %d, want %d", got.Version, invoice.Version+1)
+		}
+		updated, err := store.GetInvoice(invoice.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sameJSON(t, updated, got) {
+			t.Errorf("after update GetInvoice() = %+v, want %+v", updated, got)
+		}
+	})
+}
+
+func TestClientRoundTrip(t *testing.T) {
+	backends(t, func(t *testing.T, store models.Storage) {
+		client := models.NewClient("Acme", "1 Main St", []string{"a@acme.test", "b@acme.test"}, decimal.RequireFromString("87.50"))
+		client.DefaultCurrency = "EUR"
+		client.VATID = "DE123456789"
+		client.InvoiceTemplate = "minimal"
+		if err := store.SaveClient(client); err != nil {
+			t.Fatal(err)
+		}
+		got, err := store.GetClient(client.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sameJSON(t, got, client) {
+			t.Errorf("GetClient() = %+v, want %+v", got, client)
+		}
+	})
+}
+
+func TestCatalogRoundTrip(t *testing.T) {
+	backends(t, func(t *testing.T, store models.Storage) {
+		item := models.NewCatalogItem("DEV-1", "Development", "hour", decimal.RequireFromString("95.50"), []string{"vat"})
+		if err := store.SaveCatalogItem(item); err != nil {
+			t.Fatal(err)
+		}
+		item.Update("DEV-1", "Senior development", "hour", decimal.NewFromInt(120), nil)
+		if err := store.UpdateCatalogItem(item); err != nil {
+			t.Fatal(err)
+		}
+		got, err := store.GetCatalogItem(item.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sameJSON(t, got, item) {
+			t.Errorf("GetCatalogItem() = %+v, want %+v", got, item)
+		}
+
+		if err := store.DeleteCatalogItem(item.ID); err != nil {
+			t.Fatal(err)
+		}
+		if items, err := store.GetAllCatalogItems(); err != nil || len(items) != 0 {
+			t.Errorf("GetAllCatalogItems() after delete = %v, %v", items, err)
+		}
+	})
+}
+
+func TestStaleUpdateConflicts(t *testing.T) {
+	backends(t, func(t *testing.T, store models.Storage) {
+		invoice := newTestInvoice(t, store, "2026-01")
+		stale := *invoice
+
+		if err := store.UpdateInvoice(invoice); err != nil {
+			t.Fatal(err)
+		}
+		err := store.UpdateInvoice(&stale)
+		var conflict *models.ConflictError
+		if !errors.As(err, &conflict) || !errors.Is(err, models.ErrConflict) {
+			t.Fatalf("UpdateInvoice(stale) error = %v, want a ConflictError", err)
+		}
+		if conflict.Version != 1 || conflict.Current != 2 {
+			t.Errorf("conflict = %+v, want version 1 against 2", conflict)
+		}
+		if stale.Version != 1 {
+			t.Errorf("a failed update moved the version on to %d", stale.Version)
+		}
+
+		client, err := store.GetClient(invoice.ClientID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		staleClient := *client
+		if err := store.UpdateClient(client); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.UpdateClient(&staleClient); !errors.Is(err, models.ErrConflict) {
+			t.Errorf("UpdateClient(stale) error = %v, want a conflict", err)
+		}
+	})
+}
+
+func TestNumbering(t *testing.T) {
+	backends(t, func(t *testing.T, store models.Storage) {
+		seq, err := store.GetNextInvoiceNumber(2026)
+		if err != nil || seq != 1 {
+			t.Fatalf("GetNextInvoiceNumber() = %d, %v, want 1", seq, err)
+		}
+		newTestInvoice(t, store, "2026-01")
+		newTestInvoice(t, store, "2025-07")
+		if seq, _ := store.GetNextInvoiceNumber(2026); seq != 2 {
+			t.Errorf("GetNextInvoiceNumber(2026) = %d, want 2", seq)
+		}
+
+		// Two invoices given the same number, as by two processes at once
+		first := newTestInvoice(t, store, "2026-02")
+		second := newTestInvoice(t, store, "2026-02")
+		if first.Number != "2026-02" || second.Number != "2026-03" {
+			t.Errorf("numbers = %s and %s, want 2026-02 and 2026-03", first.Number, second.Number)
+		}
+		saved, err := store.GetInvoice(second.ID)
+		if err != nil || saved.Number != "2026-03" {
+			t.Errorf("saved number = %v, %v, want 2026-03", saved, err)
+		}
+
+		estimate := models.NewEstimate(first.ClientID, first.ClientName, "EST-2026-01")
+		again := models.NewEstimate(first.ClientID, first.ClientName, "EST-2026-01")
+		for _, e := range []*models.Estimate{estimate, again} {
+			if err := store.SaveEstimate(e); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if again.Number != "EST-2026-02" {
+			t.Errorf("second estimate number = %s, want EST-2026-02", again.Number)
+		}
+	})
+}
+
+func TestSnapshotSQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "invoicer.db")
+	store, err := NewSQLiteStorage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	invoice := newTestInvoice(t, store, "2026-01")
+
+	dest := filepath.Join(t.TempDir(), "snapshot.db")
+	if err := SnapshotSQLite(path, dest); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := NewSQLiteStorage(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer snapshot.Close()
+	got, err := snapshot.GetInvoice(invoice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameJSON(t, got, invoice) {
+		t.Errorf("snapshot has %+v, want %+v", got, invoice)
+	}
+}
@@ -0,0 +1,58 @@
+// Package templates holds the LaTeX and HTML templates shipped with
+// invoicer. They are built into the binary, so that an installed invoicer
+// can set up a data directory without the source tree at hand.
+package templates
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"io/fs"
+)
+
+//go:embed *.tex *.html
+var files embed.FS
+
+// Names lists the bundled templates by file name.
+func Names() []string {
+	entries, _ := fs.ReadDir(files, ".")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+// Read returns the bundled template with the file name name.
+func Read(name string) ([]byte, error) {
+	return files.ReadFile(name)
+}
+
+// earlier are the SHA-256 sums of the released versions of the bundled
+// templates before the current ones, so that copies made from them and never
+// edited can be told apart from edited ones.
+var earlier = map[string][]string{
+	"invoice.tex": {
+		"9eeea21e1e8c49aeace275d93d20fd78b3e999837a86dfe3474872c48bd16391",
+	},
+}
+
+// IsEarlierVersion reports whether content is, byte for byte, an earlier
+// version of the bundled template name.
+func IsEarlierVersion(name string, content []byte) bool {
+	sum := sha256.Sum256(content)
+	for _, earlierSum := range earlier[name] {
+		if hex.EncodeToString(sum[:]) == earlierSum {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOutdated reports whether content formats amounts the way the first
+// templates did, as dollars through InexactFloat64, and so ignores the
+// invoice's currency, taxes, discounts and rounding.
+func IsOutdated(content []byte) bool {
+	return bytes.Contains(content, []byte("InexactFloat64"))
+}
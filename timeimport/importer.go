@@ -0,0 +1,246 @@
+package timeimport
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/user/invoicer/models"
+)
+
+// Action is what to do with time recorded under a client name that is not
+// mapped to a client yet.
+type Action int
+
+const (
+	// MapToClient files the time under an existing client from now on
+	MapToClient Action = iota
+	// CreateClient adds a client with the name and files the time under it
+	CreateClient
+	// IgnoreName leaves out time recorded under the name, now and in later
+	// imports
+	IgnoreName
+	// SkipName leaves out time recorded under the name in this import only
+	SkipName
+)
+
+// Resolution is a decision about an unmapped client name.
+type Resolution struct {
+	Action   Action
+	ClientID string
+}
+
+// Resolver decides what to do with client names that are neither mapped
+// nor the exact name of a client, typically by asking the user.
+type Resolver interface {
+	Resolve(name string, records int, clients []models.Client) (Resolution, error)
+}
+
+type Importer struct {
+	storage  models.Storage
+	resolver Resolver
+}
+
+func NewImporter(storage models.Storage, resolver Resolver) *Importer {
+	return &Importer{storage: storage, resolver: resolver}
+}
+
+// Result describes what an import did.
+type Result struct {
+	Imported   []models.TimeEntry
+	NewClients []models.Client
+	// Duplicates were imported before and left alone
+	Duplicates int
+	// Skipped belong to ignored or skipped client names
+	Skipped int
+}
+
+// Run saves records as time entries, resolving each distinct client name
+// once. Names map to clients through the stored mappings, then through an
+// exact match on the client's name, and otherwise through the resolver;
+// the outcome is saved as a mapping unless the name is only skipped.
+func (im *Importer) Run(records []Record) (*Result, error) {
+	clients, err := im.storage.GetAllClients()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load clients: %w", err)
+	}
+	mappings, err := im.storage.GetAllClientMappings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client mappings: %w", err)
+	}
+	existing, err := im.storage.GetAllTimeEntries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load time entries: %w", err)
+	}
+	seen := map[string]bool{}
+	for _, e := range existing {
+		if e.ExternalID != "" {
+			seen[e.ExternalID] = true
+		}
+	}
+
+	counts := map[string]int{}
+	var names []string
+	for _, r := range records {
+		key := strings.ToLower(r.ClientName())
+		if counts[key] == 0 {
+			names = append(names, r.ClientName())
+		}
+		counts[key]++
+	}
+
+	result := &Result{}
+	resolved := map[string]*models.Client{}
+	for _, name := range names {
+		client, err := im.resolve(name, counts[strings.ToLower(name)], &clients, mappings, result)
+		if err != nil {
+			return result, err
+		}
+		resolved[strings.ToLower(name)] = client
+	}
+
+	for _, r := range records {
+		client := resolved[strings.ToLower(r.ClientName())]
+		if client == nil {
+			result.Skipped++
+			continue
+		}
+		if seen[r.ExternalID] {
+			result.Duplicates++
+			continue
+		}
+		entry := models.NewTimeEntry(client.ID, client.Name, r.Project, r.Description, r.Start, r.End, r.Billable)
+		entry.ExternalID = r.ExternalID
+		if err := im.storage.SaveTimeEntry(entry); err != nil {
+			return result, fmt.Errorf("failed to save time entry: %w", err)
+		}
+		seen[r.ExternalID] = true
+		result.Imported = append(result.Imported, *entry)
+	}
+	return result, nil
+}
+
+// resolve finds the client for name, or returns nil if its time is to be
+// left out.
+func (im *Importer) resolve(name string, records int, clients *[]models.Client, mappings []models.ClientMapping, result *Result) (*models.Client, error) {
+	find := func(id string) *models.Client {
+		for i := range *clients {
+			if (*clients)[i].ID == id {
+				return &(*clients)[i]
+			}
+		}
+		return nil
+	}
+
+	if mapping := models.FindClientMapping(mappings, name); mapping != nil {
+		if mapping.Ignored() {
+			return nil, nil
+		}
+		// A mapping to a client that has since been deleted is asked about again
+		if client := find(mapping.ClientID); client != nil {
+			return client, nil
+		}
+	}
+
+	for i := range *clients {
+		if strings.EqualFold((*clients)[i].Name, name) {
+			return &(*clients)[i], im.storage.SaveClientMapping(models.NewClientMapping(name, (*clients)[i].ID))
+		}
+	}
+
+	resolution, err := im.resolver.Resolve(name, records, *clients)
+	if err != nil {
+		return nil, err
+	}
+	switch resolution.Action {
+	case MapToClient:
+		client := find(resolution.ClientID)
+		if client == nil {
+			return nil, fmt.Errorf("client for %q not found", name)
+		}
+		return client, im.storage.SaveClientMapping(models.NewClientMapping(name, client.ID))
+	case CreateClient:
+		client := models.NewClient(name, "", []string{}, decimal.Zero)
+		if err := im.storage.SaveClient(client); err != nil {
+			return nil, fmt.Errorf("failed to create client %s: %w", name, err)
+		}
+		*clients = append(*clients, *client)
+		result.NewClients = append(result.NewClients, *client)
+		return client, im.storage.SaveClientMapping(models.NewClientMapping(name, client.ID))
+	case IgnoreName:
+		return nil, im.storage.SaveClientMapping(models.NewClientMapping(name, ""))
+	}
+	return nil, nil
+}
+
+// DraftInvoices creates a draft invoice for each client with billable time
+// among entries that has not been billed yet. Each invoice has a line item
+// per project priced at the client's hourly rate and covers the days the
+// time was worked; its entries are marked as billed.
+func (im *Importer) DraftInvoices(entries []models.TimeEntry) ([]models.Invoice, error) {
+	byClient := map[string][]models.TimeEntry{}
+	var clientIDs []string
+	for _, e := range entries {
+		if !e.Billable || e.IsBilled() || e.IsRunning() {
+			continue
+		}
+		if _, ok := byClient[e.ClientID]; !ok {
+			clientIDs = append(clientIDs, e.ClientID)
+		}
+		byClient[e.ClientID] = append(byClient[e.ClientID], e)
+	}
+
+	var invoices []models.Invoice
+	for _, clientID := range clientIDs {
+		invoice, err := im.draftInvoice(clientID, byClient[clientID])
+		if err != nil {
+			return invoices, err
+		}
+		invoices = append(invoices, *invoice)
+	}
+	return invoices, nil
+}
+
+func (im *Importer) draftInvoice(clientID string, entries []models.TimeEntry) (*models.Invoice, error) {
+	client, err := im.storage.GetClient(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client: %w", err)
+	}
+
+	now := time.Now()
+	seq, err := im.storage.GetNextInvoiceNumber(now.Year())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next invoice number: %w", err)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Start.Before(entries[j].Start)
+	})
+	first := entries[0].Start
+	last := entries[len(entries)-1].Start
+	start := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, first.Location())
+	end := time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, last.Location())
+
+	invoice := models.NewInvoice(client.ID, client.Name, models.GenerateInvoiceNumber(now.Year(), seq))
+	invoice.Currency = client.DefaultCurrency.Code()
+	invoice.ServiceStartDate = &start
+	invoice.ServiceEndDate = &end
+	for _, line := range models.BillTime(entries, client.DefaultHourlyRate) {
+		invoice.LineItems = append(invoice.LineItems, line.Item)
+	}
+	invoice.CalculateTotals()
+
+	if err := im.storage.SaveInvoice(invoice); err != nil {
+		return nil, fmt.Errorf("failed to save invoice for %s: %w", client.Name, err)
+	}
+
+	for i := range entries {
+		entries[i].MarkBilled(invoice.ID, invoice.Number)
+		if err := im.storage.UpdateTimeEntry(&entries[i]); err != nil {
+			return invoice, fmt.Errorf("invoice %s created but time not marked as billed: %w", invoice.Number, err)
+		}
+	}
+	return invoice, nil
+}
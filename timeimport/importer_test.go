@@ -0,0 +1,107 @@
+package timeimport
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/user/invoicer/models"
+	"github.com/user/invoicer/storage"
+)
+
+// answers resolves client names from a fixed list, recording what it was
+// asked.
+type answers struct {
+	resolutions map[string]Resolution
+	asked       []string
+}
+
+func (a *answers) Resolve(name string, records int, clients []models.Client) (Resolution, error) {
+	a.asked = append(a.asked, name)
+	return a.resolutions[name], nil
+}
+
+func TestImport(t *testing.T) {
+	store, err := storage.NewJSONStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	acme := models.NewClient("ACME", "", nil, decimal.NewFromInt(90))
+	if err := store.SaveClient(acme); err != nil {
+		t.Fatal(err)
+	}
+	records, err := Parse(FormatTimewarrior, strings.NewReader(timewJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resolver := &answers{resolutions: map[string]Resolution{"Globex": {Action: IgnoreName}}}
+	result, err := NewImporter(store, resolver).Run(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Acme matches a client by name; Globex is asked about
+	if len(resolver.asked) != 1 || resolver.asked[0] != "Globex" {
+		t.Errorf("asked about %v, want Globex only", resolver.asked)
+	}
+	if len(result.Imported) != 1 || result.Skipped != 1 || result.Imported[0].ClientID != acme.ID {
+		t.Fatalf("result = %+v", result)
+	}
+
+	// Importing again asks nothing and duplicates nothing
+	resolver.asked = nil
+	result, err = NewImporter(store, resolver).Run(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resolver.asked) != 0 || len(result.Imported) != 0 || result.Duplicates != 1 || result.Skipped != 1 {
+		t.Errorf("second import asked %v with result %+v", resolver.asked, result)
+	}
+
+	entries, err := store.GetAllTimeEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	invoices, err := NewImporter(store, resolver).DraftInvoices(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(invoices) != 1 || !invoices[0].Total.Equal(decimal.NewFromInt(135)) {
+		t.Fatalf("drafted %+v, want one invoice for 1.5 hours at 90", invoices)
+	}
+	if billed, _ := store.GetTimeEntry(entries[0].ID); billed == nil || billed.InvoiceID != invoices[0].ID {
+		t.Errorf("the entry was not marked as billed: %+v", billed)
+	}
+}
+
+func TestImportCreatesClient(t *testing.T) {
+	store, err := storage.NewJSONStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := Parse(FormatToggl, strings.NewReader(togglCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolver := &answers{resolutions: map[string]Resolution{
+		"Acme":     {Action: CreateClient},
+		"Internal": {Action: SkipName},
+	}}
+	result, err := NewImporter(store, resolver).Run(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.NewClients) != 1 || result.NewClients[0].Name != "Acme" || len(result.Imported) != 1 || result.Skipped != 1 {
+		t.Fatalf("result = %+v", result)
+	}
+
+	// A skipped name is asked about again next time, a created one is not
+	resolver.asked = nil
+	if _, err := NewImporter(store, resolver).Run(records); err != nil {
+		t.Fatal(err)
+	}
+	if len(resolver.asked) != 1 || resolver.asked[0] != "Internal" {
+		t.Errorf("asked about %v, want Internal only", resolver.asked)
+	}
+}
@@ -0,0 +1,262 @@
+package timeimport
+
+import (
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Format is the time tracker an export comes from.
+type Format string
+
+const (
+	FormatToggl       Format = "toggl"
+	FormatClockify    Format = "clockify"
+	FormatTimewarrior Format = "timewarrior"
+)
+
+// ParseFormat reads a format name as given on the command line.
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(name))); f {
+	case FormatToggl, FormatClockify, FormatTimewarrior:
+		return f, nil
+	case "timew":
+		return FormatTimewarrior, nil
+	}
+	return "", fmt.Errorf("unknown time tracker format %q (use toggl, clockify or timewarrior)", name)
+}
+
+// Record is one stretch of time read from an export, before its client has
+// been matched to one of ours.
+type Record struct {
+	Client      string
+	Project     string
+	Description string
+	Start       time.Time
+	End         time.Time
+	Billable    bool
+	// ExternalID is derived from the record's contents, so the same record
+	// gets the same ID every time it is exported
+	ExternalID string
+}
+
+// ClientName is the name the record is filed under for client mapping: its
+// client, or its project if the tracker had no client for it.
+func (r Record) ClientName() string {
+	if name := strings.TrimSpace(r.Client); name != "" {
+		return name
+	}
+	return strings.TrimSpace(r.Project)
+}
+
+func externalID(format Format, r Record) string {
+	sum := sha1.Sum([]byte(strings.Join([]string{
+		r.Client, r.Project, r.Description, r.Start.UTC().Format(time.RFC3339), r.End.UTC().Format(time.RFC3339),
+	}, "\x00")))
+	return string(format) + ":" + hex.EncodeToString(sum[:10])
+}
+
+// DetectFormat guesses the format of an export from its file name and
+// contents: JSON is Timewarrior, and CSV headers tell Clockify from Toggl.
+func DetectFormat(path string, data []byte) (Format, error) {
+	trimmed := strings.TrimSpace(string(data))
+	if strings.EqualFold(filepath.Ext(path), ".json") || strings.HasPrefix(trimmed, "[") {
+		return FormatTimewarrior, nil
+	}
+	header, _, _ := strings.Cut(trimmed, "\n")
+	header = strings.ToLower(header)
+	switch {
+	case strings.Contains(header, "duration (h)") || strings.Contains(header, "duration (decimal)"):
+		return FormatClockify, nil
+	case strings.Contains(header, "start date") && strings.Contains(header, "duration"):
+		return FormatToggl, nil
+	}
+	return "", errors.New("could not tell which time tracker the file comes from; pass the format explicitly")
+}
+
+// Parse reads the time records in an export of the given format. Entries
+// that were still running when exported are left out.
+func Parse(format Format, r io.Reader) ([]Record, error) {
+	var (
+		records []Record
+		err     error
+	)
+	switch format {
+	case FormatToggl, FormatClockify:
+		records, err = parseCSV(r)
+	case FormatTimewarrior:
+		records, err = parseTimewarrior(r)
+	default:
+		return nil, fmt.Errorf("unknown time tracker format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range records {
+		records[i].ExternalID = externalID(format, records[i])
+	}
+	return records, nil
+}
+
+// Toggl and Clockify detailed reports share their columns, only differing
+// in capitalisation and in how dates are written.
+var (
+	dateLayouts = []string{"2006-01-02", "01/02/2006", "02.01.2006", "2006/01/02"}
+	timeLayouts = []string{"15:04:05", "15:04", "03:04:05 PM", "03:04 PM", "3:04:05 PM", "3:04 PM"}
+)
+
+func parseCSV(r io.Reader) ([]Record, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	columns := map[string]int{}
+	for i, name := range rows[0] {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"start date", "start time"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV has no %q column", required)
+		}
+	}
+	field := func(row []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	var records []Record
+	for n, row := range rows[1:] {
+		line := n + 2
+		start, err := parseDateTime(field(row, "start date"), field(row, "start time"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid start: %w", line, err)
+		}
+
+		var end time.Time
+		if field(row, "end date") != "" {
+			if end, err = parseDateTime(field(row, "end date"), field(row, "end time")); err != nil {
+				return nil, fmt.Errorf("line %d: invalid end: %w", line, err)
+			}
+		} else {
+			d, err := parseClockDuration(field(row, "duration"))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid duration: %w", line, err)
+			}
+			end = start.Add(d)
+		}
+
+		billable := true
+		if value := strings.ToLower(field(row, "billable")); value != "" {
+			billable = value == "yes" || value == "true" || value == "1"
+		}
+
+		records = append(records, Record{
+			Client:      field(row, "client"),
+			Project:     field(row, "project"),
+			Description: field(row, "description"),
+			Start:       start,
+			End:         end,
+			Billable:    billable,
+		})
+	}
+	return records, nil
+}
+
+func parseDateTime(date, clock string) (time.Time, error) {
+	for _, dl := range dateLayouts {
+		for _, tl := range timeLayouts {
+			if t, err := time.ParseInLocation(dl+" "+tl, date+" "+clock, time.Local); err == nil {
+				return t, nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised date and time %q", date+" "+clock)
+}
+
+// parseClockDuration reads a duration written as H:MM:SS.
+func parseClockDuration(value string) (time.Duration, error) {
+	var h, m, s int
+	if _, err := fmt.Sscanf(value, "%d:%d:%d", &h, &m, &s); err != nil {
+		return 0, fmt.Errorf("unrecognised duration %q", value)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(s)*time.Second, nil
+}
+
+// timewInterval is one interval of `timew export`.
+type timewInterval struct {
+	Start      string   `json:"start"`
+	End        string   `json:"end"`
+	Tags       []string `json:"tags"`
+	Annotation string   `json:"annotation"`
+}
+
+const timewLayout = "20060102T150405Z"
+
+// parseTimewarrior reads the output of `timew export`. Timewarrior has no
+// clients or projects, only tags: "client:Name" and "project:Name" tags are
+// used if present, otherwise the first tag is the client and the second the
+// project. A "nonbillable" tag marks time as not billable.
+func parseTimewarrior(r io.Reader) ([]Record, error) {
+	var intervals []timewInterval
+	if err := json.NewDecoder(r).Decode(&intervals); err != nil {
+		return nil, fmt.Errorf("failed to read Timewarrior export: %w", err)
+	}
+
+	var records []Record
+	for i, interval := range intervals {
+		if interval.End == "" {
+			continue
+		}
+		start, err := time.Parse(timewLayout, interval.Start)
+		if err != nil {
+			return nil, fmt.Errorf("interval %d: invalid start: %w", i+1, err)
+		}
+		end, err := time.Parse(timewLayout, interval.End)
+		if err != nil {
+			return nil, fmt.Errorf("interval %d: invalid end: %w", i+1, err)
+		}
+
+		record := Record{
+			Description: interval.Annotation,
+			Start:       start.Local(),
+			End:         end.Local(),
+			Billable:    true,
+		}
+		var plain []string
+		for _, tag := range interval.Tags {
+			switch lower := strings.ToLower(tag); {
+			case lower == "nonbillable" || lower == "non-billable":
+				record.Billable = false
+			case strings.HasPrefix(lower, "client:"):
+				record.Client = tag[len("client:"):]
+			case strings.HasPrefix(lower, "project:"):
+				record.Project = tag[len("project:"):]
+			default:
+				plain = append(plain, tag)
+			}
+		}
+		if record.Client == "" && len(plain) > 0 {
+			record.Client, plain = plain[0], plain[1:]
+		}
+		if record.Project == "" && len(plain) > 0 {
+			record.Project = plain[0]
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
@@ -0,0 +1,144 @@
+package timeimport
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Toggl writes a byte order mark
+const togglCSV = "\ufeffUser,Email,Client,Project,Task,Description,Billable,Start date,Start time,End date,End time,Duration,Tags,Amount (USD)\n" +
+	"Ann,ann@example.com,Acme,Website,,Layout,Yes,2026-03-02,09:00:00,2026-03-02,10:30:00,01:30:00,,135.00\n" +
+	"Ann,ann@example.com,,Internal,,\"Planning, weekly\",No,2026-03-02,14:00:00,,,00:45:00,,0.00\n"
+
+const clockifyCSV = "Project,Client,Description,Task,User,Group,Email,Tags,Billable,Start Date,Start Time,End Date,End Time,Duration (h),Duration (decimal),Billable Rate (USD),Billable Amount (USD)\n" +
+	"Website,Acme,Fonts,,Ann,,ann@example.com,,Yes,03/02/2026,01:15:00 PM,03/02/2026,02:00:00 PM,00:45:00,0.75,90.00,67.50\n"
+
+const timewJSON = `[
+{"id":3,"start":"20260302T080000Z","end":"20260302T093000Z","tags":["Acme","Website"],"annotation":"Layout"},
+{"id":2,"start":"20260302T100000Z","end":"20260302T110000Z","tags":["project:Support","nonbillable","client:Globex"]},
+{"id":1,"start":"20260302T120000Z","tags":["Acme"]}
+]`
+
+func local(hour, min int) time.Time {
+	return time.Date(2026, 3, 2, hour, min, 0, 0, time.Local)
+}
+
+func TestParseToggl(t *testing.T) {
+	records, err := Parse(FormatToggl, strings.NewReader(togglCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("%d records, want 2", len(records))
+	}
+	layout := records[0]
+	if layout.Client != "Acme" || layout.Project != "Website" || layout.Description != "Layout" || !layout.Billable {
+		t.Errorf("first record = %+v", layout)
+	}
+	if !layout.Start.Equal(local(9, 0)) || !layout.End.Equal(local(10, 30)) {
+		t.Errorf("first record runs %s to %s", layout.Start, layout.End)
+	}
+	// Without an end, the duration counts
+	planning := records[1]
+	if planning.Billable || !planning.End.Equal(local(14, 45)) || planning.Description != "Planning, weekly" {
+		t.Errorf("second record = %+v", planning)
+	}
+	if planning.ClientName() != "Internal" {
+		t.Errorf("ClientName() = %q, want the project", planning.ClientName())
+	}
+}
+
+func TestParseClockify(t *testing.T) {
+	records, err := Parse(FormatClockify, strings.NewReader(clockifyCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("%d records, want 1", len(records))
+	}
+	fonts := records[0]
+	if fonts.Client != "Acme" || fonts.Description != "Fonts" || !fonts.Start.Equal(local(13, 15)) || !fonts.End.Equal(local(14, 0)) {
+		t.Errorf("record = %+v", fonts)
+	}
+}
+
+func TestParseTimewarrior(t *testing.T) {
+	records, err := Parse(FormatTimewarrior, strings.NewReader(timewJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The running interval is left out
+	if len(records) != 2 {
+		t.Fatalf("%d records, want 2", len(records))
+	}
+	if r := records[0]; r.Client != "Acme" || r.Project != "Website" || r.Description != "Layout" || !r.Billable {
+		t.Errorf("plain tags = %+v", r)
+	}
+	if r := records[0]; !r.Start.Equal(time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)) || r.End.Sub(r.Start) != 90*time.Minute {
+		t.Errorf("first interval runs %s to %s", r.Start, r.End)
+	}
+	if r := records[1]; r.Client != "Globex" || r.Project != "Support" || r.Billable {
+		t.Errorf("prefixed tags = %+v", r)
+	}
+}
+
+func TestExternalIDsAreStable(t *testing.T) {
+	first, err := Parse(FormatToggl, strings.NewReader(togglCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := Parse(FormatToggl, strings.NewReader(togglCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first[0].ExternalID != again[0].ExternalID || first[0].ExternalID == first[1].ExternalID {
+		t.Errorf("external IDs %s, %s and %s", first[0].ExternalID, again[0].ExternalID, first[1].ExternalID)
+	}
+	if !strings.HasPrefix(first[0].ExternalID, "toggl:") {
+		t.Errorf("external ID %s does not name the tracker", first[0].ExternalID)
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		path string
+		data string
+		want Format
+	}{
+		{"export.csv", togglCSV, FormatToggl},
+		{"export.csv", clockifyCSV, FormatClockify},
+		{"timew.txt", timewJSON, FormatTimewarrior},
+		{"timew.json", "", FormatTimewarrior},
+	}
+	for _, tt := range tests {
+		if got, err := DetectFormat(tt.path, []byte(tt.data)); err != nil || got != tt.want {
+			t.Errorf("DetectFormat(%s) = %s, %v, want %s", tt.path, got, err, tt.want)
+		}
+	}
+	if _, err := DetectFormat("hours.csv", []byte("Date,Hours\n")); err == nil {
+		t.Error("DetectFormat() guessed the format of an unknown CSV")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		format Format
+		data   string
+		want   string
+	}{
+		{FormatToggl, "Client,Duration\nAcme,1:00:00\n", `no "start date" column`},
+		{FormatToggl, "Start date,Start time,Duration\n2026-03-02,9am,1:00:00\n", "line 2: invalid start"},
+		{FormatClockify, "Start Date,Start Time,Duration\n2026-03-02,09:00,an hour\n", "line 2: invalid duration"},
+		{FormatTimewarrior, `[{"start":"yesterday","end":"20260302T093000Z"}]`, "interval 1: invalid start"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.format, strings.NewReader(tt.data))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%s, %q) error = %v, want %q", tt.format, tt.data, err, tt.want)
+		}
+	}
+	if f, err := ParseFormat(" Timew "); err != nil || f != FormatTimewarrior {
+		t.Errorf("ParseFormat(timew) = %s, %v", f, err)
+	}
+}
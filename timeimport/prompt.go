@@ -0,0 +1,71 @@
+package timeimport
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/user/invoicer/models"
+)
+
+// PromptResolver asks on the terminal what to do with each unknown client
+// name.
+type PromptResolver struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func NewPromptResolver(in io.Reader, out io.Writer) *PromptResolver {
+	return &PromptResolver{in: bufio.NewReader(in), out: out}
+}
+
+func (p *PromptResolver) Resolve(name string, records int, clients []models.Client) (Resolution, error) {
+	label := fmt.Sprintf("%q", name)
+	if name == "" {
+		label = "(no client or project)"
+	}
+	noun := "entries"
+	if records == 1 {
+		noun = "entry"
+	}
+	fmt.Fprintf(p.out, "\nUnknown client %s (%d time %s)\n", label, records, noun)
+	for i, client := range clients {
+		fmt.Fprintf(p.out, "  %d) %s\n", i+1, client.Name)
+	}
+	if name != "" {
+		fmt.Fprintf(p.out, "  n) Create a new client named %q\n", name)
+	}
+	fmt.Fprintln(p.out, "  s) Skip this time for now")
+	fmt.Fprintln(p.out, "  i) Always ignore this name")
+
+	for {
+		fmt.Fprint(p.out, "Choice: ")
+		line, err := p.in.ReadString('\n')
+		choice := strings.ToLower(strings.TrimSpace(line))
+		if err != nil && choice == "" {
+			if errors.Is(err, io.EOF) {
+				return Resolution{}, errors.New("import cancelled")
+			}
+			return Resolution{}, err
+		}
+
+		switch choice {
+		case "n":
+			if name != "" {
+				return Resolution{Action: CreateClient}, nil
+			}
+		case "s":
+			return Resolution{Action: SkipName}, nil
+		case "i":
+			return Resolution{Action: IgnoreName}, nil
+		default:
+			if n, err := strconv.Atoi(choice); err == nil && n >= 1 && n <= len(clients) {
+				return Resolution{Action: MapToClient, ClientID: clients[n-1].ID}, nil
+			}
+		}
+		fmt.Fprintln(p.out, "Please enter one of the choices above.")
+	}
+}
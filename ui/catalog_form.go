@@ -0,0 +1,228 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/shopspring/decimal"
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/models"
+)
+
+const (
+	catalogFocusSKU = iota
+	catalogFocusDescription
+	catalogFocusUnit
+	catalogFocusPrice
+	catalogFocusTaxes
+	catalogFocusSave
+	catalogFocusCount
+)
+
+type CatalogFormModel struct {
+	inputs     []textinput.Model
+	focusIndex int
+	taxes      []models.Tax
+	storage    models.Storage
+	config     *config.Config
+	item       *models.CatalogItem
+	isEdit     bool
+	err        error
+}
+
+func NewCatalogFormModel(storage models.Storage, cfg *config.Config, item *models.CatalogItem) CatalogFormModel {
+	taxes, err := storage.GetAllTaxes()
+
+	inputs := make([]textinput.Model, catalogFocusSave)
+
+	inputs[catalogFocusSKU] = textinput.New()
+	inputs[catalogFocusSKU].Placeholder = "DEV-01 (optional)"
+	inputs[catalogFocusSKU].Width = 20
+	inputs[catalogFocusSKU].Focus()
+
+	inputs[catalogFocusDescription] = textinput.New()
+	inputs[catalogFocusDescription].Placeholder = "Development"
+	inputs[catalogFocusDescription].Width = 50
+
+	inputs[catalogFocusUnit] = textinput.New()
+	inputs[catalogFocusUnit].Placeholder = "hour, day, item..."
+	inputs[catalogFocusUnit].Width = 15
+
+	inputs[catalogFocusPrice] = textinput.New()
+	inputs[catalogFocusPrice].Placeholder = "0 = client's hourly rate"
+	inputs[catalogFocusPrice].Width = 25
+
+	inputs[catalogFocusTaxes] = textinput.New()
+	inputs[catalogFocusTaxes].Placeholder = lineTaxesPlaceholder(taxes)
+	inputs[catalogFocusTaxes].Width = 30
+
+	for i := catalogFocusDescription; i < catalogFocusSave; i++ {
+		inputs[i].PromptStyle = dimStyle
+		inputs[i].TextStyle = dimStyle
+	}
+
+	isEdit := item != nil
+	if isEdit {
+		inputs[catalogFocusSKU].SetValue(item.SKU)
+		inputs[catalogFocusDescription].SetValue(item.Description)
+		inputs[catalogFocusUnit].SetValue(item.Unit)
+		inputs[catalogFocusPrice].SetValue(item.UnitPrice.String())
+		inputs[catalogFocusTaxes].SetValue(models.TaxNames(item.Taxes(taxes)))
+	}
+
+	return CatalogFormModel{
+		inputs:  inputs,
+		taxes:   taxes,
+		storage: storage,
+		config:  cfg,
+		item:    item,
+		isEdit:  isEdit,
+		err:     err,
+	}
+}
+
+func (m CatalogFormModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m CatalogFormModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			return NewCatalogListModel(m.storage, m.config), func() tea.Msg { return BackToCatalogListMsg{} }
+		case "tab", "shift+tab", "enter", "up", "down":
+			s := msg.String()
+
+			if s == "enter" && m.focusIndex == catalogFocusSave {
+				if err := m.saveItem(); err != nil {
+					m.err = err
+					return m, nil
+				}
+				return NewCatalogListModel(m.storage, m.config), func() tea.Msg { return BackToCatalogListMsg{} }
+			}
+
+			if s == "up" || s == "shift+tab" {
+				m.focusIndex--
+			} else {
+				m.focusIndex++
+			}
+
+			if m.focusIndex >= catalogFocusCount {
+				m.focusIndex = 0
+			} else if m.focusIndex < 0 {
+				m.focusIndex = catalogFocusCount - 1
+			}
+
+			return m.updateFocus()
+		}
+	}
+
+	var cmd tea.Cmd
+	if m.focusIndex < len(m.inputs) {
+		m.inputs[m.focusIndex], cmd = m.inputs[m.focusIndex].Update(msg)
+	}
+	return m, cmd
+}
+
+func (m CatalogFormModel) updateFocus() (CatalogFormModel, tea.Cmd) {
+	cmds := []tea.Cmd{}
+	for i := range m.inputs {
+		if i == m.focusIndex {
+			cmds = append(cmds, m.inputs[i].Focus())
+			m.inputs[i].PromptStyle = formInputStyle
+			m.inputs[i].TextStyle = formInputStyle
+		} else {
+			m.inputs[i].Blur()
+			m.inputs[i].PromptStyle = dimStyle
+			m.inputs[i].TextStyle = dimStyle
+		}
+	}
+	return m, tea.Batch(cmds...)
+}
+
+func (m *CatalogFormModel) saveItem() error {
+	sku := strings.TrimSpace(m.inputs[catalogFocusSKU].Value())
+	description := strings.TrimSpace(m.inputs[catalogFocusDescription].Value())
+	unit := strings.TrimSpace(m.inputs[catalogFocusUnit].Value())
+
+	price := decimal.Zero
+	if value := strings.TrimSpace(m.inputs[catalogFocusPrice].Value()); value != "" {
+		var err error
+		price, err = decimal.NewFromString(value)
+		if err != nil {
+			return fmt.Errorf("invalid unit price: %v", err)
+		}
+	}
+
+	taxes, err := parseLineTaxes(m.inputs[catalogFocusTaxes].Value(), m.taxes)
+	if err != nil {
+		return err
+	}
+	var taxIDs []string
+	for _, t := range taxes {
+		taxIDs = append(taxIDs, t.ID)
+	}
+
+	if sku != "" {
+		items, err := m.storage.GetAllCatalogItems()
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if strings.EqualFold(item.SKU, sku) && (!m.isEdit || item.ID != m.item.ID) {
+				return fmt.Errorf("SKU %q is already used by %q", item.SKU, item.Description)
+			}
+		}
+	}
+
+	if m.isEdit {
+		updated := *m.item
+		updated.Update(sku, description, unit, price, taxIDs)
+		if err := updated.Validate(); err != nil {
+			return err
+		}
+		return m.storage.UpdateCatalogItem(&updated)
+	}
+
+	item := models.NewCatalogItem(sku, description, unit, price, taxIDs)
+	if err := item.Validate(); err != nil {
+		return err
+	}
+	return m.storage.SaveCatalogItem(item)
+}
+
+func (m CatalogFormModel) View() string {
+	var s strings.Builder
+
+	title := "New Catalog Item"
+	if m.isEdit {
+		title = "Edit Catalog Item"
+	}
+	s.WriteString(titleStyle.Render(title) + "\n\n")
+
+	if m.err != nil {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)) + "\n\n")
+	}
+
+	labels := []string{"SKU:", "Description:", "Unit:", "Unit Price:", "Taxes:"}
+	for i, label := range labels {
+		s.WriteString(formLabelStyle.Render(label))
+		s.WriteString(m.inputs[i].View() + "\n")
+	}
+	s.WriteString(dimStyle.Render("Hourly items without a price are billed at the client's hourly rate.") + "\n\n")
+
+	saveButton := "[ Save ]"
+	if m.focusIndex == catalogFocusSave {
+		saveButton = selectedStyle.Render(saveButton)
+	}
+	s.WriteString(saveButton)
+
+	s.WriteString("\n\n" + helpStyle.Render("tab/shift+tab navigate • enter select • esc cancel"))
+
+	return appStyle.Render(s.String())
+}
@@ -0,0 +1,175 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/models"
+)
+
+type catalogListMode int
+
+const (
+	catalogListModeView catalogListMode = iota
+	catalogListModeConfirmDelete
+)
+
+// CatalogListModel manages the products and services offered in the line
+// item description autocomplete. Lines copy what they use from an item, so
+// editing or deleting one here does not change existing documents.
+type CatalogListModel struct {
+	items   []models.CatalogItem
+	taxes   []models.Tax
+	cursor  int
+	storage models.Storage
+	config  *config.Config
+	mode    catalogListMode
+	err     error
+}
+
+func NewCatalogListModel(storage models.Storage, cfg *config.Config) CatalogListModel {
+	m := CatalogListModel{
+		storage: storage,
+		config:  cfg,
+		mode:    catalogListModeView,
+	}
+	m.loadItems()
+	return m
+}
+
+func (m *CatalogListModel) loadItems() {
+	items, err := m.storage.GetAllCatalogItems()
+	if err != nil {
+		m.err = err
+		return
+	}
+	taxes, err := m.storage.GetAllTaxes()
+	if err != nil {
+		m.err = err
+		return
+	}
+	m.items = items
+	m.taxes = taxes
+	m.err = nil
+}
+
+func (m CatalogListModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m CatalogListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch m.mode {
+		case catalogListModeView:
+			switch msg.String() {
+			case "ctrl+c", "q":
+				return m, tea.Quit
+			case "esc":
+				return NewMainMenuModel(m.storage, m.config), nil
+			case "up", "k":
+				if m.cursor > 0 {
+					m.cursor--
+				}
+			case "down", "j":
+				if m.cursor < len(m.items)-1 {
+					m.cursor++
+				}
+			case "a":
+				return NewCatalogFormModel(m.storage, m.config, nil), nil
+			case "e", "enter":
+				if len(m.items) > 0 {
+					return NewCatalogFormModel(m.storage, m.config, &m.items[m.cursor]), nil
+				}
+			case "d":
+				if len(m.items) > 0 {
+					m.mode = catalogListModeConfirmDelete
+				}
+			}
+		case catalogListModeConfirmDelete:
+			switch msg.String() {
+			case "y":
+				if err := m.storage.DeleteCatalogItem(m.items[m.cursor].ID); err != nil {
+					m.err = err
+				} else {
+					m.loadItems()
+				}
+				m.mode = catalogListModeView
+				if m.cursor >= len(m.items) && m.cursor > 0 {
+					m.cursor = len(m.items) - 1
+				}
+			case "n", "esc":
+				m.mode = catalogListModeView
+			}
+		}
+	case BackToCatalogListMsg:
+		m.loadItems()
+	}
+	return m, nil
+}
+
+func (m CatalogListModel) View() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("Catalog") + "\n\n")
+
+	if m.err != nil {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)) + "\n\n")
+	}
+
+	if m.mode == catalogListModeConfirmDelete {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Delete '%s' from the catalog? (y/n)", m.items[m.cursor].Description)) + "\n")
+		return appStyle.Render(s.String())
+	}
+
+	if len(m.items) == 0 {
+		s.WriteString(dimStyle.Render("The catalog is empty. Press 'a' to add a product or service.") + "\n")
+	} else {
+		headers := []string{"SKU", "Description", "Unit", "Price", "Taxes"}
+		widths := []int{12, 30, 8, 12, 20}
+
+		headerRow := ""
+		for i, h := range headers {
+			headerRow += tableCellStyle.Width(widths[i]).Render(h)
+		}
+		s.WriteString(tableHeaderStyle.Render(headerRow) + "\n")
+
+		for i, item := range m.items {
+			price := item.UnitPrice.StringFixed(2)
+			if item.UnitPrice.IsZero() && item.IsHourly() {
+				price = "client rate"
+			}
+			cells := []string{
+				truncate(item.SKU, widths[0]-2),
+				truncate(item.Description, widths[1]-2),
+				truncate(item.Unit, widths[2]-2),
+				price,
+				truncate(models.TaxNames(item.Taxes(m.taxes)), widths[4]-2),
+			}
+
+			row := ""
+			for j, cell := range cells {
+				style := tableCellStyle.Width(widths[j])
+				if i == m.cursor {
+					style = style.Inherit(selectedStyle)
+				}
+				row += style.Render(cell)
+			}
+
+			if i == m.cursor {
+				s.WriteString("> " + row + "\n")
+			} else {
+				s.WriteString("  " + row + "\n")
+			}
+		}
+	}
+
+	s.WriteString("\n" + dimStyle.Render("Start typing a SKU or description on a line item to pick from the catalog.") + "\n")
+	s.WriteString("\n" + helpStyle.Render("a add • e edit • d delete • ↑/k up • ↓/j down • esc back • q quit"))
+
+	return appStyle.Render(s.String())
+}
+
+type BackToCatalogListMsg struct{}
@@ -8,6 +8,7 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/shopspring/decimal"
 	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/export"
 	"github.com/user/invoicer/models"
 )
 
@@ -16,16 +17,36 @@ type clientFormMode int
 const (
 	clientFormModeEdit clientFormMode = iota
 	clientFormModeManageEmails
+	clientFormModeEInvoicing
 )
 
+// eInvoicingFields are the client details only needed for e-invoices, in
+// the order of ClientFormModel.eInvoicingInputs.
+var eInvoicingFields = []struct {
+	label, placeholder string
+	width              int
+}{
+	{"VAT ID:", "DE123456789", 20},
+	{"Street:", "Hauptstraße 1", 40},
+	{"City:", "Berlin", 30},
+	{"Postal Code:", "10115", 12},
+	{"Country:", "DE", 4},
+	{"Peppol ID:", "0088:5790000435968", 40},
+	{"Reference:", "Buyer reference, e.g. PO number or Leitweg-ID", 46},
+}
+
 type ClientFormModel struct {
 	nameInput       textinput.Model
 	addressInput    textinput.Model
 	hourlyRateInput textinput.Model
+	currencyInput   textinput.Model
+	templateInput   textinput.Model
 	emailInputs     []textinput.Model
 	emails          []string
 	focusIndex      int
 	emailFocusIndex int
+	eInvoicingInputs     []textinput.Model
+	eInvoicingFocusIndex int
 	storage         models.Storage
 	config          *config.Config
 	client          *models.Client
@@ -47,14 +68,35 @@ func NewClientFormModel(storage models.Storage, cfg *config.Config, client *mode
 	hourlyRateInput.Placeholder = "150.00"
 	hourlyRateInput.Width = 15
 	
+	currencyInput := textinput.New()
+	currencyInput.Placeholder = string(models.DefaultCurrency)
+	currencyInput.Width = 10
+	
+	// Empty leaves the template to the config
+	templateInput := textinput.New()
+	templateInput.Placeholder = export.InvoiceTemplateName(cfg, nil, "")
+	templateInput.Width = 20
+	
 	emails := []string{""}
 	emailInputs := []textinput.Model{createEmailInput()}
 	
+	eInvoicingInputs := make([]textinput.Model, len(eInvoicingFields))
+	for i, field := range eInvoicingFields {
+		eInvoicingInputs[i] = textinput.New()
+		eInvoicingInputs[i].Placeholder = field.placeholder
+		eInvoicingInputs[i].Width = field.width
+	}
+	
 	isEdit := client != nil
 	if isEdit {
 		nameInput.SetValue(client.Name)
 		addressInput.SetValue(client.Address)
 		hourlyRateInput.SetValue(client.DefaultHourlyRate.String())
+		currencyInput.SetValue(string(client.DefaultCurrency.Code()))
+		templateInput.SetValue(client.InvoiceTemplate)
+		for i, value := range []string{client.VATID, client.Street, client.City, client.PostalCode, client.Country, client.PeppolID, client.BuyerReference} {
+			eInvoicingInputs[i].SetValue(value)
+		}
 		if len(client.Emails) > 0 {
 			emails = client.Emails
 			emailInputs = make([]textinput.Model, len(emails))
@@ -69,8 +111,11 @@ func NewClientFormModel(storage models.Storage, cfg *config.Config, client *mode
 		nameInput:       nameInput,
 		addressInput:    addressInput,
 		hourlyRateInput: hourlyRateInput,
+		currencyInput:   currencyInput,
+		templateInput:   templateInput,
 		emailInputs:     emailInputs,
 		emails:          emails,
+		eInvoicingInputs: eInvoicingInputs,
 		storage:         storage,
 		config:          cfg,
 		client:          client,
@@ -94,6 +139,8 @@ func (m ClientFormModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch m.mode {
 	case clientFormModeManageEmails:
 		return m.updateManageEmails(msg)
+	case clientFormModeEInvoicing:
+		return m.updateEInvoicing(msg)
 	default:
 		return m.updateEditMode(msg)
 	}
@@ -110,16 +157,22 @@ func (m ClientFormModel) updateEditMode(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "tab", "shift+tab", "enter", "up", "down":
 			s := msg.String()
 			
-			// Total fields: name, address, hourly rate, manage emails button, save button
-			totalFields := 5
+			// Total fields: name, address, hourly rate, currency, template, manage emails button, e-invoicing button, save button
+			totalFields := 8
 			
-			if s == "enter" && m.focusIndex == 3 {
+			if s == "enter" && m.focusIndex == 5 {
 				// Enter manage emails mode
 				m.mode = clientFormModeManageEmails
 				m.emailFocusIndex = 0
 				return m.updateEmailFocus()
 			}
 			
+			if s == "enter" && m.focusIndex == 6 {
+				m.mode = clientFormModeEInvoicing
+				m.eInvoicingFocusIndex = 0
+				return m.updateEInvoicingFocus()
+			}
+			
 			if s == "enter" && m.focusIndex == totalFields-1 {
 				if err := m.saveClient(); err != nil {
 					m.err = err
@@ -202,6 +255,47 @@ func (m ClientFormModel) updateManageEmails(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+func (m ClientFormModel) updateEInvoicing(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc", "enter":
+			m.mode = clientFormModeEdit
+			return m.updateMainFocus()
+		case "tab", "down":
+			m.eInvoicingFocusIndex = (m.eInvoicingFocusIndex + 1) % len(m.eInvoicingInputs)
+			return m.updateEInvoicingFocus()
+		case "shift+tab", "up":
+			m.eInvoicingFocusIndex = (m.eInvoicingFocusIndex + len(m.eInvoicingInputs) - 1) % len(m.eInvoicingInputs)
+			return m.updateEInvoicingFocus()
+		}
+	}
+	
+	var cmd tea.Cmd
+	m.eInvoicingInputs[m.eInvoicingFocusIndex], cmd = m.eInvoicingInputs[m.eInvoicingFocusIndex].Update(msg)
+	return m, cmd
+}
+
+func (m *ClientFormModel) updateEInvoicingFocus() (ClientFormModel, tea.Cmd) {
+	cmds := []tea.Cmd{}
+	
+	for i := range m.eInvoicingInputs {
+		if i == m.eInvoicingFocusIndex {
+			cmds = append(cmds, m.eInvoicingInputs[i].Focus())
+			m.eInvoicingInputs[i].PromptStyle = formInputStyle
+			m.eInvoicingInputs[i].TextStyle = formInputStyle
+		} else {
+			m.eInvoicingInputs[i].Blur()
+			m.eInvoicingInputs[i].PromptStyle = dimStyle
+			m.eInvoicingInputs[i].TextStyle = dimStyle
+		}
+	}
+	
+	return *m, tea.Batch(cmds...)
+}
+
 func (m *ClientFormModel) updateMainFocus() (ClientFormModel, tea.Cmd) {
 	cmds := []tea.Cmd{}
 	
@@ -241,6 +335,30 @@ func (m *ClientFormModel) updateMainFocus() (ClientFormModel, tea.Cmd) {
 		m.hourlyRateInput.TextStyle = dimStyle
 	}
 	
+	// Update currency input
+	if m.focusIndex == 3 {
+		cmd := m.currencyInput.Focus()
+		m.currencyInput.PromptStyle = formInputStyle
+		m.currencyInput.TextStyle = formInputStyle
+		cmds = append(cmds, cmd)
+	} else {
+		m.currencyInput.Blur()
+		m.currencyInput.PromptStyle = dimStyle
+		m.currencyInput.TextStyle = dimStyle
+	}
+	
+	// Update template input
+	if m.focusIndex == 4 {
+		cmd := m.templateInput.Focus()
+		m.templateInput.PromptStyle = formInputStyle
+		m.templateInput.TextStyle = formInputStyle
+		cmds = append(cmds, cmd)
+	} else {
+		m.templateInput.Blur()
+		m.templateInput.PromptStyle = dimStyle
+		m.templateInput.TextStyle = dimStyle
+	}
+	
 	return *m, tea.Batch(cmds...)
 }
 
@@ -278,6 +396,14 @@ func (m *ClientFormModel) updateMainInputs(msg tea.Msg) tea.Cmd {
 		newInput, cmd := m.hourlyRateInput.Update(msg)
 		m.hourlyRateInput = newInput
 		cmds = append(cmds, cmd)
+	} else if m.focusIndex == 3 {
+		newInput, cmd := m.currencyInput.Update(msg)
+		m.currencyInput = newInput
+		cmds = append(cmds, cmd)
+	} else if m.focusIndex == 4 {
+		newInput, cmd := m.templateInput.Update(msg)
+		m.templateInput = newInput
+		cmds = append(cmds, cmd)
 	}
 	
 	return tea.Batch(cmds...)
@@ -317,12 +443,40 @@ func (m *ClientFormModel) saveClient() error {
 		hourlyRate = rate
 	}
 	
+	currency, err := models.ParseCurrency(m.currencyInput.Value())
+	if err != nil {
+		return err
+	}
+	
+	invoiceTemplate, err := export.ParseInvoiceTemplateName(m.templateInput.Value())
+	if err != nil {
+		return err
+	}
+	if invoiceTemplate != "" {
+		if _, err := export.InvoiceTemplatePath(m.config, invoiceTemplate); err != nil {
+			return err
+		}
+	}
+	
+	client := m.client
 	if m.isEdit {
-		m.client.Update(name, address, validEmails, hourlyRate)
-		return m.storage.UpdateClient(m.client)
+		client.Update(name, address, validEmails, hourlyRate)
+	} else {
+		client = models.NewClient(name, address, validEmails, hourlyRate)
 	}
+	client.DefaultCurrency = currency
+	client.InvoiceTemplate = invoiceTemplate
 	
-	client := models.NewClient(name, address, validEmails, hourlyRate)
+	values := make([]string, len(m.eInvoicingInputs))
+	for i, input := range m.eInvoicingInputs {
+		values[i] = strings.TrimSpace(input.Value())
+	}
+	client.VATID, client.Street, client.City, client.PostalCode = values[0], values[1], values[2], values[3]
+	client.Country, client.PeppolID, client.BuyerReference = strings.ToUpper(values[4]), values[5], values[6]
+	
+	if m.isEdit {
+		return m.storage.UpdateClient(client)
+	}
 	return m.storage.SaveClient(client)
 }
 
@@ -330,6 +484,9 @@ func (m ClientFormModel) View() string {
 	if m.mode == clientFormModeManageEmails {
 		return m.viewManageEmails()
 	}
+	if m.mode == clientFormModeEInvoicing {
+		return m.viewEInvoicing()
+	}
 	
 	return m.viewEditMode()
 }
@@ -359,6 +516,14 @@ func (m ClientFormModel) viewEditMode() string {
 	s.WriteString(formLabelStyle.Render("Hourly Rate:"))
 	s.WriteString(m.hourlyRateInput.View() + "\n")
 	
+	// Default currency field
+	s.WriteString(formLabelStyle.Render("Currency:"))
+	s.WriteString(m.currencyInput.View() + "\n")
+	
+	// Invoice template field
+	s.WriteString(formLabelStyle.Render("Template:"))
+	s.WriteString(m.templateInput.View() + "\n")
+	
 	// Emails summary with manage button
 	emailCount := 0
 	for _, input := range m.emailInputs {
@@ -368,14 +533,25 @@ func (m ClientFormModel) viewEditMode() string {
 	}
 	emailsText := fmt.Sprintf("%d email(s)", emailCount)
 	manageButton := "[ Manage Emails ]"
-	if m.focusIndex == 3 {
+	if m.focusIndex == 5 {
 		manageButton = selectedStyle.Render(manageButton)
 	}
-	s.WriteString(formLabelStyle.Render("Emails:") + emailsText + " " + manageButton + "\n\n")
+	s.WriteString(formLabelStyle.Render("Emails:") + emailsText + " " + manageButton + "\n")
+	
+	// E-invoicing details summary with edit button
+	eInvoicingText := "not set"
+	if vatID := strings.TrimSpace(m.eInvoicingInputs[0].Value()); vatID != "" {
+		eInvoicingText = "VAT ID " + vatID
+	}
+	eInvoicingButton := "[ E-invoicing ]"
+	if m.focusIndex == 6 {
+		eInvoicingButton = selectedStyle.Render(eInvoicingButton)
+	}
+	s.WriteString(formLabelStyle.Render("E-invoicing:") + eInvoicingText + " " + eInvoicingButton + "\n\n")
 	
 	// Save button
 	saveButton := "[ Save ]"
-	if m.focusIndex == 4 {
+	if m.focusIndex == 7 {
 		saveButton = selectedStyle.Render(saveButton)
 	}
 	s.WriteString(saveButton)
@@ -406,4 +582,20 @@ func (m ClientFormModel) viewManageEmails() string {
 	s.WriteString("\n" + helpStyle.Render("+ add email • - remove email • tab/↑/↓ navigate • esc done"))
 	
 	return appStyle.Render(s.String())
-}
\ No newline at end of file
+}
+
+func (m ClientFormModel) viewEInvoicing() string {
+	var s strings.Builder
+	
+	s.WriteString(titleStyle.Render("E-invoicing Details") + "\n")
+	s.WriteString(dimStyle.Render("Needed for Peppol e-invoices; the country is a two-letter code") + "\n\n")
+	
+	for i, field := range eInvoicingFields {
+		s.WriteString(formLabelStyle.Render(field.label))
+		s.WriteString(m.eInvoicingInputs[i].View() + "\n")
+	}
+	
+	s.WriteString("\n" + helpStyle.Render("tab/↑/↓ navigate • enter/esc done"))
+	
+	return appStyle.Render(s.String())
+}
@@ -0,0 +1,242 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/shopspring/decimal"
+	"github.com/user/invoicer/models"
+)
+
+type CreditNoteEnteredMsg struct {
+	Note *models.CreditNote
+}
+
+type CancelCreditNoteMsg struct{}
+
+// Indexes into CreditNoteFormModel.inputs; the two buttons follow them.
+const (
+	creditInputReason = iota
+	creditInputDescription
+	creditInputQuantity
+	creditInputUnitPrice
+	creditInputTaxes
+)
+
+// CreditNoteFormModel collects the reason and lines of a credit note against
+// an invoice. The note is numbered when it is issued.
+type CreditNoteFormModel struct {
+	invoice    *models.Invoice
+	note       *models.CreditNote
+	taxes      []models.Tax
+	inputs     []textinput.Model
+	focusIndex int
+	err        error
+}
+
+// NewCreditNoteFormModel starts a credit note against invoice. taxes is the
+// tax catalogue that credited lines can reverse taxes from.
+func NewCreditNoteFormModel(invoice *models.Invoice, taxes []models.Tax) CreditNoteFormModel {
+	placeholders := []string{"Why is this being credited?", "Description", "1", "0.00", lineTaxesPlaceholder(taxes)}
+	widths := []int{50, 40, 10, 10, 40}
+	inputs := make([]textinput.Model, len(placeholders))
+	for i := range inputs {
+		inputs[i] = textinput.New()
+		inputs[i].Placeholder = placeholders[i]
+		inputs[i].Width = widths[i]
+	}
+	inputs[creditInputReason].Focus()
+
+	return CreditNoteFormModel{
+		invoice: invoice,
+		note:    models.NewCreditNote(invoice, "", ""),
+		taxes:   taxes,
+		inputs:  inputs,
+	}
+}
+
+func (m CreditNoteFormModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m CreditNoteFormModel) addButton() int {
+	return len(m.inputs)
+}
+
+func (m CreditNoteFormModel) issueButton() int {
+	return len(m.inputs) + 1
+}
+
+func (m CreditNoteFormModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			return m, func() tea.Msg { return CancelCreditNoteMsg{} }
+		case "ctrl+d":
+			// Remove the last line
+			if n := len(m.note.LineItems); n > 0 {
+				m.note.RemoveLineItem(m.note.LineItems[n-1].ID)
+			}
+			return m, nil
+		case "tab", "shift+tab", "enter", "up", "down":
+			s := msg.String()
+
+			if s == "enter" && m.focusIndex == m.addButton() {
+				if err := m.addLine(); err != nil {
+					m.err = err
+					return m, nil
+				}
+				m.err = nil
+				m.focusIndex = creditInputDescription
+				return m, m.updateFocus()
+			}
+
+			if s == "enter" && m.focusIndex == m.issueButton() {
+				if err := m.validate(); err != nil {
+					m.err = err
+					return m, nil
+				}
+				m.note.Reason = strings.TrimSpace(m.inputs[creditInputReason].Value())
+				note := m.note
+				return m, func() tea.Msg { return CreditNoteEnteredMsg{Note: note} }
+			}
+
+			if s == "up" || s == "shift+tab" {
+				m.focusIndex--
+			} else {
+				m.focusIndex++
+			}
+
+			if m.focusIndex > m.issueButton() {
+				m.focusIndex = 0
+			} else if m.focusIndex < 0 {
+				m.focusIndex = m.issueButton()
+			}
+
+			return m, m.updateFocus()
+		}
+	}
+
+	if m.focusIndex < len(m.inputs) {
+		var cmd tea.Cmd
+		m.inputs[m.focusIndex], cmd = m.inputs[m.focusIndex].Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m *CreditNoteFormModel) updateFocus() tea.Cmd {
+	cmds := make([]tea.Cmd, len(m.inputs))
+	for i := range m.inputs {
+		if i == m.focusIndex {
+			cmds[i] = m.inputs[i].Focus()
+			m.inputs[i].PromptStyle = formInputStyle
+			m.inputs[i].TextStyle = formInputStyle
+		} else {
+			m.inputs[i].Blur()
+			m.inputs[i].PromptStyle = dimStyle
+			m.inputs[i].TextStyle = dimStyle
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m *CreditNoteFormModel) addLine() error {
+	desc := strings.TrimSpace(m.inputs[creditInputDescription].Value())
+	if desc == "" {
+		return fmt.Errorf("description is required")
+	}
+
+	qtyStr := strings.TrimSpace(m.inputs[creditInputQuantity].Value())
+	if qtyStr == "" {
+		qtyStr = "1"
+	}
+	qty, err := decimal.NewFromString(qtyStr)
+	if err != nil || !qty.GreaterThan(decimal.Zero) {
+		return fmt.Errorf("invalid quantity")
+	}
+
+	price, err := decimal.NewFromString(strings.TrimSpace(m.inputs[creditInputUnitPrice].Value()))
+	if err != nil || price.IsZero() {
+		return fmt.Errorf("invalid price")
+	}
+
+	taxes, err := parseLineTaxes(m.inputs[creditInputTaxes].Value(), m.taxes)
+	if err != nil {
+		return err
+	}
+
+	m.note.AddCredit(desc, qty, price, taxes)
+	for _, i := range []int{creditInputDescription, creditInputQuantity, creditInputUnitPrice, creditInputTaxes} {
+		m.inputs[i].SetValue("")
+	}
+	return nil
+}
+
+func (m CreditNoteFormModel) validate() error {
+	if strings.TrimSpace(m.inputs[creditInputReason].Value()) == "" {
+		return fmt.Errorf("a reason is required")
+	}
+	if len(m.note.LineItems) == 0 {
+		return fmt.Errorf("add at least one line")
+	}
+	if m.note.Amount().GreaterThan(m.invoice.CreditableAmount()) {
+		return fmt.Errorf("credit of %s exceeds the %s left to credit",
+			m.note.Currency.Format(m.note.Amount()), m.invoice.Currency.Format(m.invoice.CreditableAmount()))
+	}
+	return nil
+}
+
+func (m CreditNoteFormModel) View() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render(fmt.Sprintf("Credit Note - Invoice %s", m.invoice.Number)) + "\n\n")
+	s.WriteString(formLabelStyle.Render("Invoice Total:") + " "+m.invoice.Currency.Format(m.invoice.Total) + "\n")
+	s.WriteString(formLabelStyle.Render("Left to Credit:") + " "+m.invoice.Currency.Format(m.invoice.CreditableAmount()) + "\n\n")
+
+	if m.err != nil {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)) + "\n\n")
+	}
+
+	s.WriteString(formLabelStyle.Render("Reason:"))
+	s.WriteString(m.inputs[creditInputReason].View() + "\n\n")
+
+	if len(m.note.LineItems) == 0 {
+		s.WriteString(dimStyle.Render("No lines yet. Enter what is being credited below.") + "\n")
+	} else {
+		for _, item := range m.note.LineItems {
+			s.WriteString(fmt.Sprintf("  %-40s %8s x %10s = %11s\n",
+				truncate(describeLineItem(item, m.note.Currency), 40),
+				item.Quantity.String(),
+				m.note.Currency.Format(item.UnitPrice),
+				m.note.Currency.Format(item.Total)))
+		}
+		s.WriteString(fmt.Sprintf("  %75s\n", "Total credited: "+m.note.Currency.Format(m.note.Amount())))
+	}
+	s.WriteString("\n")
+
+	labels := []string{"Description:", "Quantity:", "Unit Price:", "Taxes:"}
+	for i, label := range labels {
+		s.WriteString(formLabelStyle.Render(label))
+		s.WriteString(m.inputs[creditInputDescription+i].View() + "\n")
+	}
+
+	s.WriteString("\n")
+	addButton, issueButton := "[ Add Line ]", "[ Issue Credit Note ]"
+	if m.focusIndex == m.addButton() {
+		addButton = selectedStyle.Render(addButton)
+	}
+	if m.focusIndex == m.issueButton() {
+		issueButton = selectedStyle.Render(issueButton)
+	}
+	s.WriteString(addButton + "  " + issueButton)
+
+	s.WriteString("\n\n" + helpStyle.Render("tab navigate • enter select • ctrl+d remove last line • esc cancel"))
+
+	return appStyle.Render(s.String())
+}
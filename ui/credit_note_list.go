@@ -0,0 +1,187 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/export"
+	"github.com/user/invoicer/models"
+)
+
+type creditNoteListMode int
+
+const (
+	creditNoteListModeView creditNoteListMode = iota
+	creditNoteListModeExportLocation
+)
+
+// CreditNoteListModel lists every credit note issued. Credit notes are
+// issued from the invoice details screen and cannot be changed afterwards.
+type CreditNoteListModel struct {
+	notes               []models.CreditNote
+	cursor              int
+	storage             models.Storage
+	config              *config.Config
+	mode                creditNoteListMode
+	exportLocationModel ExportLocationModel
+	message             string
+	err                 error
+}
+
+func NewCreditNoteListModel(storage models.Storage, cfg *config.Config) CreditNoteListModel {
+	m := CreditNoteListModel{
+		storage: storage,
+		config:  cfg,
+		mode:    creditNoteListModeView,
+	}
+	notes, err := storage.GetAllCreditNotes()
+	m.notes = notes
+	m.err = err
+	return m
+}
+
+func (m CreditNoteListModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m CreditNoteListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.mode == creditNoteListModeExportLocation {
+		return m.updateExportLocation(msg)
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q":
+			return m, tea.Quit
+		case "esc":
+			return NewMainMenuModel(m.storage, m.config), nil
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.notes)-1 {
+				m.cursor++
+			}
+		case "v", "enter":
+			// Open the credited invoice
+			if len(m.notes) > 0 {
+				invoice, err := m.storage.GetInvoice(m.notes[m.cursor].InvoiceID)
+				if err != nil {
+					m.err = err
+					return m, nil
+				}
+				return NewInvoiceDetailModel(m.storage, m.config, invoice), nil
+			}
+		case "p":
+			if len(m.notes) > 0 {
+				note := m.notes[m.cursor]
+				m.mode = creditNoteListModeExportLocation
+				m.exportLocationModel = NewExportLocationModel("Export Credit Note to PDF", fmt.Sprintf("credit_note_%s.pdf", note.Number))
+				return m, m.exportLocationModel.Init()
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m CreditNoteListModel) updateExportLocation(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case ExportLocationSelectedMsg:
+		m.mode = creditNoteListModeView
+		m.message, m.err = "", nil
+		note := &m.notes[m.cursor]
+
+		invoice, err := m.storage.GetInvoice(note.InvoiceID)
+		if err != nil {
+			m.err = fmt.Errorf("failed to load invoice %s: %w", note.InvoiceNumber, err)
+			return m, nil
+		}
+		client, err := m.storage.GetClient(note.ClientID)
+		if err != nil {
+			m.err = fmt.Errorf("failed to load client: %w", err)
+			return m, nil
+		}
+
+		templatePath := filepath.Join(m.config.TemplatesDir(), "credit_note.tex")
+		if err := export.ExportCreditNoteToPDF(note, invoice, client, m.config, msg.Path, templatePath); err != nil {
+			m.err = fmt.Errorf("failed to export PDF: %w", err)
+			return m, nil
+		}
+		m.message = fmt.Sprintf("Credit note exported to: %s", export.GetCreditNoteExportPath(note, msg.Path))
+		return m, nil
+
+	case CancelExportMsg:
+		m.mode = creditNoteListModeView
+		return m, nil
+
+	default:
+		model, cmd := m.exportLocationModel.Update(msg)
+		m.exportLocationModel = model.(ExportLocationModel)
+		return m, cmd
+	}
+}
+
+func (m CreditNoteListModel) View() string {
+	if m.mode == creditNoteListModeExportLocation {
+		return m.exportLocationModel.View()
+	}
+
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("Credit Notes") + "\n\n")
+
+	if m.err != nil {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)) + "\n\n")
+	}
+	if m.message != "" {
+		s.WriteString(successStyle.Render(m.message) + "\n\n")
+	}
+
+	if len(m.notes) == 0 {
+		s.WriteString(dimStyle.Render("No credit notes. Press 'n' on an invoice's details to issue one.") + "\n")
+	} else {
+		headers := []string{"Number", "Invoice", "Client", "Date", "Amount", "Reason"}
+		widths := []int{13, 10, 20, 12, 12, 24}
+
+		headerRow := ""
+		for i, h := range headers {
+			headerRow += tableCellStyle.Width(widths[i]).Render(h)
+		}
+		s.WriteString(tableHeaderStyle.Render(headerRow) + "\n")
+
+		for i, note := range m.notes {
+			cells := []string{
+				note.Number,
+				note.InvoiceNumber,
+				truncate(note.ClientName, widths[2]-2),
+				note.Date.Format("2006-01-02"),
+				note.Currency.Format(note.Amount().Neg()),
+				truncate(note.Reason, widths[5]-2),
+			}
+
+			row := ""
+			for j, cell := range cells {
+				style := tableCellStyle.Width(widths[j])
+				if i == m.cursor {
+					style = style.Inherit(selectedStyle)
+				}
+				row += style.Render(cell)
+			}
+
+			if i == m.cursor {
+				s.WriteString("> " + row + "\n")
+			} else {
+				s.WriteString("  " + row + "\n")
+			}
+		}
+	}
+
+	s.WriteString("\n" + helpStyle.Render("v view invoice • p export PDF • ↑/k up • ↓/j down • esc back • q quit"))
+
+	return appStyle.Render(s.String())
+}
@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"github.com/user/invoicer/models"
+)
+
+const discountPlaceholder = "10% or 200"
+
+// parseDiscount reads a discount entered as a percentage ("10%"), a fixed
+// amount ("200") or both ("10% + 200"). An empty input is no discount.
+func parseDiscount(input string) (rate, fixed decimal.Decimal, err error) {
+	rate, fixed = decimal.Zero, decimal.Zero
+	for _, part := range strings.Split(input, "+") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		isRate := strings.HasSuffix(part, "%")
+		value, err := decimal.NewFromString(strings.TrimSpace(strings.TrimSuffix(part, "%")))
+		if err != nil || value.IsNegative() {
+			return decimal.Zero, decimal.Zero, fmt.Errorf("invalid discount %q", part)
+		}
+		if isRate {
+			rate = rate.Add(value)
+		} else {
+			fixed = fixed.Add(value)
+		}
+	}
+	if rate.GreaterThan(decimal.NewFromInt(100)) {
+		return decimal.Zero, decimal.Zero, fmt.Errorf("discount cannot be more than 100%%")
+	}
+	return rate, fixed, nil
+}
+
+// formatDiscountInput is the inverse of parseDiscount, for filling in a form.
+func formatDiscountInput(rate, fixed decimal.Decimal) string {
+	var parts []string
+	if rate.IsPositive() {
+		parts = append(parts, rate.String()+"%")
+	}
+	if fixed.IsPositive() {
+		parts = append(parts, fixed.String())
+	}
+	return strings.Join(parts, " + ")
+}
+
+// discountLabel names a discount for display, e.g. "Discount (10.0%)" or
+// "Discount ($200.00)".
+func discountLabel(rate, fixed decimal.Decimal, currency models.CurrencyCode) string {
+	var parts []string
+	if rate.IsPositive() {
+		parts = append(parts, models.FormatPercent(rate)+"%")
+	}
+	if fixed.IsPositive() {
+		parts = append(parts, currency.Format(fixed))
+	}
+	if len(parts) == 0 {
+		return "Discount"
+	}
+	return fmt.Sprintf("Discount (%s)", strings.Join(parts, " + "))
+}
@@ -0,0 +1,293 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/estimates"
+	"github.com/user/invoicer/export"
+	"github.com/user/invoicer/models"
+)
+
+type estimateDetailMode int
+
+const (
+	estimateDetailModeView estimateDetailMode = iota
+	estimateDetailModeExportLocation
+	estimateDetailModeStatusSelect
+)
+
+type EstimateDetailModel struct {
+	estimate            *models.Estimate
+	storage             models.Storage
+	config              *config.Config
+	message             string
+	isError             bool
+	mode                estimateDetailMode
+	exportLocationModel ExportLocationModel
+	statusOptions       []models.EstimateStatus
+	statusCursor        int
+}
+
+func NewEstimateDetailModel(storage models.Storage, cfg *config.Config, estimate *models.Estimate) EstimateDetailModel {
+	return EstimateDetailModel{
+		estimate: estimate,
+		storage:  storage,
+		config:   cfg,
+		mode:     estimateDetailModeView,
+	}
+}
+
+func (m EstimateDetailModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m EstimateDetailModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch m.mode {
+	case estimateDetailModeView:
+		return m.updateView(msg)
+	case estimateDetailModeExportLocation:
+		return m.updateExportLocation(msg)
+	case estimateDetailModeStatusSelect:
+		return m.updateStatusSelect(msg)
+	}
+	return m, nil
+}
+
+func (m EstimateDetailModel) updateView(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "esc":
+		return NewEstimateListModel(m.storage, m.config), func() tea.Msg { return BackToEstimateListMsg{} }
+	case "e":
+		if m.estimate.InvoiceID != "" {
+			m.message = fmt.Sprintf("Estimate %s has been converted and can no longer be edited", m.estimate.Number)
+			m.isError = true
+			return m, nil
+		}
+		return NewEstimateFormModel(m.storage, m.config, m.estimate), nil
+	case "p":
+		m.mode = estimateDetailModeExportLocation
+		m.exportLocationModel = NewExportLocationModel("Export Estimate to PDF", fmt.Sprintf("estimate_%s.pdf", m.estimate.Number))
+		return m, m.exportLocationModel.Init()
+	case "s":
+		m.statusOptions = models.AllowedEstimateTransitions(m.estimate.Status)
+		if len(m.statusOptions) == 0 {
+			m.message = fmt.Sprintf("A %s estimate cannot change status", m.estimate.Status)
+			m.isError = true
+			return m, nil
+		}
+		m.statusCursor = 0
+		m.mode = estimateDetailModeStatusSelect
+	case "c":
+		invoice, err := estimates.NewService(m.storage).ConvertToInvoice(m.estimate)
+		if err != nil {
+			m.message = fmt.Sprintf("Error converting estimate: %v", err)
+			m.isError = true
+			m.reloadOnConflict(err)
+			return m, nil
+		}
+		m.message = fmt.Sprintf("Created draft invoice %s", invoice.Number)
+		m.isError = false
+	}
+	return m, nil
+}
+
+// reloadOnConflict replaces the estimate with the stored one when err is a
+// version conflict, so the user sees what someone else saved.
+func (m *EstimateDetailModel) reloadOnConflict(err error) {
+	if !errors.Is(err, models.ErrConflict) {
+		return
+	}
+	if latest, loadErr := m.storage.GetEstimate(m.estimate.ID); loadErr == nil {
+		m.estimate = latest
+	}
+}
+
+func (m EstimateDetailModel) updateExportLocation(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case ExportLocationSelectedMsg:
+		m.mode = estimateDetailModeView
+		client, err := m.storage.GetClient(m.estimate.ClientID)
+		if err != nil {
+			m.message = fmt.Sprintf("Error loading client: %v", err)
+			m.isError = true
+			return m, nil
+		}
+
+		templatePath := filepath.Join(m.config.TemplatesDir(), "estimate.tex")
+		if err := export.ExportEstimateToPDF(m.estimate, client, m.config, msg.Path, templatePath); err != nil {
+			m.message = fmt.Sprintf("Error exporting PDF: %v", err)
+			m.isError = true
+			return m, nil
+		}
+		m.message = fmt.Sprintf("Estimate exported to: %s", export.GetEstimateExportPath(m.estimate, msg.Path))
+		m.isError = false
+		return m, nil
+
+	case CancelExportMsg:
+		m.mode = estimateDetailModeView
+		return m, nil
+
+	default:
+		model, cmd := m.exportLocationModel.Update(msg)
+		m.exportLocationModel = model.(ExportLocationModel)
+		return m, cmd
+	}
+}
+
+func (m EstimateDetailModel) updateStatusSelect(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.mode = estimateDetailModeView
+	case "up", "k":
+		if m.statusCursor > 0 {
+			m.statusCursor--
+		}
+	case "down", "j":
+		if m.statusCursor < len(m.statusOptions)-1 {
+			m.statusCursor++
+		}
+	case "enter":
+		m.mode = estimateDetailModeView
+		oldStatus := m.estimate.Status
+		newStatus := m.statusOptions[m.statusCursor]
+		if err := m.estimate.UpdateStatus(newStatus); err != nil {
+			m.message = fmt.Sprintf("Error updating status: %v", err)
+			m.isError = true
+			return m, nil
+		}
+		if err := m.storage.UpdateEstimate(m.estimate); err != nil {
+			m.estimate.Status = oldStatus
+			m.message = fmt.Sprintf("Error updating status: %v", err)
+			m.isError = true
+			m.reloadOnConflict(err)
+			return m, nil
+		}
+		m.message = fmt.Sprintf("Status changed from %s to %s", oldStatus, newStatus)
+		m.isError = false
+	}
+	return m, nil
+}
+
+func (m EstimateDetailModel) View() string {
+	switch m.mode {
+	case estimateDetailModeExportLocation:
+		return m.exportLocationModel.View()
+	case estimateDetailModeStatusSelect:
+		return m.viewStatusSelect()
+	}
+	return m.viewDetail()
+}
+
+func (m EstimateDetailModel) viewDetail() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render(fmt.Sprintf("Estimate %s", m.estimate.Number)) + "\n\n")
+
+	s.WriteString(formLabelStyle.Render("Client:") + " " + m.estimate.ClientName + "\n")
+	s.WriteString(formLabelStyle.Render("Date:") + " " + m.estimate.Date.Format("January 2, 2006") + "\n")
+	s.WriteString(formLabelStyle.Render("Valid Until:") + " " + m.estimate.ValidUntil.Format("January 2, 2006") + "\n")
+	s.WriteString(formLabelStyle.Render("Status:") + " " + estimateStatusStyle(m.estimate.Status).Render(string(m.estimate.Status)) + "\n")
+	if m.estimate.InvoiceID != "" {
+		invoiceRef := m.estimate.InvoiceID
+		if invoice, err := m.storage.GetInvoice(m.estimate.InvoiceID); err == nil {
+			invoiceRef = invoice.Number
+		}
+		s.WriteString(formLabelStyle.Render("Invoice:") + " " + invoiceRef + "\n")
+	}
+
+	s.WriteString("\n" + subtitleStyle.Render("Line Items") + "\n")
+	s.WriteString(strings.Repeat("─", 74) + "\n")
+
+	if len(m.estimate.LineItems) == 0 {
+		s.WriteString(dimStyle.Render("No line items") + "\n")
+	} else {
+		headers := []string{"Description", "Qty", "Price", "Total"}
+		widths := []int{40, 10, 12, 12}
+
+		headerRow := ""
+		for i, h := range headers {
+			headerRow += tableCellStyle.Width(widths[i]).Render(h)
+		}
+		s.WriteString(tableHeaderStyle.Render(headerRow) + "\n")
+
+		for _, item := range m.estimate.LineItems {
+			cells := []string{
+				truncate(describeLineItem(item, m.estimate.Currency), widths[0]-2),
+				fmt.Sprintf("%.2f", item.Quantity.InexactFloat64()),
+				m.estimate.Currency.Format(item.UnitPrice),
+				m.estimate.Currency.Format(item.Total),
+			}
+			row := ""
+			for j, cell := range cells {
+				row += tableCellStyle.Width(widths[j]).Render(cell)
+			}
+			s.WriteString("  " + row + "\n")
+		}
+	}
+
+	s.WriteString(strings.Repeat("─", 74) + "\n")
+	s.WriteString(fmt.Sprintf("%*s", 74, "Subtotal: "+m.estimate.Currency.Format(m.estimate.Subtotal)) + "\n")
+	if !m.estimate.Discount.IsZero() {
+		label := discountLabel(m.estimate.DiscountRate, m.estimate.FixedDiscount, m.estimate.Currency)
+		s.WriteString(fmt.Sprintf("%*s", 74, label+": "+m.estimate.Currency.Format(m.estimate.Discount.Neg())) + "\n")
+	}
+	for _, t := range m.estimate.TaxBreakdown() {
+		s.WriteString(fmt.Sprintf("%*s", 74, taxLabel(t)+": "+m.estimate.Currency.Format(t.Amount)) + "\n")
+	}
+	s.WriteString(fmt.Sprintf("%*s", 74, "Total: "+m.estimate.Currency.Format(m.estimate.Total)) + "\n")
+
+	if m.estimate.Notes != "" {
+		s.WriteString("\n" + formLabelStyle.Render("Notes:") + " " + m.estimate.Notes + "\n")
+	}
+
+	if m.message != "" {
+		s.WriteString("\n")
+		if m.isError {
+			s.WriteString(errorStyle.Render(m.message) + "\n")
+		} else {
+			s.WriteString(successStyle.Render(m.message) + "\n")
+		}
+	}
+
+	s.WriteString("\n" + helpStyle.Render("e edit • s change status • p export PDF • c convert to invoice • esc back • q quit"))
+
+	return appStyle.Render(s.String())
+}
+
+func (m EstimateDetailModel) viewStatusSelect() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render(fmt.Sprintf("Change Status of %s", m.estimate.Number)) + "\n\n")
+	s.WriteString(formLabelStyle.Render("Current:") + " " + estimateStatusStyle(m.estimate.Status).Render(string(m.estimate.Status)) + "\n\n")
+
+	for i, status := range m.statusOptions {
+		if i == m.statusCursor {
+			s.WriteString(selectedListItemStyle.Render("> "+string(status)) + "\n")
+		} else {
+			s.WriteString(listItemStyle.Render(string(status)) + "\n")
+		}
+	}
+
+	s.WriteString("\n" + helpStyle.Render("↑/k up • ↓/j down • enter select • esc cancel"))
+
+	return appStyle.Render(s.String())
+}
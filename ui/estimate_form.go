@@ -0,0 +1,634 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/shopspring/decimal"
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/models"
+)
+
+type estimateFormMode int
+
+const (
+	estimateFormModeEditBasic estimateFormMode = iota
+	estimateFormModeSelectClient
+	estimateFormModeEditLineItems
+	estimateFormModeAddLineItem
+	estimateFormModeEditLineItem
+)
+
+// Indexes into EstimateFormModel.inputs
+const (
+	estimateInputDate = iota
+	estimateInputValidUntil
+	estimateInputDiscount
+	estimateInputTax
+	estimateInputCurrency
+	estimateInputNotes
+)
+
+// EstimateFormModel creates or edits an estimate. The first focus position is
+// the client selector and the last the button that moves on to the line
+// items.
+type EstimateFormModel struct {
+	storage  models.Storage
+	config   *config.Config
+	estimate *models.Estimate
+	isEdit   bool
+	mode     estimateFormMode
+
+	clients      []models.Client
+	clientCursor int
+	taxes        []models.Tax
+	catalog      []models.CatalogItem
+
+	inputs     []textinput.Model
+	focusIndex int
+
+	lineItemInputs     []textinput.Model
+	lineItemFocusIndex int
+	lineItemCursor     int
+	editingIndex       int
+
+	err error
+}
+
+func NewEstimateFormModel(storage models.Storage, cfg *config.Config, estimate *models.Estimate) EstimateFormModel {
+	m := EstimateFormModel{
+		storage:  storage,
+		config:   cfg,
+		estimate: estimate,
+		isEdit:   estimate != nil,
+		mode:     estimateFormModeEditBasic,
+	}
+
+	clients, _ := storage.GetAllClients()
+	m.clients = clients
+	m.taxes, _ = storage.GetAllTaxes()
+	m.catalog, _ = storage.GetAllCatalogItems()
+
+	if !m.isEdit {
+		year := time.Now().Year()
+		seq, _ := storage.GetNextEstimateNumber(year)
+		m.estimate = models.NewEstimate("", "", models.GenerateEstimateNumber(year, seq))
+		if len(clients) > 0 {
+			m.estimate.ClientID = clients[0].ID
+			m.estimate.ClientName = clients[0].Name
+			m.estimate.Currency = clients[0].DefaultCurrency.Code()
+		}
+	} else {
+		for i, client := range clients {
+			if client.ID == estimate.ClientID {
+				m.clientCursor = i
+			}
+		}
+	}
+
+	placeholders := []string{"YYYY-MM-DD", "YYYY-MM-DD", discountPlaceholder, "0", string(models.DefaultCurrency), "Scope, assumptions, terms..."}
+	widths := []int{15, 15, 15, 10, 10, 50}
+	m.inputs = make([]textinput.Model, len(placeholders))
+	for i := range m.inputs {
+		m.inputs[i] = textinput.New()
+		m.inputs[i].Placeholder = placeholders[i]
+		m.inputs[i].Width = widths[i]
+	}
+
+	m.inputs[estimateInputDate].SetValue(m.estimate.Date.Format("2006-01-02"))
+	m.inputs[estimateInputValidUntil].SetValue(m.estimate.ValidUntil.Format("2006-01-02"))
+	m.inputs[estimateInputDiscount].SetValue(formatDiscountInput(m.estimate.DiscountRate, m.estimate.FixedDiscount))
+	m.inputs[estimateInputTax].SetValue(m.estimate.TaxRate.String())
+	m.inputs[estimateInputCurrency].SetValue(string(m.estimate.Currency.Code()))
+	m.inputs[estimateInputNotes].SetValue(m.estimate.Notes)
+
+	m.setupLineItemInputs(nil)
+	return m
+}
+
+func (m *EstimateFormModel) setupLineItemInputs(item *models.LineItem) {
+	descInput := textinput.New()
+	descInput.Placeholder = "Description"
+	descInput.Width = 40
+	descInput.Focus()
+	enableCatalogSuggestions(&descInput, m.catalog)
+
+	qtyInput := textinput.New()
+	qtyInput.Placeholder = "1"
+	qtyInput.Width = 10
+
+	priceInput := textinput.New()
+	priceInput.Placeholder = "0.00"
+	priceInput.Width = 10
+
+	taxesInput := textinput.New()
+	taxesInput.Placeholder = lineTaxesPlaceholder(m.taxes)
+	taxesInput.Width = 40
+
+	discountInput := textinput.New()
+	discountInput.Placeholder = discountPlaceholder
+	discountInput.Width = 15
+
+	if item != nil {
+		descInput.SetValue(item.Description)
+		qtyInput.SetValue(item.Quantity.String())
+		priceInput.SetValue(item.UnitPrice.StringFixed(2))
+		taxesInput.SetValue(models.TaxNames(item.Taxes))
+		discountInput.SetValue(formatDiscountInput(item.DiscountRate, item.FixedDiscount))
+	}
+
+	m.lineItemInputs = []textinput.Model{descInput, qtyInput, priceInput, taxesInput, discountInput}
+	m.lineItemFocusIndex = 0
+}
+
+// fillFromCatalog completes the line item being entered from the catalog
+// when the description names a catalog item.
+func (m *EstimateFormModel) fillFromCatalog(accept bool) {
+	original := ""
+	if m.mode == estimateFormModeEditLineItem {
+		original = m.estimate.LineItems[m.editingIndex].Description
+	}
+	client, _ := m.storage.GetClient(m.estimate.ClientID)
+	completeFromCatalog(m.lineItemInputs, m.catalog, m.taxes, client, original, accept)
+}
+
+func (m EstimateFormModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m EstimateFormModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch m.mode {
+		case estimateFormModeEditBasic:
+			return m.updateBasicMode(msg)
+		case estimateFormModeSelectClient:
+			return m.updateSelectClientMode(msg)
+		case estimateFormModeEditLineItems:
+			return m.updateLineItemsMode(msg)
+		case estimateFormModeAddLineItem, estimateFormModeEditLineItem:
+			return m.updateLineItemInputMode(msg)
+		}
+	}
+
+	return m, nil
+}
+
+func (m EstimateFormModel) back() (tea.Model, tea.Cmd) {
+	return NewEstimateListModel(m.storage, m.config), func() tea.Msg { return BackToEstimateListMsg{} }
+}
+
+func (m EstimateFormModel) updateBasicMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		return m.back()
+	case "tab", "shift+tab", "enter", "up", "down":
+		s := msg.String()
+
+		// Client selector, the inputs and the next button
+		totalFocusItems := len(m.inputs) + 2
+
+		if s == "enter" && m.focusIndex == 0 {
+			m.mode = estimateFormModeSelectClient
+			return m, nil
+		}
+
+		if s == "enter" && m.focusIndex == totalFocusItems-1 {
+			if err := m.applyInputs(); err != nil {
+				m.err = err
+				return m, nil
+			}
+			m.err = nil
+			m.mode = estimateFormModeEditLineItems
+			return m, nil
+		}
+
+		if s == "up" || s == "shift+tab" {
+			m.focusIndex--
+		} else {
+			m.focusIndex++
+		}
+
+		if m.focusIndex >= totalFocusItems {
+			m.focusIndex = 0
+		} else if m.focusIndex < 0 {
+			m.focusIndex = totalFocusItems - 1
+		}
+
+		return m.updateFocus()
+	}
+
+	if i := m.focusIndex - 1; i >= 0 && i < len(m.inputs) {
+		var cmd tea.Cmd
+		m.inputs[i], cmd = m.inputs[i].Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m *EstimateFormModel) updateFocus() (EstimateFormModel, tea.Cmd) {
+	cmds := make([]tea.Cmd, len(m.inputs))
+
+	for i := range m.inputs {
+		if i == m.focusIndex-1 {
+			cmds[i] = m.inputs[i].Focus()
+			m.inputs[i].PromptStyle = formInputStyle
+			m.inputs[i].TextStyle = formInputStyle
+		} else {
+			m.inputs[i].Blur()
+			m.inputs[i].PromptStyle = dimStyle
+			m.inputs[i].TextStyle = dimStyle
+		}
+	}
+
+	return *m, tea.Batch(cmds...)
+}
+
+func (m EstimateFormModel) updateSelectClientMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = estimateFormModeEditBasic
+	case "up", "k":
+		if m.clientCursor > 0 {
+			m.clientCursor--
+		}
+	case "down", "j":
+		if m.clientCursor < len(m.clients)-1 {
+			m.clientCursor++
+		}
+	case "enter":
+		if len(m.clients) > 0 {
+			client := m.clients[m.clientCursor]
+			m.estimate.ClientID = client.ID
+			m.estimate.ClientName = client.Name
+			m.estimate.Currency = client.DefaultCurrency.Code()
+			m.inputs[estimateInputCurrency].SetValue(string(m.estimate.Currency))
+		}
+		m.mode = estimateFormModeEditBasic
+	}
+	return m, nil
+}
+
+func (m EstimateFormModel) updateLineItemsMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.mode = estimateFormModeEditBasic
+	case "a":
+		m.mode = estimateFormModeAddLineItem
+		m.setupLineItemInputs(nil)
+		return m, textinput.Blink
+	case "e":
+		if m.lineItemCursor < len(m.estimate.LineItems) {
+			m.mode = estimateFormModeEditLineItem
+			m.editingIndex = m.lineItemCursor
+			m.setupLineItemInputs(&m.estimate.LineItems[m.lineItemCursor])
+			return m, textinput.Blink
+		}
+	case "d":
+		if m.lineItemCursor < len(m.estimate.LineItems) {
+			m.estimate.LineItems = append(m.estimate.LineItems[:m.lineItemCursor], m.estimate.LineItems[m.lineItemCursor+1:]...)
+			m.estimate.CalculateTotals()
+			if m.lineItemCursor >= len(m.estimate.LineItems) && m.lineItemCursor > 0 {
+				m.lineItemCursor--
+			}
+		}
+	case "up", "k":
+		if m.lineItemCursor > 0 {
+			m.lineItemCursor--
+		}
+	case "down", "j":
+		if m.lineItemCursor < len(m.estimate.LineItems)-1 {
+			m.lineItemCursor++
+		}
+	case "s":
+		if err := m.saveEstimate(); err != nil {
+			m.err = err
+			return m, nil
+		}
+		return m.back()
+	}
+	return m, nil
+}
+
+func (m EstimateFormModel) updateLineItemInputMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = estimateFormModeEditLineItems
+		return m, nil
+	case "tab", "shift+tab", "enter":
+		s := msg.String()
+
+		if s == "enter" && m.lineItemFocusIndex == len(m.lineItemInputs) {
+			item, err := m.buildLineItem()
+			if err != nil {
+				m.err = err
+				return m, nil
+			}
+			if m.mode == estimateFormModeEditLineItem {
+				m.estimate.LineItems[m.editingIndex] = *item
+			} else {
+				m.estimate.LineItems = append(m.estimate.LineItems, *item)
+			}
+			m.estimate.CalculateTotals()
+			m.err = nil
+			m.mode = estimateFormModeEditLineItems
+			return m, nil
+		}
+
+		if m.lineItemFocusIndex == 0 {
+			m.fillFromCatalog(s == "tab")
+		}
+
+		if s == "shift+tab" {
+			m.lineItemFocusIndex--
+		} else {
+			m.lineItemFocusIndex++
+		}
+
+		if m.lineItemFocusIndex > len(m.lineItemInputs) {
+			m.lineItemFocusIndex = 0
+		} else if m.lineItemFocusIndex < 0 {
+			m.lineItemFocusIndex = len(m.lineItemInputs)
+		}
+
+		cmds := make([]tea.Cmd, len(m.lineItemInputs))
+		for i := range m.lineItemInputs {
+			if i == m.lineItemFocusIndex {
+				cmds[i] = m.lineItemInputs[i].Focus()
+			} else {
+				m.lineItemInputs[i].Blur()
+			}
+		}
+		return m, tea.Batch(cmds...)
+	}
+
+	cmds := make([]tea.Cmd, len(m.lineItemInputs))
+	for i := range m.lineItemInputs {
+		m.lineItemInputs[i], cmds[i] = m.lineItemInputs[i].Update(msg)
+	}
+	return m, tea.Batch(cmds...)
+}
+
+func (m *EstimateFormModel) buildLineItem() (*models.LineItem, error) {
+	desc := strings.TrimSpace(m.lineItemInputs[0].Value())
+	if desc == "" {
+		return nil, fmt.Errorf("description is required")
+	}
+
+	qtyStr := m.lineItemInputs[1].Value()
+	if qtyStr == "" {
+		qtyStr = "1"
+	}
+	qty, err := decimal.NewFromString(qtyStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quantity")
+	}
+
+	priceStr := m.lineItemInputs[2].Value()
+	if priceStr == "" {
+		priceStr = "0"
+	}
+	price, err := decimal.NewFromString(priceStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid price")
+	}
+
+	taxes, err := parseLineTaxes(m.lineItemInputs[3].Value(), m.taxes)
+	if err != nil {
+		return nil, err
+	}
+
+	discountRate, fixedDiscount, err := parseDiscount(m.lineItemInputs[4].Value())
+	if err != nil {
+		return nil, err
+	}
+
+	item := models.NewLineItem(desc, qty, price)
+	item.Taxes = taxes
+	item.SetDiscount(discountRate, fixedDiscount)
+	return item, nil
+}
+
+// applyInputs validates the basic inputs and copies them onto the estimate.
+func (m *EstimateFormModel) applyInputs() error {
+	if m.estimate.ClientID == "" {
+		return fmt.Errorf("select a client first")
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(m.inputs[estimateInputDate].Value()), time.Local)
+	if err != nil {
+		return fmt.Errorf("invalid date, use YYYY-MM-DD")
+	}
+
+	validUntil, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(m.inputs[estimateInputValidUntil].Value()), time.Local)
+	if err != nil {
+		return fmt.Errorf("invalid valid until date, use YYYY-MM-DD")
+	}
+	if validUntil.Before(date) {
+		return fmt.Errorf("valid until date cannot be before the estimate date")
+	}
+
+	discountRate, fixedDiscount, err := parseDiscount(m.inputs[estimateInputDiscount].Value())
+	if err != nil {
+		return err
+	}
+
+	tax, err := decimal.NewFromString(strings.TrimSpace(m.inputs[estimateInputTax].Value()))
+	if err != nil {
+		return fmt.Errorf("invalid tax")
+	}
+
+	currency, err := models.ParseCurrency(m.inputs[estimateInputCurrency].Value())
+	if err != nil {
+		return err
+	}
+
+	m.estimate.Date = date
+	m.estimate.ValidUntil = validUntil
+	m.estimate.DiscountRate = discountRate
+	m.estimate.FixedDiscount = fixedDiscount
+	m.estimate.TaxRate = tax
+	m.estimate.Currency = currency
+	m.estimate.Notes = strings.TrimSpace(m.inputs[estimateInputNotes].Value())
+	m.estimate.CalculateTotals()
+	return nil
+}
+
+func (m *EstimateFormModel) saveEstimate() error {
+	if err := m.applyInputs(); err != nil {
+		return err
+	}
+	if len(m.estimate.LineItems) == 0 {
+		return fmt.Errorf("add at least one line item")
+	}
+
+	if m.isEdit {
+		return m.storage.UpdateEstimate(m.estimate)
+	}
+	return m.storage.SaveEstimate(m.estimate)
+}
+
+func (m EstimateFormModel) View() string {
+	switch m.mode {
+	case estimateFormModeSelectClient:
+		return m.viewSelectClient()
+	case estimateFormModeEditLineItems:
+		return m.viewLineItems()
+	case estimateFormModeAddLineItem, estimateFormModeEditLineItem:
+		return m.viewLineItemInput()
+	default:
+		return m.viewBasic()
+	}
+}
+
+func (m EstimateFormModel) viewBasic() string {
+	var s strings.Builder
+
+	title := fmt.Sprintf("New Estimate %s", m.estimate.Number)
+	if m.isEdit {
+		title = fmt.Sprintf("Edit Estimate %s", m.estimate.Number)
+	}
+	s.WriteString(titleStyle.Render(title) + "\n\n")
+
+	if m.err != nil {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)) + "\n\n")
+	}
+
+	clientText := "[ Select Client ]"
+	if m.focusIndex == 0 {
+		clientText = selectedStyle.Render(clientText)
+	}
+	s.WriteString(formLabelStyle.Render("Client:") + " " + m.estimate.ClientName + " " + clientText + "\n\n")
+
+	labels := []string{"Date:", "Valid Until:", "Discount:", "Tax %:", "Currency:", "Notes:"}
+	for i, label := range labels {
+		s.WriteString(formLabelStyle.Render(label))
+		s.WriteString(m.inputs[i].View() + "\n")
+	}
+
+	nextButton := "[ Next: Line Items ]"
+	if m.focusIndex == len(m.inputs)+1 {
+		nextButton = selectedStyle.Render(nextButton)
+	}
+	s.WriteString("\n" + nextButton)
+
+	s.WriteString("\n\n" + helpStyle.Render("tab navigate • enter select • esc cancel"))
+
+	return appStyle.Render(s.String())
+}
+
+func (m EstimateFormModel) viewSelectClient() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("Select Client") + "\n\n")
+
+	if len(m.clients) == 0 {
+		s.WriteString(dimStyle.Render("No clients found. Please add a client first.") + "\n")
+	} else {
+		for i, client := range m.clients {
+			if i == m.clientCursor {
+				s.WriteString(selectedListItemStyle.Render("> "+client.Name) + "\n")
+			} else {
+				s.WriteString(listItemStyle.Render(client.Name) + "\n")
+			}
+		}
+	}
+
+	s.WriteString("\n" + helpStyle.Render("↑/k up • ↓/j down • enter select • esc cancel"))
+
+	return appStyle.Render(s.String())
+}
+
+func (m EstimateFormModel) viewLineItems() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render(fmt.Sprintf("Estimate %s - Line Items", m.estimate.Number)) + "\n\n")
+
+	if m.err != nil {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)) + "\n\n")
+	}
+
+	if len(m.estimate.LineItems) == 0 {
+		s.WriteString(dimStyle.Render("No line items. Press 'a' to add.") + "\n")
+	} else {
+		headers := []string{"Description", "Qty", "Price", "Total"}
+		widths := []int{40, 10, 12, 12}
+
+		headerRow := ""
+		for i, h := range headers {
+			headerRow += tableCellStyle.Width(widths[i]).Render(h)
+		}
+		s.WriteString(tableHeaderStyle.Render(headerRow) + "\n")
+
+		for i, item := range m.estimate.LineItems {
+			row := ""
+			cells := []string{
+				truncate(describeLineItem(item, m.estimate.Currency), widths[0]-2),
+				fmt.Sprintf("%.2f", item.Quantity.InexactFloat64()),
+				m.estimate.Currency.Format(item.UnitPrice),
+				m.estimate.Currency.Format(item.Total),
+			}
+
+			for j, cell := range cells {
+				style := tableCellStyle.Width(widths[j])
+				if i == m.lineItemCursor {
+					style = style.Inherit(selectedStyle)
+				}
+				row += style.Render(cell)
+			}
+
+			if i == m.lineItemCursor {
+				s.WriteString("> " + row + "\n")
+			} else {
+				s.WriteString("  " + row + "\n")
+			}
+		}
+
+		s.WriteString(strings.Repeat("─", 74) + "\n")
+		s.WriteString(fmt.Sprintf("%*s", 74, "Total: "+m.estimate.Currency.Format(m.estimate.Total)) + "\n")
+	}
+
+	s.WriteString("\n" + helpStyle.Render("a add • e edit • d delete • s save • ↑/k up • ↓/j down • esc back"))
+
+	return appStyle.Render(s.String())
+}
+
+func (m EstimateFormModel) viewLineItemInput() string {
+	var s strings.Builder
+
+	title, button := "Add Line Item", "[ Add Item ]"
+	if m.mode == estimateFormModeEditLineItem {
+		title, button = "Edit Line Item", "[ Update Item ]"
+	}
+	s.WriteString(titleStyle.Render(title) + "\n\n")
+
+	if m.err != nil {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)) + "\n\n")
+	}
+
+	labels := []string{"Description:", "Quantity:", "Unit Price:", "Taxes:", "Discount:"}
+	for i, label := range labels {
+		s.WriteString(formLabelStyle.Render(label))
+		s.WriteString(m.lineItemInputs[i].View())
+		s.WriteString("\n")
+		if i == 0 {
+			s.WriteString(catalogHint(&m.lineItemInputs[0]))
+		}
+	}
+
+	if m.lineItemFocusIndex == len(m.lineItemInputs) {
+		button = selectedStyle.Render(button)
+	}
+	s.WriteString("\n" + button)
+
+	s.WriteString("\n\n" + helpStyle.Render("tab navigate • enter save item • esc cancel"))
+
+	return appStyle.Render(s.String())
+}
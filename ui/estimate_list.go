@@ -0,0 +1,200 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/models"
+)
+
+type estimateListMode int
+
+const (
+	estimateListModeView estimateListMode = iota
+	estimateListModeConfirmDelete
+)
+
+type EstimateListModel struct {
+	estimates []models.Estimate
+	cursor    int
+	storage   models.Storage
+	config    *config.Config
+	mode      estimateListMode
+	err       error
+}
+
+func NewEstimateListModel(storage models.Storage, cfg *config.Config) EstimateListModel {
+	m := EstimateListModel{
+		storage: storage,
+		config:  cfg,
+		mode:    estimateListModeView,
+	}
+	m.loadEstimates()
+	return m
+}
+
+func (m *EstimateListModel) loadEstimates() {
+	estimates, err := m.storage.GetAllEstimates()
+	if err != nil {
+		m.err = err
+		return
+	}
+	m.estimates = estimates
+	m.err = nil
+	if m.cursor >= len(m.estimates) && m.cursor > 0 {
+		m.cursor = len(m.estimates) - 1
+	}
+}
+
+func (m EstimateListModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m EstimateListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.mode == estimateListModeConfirmDelete {
+			switch msg.String() {
+			case "y":
+				err := m.storage.DeleteEstimate(m.estimates[m.cursor].ID)
+				m.loadEstimates()
+				if err != nil {
+					m.err = err
+				}
+				m.mode = estimateListModeView
+			case "n", "esc":
+				m.mode = estimateListModeView
+			}
+			return m, nil
+		}
+
+		switch msg.String() {
+		case "ctrl+c", "q":
+			return m, tea.Quit
+		case "esc":
+			return NewMainMenuModel(m.storage, m.config), nil
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.estimates)-1 {
+				m.cursor++
+			}
+		case "a":
+			clients, err := m.storage.GetAllClients()
+			if err != nil {
+				m.err = err
+				return m, nil
+			}
+			if len(clients) == 0 {
+				m.err = fmt.Errorf("no clients found. Please add a client first")
+				return m, nil
+			}
+			return NewEstimateFormModel(m.storage, m.config, nil), nil
+		case "e":
+			if len(m.estimates) > 0 {
+				estimate := &m.estimates[m.cursor]
+				if estimate.InvoiceID != "" {
+					m.err = fmt.Errorf("estimate %s has been converted and can no longer be edited", estimate.Number)
+					return m, nil
+				}
+				return NewEstimateFormModel(m.storage, m.config, estimate), nil
+			}
+		case "v", "enter":
+			if len(m.estimates) > 0 {
+				return NewEstimateDetailModel(m.storage, m.config, &m.estimates[m.cursor]), nil
+			}
+		case "d":
+			if len(m.estimates) > 0 {
+				m.mode = estimateListModeConfirmDelete
+			}
+		}
+	case BackToEstimateListMsg:
+		m.loadEstimates()
+	}
+	return m, nil
+}
+
+func (m EstimateListModel) View() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("Estimates") + "\n\n")
+
+	if m.err != nil {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)) + "\n\n")
+	}
+
+	if m.mode == estimateListModeConfirmDelete {
+		estimate := m.estimates[m.cursor]
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Delete estimate '%s'? (y/n)", estimate.Number)) + "\n")
+		return appStyle.Render(s.String())
+	}
+
+	if len(m.estimates) == 0 {
+		s.WriteString(dimStyle.Render("No estimates found. Press 'a' to create a new estimate.") + "\n")
+	} else {
+		headers := []string{"Number", "Client", "Date", "Valid Until", "Total", "Status"}
+		widths := []int{13, 25, 12, 12, 12, 10}
+
+		headerRow := ""
+		for i, h := range headers {
+			headerRow += tableCellStyle.Width(widths[i]).Render(h)
+		}
+		s.WriteString(tableHeaderStyle.Render(headerRow) + "\n")
+
+		for i, estimate := range m.estimates {
+			cells := []string{
+				estimate.Number,
+				truncate(estimate.ClientName, widths[1]-2),
+				estimate.Date.Format("2006-01-02"),
+				estimate.ValidUntil.Format("2006-01-02"),
+				estimate.Currency.Format(estimate.Total),
+				string(estimate.Status),
+			}
+
+			row := ""
+			for j, cell := range cells {
+				style := tableCellStyle.Width(widths[j])
+				if i == m.cursor {
+					style = style.Inherit(selectedStyle)
+				}
+				if j == 5 {
+					style = style.Inherit(estimateStatusStyle(estimate.Status))
+				}
+				row += style.Render(cell)
+			}
+
+			if i == m.cursor {
+				s.WriteString("> " + row + "\n")
+			} else {
+				s.WriteString("  " + row + "\n")
+			}
+		}
+	}
+
+	s.WriteString("\n" + helpStyle.Render("a add • e edit • v view • d delete • ↑/k up • ↓/j down • esc back • q quit"))
+
+	return appStyle.Render(s.String())
+}
+
+// estimateStatusStyle reuses the invoice status colours: accepted reads like
+// paid, declined and expired like void and overdue.
+func estimateStatusStyle(status models.EstimateStatus) lipgloss.Style {
+	switch status {
+	case models.EstimateSent:
+		return statusSentStyle
+	case models.EstimateAccepted:
+		return statusPaidStyle
+	case models.EstimateDeclined:
+		return statusVoidStyle
+	case models.EstimateExpired:
+		return statusOverdueStyle
+	}
+	return statusDraftStyle
+}
+
+type BackToEstimateListMsg struct{}
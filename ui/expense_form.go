@@ -0,0 +1,349 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/shopspring/decimal"
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/models"
+)
+
+const (
+	expenseFocusClient = iota
+	expenseFocusDate
+	expenseFocusVendor
+	expenseFocusCategory
+	expenseFocusDescription
+	expenseFocusAmount
+	expenseFocusMarkup
+	expenseFocusReceipt
+	expenseFocusSave
+	expenseFocusCount
+)
+
+// receiptTypes are the receipt formats that can be appended to an exported
+// invoice.
+var receiptTypes = map[string]bool{".pdf": true, ".png": true, ".jpg": true, ".jpeg": true}
+
+type ExpenseFormModel struct {
+	inputs      []textinput.Model
+	focusIndex  int
+	clients     []models.Client
+	clientIndex int
+	storage     models.Storage
+	config      *config.Config
+	expense     *models.Expense
+	isEdit      bool
+	err         error
+}
+
+func NewExpenseFormModel(storage models.Storage, cfg *config.Config, expense *models.Expense) ExpenseFormModel {
+	clients, err := storage.GetAllClients()
+	if err == nil && len(clients) == 0 {
+		err = errors.New("add a client before logging expenses")
+	}
+
+	// The inputs follow the client selector, so field i is inputs[i-expenseFocusDate]
+	placeholders := []string{"YYYY-MM-DD", "Amtrak", "Travel", "Tickets to the client site", "0.00", "0", "~/Downloads/receipt.pdf"}
+	widths := []int{15, 30, 20, 50, 15, 10, 50}
+	inputs := make([]textinput.Model, len(placeholders))
+	for i := range inputs {
+		inputs[i] = textinput.New()
+		inputs[i].Placeholder = placeholders[i]
+		inputs[i].Width = widths[i]
+		inputs[i].PromptStyle = dimStyle
+		inputs[i].TextStyle = dimStyle
+	}
+
+	m := ExpenseFormModel{
+		inputs:  inputs,
+		clients: clients,
+		storage: storage,
+		config:  cfg,
+		expense: expense,
+		isEdit:  expense != nil,
+		err:     err,
+	}
+
+	if m.isEdit {
+		for i, client := range clients {
+			if client.ID == expense.ClientID {
+				m.clientIndex = i
+			}
+		}
+		m.input(expenseFocusDate).SetValue(expense.Date.Format("2006-01-02"))
+		m.input(expenseFocusVendor).SetValue(expense.Vendor)
+		m.input(expenseFocusCategory).SetValue(expense.Category)
+		m.input(expenseFocusDescription).SetValue(expense.Description)
+		m.input(expenseFocusAmount).SetValue(expense.Amount.StringFixed(2))
+		if !expense.MarkupRate.IsZero() {
+			m.input(expenseFocusMarkup).SetValue(expense.MarkupRate.String())
+		}
+	} else {
+		m.input(expenseFocusDate).SetValue(time.Now().Format("2006-01-02"))
+	}
+
+	return m
+}
+
+// input returns the text input for the field at focus, or nil if the field
+// is not a text input.
+func (m *ExpenseFormModel) input(focus int) *textinput.Model {
+	if focus < expenseFocusDate || focus > expenseFocusReceipt {
+		return nil
+	}
+	return &m.inputs[focus-expenseFocusDate]
+}
+
+func (m ExpenseFormModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m ExpenseFormModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			return NewExpenseListModel(m.storage, m.config), func() tea.Msg { return BackToExpenseListMsg{} }
+		case "left", "right":
+			if m.focusIndex == expenseFocusClient {
+				if len(m.clients) > 0 {
+					if msg.String() == "left" {
+						m.clientIndex = (m.clientIndex + len(m.clients) - 1) % len(m.clients)
+					} else {
+						m.clientIndex = (m.clientIndex + 1) % len(m.clients)
+					}
+				}
+				return m, nil
+			}
+		case "tab", "shift+tab", "enter", "up", "down":
+			s := msg.String()
+
+			if s == "enter" && m.focusIndex == expenseFocusSave {
+				if err := m.saveExpense(); err != nil {
+					m.err = err
+					return m, nil
+				}
+				return NewExpenseListModel(m.storage, m.config), func() tea.Msg { return BackToExpenseListMsg{} }
+			}
+
+			if s == "up" || s == "shift+tab" {
+				m.focusIndex--
+			} else {
+				m.focusIndex++
+			}
+
+			if m.focusIndex >= expenseFocusCount {
+				m.focusIndex = 0
+			} else if m.focusIndex < 0 {
+				m.focusIndex = expenseFocusCount - 1
+			}
+
+			return m.updateFocus()
+		}
+	}
+
+	var cmd tea.Cmd
+	if input := m.input(m.focusIndex); input != nil {
+		*input, cmd = input.Update(msg)
+	}
+	return m, cmd
+}
+
+func (m ExpenseFormModel) updateFocus() (ExpenseFormModel, tea.Cmd) {
+	cmds := []tea.Cmd{}
+	for i := range m.inputs {
+		if i == m.focusIndex-expenseFocusDate {
+			cmds = append(cmds, m.inputs[i].Focus())
+			m.inputs[i].PromptStyle = formInputStyle
+			m.inputs[i].TextStyle = formInputStyle
+		} else {
+			m.inputs[i].Blur()
+			m.inputs[i].PromptStyle = dimStyle
+			m.inputs[i].TextStyle = dimStyle
+		}
+	}
+	return m, tea.Batch(cmds...)
+}
+
+func (m *ExpenseFormModel) saveExpense() error {
+	if len(m.clients) == 0 {
+		return errors.New("add a client before logging expenses")
+	}
+	client := m.clients[m.clientIndex]
+
+	date, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(m.input(expenseFocusDate).Value()), time.Local)
+	if err != nil {
+		return fmt.Errorf("invalid date: %v", err)
+	}
+	vendor := strings.TrimSpace(m.input(expenseFocusVendor).Value())
+	category := strings.TrimSpace(m.input(expenseFocusCategory).Value())
+	description := strings.TrimSpace(m.input(expenseFocusDescription).Value())
+
+	amount, err := decimal.NewFromString(strings.TrimSpace(m.input(expenseFocusAmount).Value()))
+	if err != nil {
+		return fmt.Errorf("invalid amount: %v", err)
+	}
+	markup := decimal.Zero
+	if value := strings.TrimSuffix(strings.TrimSpace(m.input(expenseFocusMarkup).Value()), "%"); value != "" {
+		if markup, err = decimal.NewFromString(value); err != nil {
+			return fmt.Errorf("invalid markup: %v", err)
+		}
+	}
+
+	expense := models.NewExpense(client.ID, client.Name, date, vendor, category, description, amount, markup)
+	if m.isEdit {
+		updated := *m.expense
+		updated.Update(client.ID, client.Name, date, vendor, category, description, amount, markup)
+		expense = &updated
+	}
+	if err := expense.Validate(); err != nil {
+		return err
+	}
+
+	previous := expense.ReceiptPath
+	if src := strings.TrimSpace(m.input(expenseFocusReceipt).Value()); src != "" {
+		receiptPath, err := storeReceipt(m.config, expense.ID, src)
+		if err != nil {
+			return err
+		}
+		expense.ReceiptPath = receiptPath
+	}
+
+	if m.isEdit {
+		err = m.storage.UpdateExpense(expense)
+	} else {
+		err = m.storage.SaveExpense(expense)
+	}
+	if err != nil {
+		if expense.ReceiptPath != previous {
+			removeReceipt(m.config, expense.ReceiptPath)
+		}
+		return err
+	}
+	if previous != "" && previous != expense.ReceiptPath {
+		removeReceipt(m.config, previous)
+	}
+	return nil
+}
+
+// storeReceipt copies the receipt at src into the receipts directory, named
+// after the expense, and returns its path relative to the data directory.
+func storeReceipt(cfg *config.Config, expenseID, src string) (string, error) {
+	if strings.HasPrefix(src, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		src = filepath.Join(home, src[2:])
+	}
+
+	ext := strings.ToLower(filepath.Ext(src))
+	if !receiptTypes[ext] {
+		return "", errors.New("receipts must be PDF, PNG or JPEG files")
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return "", fmt.Errorf("failed to open receipt: %w", err)
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(cfg.ReceiptsDir(), 0755); err != nil {
+		return "", fmt.Errorf("failed to create receipts directory: %w", err)
+	}
+	name := expenseID + ext
+	out, err := os.Create(filepath.Join(cfg.ReceiptsDir(), name))
+	if err != nil {
+		return "", fmt.Errorf("failed to copy receipt: %w", err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return "", fmt.Errorf("failed to copy receipt: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return "", fmt.Errorf("failed to copy receipt: %w", err)
+	}
+
+	rel, err := filepath.Rel(cfg.DataDir(), filepath.Join(cfg.ReceiptsDir(), name))
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// removeReceipt deletes a stored receipt. A receipt that is already gone is
+// not an error worth reporting.
+func removeReceipt(cfg *config.Config, receiptPath string) {
+	if receiptPath != "" {
+		os.Remove(filepath.Join(cfg.DataDir(), filepath.FromSlash(receiptPath)))
+	}
+}
+
+func (m ExpenseFormModel) View() string {
+	var s strings.Builder
+
+	title := "Log Expense"
+	if m.isEdit {
+		title = "Edit Expense"
+	}
+	s.WriteString(titleStyle.Render(title) + "\n\n")
+
+	if m.err != nil {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)) + "\n\n")
+	}
+
+	client := "< none >"
+	currency := models.DefaultCurrency
+	if len(m.clients) > 0 {
+		client = fmt.Sprintf("< %s >", m.clients[m.clientIndex].Name)
+		currency = m.clients[m.clientIndex].DefaultCurrency.Code()
+	}
+	if m.focusIndex == expenseFocusClient {
+		client = selectedStyle.Render(client)
+	}
+	s.WriteString(formLabelStyle.Render("Client:") + client + "\n")
+
+	fields := []struct {
+		focus int
+		label string
+	}{
+		{expenseFocusDate, "Date:"},
+		{expenseFocusVendor, "Vendor:"},
+		{expenseFocusCategory, "Category:"},
+		{expenseFocusDescription, "Description:"},
+		{expenseFocusAmount, fmt.Sprintf("Amount (%s):", currency)},
+		{expenseFocusMarkup, "Markup (%):"},
+		{expenseFocusReceipt, "Receipt:"},
+	}
+	for _, f := range fields {
+		s.WriteString(formLabelStyle.Render(f.label))
+		s.WriteString(m.input(f.focus).View() + "\n")
+	}
+	if m.isEdit && m.expense.HasReceipt() {
+		s.WriteString(dimStyle.Render(fmt.Sprintf("Stored receipt: %s. Enter a path to replace it.", m.expense.ReceiptPath)) + "\n")
+	} else {
+		s.WriteString(dimStyle.Render("Optional: a PDF, PNG or JPEG that is copied into the data directory.") + "\n")
+	}
+	s.WriteString("\n")
+
+	saveButton := "[ Save ]"
+	if m.focusIndex == expenseFocusSave {
+		saveButton = selectedStyle.Render(saveButton)
+	}
+	s.WriteString(saveButton)
+
+	s.WriteString("\n\n" + helpStyle.Render("tab/shift+tab navigate • ←/→ change client • enter select • esc cancel"))
+
+	return appStyle.Render(s.String())
+}
@@ -0,0 +1,221 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/models"
+)
+
+type expenseListMode int
+
+const (
+	expenseListModeView expenseListMode = iota
+	expenseListModeConfirmDelete
+)
+
+type ExpenseListModel struct {
+	expenses []models.Expense
+	// currencies holds each client's currency, which expense amounts are in
+	currencies map[string]models.CurrencyCode
+	cursor     int
+	storage    models.Storage
+	config     *config.Config
+	mode       expenseListMode
+	err        error
+}
+
+func NewExpenseListModel(storage models.Storage, cfg *config.Config) ExpenseListModel {
+	m := ExpenseListModel{
+		storage: storage,
+		config:  cfg,
+		mode:    expenseListModeView,
+	}
+	m.loadExpenses()
+	return m
+}
+
+func (m *ExpenseListModel) loadExpenses() {
+	expenses, err := m.storage.GetAllExpenses()
+	if err != nil {
+		m.err = err
+		return
+	}
+	clients, err := m.storage.GetAllClients()
+	if err != nil {
+		m.err = err
+		return
+	}
+	sort.SliceStable(expenses, func(i, j int) bool {
+		return expenses[i].Date.After(expenses[j].Date)
+	})
+	m.currencies = map[string]models.CurrencyCode{}
+	for _, client := range clients {
+		m.currencies[client.ID] = client.DefaultCurrency.Code()
+	}
+	m.expenses = expenses
+	m.err = nil
+}
+
+func (m ExpenseListModel) currency(e models.Expense) models.CurrencyCode {
+	return m.currencies[e.ClientID].Code()
+}
+
+func (m ExpenseListModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m ExpenseListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch m.mode {
+		case expenseListModeView:
+			switch msg.String() {
+			case "ctrl+c", "q":
+				return m, tea.Quit
+			case "esc":
+				return NewMainMenuModel(m.storage, m.config), nil
+			case "up", "k":
+				if m.cursor > 0 {
+					m.cursor--
+				}
+			case "down", "j":
+				if m.cursor < len(m.expenses)-1 {
+					m.cursor++
+				}
+			case "a":
+				return NewExpenseFormModel(m.storage, m.config, nil), nil
+			case "e", "enter":
+				if len(m.expenses) > 0 {
+					return NewExpenseFormModel(m.storage, m.config, &m.expenses[m.cursor]), nil
+				}
+			case "d":
+				if len(m.expenses) > 0 {
+					m.mode = expenseListModeConfirmDelete
+				}
+			}
+		case expenseListModeConfirmDelete:
+			switch msg.String() {
+			case "y":
+				expense := m.expenses[m.cursor]
+				if err := m.storage.DeleteExpense(expense.ID); err != nil {
+					m.err = err
+				} else {
+					removeReceipt(m.config, expense.ReceiptPath)
+					m.loadExpenses()
+				}
+				m.mode = expenseListModeView
+				if m.cursor >= len(m.expenses) && m.cursor > 0 {
+					m.cursor = len(m.expenses) - 1
+				}
+			case "n", "esc":
+				m.mode = expenseListModeView
+			}
+		}
+	case BackToExpenseListMsg:
+		m.loadExpenses()
+	}
+	return m, nil
+}
+
+func expenseStatus(e models.Expense) string {
+	if e.IsBilled() {
+		return "billed " + e.InvoiceNumber
+	}
+	return "unbilled"
+}
+
+func (m ExpenseListModel) View() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("Expenses") + "\n\n")
+
+	if m.err != nil {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)) + "\n\n")
+	}
+
+	if m.mode == expenseListModeConfirmDelete {
+		expense := m.expenses[m.cursor]
+		prompt := fmt.Sprintf("Delete the %s expense from %s on %s?",
+			m.currency(expense).Format(expense.Amount), expense.Vendor, expense.Date.Format("2006-01-02"))
+		if expense.HasReceipt() {
+			prompt += " Its receipt is deleted too."
+		}
+		s.WriteString(errorStyle.Render(prompt+" (y/n)") + "\n")
+		return appStyle.Render(s.String())
+	}
+
+	if len(m.expenses) == 0 {
+		s.WriteString(dimStyle.Render("No expenses logged. Press 'a' to log one.") + "\n")
+	} else {
+		headers := []string{"Date", "Client", "Vendor", "Category", "Amount", "Billed At", "Receipt", "Status"}
+		widths := []int{12, 18, 18, 12, 12, 12, 9, 16}
+
+		headerRow := ""
+		for i, h := range headers {
+			headerRow += tableCellStyle.Width(widths[i]).Render(h)
+		}
+		s.WriteString(tableHeaderStyle.Render(headerRow) + "\n")
+
+		for i, expense := range m.expenses {
+			currency := m.currency(expense)
+			receipt := ""
+			if expense.HasReceipt() {
+				receipt = "yes"
+			}
+			cells := []string{
+				expense.Date.Format("2006-01-02"),
+				truncate(expense.ClientName, widths[1]-2),
+				truncate(expense.Vendor, widths[2]-2),
+				truncate(expense.Category, widths[3]-2),
+				currency.Format(expense.Amount),
+				currency.Format(expense.BilledAmount(currency)),
+				receipt,
+				truncate(expenseStatus(expense), widths[7]-2),
+			}
+
+			row := ""
+			for j, cell := range cells {
+				style := tableCellStyle.Width(widths[j])
+				if i == m.cursor {
+					style = style.Inherit(selectedStyle)
+				}
+				row += style.Render(cell)
+			}
+
+			if i == m.cursor {
+				s.WriteString("> " + row + "\n")
+			} else {
+				s.WriteString("  " + row + "\n")
+			}
+		}
+	}
+
+	s.WriteString("\n" + helpStyle.Render("a add • e edit • d delete • ↑/k up • ↓/j down • esc back • q quit"))
+
+	return appStyle.Render(s.String())
+}
+
+// releaseBilledExpenses marks the expenses billed on a deleted invoice as
+// unbilled again, so that they can be invoiced anew.
+func releaseBilledExpenses(storage models.Storage, invoiceID string) error {
+	expenses, err := storage.GetAllExpenses()
+	if err != nil {
+		return err
+	}
+	for i := range expenses {
+		if expenses[i].InvoiceID != invoiceID {
+			continue
+		}
+		expenses[i].MarkBilled("", "")
+		if err := storage.UpdateExpense(&expenses[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type BackToExpenseListMsg struct{}
@@ -8,6 +8,7 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/user/invoicer/export"
 )
 
 type exportLocationMode int
@@ -22,11 +23,36 @@ type ExportLocationModel struct {
 	cursor       int
 	customInput  textinput.Model
 	selectedPath string
-	invoiceNumber string
+	title        string
+	fileName     string
+	// receipts is how many receipts can be appended to the PDF, which
+	// withReceipts switches on and off
+	receipts     int
+	withReceipts bool
+	// renderer is the PDF renderer, switched with r once WithRenderer has
+	// offered the choice
+	renderer     export.Renderer
+	pickRenderer bool
+	// template is the invoice template, switched with t among templates
+	// once WithTemplates has offered the choice
+	template     string
+	templates    []string
 	err          error
 }
 
-func NewExportLocationModel(invoiceNumber string) ExportLocationModel {
+// renderers are the choices r cycles through, with their descriptions.
+var renderers = []struct {
+	renderer export.Renderer
+	label    string
+}{
+	{export.RendererAuto, "as configured"},
+	{export.RendererLaTeX, "LaTeX"},
+	{export.RendererGo, "built-in"},
+}
+
+// NewExportLocationModel asks where to save fileName; title heads the screen,
+// e.g. "Export Invoice to PDF".
+func NewExportLocationModel(title, fileName string) ExportLocationModel {
 	input := textinput.New()
 	input.Placeholder = "Enter custom directory path..."
 	input.Width = 50
@@ -37,10 +63,43 @@ func NewExportLocationModel(invoiceNumber string) ExportLocationModel {
 		mode:          exportLocationModeSelect,
 		customInput:   input,
 		selectedPath:  cwd,
-		invoiceNumber: invoiceNumber,
+		title:         title,
+		fileName:      fileName,
 	}
 }
 
+// WithReceipts offers to append count receipts to the PDF, which is done
+// unless the user turns it off.
+func (m ExportLocationModel) WithReceipts(count int) ExportLocationModel {
+	m.receipts = count
+	m.withReceipts = count > 0
+	return m
+}
+
+// WithRenderer offers to choose the PDF renderer, starting from renderer.
+func (m ExportLocationModel) WithRenderer(renderer export.Renderer) ExportLocationModel {
+	m.renderer = renderer
+	m.pickRenderer = true
+	return m
+}
+
+// WithTemplates offers to choose among the invoice templates called names,
+// starting from selected.
+func (m ExportLocationModel) WithTemplates(names []string, selected string) ExportLocationModel {
+	m.templates = names
+	m.template = selected
+	return m
+}
+
+func (m ExportLocationModel) rendererIndex() int {
+	for i, r := range renderers {
+		if r.renderer == m.renderer {
+			return i
+		}
+	}
+	return 0
+}
+
 func (m ExportLocationModel) Init() tea.Cmd {
 	return nil
 }
@@ -72,6 +131,24 @@ func (m ExportLocationModel) updateSelect(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.cursor < 1 {
 				m.cursor++
 			}
+		case " ":
+			if m.receipts > 0 {
+				m.withReceipts = !m.withReceipts
+			}
+		case "r":
+			if m.pickRenderer {
+				m.renderer = renderers[(m.rendererIndex()+1)%len(renderers)].renderer
+			}
+		case "t":
+			if len(m.templates) > 0 {
+				next := 0
+				for i, name := range m.templates {
+					if name == m.template {
+						next = (i + 1) % len(m.templates)
+					}
+				}
+				m.template = m.templates[next]
+			}
 		case "enter":
 			if m.cursor == 0 {
 				// Current directory selected
@@ -81,7 +158,9 @@ func (m ExportLocationModel) updateSelect(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, nil
 				}
 				m.selectedPath = cwd
-				return m, func() tea.Msg { return ExportLocationSelectedMsg{Path: cwd} }
+				return m, func() tea.Msg {
+					return ExportLocationSelectedMsg{Path: cwd, AppendReceipts: m.withReceipts, Renderer: m.renderer, Template: m.template}
+				}
 			} else {
 				// Custom directory
 				m.mode = exportLocationModeCustomPath
@@ -138,7 +217,9 @@ func (m ExportLocationModel) updateCustomPath(msg tea.Msg) (tea.Model, tea.Cmd)
 			}
 			
 			m.selectedPath = path
-			return m, func() tea.Msg { return ExportLocationSelectedMsg{Path: path} }
+			return m, func() tea.Msg {
+				return ExportLocationSelectedMsg{Path: path, AppendReceipts: m.withReceipts, Renderer: m.renderer, Template: m.template}
+			}
 		}
 	}
 
@@ -151,9 +232,10 @@ func (m ExportLocationModel) updateCustomPath(msg tea.Msg) (tea.Model, tea.Cmd)
 func (m ExportLocationModel) View() string {
 	var s strings.Builder
 	
-	s.WriteString(titleStyle.Render("Export Invoice to PDF") + "\n")
+	s.WriteString(titleStyle.Render(m.title) + "\n")
 	s.WriteString(strings.Repeat("─", 40) + "\n")
-	s.WriteString("Where would you like to save the PDF?\n\n")
+	kind := strings.ToUpper(strings.TrimPrefix(filepath.Ext(m.fileName), "."))
+	s.WriteString(fmt.Sprintf("Where would you like to save the %s file?\n\n", kind))
 
 	switch m.mode {
 	case exportLocationModeSelect:
@@ -175,15 +257,41 @@ func (m ExportLocationModel) View() string {
 		}
 		
 		s.WriteString("\n")
-		fileName := fmt.Sprintf("invoice_%s.pdf", m.invoiceNumber)
-		previewPath := filepath.Join(m.selectedPath, fileName)
+		previewPath := filepath.Join(m.selectedPath, m.fileName)
 		s.WriteString(helpStyle.Render(fmt.Sprintf("File will be saved as: %s", previewPath)) + "\n")
 		
+		keys := "↑/k up • ↓/j down"
+		if m.pickRenderer {
+			s.WriteString(fmt.Sprintf("\nRenderer: %s\n", renderers[m.rendererIndex()].label))
+			keys += " • r renderer"
+		}
+		if len(m.templates) > 0 {
+			note := ""
+			if m.renderer == export.RendererGo {
+				note = " (not used by the built-in renderer)"
+			}
+			s.WriteString(fmt.Sprintf("Template: %s%s\n", m.template, note))
+			keys += " • t template"
+		}
+		help := keys + " • enter select • esc cancel"
+		if m.receipts > 0 {
+			check := "[ ]"
+			if m.withReceipts {
+				check = "[x]"
+			}
+			noun := "receipts"
+			if m.receipts == 1 {
+				noun = "receipt"
+			}
+			s.WriteString(fmt.Sprintf("\n%s Append %d expense %s\n", check, m.receipts, noun))
+			help = keys + " • space toggle receipts • enter select • esc cancel"
+		}
+		
 		if m.err != nil {
 			s.WriteString("\n" + errorStyle.Render(fmt.Sprintf("Error: %v", m.err)) + "\n")
 		}
 		
-		s.WriteString("\n" + helpStyle.Render("↑/k up • ↓/j down • enter select • esc cancel"))
+		s.WriteString("\n" + helpStyle.Render(help))
 		
 	case exportLocationModeCustomPath:
 		s.WriteString("Enter the directory path:\n\n")
@@ -195,8 +303,7 @@ func (m ExportLocationModel) View() string {
 				home, _ := os.UserHomeDir()
 				path = filepath.Join(home, path[2:])
 			}
-			fileName := fmt.Sprintf("invoice_%s.pdf", m.invoiceNumber)
-			previewPath := filepath.Join(path, fileName)
+			previewPath := filepath.Join(path, m.fileName)
 			s.WriteString(helpStyle.Render(fmt.Sprintf("File will be saved as: %s", previewPath)) + "\n")
 		}
 		
@@ -213,6 +320,12 @@ func (m ExportLocationModel) View() string {
 // Messages
 type ExportLocationSelectedMsg struct {
 	Path string
+	// AppendReceipts is set if receipts were offered and left switched on
+	AppendReceipts bool
+	// Renderer is the renderer chosen, if the choice was offered
+	Renderer export.Renderer
+	// Template is the invoice template chosen, if the choice was offered
+	Template string
 }
 
 type CancelExportMsg struct{}
\ No newline at end of file
@@ -1,15 +1,18 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/user/invoicer/audit"
 	"github.com/user/invoicer/config"
 	"github.com/user/invoicer/export"
 	"github.com/user/invoicer/models"
+	"github.com/user/invoicer/payments"
 )
 
 type invoiceDetailMode int
@@ -17,7 +20,12 @@ type invoiceDetailMode int
 const (
 	invoiceDetailModeView invoiceDetailMode = iota
 	invoiceDetailModeExportLocation
+	invoiceDetailModeExportHTML
+	invoiceDetailModeExportUBL
 	invoiceDetailModeStatusSelect
+	invoiceDetailModePayment
+	invoiceDetailModeCreditNote
+	invoiceDetailModeRemovePayment
 )
 
 type InvoiceDetailModel struct {
@@ -30,6 +38,10 @@ type InvoiceDetailModel struct {
 	mode            invoiceDetailMode
 	exportLocationModel ExportLocationModel
 	statusSelectModel   StatusSelectModel
+	paymentFormModel    PaymentFormModel
+	creditNoteFormModel CreditNoteFormModel
+	ledger              []models.Payment
+	ledgerCursor        int
 }
 
 func NewInvoiceDetailModel(storage models.Storage, cfg *config.Config, invoice *models.Invoice) InvoiceDetailModel {
@@ -50,10 +62,16 @@ func (m InvoiceDetailModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch m.mode {
 	case invoiceDetailModeView:
 		return m.updateView(msg)
-	case invoiceDetailModeExportLocation:
+	case invoiceDetailModeExportLocation, invoiceDetailModeExportHTML, invoiceDetailModeExportUBL:
 		return m.updateExportLocation(msg)
 	case invoiceDetailModeStatusSelect:
 		return m.updateStatusSelect(msg)
+	case invoiceDetailModePayment:
+		return m.updatePayment(msg)
+	case invoiceDetailModeCreditNote:
+		return m.updateCreditNote(msg)
+	case invoiceDetailModeRemovePayment:
+		return m.updateRemovePayment(msg)
 	}
 	return m, nil
 }
@@ -67,17 +85,110 @@ func (m InvoiceDetailModel) updateView(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "esc":
 			return NewInvoiceListModel(m.storage, m.config), func() tea.Msg { return BackToInvoiceListMsg{} }
 		case "e":
+			if err := checkEditable(m.invoice); err != nil {
+				m.message = err.Error()
+				m.isError = true
+				return m, nil
+			}
 			return NewInvoiceFormModel(m.storage, m.config, m.invoice), nil
 		case "p":
 			// Switch to export location mode
 			m.mode = invoiceDetailModeExportLocation
-			m.exportLocationModel = NewExportLocationModel(m.invoice.Number)
+			m.exportLocationModel = NewExportLocationModel("Export Invoice to PDF", fmt.Sprintf("invoice_%s.pdf", m.invoice.Number)).WithRenderer(export.RendererAuto)
+			if receipts, err := export.InvoiceReceipts(m.storage, m.config, m.invoice.ID); err == nil {
+				m.exportLocationModel = m.exportLocationModel.WithReceipts(len(receipts))
+			}
+			client, err := m.storage.GetClient(m.invoice.ClientID)
+			templates, listErr := export.InvoiceTemplates(m.config)
+			if err == nil && listErr == nil && len(templates) > 0 {
+				names := make([]string, len(templates))
+				for i, t := range templates {
+					names[i] = t.Name
+				}
+				m.exportLocationModel = m.exportLocationModel.WithTemplates(names, export.InvoiceTemplateName(m.config, client, ""))
+			}
+			return m, m.exportLocationModel.Init()
+		case "h":
+			m.mode = invoiceDetailModeExportHTML
+			m.exportLocationModel = NewExportLocationModel("Export Invoice to HTML", fmt.Sprintf("invoice_%s.html", m.invoice.Number))
+			return m, m.exportLocationModel.Init()
+		case "u":
+			// Report missing details before asking where to save
+			client, err := m.storage.GetClient(m.invoice.ClientID)
+			if err == nil {
+				err = export.ValidateUBL(m.invoice, client, m.config)
+			}
+			if err != nil {
+				m.message = err.Error()
+				m.isError = true
+				return m, nil
+			}
+			m.mode = invoiceDetailModeExportUBL
+			m.exportLocationModel = NewExportLocationModel("Export Invoice as E-invoice (UBL)", fmt.Sprintf("invoice_%s.xml", m.invoice.Number))
 			return m, m.exportLocationModel.Init()
 		case "s":
 			// Switch to status select mode
+			if len(models.AllowedTransitions(m.invoice.Status)) == 0 {
+				m.message = fmt.Sprintf("A %s invoice cannot change status", m.invoice.Status)
+				m.isError = true
+				return m, nil
+			}
 			m.mode = invoiceDetailModeStatusSelect
 			m.statusSelectModel = NewStatusSelectModel(m.invoice.Status)
 			return m, m.statusSelectModel.Init()
+		case "r":
+			// Switch to payment entry mode
+			if !m.invoice.AcceptsPayments() {
+				m.message = fmt.Sprintf("Cannot record a payment against a %s invoice", m.invoice.Status)
+				m.isError = true
+				return m, nil
+			}
+			if !m.invoice.BalanceDue().GreaterThan(models.DecimalZero) {
+				m.message = "Invoice has no balance due"
+				m.isError = true
+				return m, nil
+			}
+			m.mode = invoiceDetailModePayment
+			m.paymentFormModel = NewPaymentFormModel(m.invoice)
+			return m, m.paymentFormModel.Init()
+		case "x":
+			// Pick a payment to remove, e.g. one that bounced
+			ledger, err := m.storage.GetPayments(m.invoice.ID)
+			if err != nil {
+				m.message = fmt.Sprintf("Error loading payments: %v", err)
+				m.isError = true
+				return m, nil
+			}
+			if len(ledger) == 0 {
+				m.message = "No payments have been recorded"
+				m.isError = true
+				return m, nil
+			}
+			m.mode = invoiceDetailModeRemovePayment
+			m.ledger = ledger
+			m.ledgerCursor = 0
+			return m, nil
+		case "n":
+			// Switch to credit note entry mode
+			if !m.invoice.AcceptsPayments() {
+				m.message = fmt.Sprintf("Cannot issue a credit note against a %s invoice", m.invoice.Status)
+				m.isError = true
+				return m, nil
+			}
+			if !m.invoice.CreditableAmount().GreaterThan(models.DecimalZero) {
+				m.message = "Invoice has been credited in full"
+				m.isError = true
+				return m, nil
+			}
+			taxes, err := m.storage.GetAllTaxes()
+			if err != nil {
+				m.message = fmt.Sprintf("Error loading taxes: %v", err)
+				m.isError = true
+				return m, nil
+			}
+			m.mode = invoiceDetailModeCreditNote
+			m.creditNoteFormModel = NewCreditNoteFormModel(m.invoice, taxes)
+			return m, m.creditNoteFormModel.Init()
 		}
 	case tea.WindowSizeMsg:
 		m.width = msg.Width - 4
@@ -101,10 +212,60 @@ func (m InvoiceDetailModel) updateExportLocation(msg tea.Msg) (tea.Model, tea.Cm
 			return m, nil
 		}
 		
-		// Get template path
-		templatePath := filepath.Join(m.config.TemplatesDir(), "invoice.tex")
+		if m.mode == invoiceDetailModeExportHTML {
+			err = export.ExportInvoiceToHTML(m.invoice, client, m.config, msg.Path, filepath.Join(m.config.TemplatesDir(), "invoice.html"))
+			if err != nil {
+				m.message = fmt.Sprintf("Error exporting HTML: %v", err)
+				m.isError = true
+			} else {
+				m.message = fmt.Sprintf("Invoice exported to: %s", export.GetHTMLExportPath(m.invoice, msg.Path))
+				m.isError = false
+			}
+			m.mode = invoiceDetailModeView
+			return m, nil
+		}
+		
+		if m.mode == invoiceDetailModeExportUBL {
+			err = export.ExportInvoiceToUBL(m.invoice, client, m.config, msg.Path)
+			if err != nil {
+				m.message = fmt.Sprintf("Error exporting e-invoice: %v", err)
+				m.isError = true
+			} else {
+				m.message = fmt.Sprintf("Invoice exported to: %s", export.GetUBLExportPath(m.invoice, msg.Path))
+				m.isError = false
+			}
+			m.mode = invoiceDetailModeView
+			return m, nil
+		}
 		
-		err = export.ExportInvoiceToPDF(m.invoice, client, m.config, msg.Path, templatePath)
+		templatePath, err := export.InvoiceTemplatePath(m.config, export.InvoiceTemplateName(m.config, client, msg.Template))
+		if err != nil {
+			m.message = fmt.Sprintf("Error exporting PDF: %v", err)
+			m.isError = true
+			m.mode = invoiceDetailModeView
+			return m, nil
+		}
+		
+		var receipts []export.Receipt
+		if msg.AppendReceipts {
+			receipts, err = export.InvoiceReceipts(m.storage, m.config, m.invoice.ID)
+			if err != nil {
+				m.message = fmt.Sprintf("Error loading receipts: %v", err)
+				m.isError = true
+				m.mode = invoiceDetailModeView
+				return m, nil
+			}
+		}
+		
+		timesheet, err := export.InvoiceTimeEntries(m.storage, m.invoice.ID)
+		if err != nil {
+			m.message = fmt.Sprintf("Error loading time entries: %v", err)
+			m.isError = true
+			m.mode = invoiceDetailModeView
+			return m, nil
+		}
+		
+		err = export.ExportInvoiceToPDF(m.invoice, client, m.config, msg.Path, templatePath, receipts, timesheet, msg.Renderer)
 		if err != nil {
 			m.message = fmt.Sprintf("Error exporting PDF: %v", err)
 			m.isError = true
@@ -133,57 +294,199 @@ func (m InvoiceDetailModel) updateExportLocation(msg tea.Msg) (tea.Model, tea.Cm
 func (m InvoiceDetailModel) updateStatusSelect(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case StatusSelectedMsg:
-		// Update invoice status
+		// Update invoice status, save it and log the change
 		oldStatus := m.invoice.Status
-		err := m.invoice.UpdateStatus(msg.Status, msg.Reason)
+		err := audit.NewService(m.storage).ChangeStatus(m.invoice, msg.Status, msg.Reason, models.ChangedBySystem)
+		m.mode = invoiceDetailModeView
 		if err != nil {
-			m.message = fmt.Sprintf("Error updating status: %v", err)
 			m.isError = true
-			m.mode = invoiceDetailModeView
+			if m.invoice.Status == msg.Status {
+				// Saved, but the audit entry could not be written
+				m.message = fmt.Sprintf("Warning: %v", err)
+				return m, nil
+			}
+			m.message = fmt.Sprintf("Error updating status: %v", err)
+			if errors.Is(err, models.ErrConflict) {
+				// Someone else changed the invoice; show their version
+				if latest, loadErr := m.storage.GetInvoice(m.invoice.ID); loadErr == nil {
+					m.invoice = latest
+				}
+			}
 			return m, nil
 		}
 		
-		// Save the updated invoice
-		err = m.storage.UpdateInvoice(m.invoice)
+		m.message = fmt.Sprintf("Status changed from %s to %s", oldStatus, msg.Status)
+		m.isError = false
+		return m, nil
+		
+	case CancelStatusChangeMsg:
+		// Cancel status change and go back to view mode
+		m.mode = invoiceDetailModeView
+		return m, nil
+		
+	default:
+		// Pass through to status select model
+		var cmd tea.Cmd
+		model, cmd := m.statusSelectModel.Update(msg)
+		m.statusSelectModel = model.(StatusSelectModel)
+		return m, cmd
+	}
+}
+
+func (m InvoiceDetailModel) updatePayment(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case PaymentEnteredMsg:
+		changed, err := payments.NewService(m.storage).RecordPayment(m.invoice, msg.Payment)
+		if err != nil && !changed {
+			if errors.Is(err, models.ErrConflict) {
+				if latest, loadErr := m.storage.GetInvoice(m.invoice.ID); loadErr == nil {
+					m.invoice = latest
+				}
+				m.message = fmt.Sprintf("Error recording payment: %v", err)
+				m.isError = true
+				m.mode = invoiceDetailModeView
+				return m, nil
+			}
+			// Let the user correct the amount or date
+			m.paymentFormModel.err = err
+			return m, nil
+		}
+
 		if err != nil {
-			m.message = fmt.Sprintf("Error saving invoice: %v", err)
+			m.message = fmt.Sprintf("Payment recorded but %v", err)
 			m.isError = true
-			m.mode = invoiceDetailModeView
+		} else {
+			m.message = fmt.Sprintf("Recorded payment of %s", m.invoice.Currency.Format(msg.Payment.Amount))
+			if changed {
+				m.message += " - invoice is now paid"
+			}
+			m.isError = false
+		}
+		m.mode = invoiceDetailModeView
+		return m, nil
+
+	case CancelPaymentMsg:
+		m.mode = invoiceDetailModeView
+		return m, nil
+
+	default:
+		var cmd tea.Cmd
+		model, cmd := m.paymentFormModel.Update(msg)
+		m.paymentFormModel = model.(PaymentFormModel)
+		return m, cmd
+	}
+}
+
+func (m InvoiceDetailModel) updateRemovePayment(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "esc", "n":
+		m.mode = invoiceDetailModeView
+	case "up", "k":
+		if m.ledgerCursor > 0 {
+			m.ledgerCursor--
+		}
+	case "down", "j":
+		if m.ledgerCursor < len(m.ledger)-1 {
+			m.ledgerCursor++
+		}
+	case "enter", "y":
+		payment := m.ledger[m.ledgerCursor]
+		changed, err := payments.NewService(m.storage).RemovePayment(m.invoice, payment.ID)
+		if errors.Is(err, models.ErrConflict) {
+			if latest, loadErr := m.storage.GetInvoice(m.invoice.ID); loadErr == nil {
+				m.invoice = latest
+			}
+		}
+		switch {
+		case err != nil && !changed:
+			m.message = fmt.Sprintf("Error removing payment: %v", err)
+			m.isError = true
+		case err != nil:
+			m.message = fmt.Sprintf("Payment removed but %v", err)
+			m.isError = true
+		default:
+			m.message = fmt.Sprintf("Removed payment of %s", m.invoice.Currency.Format(payment.Amount))
+			if changed {
+				m.message += fmt.Sprintf(" - invoice is now %s", m.invoice.Status)
+			}
+			m.isError = false
+		}
+		m.mode = invoiceDetailModeView
+	}
+	return m, nil
+}
+
+func (m InvoiceDetailModel) viewRemovePayment() string {
+	var s strings.Builder
+	s.WriteString(titleStyle.Render("Remove Payment from Invoice "+m.invoice.Number) + "\n\n")
+	for i, payment := range m.ledger {
+		line := fmt.Sprintf("%s  %-15s %12s", payment.Date.Format("Jan 2, 2006"), truncate(payment.Method, 15), m.invoice.Currency.Format(payment.Amount))
+		if payment.Reference != "" {
+			line += "  ref " + payment.Reference
+		}
+		if i == m.ledgerCursor {
+			s.WriteString("> " + selectedStyle.Render(line) + "\n")
+		} else {
+			s.WriteString("  " + line + "\n")
+		}
+	}
+	s.WriteString("\n" + helpStyle.Render("↑/↓ select • enter remove payment • esc cancel"))
+	return appStyle.Render(s.String())
+}
+
+func (m InvoiceDetailModel) updateCreditNote(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case CreditNoteEnteredMsg:
+		year := msg.Note.Date.Year()
+		seq, err := m.storage.GetNextCreditNoteNumber(year)
+		if err != nil {
+			m.creditNoteFormModel.err = fmt.Errorf("failed to get next credit note number: %w", err)
 			return m, nil
 		}
-		
-		// Log the status change in audit
-		entry := models.NewAuditEntry(
-			m.invoice.ID,
-			m.invoice.Number,
-			oldStatus,
-			m.invoice.Status,
-			msg.Reason,
-		)
-		
-		err = m.storage.SaveAuditEntry(entry)
+		msg.Note.Number = models.GenerateCreditNoteNumber(year, seq)
+
+		changed, err := payments.NewService(m.storage).IssueCreditNote(m.invoice, msg.Note)
+		if err != nil && !changed {
+			if errors.Is(err, models.ErrConflict) {
+				if latest, loadErr := m.storage.GetInvoice(m.invoice.ID); loadErr == nil {
+					m.invoice = latest
+				}
+				m.message = fmt.Sprintf("Error issuing credit note: %v", err)
+				m.isError = true
+				m.mode = invoiceDetailModeView
+				return m, nil
+			}
+			m.creditNoteFormModel.err = err
+			return m, nil
+		}
+
 		if err != nil {
-			// Log error but don't fail the operation
-			m.message = fmt.Sprintf("Status updated but audit log failed: %v", err)
+			m.message = fmt.Sprintf("Credit note %s issued but %v", msg.Note.Number, err)
 			m.isError = true
 		} else {
-			m.message = fmt.Sprintf("Status changed from %s to %s", oldStatus, msg.Status)
+			m.message = fmt.Sprintf("Issued credit note %s for %s", msg.Note.Number, msg.Note.Currency.Format(msg.Note.Amount()))
+			if changed {
+				m.message += " - invoice is now paid"
+			}
 			m.isError = false
 		}
-		
 		m.mode = invoiceDetailModeView
 		return m, nil
-		
-	case CancelStatusChangeMsg:
-		// Cancel status change and go back to view mode
+
+	case CancelCreditNoteMsg:
 		m.mode = invoiceDetailModeView
 		return m, nil
-		
+
 	default:
-		// Pass through to status select model
 		var cmd tea.Cmd
-		model, cmd := m.statusSelectModel.Update(msg)
-		m.statusSelectModel = model.(StatusSelectModel)
+		model, cmd := m.creditNoteFormModel.Update(msg)
+		m.creditNoteFormModel = model.(CreditNoteFormModel)
 		return m, cmd
 	}
 }
@@ -192,10 +495,16 @@ func (m InvoiceDetailModel) View() string {
 	switch m.mode {
 	case invoiceDetailModeView:
 		return m.viewDetail()
-	case invoiceDetailModeExportLocation:
+	case invoiceDetailModeExportLocation, invoiceDetailModeExportHTML, invoiceDetailModeExportUBL:
 		return m.exportLocationModel.View()
 	case invoiceDetailModeStatusSelect:
 		return m.statusSelectModel.View()
+	case invoiceDetailModePayment:
+		return m.paymentFormModel.View()
+	case invoiceDetailModeCreditNote:
+		return m.creditNoteFormModel.View()
+	case invoiceDetailModeRemovePayment:
+		return m.viewRemovePayment()
 	}
 	return ""
 }
@@ -223,6 +532,15 @@ func (m InvoiceDetailModel) viewDetail() string {
 		leftCol = append(leftCol, formLabelStyle.Render("Service Period:") + " " + servicePeriod)
 	}
 	
+	// Link back to the estimate this invoice was converted from
+	if m.invoice.EstimateID != "" {
+		estimateRef := m.invoice.EstimateID
+		if estimate, err := m.storage.GetEstimate(m.invoice.EstimateID); err == nil {
+			estimateRef = estimate.Number
+		}
+		leftCol = append(leftCol, formLabelStyle.Render("Estimate:") + " " + estimateRef)
+	}
+	
 	statusStyle := normalStyle
 	switch m.invoice.Status {
 	case models.StatusDraft:
@@ -241,8 +559,8 @@ func (m InvoiceDetailModel) viewDetail() string {
 		"",
 	}
 	
-	// Add empty entry if service period exists to align with left column
-	if m.invoice.ServiceStartDate != nil && m.invoice.ServiceEndDate != nil {
+	// Pad to align with the extra rows in the left column
+	for len(rightCol) < len(leftCol) {
 		rightCol = append(rightCol, "")
 	}
 	
@@ -296,10 +614,10 @@ func (m InvoiceDetailModel) viewDetail() string {
 		// Render line items with alternating background
 		for idx, item := range m.invoice.LineItems {
 			cells := []string{
-				truncate(item.Description, widths[0]-4),
+				truncate(describeLineItem(item, m.invoice.Currency), widths[0]-4),
 				fmt.Sprintf("%.2f", item.Quantity.InexactFloat64()),
-				fmt.Sprintf("$%.2f", item.UnitPrice.InexactFloat64()),
-				fmt.Sprintf("$%.2f", item.Total.InexactFloat64()),
+				m.invoice.Currency.Format(item.UnitPrice),
+				m.invoice.Currency.Format(item.Total),
 			}
 			
 			row := ""
@@ -321,12 +639,12 @@ func (m InvoiceDetailModel) viewDetail() string {
 	s.WriteString(strings.Repeat("─", m.width) + "\n")
 	
 	// Create a summary section with better formatting
-	summaryWidth := 30
+	summaryWidth := 40
 	summaryOffset := m.width - summaryWidth
 	
 	// Helper function to format summary lines
 	formatSummaryLine := func(label, amount string, isBold bool) string {
-		labelStyle := lipgloss.NewStyle().Width(15).Align(lipgloss.Right)
+		labelStyle := lipgloss.NewStyle().Width(25).Align(lipgloss.Right)
 		amountStyle := lipgloss.NewStyle().Width(15).Align(lipgloss.Right)
 		if isBold {
 			labelStyle = labelStyle.Bold(true)
@@ -337,20 +655,20 @@ func (m InvoiceDetailModel) viewDetail() string {
 			amountStyle.Render(amount)
 	}
 	
-	s.WriteString(formatSummaryLine("Subtotal:", fmt.Sprintf("$%.2f", m.invoice.Subtotal.InexactFloat64()), false) + "\n")
+	s.WriteString(formatSummaryLine("Subtotal:", m.invoice.Currency.Format(m.invoice.Subtotal), false) + "\n")
 	
-	if m.invoice.DiscountRate.GreaterThan(models.DecimalZero) {
+	if !m.invoice.Discount.IsZero() {
 		s.WriteString(formatSummaryLine(
-			fmt.Sprintf("Discount (%.1f%%):", m.invoice.DiscountRate.InexactFloat64()),
-			fmt.Sprintf("-$%.2f", m.invoice.Discount.InexactFloat64()),
+			discountLabel(m.invoice.DiscountRate, m.invoice.FixedDiscount, m.invoice.Currency)+":",
+			m.invoice.Currency.Format(m.invoice.Discount.Neg()),
 			false,
 		) + "\n")
 	}
 	
-	if m.invoice.TaxRate.GreaterThan(models.DecimalZero) {
+	for _, t := range m.invoice.TaxBreakdown() {
 		s.WriteString(formatSummaryLine(
-			fmt.Sprintf("Tax (%.1f%%):", m.invoice.TaxRate.InexactFloat64()),
-			fmt.Sprintf("$%.2f", m.invoice.Tax.InexactFloat64()),
+			truncate(taxLabel(t), 24)+":",
+			m.invoice.Currency.Format(t.Amount),
 			false,
 		) + "\n")
 	}
@@ -358,7 +676,19 @@ func (m InvoiceDetailModel) viewDetail() string {
 	// Add a separator before total
 	s.WriteString(strings.Repeat(" ", summaryOffset) + dimStyle.Render(strings.Repeat("─", summaryWidth)) + "\n")
 	
-	s.WriteString(formatSummaryLine("Total:", fmt.Sprintf("$%.2f", m.invoice.Total.InexactFloat64()), true) + "\n")
+	s.WriteString(formatSummaryLine("Total:", m.invoice.Currency.Format(m.invoice.Total), true) + "\n")
+	
+	if m.invoice.AmountCredited.GreaterThan(models.DecimalZero) {
+		s.WriteString(formatSummaryLine("Credited:", m.invoice.Currency.Format(m.invoice.AmountCredited.Neg()), false) + "\n")
+	}
+	
+	if m.invoice.AmountPaid.GreaterThan(models.DecimalZero) {
+		s.WriteString(formatSummaryLine("Paid:", m.invoice.Currency.Format(m.invoice.AmountPaid.Neg()), false) + "\n")
+	}
+	
+	if m.invoice.AmountPaid.GreaterThan(models.DecimalZero) || m.invoice.AmountCredited.GreaterThan(models.DecimalZero) {
+		s.WriteString(formatSummaryLine("Balance Due:", m.invoice.Currency.Format(m.invoice.BalanceDue()), true) + "\n")
+	}
 	
 	if m.message != "" {
 		s.WriteString("\n")
@@ -369,10 +699,40 @@ func (m InvoiceDetailModel) viewDetail() string {
 		}
 	}
 	
+	// Show payment ledger
+	payments, err := m.storage.GetPayments(m.invoice.ID)
+	if err == nil && len(payments) > 0 {
+		s.WriteString("\n\n" + subtitleStyle.Render("Payments") + "\n")
+		s.WriteString(strings.Repeat("─", m.width) + "\n")
+		
+		for _, payment := range payments {
+			line := fmt.Sprintf("%s  %-15s %12s", payment.Date.Format("Jan 2, 2006"), truncate(payment.Method, 15), m.invoice.Currency.Format(payment.Amount))
+			if payment.Reference != "" {
+				line += "  " + dimStyle.Render("ref "+payment.Reference)
+			}
+			s.WriteString(line + "\n")
+			if payment.Note != "" {
+				s.WriteString("  " + dimStyle.Render(payment.Note) + "\n")
+			}
+		}
+	}
+	
+	// Show credit notes
+	creditNotes, err := m.storage.GetCreditNotes(m.invoice.ID)
+	if err == nil && len(creditNotes) > 0 {
+		s.WriteString("\n\n" + subtitleStyle.Render("Credit Notes") + "\n")
+		s.WriteString(strings.Repeat("─", m.width) + "\n")
+		
+		for _, note := range creditNotes {
+			s.WriteString(fmt.Sprintf("%s  %-12s %12s", note.Date.Format("Jan 2, 2006"), note.Number, m.invoice.Currency.Format(note.Amount().Neg())) + "\n")
+			s.WriteString("  " + dimStyle.Render(note.Reason) + "\n")
+		}
+	}
+	
 	// Show audit history
 	auditEntries, err := m.storage.GetAuditEntries(m.invoice.ID)
 	if err == nil && len(auditEntries) > 0 {
-		s.WriteString("\n\n" + subtitleStyle.Render("Status History") + "\n")
+		s.WriteString("\n\n" + subtitleStyle.Render("History") + "\n")
 		s.WriteString(strings.Repeat("─", m.width) + "\n")
 		
 		for i, entry := range auditEntries {
@@ -382,6 +742,10 @@ func (m InvoiceDetailModel) viewDetail() string {
 			
 			timeStr := entry.ChangedAt.Format("Jan 2, 2006 3:04 PM")
 			s.WriteString(formLabelStyle.Render("Date:") + " " + timeStr + "\n")
+			if !entry.IsStatusChange() {
+				s.WriteString(formLabelStyle.Render("Credited:") + " " + entry.Reason + "\n")
+				continue
+			}
 			s.WriteString(formLabelStyle.Render("Changed:") + " " + 
 				getStatusStyle(entry.OldStatus).Render(string(entry.OldStatus)) + 
 				" → " + 
@@ -393,7 +757,7 @@ func (m InvoiceDetailModel) viewDetail() string {
 		}
 	}
 	
-	s.WriteString("\n" + helpStyle.Render("e edit invoice • s change status • p export PDF • esc back • q quit"))
+	s.WriteString("\n" + helpStyle.Render("e edit invoice • s change status • r record payment • x remove payment • n credit note • p export PDF • h export HTML • u export e-invoice • esc back • q quit"))
 	
 	return appStyle.Render(s.String())
 }
\ No newline at end of file
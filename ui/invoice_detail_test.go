@@ -0,0 +1,33 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/models"
+	"github.com/user/invoicer/storage"
+)
+
+func TestOnlyDraftsOpenTheForm(t *testing.T) {
+	store, err := storage.NewJSONStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range []models.InvoiceStatus{models.StatusDraft, models.StatusSent, models.StatusPaid, models.StatusVoid} {
+		invoice := models.NewInvoice("c1", "Acme", "2026-01")
+		invoice.Status = status
+		model, _ := NewInvoiceDetailModel(store, &config.Config{}, invoice).Update(runes("e")[0])
+		_, editing := model.(InvoiceFormModel)
+		if editing != (status == models.StatusDraft) {
+			t.Errorf("e on a %s invoice opened the form: %v", status, editing)
+		}
+	}
+
+	// A stale form cannot save over an issued invoice either
+	invoice := models.NewInvoice("c1", "Acme", "2026-01")
+	form := NewInvoiceFormModel(store, &config.Config{}, invoice)
+	invoice.Status = models.StatusSent
+	if _, _ = form.saveInvoice(); form.err == nil {
+		t.Error("saved a sent invoice from the form")
+	}
+}
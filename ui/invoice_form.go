@@ -32,12 +32,15 @@ type InvoiceFormModel struct {
 	
 	clients            []models.Client
 	clientCursor       int
+	taxes              []models.Tax
+	catalog            []models.CatalogItem
 	
 	discountInput      textinput.Model
 	taxInput           textinput.Model
 	dueDaysInput       textinput.Model
 	serviceStartInput  textinput.Model
 	serviceEndInput    textinput.Model
+	currencyInput      textinput.Model
 	basicInputs        []textinput.Model
 	basicFocusIndex    int
 	
@@ -45,14 +48,20 @@ type InvoiceFormModel struct {
 	lineItemFocusIndex int
 	lineItemCursor     int
 	editingLineItemID  string
+	// timeLines maps line items added from time entries to the entries they
+	// bill, which are marked as billed once the invoice is saved
+	timeLines          map[string][]string
+	// expenseLines maps line items added from expenses to the expense each
+	// bills, in the same way
+	expenseLines       map[string]string
 	
 	err                error
 }
 
 func NewInvoiceFormModel(storage models.Storage, cfg *config.Config, invoice *models.Invoice) InvoiceFormModel {
 	discountInput := textinput.New()
-	discountInput.Placeholder = "0"
-	discountInput.Width = 10
+	discountInput.Placeholder = discountPlaceholder
+	discountInput.Width = 15
 	
 	taxInput := textinput.New()
 	taxInput.Placeholder = "0"
@@ -70,6 +79,10 @@ func NewInvoiceFormModel(storage models.Storage, cfg *config.Config, invoice *mo
 	serviceEndInput.Placeholder = "YYYY-MM-DD"
 	serviceEndInput.Width = 15
 	
+	currencyInput := textinput.New()
+	currencyInput.Placeholder = string(models.DefaultCurrency)
+	currencyInput.Width = 10
+	
 	m := InvoiceFormModel{
 		storage:           storage,
 		config:            cfg,
@@ -81,11 +94,14 @@ func NewInvoiceFormModel(storage models.Storage, cfg *config.Config, invoice *mo
 		dueDaysInput:      dueDaysInput,
 		serviceStartInput: serviceStartInput,
 		serviceEndInput:   serviceEndInput,
-		basicInputs:       []textinput.Model{discountInput, taxInput, dueDaysInput, serviceStartInput, serviceEndInput},
+		currencyInput:     currencyInput,
+		timeLines:         map[string][]string{},
+		expenseLines:      map[string]string{},
+		basicInputs:       []textinput.Model{discountInput, taxInput, dueDaysInput, serviceStartInput, serviceEndInput, currencyInput},
 	}
 	
 	if m.isEdit {
-		m.discountInput.SetValue(fmt.Sprintf("%.1f", invoice.DiscountRate.InexactFloat64()))
+		m.discountInput.SetValue(formatDiscountInput(invoice.DiscountRate, invoice.FixedDiscount))
 		m.taxInput.SetValue(fmt.Sprintf("%.1f", invoice.TaxRate.InexactFloat64()))
 		
 		dueDays := int(invoice.DueDate.Sub(invoice.Date).Hours() / 24)
@@ -97,6 +113,7 @@ func NewInvoiceFormModel(storage models.Storage, cfg *config.Config, invoice *mo
 		if invoice.ServiceEndDate != nil {
 			m.serviceEndInput.SetValue(invoice.ServiceEndDate.Format("2006-01-02"))
 		}
+		m.currencyInput.SetValue(string(invoice.Currency.Code()))
 	} else {
 		clients, _ := storage.GetAllClients()
 		m.clients = clients
@@ -107,6 +124,7 @@ func NewInvoiceFormModel(storage models.Storage, cfg *config.Config, invoice *mo
 		
 		if len(clients) > 0 {
 			m.invoice = models.NewInvoice(clients[0].ID, clients[0].Name, number)
+			m.setClientCurrency(&clients[0])
 			// Set default service dates in the inputs
 			if m.invoice.ServiceStartDate != nil {
 				m.serviceStartInput.SetValue(m.invoice.ServiceStartDate.Format("2006-01-02"))
@@ -117,15 +135,26 @@ func NewInvoiceFormModel(storage models.Storage, cfg *config.Config, invoice *mo
 		}
 	}
 	
+	m.basicInputs = []textinput.Model{m.discountInput, m.taxInput, m.dueDaysInput, m.serviceStartInput, m.serviceEndInput, m.currencyInput}
+	m.taxes, _ = storage.GetAllTaxes()
+	m.catalog, _ = storage.GetAllCatalogItems()
 	m.setupLineItemInputs()
 	return m
 }
 
+// setClientCurrency bills the invoice in client's default currency.
+func (m *InvoiceFormModel) setClientCurrency(client *models.Client) {
+	m.invoice.Currency = client.DefaultCurrency.Code()
+	m.currencyInput.SetValue(string(m.invoice.Currency))
+	m.basicInputs[5] = m.currencyInput
+}
+
 func (m *InvoiceFormModel) setupLineItemInputs() {
 	descInput := textinput.New()
 	descInput.Placeholder = "Description"
 	descInput.Width = 40
 	descInput.Focus()
+	enableCatalogSuggestions(&descInput, m.catalog)
 	
 	qtyInput := textinput.New()
 	qtyInput.Placeholder = "1"
@@ -135,7 +164,15 @@ func (m *InvoiceFormModel) setupLineItemInputs() {
 	priceInput.Placeholder = "0.00"
 	priceInput.Width = 10
 	
-	m.lineItemInputs = []textinput.Model{descInput, qtyInput, priceInput}
+	taxesInput := textinput.New()
+	taxesInput.Placeholder = lineTaxesPlaceholder(m.taxes)
+	taxesInput.Width = 40
+	
+	discountInput := textinput.New()
+	discountInput.Placeholder = discountPlaceholder
+	discountInput.Width = 15
+	
+	m.lineItemInputs = []textinput.Model{descInput, qtyInput, priceInput, taxesInput, discountInput}
 }
 
 func (m *InvoiceFormModel) setupEditLineItemInputs(index int) {
@@ -150,6 +187,7 @@ func (m *InvoiceFormModel) setupEditLineItemInputs(index int) {
 	descInput.Width = 40
 	descInput.SetValue(item.Description)
 	descInput.Focus()
+	enableCatalogSuggestions(&descInput, m.catalog)
 	
 	qtyInput := textinput.New()
 	qtyInput.Placeholder = "1"
@@ -161,7 +199,17 @@ func (m *InvoiceFormModel) setupEditLineItemInputs(index int) {
 	priceInput.Width = 10
 	priceInput.SetValue(fmt.Sprintf("%.2f", item.UnitPrice.InexactFloat64()))
 	
-	m.lineItemInputs = []textinput.Model{descInput, qtyInput, priceInput}
+	taxesInput := textinput.New()
+	taxesInput.Placeholder = lineTaxesPlaceholder(m.taxes)
+	taxesInput.Width = 40
+	taxesInput.SetValue(models.TaxNames(item.Taxes))
+	
+	discountInput := textinput.New()
+	discountInput.Placeholder = discountPlaceholder
+	discountInput.Width = 15
+	discountInput.SetValue(formatDiscountInput(item.DiscountRate, item.FixedDiscount))
+	
+	m.lineItemInputs = []textinput.Model{descInput, qtyInput, priceInput, taxesInput, discountInput}
 	m.lineItemFocusIndex = 0
 }
 
@@ -251,8 +299,13 @@ func (m InvoiceFormModel) updateSelectClientMode(msg tea.KeyMsg) (tea.Model, tea
 	case "enter":
 		if len(m.clients) > 0 {
 			client := m.clients[m.clientCursor]
+			if client.ID != m.invoice.ClientID {
+				m.dropUnbilledTime()
+				m.dropUnbilledExpenses()
+			}
 			m.invoice.ClientID = client.ID
 			m.invoice.ClientName = client.Name
+			m.setClientCurrency(&client)
 		}
 		m.mode = invoiceFormModeEditBasic
 		return m, nil
@@ -293,12 +346,155 @@ func (m InvoiceFormModel) updateLineItemsMode(msg tea.KeyMsg) (tea.Model, tea.Cm
 		if m.lineItemCursor < len(m.invoice.LineItems)-1 {
 			m.lineItemCursor++
 		}
+	case "t":
+		if err := m.addUnbilledTime(); err != nil {
+			m.err = err
+		} else {
+			m.err = nil
+		}
+	case "x":
+		if err := m.addUnbilledExpenses(); err != nil {
+			m.err = err
+		} else {
+			m.err = nil
+		}
 	case "s":
 		return m.saveInvoice()
 	}
 	return m, nil
 }
 
+// addUnbilledTime adds the client's billable time in the service period
+// that has not been invoiced yet, one line item per project, priced at the
+// client's hourly rate.
+func (m *InvoiceFormModel) addUnbilledTime() error {
+	client, err := m.storage.GetClient(m.invoice.ClientID)
+	if err != nil {
+		return err
+	}
+	entries, err := m.storage.GetAllTimeEntries()
+	if err != nil {
+		return err
+	}
+	
+	// Leave out time already added to this invoice
+	added := map[string]bool{}
+	for _, ids := range m.timeLines {
+		for _, id := range ids {
+			added[id] = true
+		}
+	}
+	var unbilled []models.TimeEntry
+	for _, e := range models.UnbilledTime(entries, client.ID, m.invoice.ServiceStartDate, m.invoice.ServiceEndDate) {
+		if !added[e.ID] {
+			unbilled = append(unbilled, e)
+		}
+	}
+	if len(unbilled) == 0 {
+		return fmt.Errorf("no unbilled time for %s in the service period", client.Name)
+	}
+	
+	for _, line := range models.BillTime(unbilled, client.DefaultHourlyRate) {
+		m.invoice.AddLineItem(line.Item)
+		m.timeLines[line.Item.ID] = line.EntryIDs
+	}
+	m.lineItemCursor = len(m.invoice.LineItems) - 1
+	return nil
+}
+
+// dropUnbilledTime removes the line items added from time entries, e.g.
+// when the invoice's client changes.
+func (m *InvoiceFormModel) dropUnbilledTime() {
+	for id := range m.timeLines {
+		m.invoice.RemoveLineItem(id)
+		delete(m.timeLines, id)
+	}
+	m.lineItemCursor = 0
+}
+
+// markTimeBilled marks the time entries behind the invoice's line items as
+// billed. Entries whose line was deleted stay unbilled.
+func (m *InvoiceFormModel) markTimeBilled() error {
+	for _, item := range m.invoice.LineItems {
+		for _, id := range m.timeLines[item.ID] {
+			entry, err := m.storage.GetTimeEntry(id)
+			if err != nil {
+				return err
+			}
+			entry.MarkBilled(m.invoice.ID, m.invoice.Number)
+			if err := m.storage.UpdateTimeEntry(entry); err != nil {
+				return err
+			}
+		}
+		delete(m.timeLines, item.ID)
+	}
+	return nil
+}
+
+// addUnbilledExpenses adds the client's expenses in the service period that
+// have not been invoiced yet, one line item each at the marked-up amount.
+func (m *InvoiceFormModel) addUnbilledExpenses() error {
+	client, err := m.storage.GetClient(m.invoice.ClientID)
+	if err != nil {
+		return err
+	}
+	expenses, err := m.storage.GetAllExpenses()
+	if err != nil {
+		return err
+	}
+	
+	// Leave out expenses already added to this invoice
+	added := map[string]bool{}
+	for _, id := range m.expenseLines {
+		added[id] = true
+	}
+	count := 0
+	for _, e := range models.UnbilledExpenses(expenses, client.ID, m.invoice.ServiceStartDate, m.invoice.ServiceEndDate) {
+		if added[e.ID] {
+			continue
+		}
+		item := e.LineItem(m.invoice.Currency.Code())
+		m.invoice.AddLineItem(*item)
+		m.expenseLines[item.ID] = e.ID
+		count++
+	}
+	if count == 0 {
+		return fmt.Errorf("no unbilled expenses for %s in the service period", client.Name)
+	}
+	m.lineItemCursor = len(m.invoice.LineItems) - 1
+	return nil
+}
+
+// dropUnbilledExpenses removes the line items added from expenses.
+func (m *InvoiceFormModel) dropUnbilledExpenses() {
+	for id := range m.expenseLines {
+		m.invoice.RemoveLineItem(id)
+		delete(m.expenseLines, id)
+	}
+	m.lineItemCursor = 0
+}
+
+// markExpensesBilled marks the expenses behind the invoice's line items as
+// billed. Expenses whose line was deleted stay unbilled.
+func (m *InvoiceFormModel) markExpensesBilled() error {
+	for _, item := range m.invoice.LineItems {
+		id, ok := m.expenseLines[item.ID]
+		if !ok {
+			continue
+		}
+		expense, err := m.storage.GetExpense(id)
+		if err != nil {
+			return err
+		}
+		expense.MarkBilled(m.invoice.ID, m.invoice.Number)
+		if err := m.storage.UpdateExpense(expense); err != nil {
+			return err
+		}
+		delete(m.expenseLines, item.ID)
+	}
+	return nil
+}
+
 func (m InvoiceFormModel) updateAddLineItemMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc":
@@ -316,6 +512,10 @@ func (m InvoiceFormModel) updateAddLineItemMode(msg tea.KeyMsg) (tea.Model, tea.
 			return m, nil
 		}
 		
+		if m.lineItemFocusIndex == 0 {
+			m.fillFromCatalog(s == "tab")
+		}
+		
 		if s == "shift+tab" {
 			m.lineItemFocusIndex--
 		} else {
@@ -352,6 +552,10 @@ func (m InvoiceFormModel) updateEditLineItemMode(msg tea.KeyMsg) (tea.Model, tea
 			return m, nil
 		}
 		
+		if m.lineItemFocusIndex == 0 {
+			m.fillFromCatalog(s == "tab")
+		}
+		
 		if s == "shift+tab" {
 			m.lineItemFocusIndex--
 		} else {
@@ -371,6 +575,17 @@ func (m InvoiceFormModel) updateEditLineItemMode(msg tea.KeyMsg) (tea.Model, tea
 	return m, cmd
 }
 
+// fillFromCatalog completes the line item being entered from the catalog
+// when the description names a catalog item.
+func (m *InvoiceFormModel) fillFromCatalog(accept bool) {
+	original := ""
+	if m.mode == invoiceFormModeEditLineItem && m.lineItemCursor < len(m.invoice.LineItems) {
+		original = m.invoice.LineItems[m.lineItemCursor].Description
+	}
+	client, _ := m.storage.GetClient(m.invoice.ClientID)
+	completeFromCatalog(m.lineItemInputs, m.catalog, m.taxes, client, original, accept)
+}
+
 func (m *InvoiceFormModel) updateBasicFocus() (InvoiceFormModel, tea.Cmd) {
 	cmds := make([]tea.Cmd, len(m.basicInputs))
 	
@@ -424,6 +639,7 @@ func (m *InvoiceFormModel) updateBasicInputs(msg tea.Msg) tea.Cmd {
 	m.dueDaysInput = m.basicInputs[2]
 	m.serviceStartInput = m.basicInputs[3]
 	m.serviceEndInput = m.basicInputs[4]
+	m.currencyInput = m.basicInputs[5]
 	
 	m.updateInvoiceRates()
 	
@@ -441,8 +657,9 @@ func (m *InvoiceFormModel) updateLineItemInputs(msg tea.Msg) tea.Cmd {
 }
 
 func (m *InvoiceFormModel) updateInvoiceRates() {
-	if discount, err := strconv.ParseFloat(m.discountInput.Value(), 64); err == nil {
-		m.invoice.SetDiscountRate(decimal.NewFromFloat(discount))
+	if rate, fixed, err := parseDiscount(m.discountInput.Value()); err == nil {
+		m.invoice.DiscountRate = rate
+		m.invoice.SetFixedDiscount(fixed)
 	}
 	
 	if tax, err := strconv.ParseFloat(m.taxInput.Value(), 64); err == nil {
@@ -470,6 +687,13 @@ func (m *InvoiceFormModel) updateInvoiceRates() {
 	} else {
 		m.invoice.ServiceEndDate = nil
 	}
+	
+	// Money already received stays in the currency it was received in
+	if m.invoice.AmountPaid.IsZero() && m.invoice.AmountCredited.IsZero() {
+		if currency, err := models.ParseCurrency(m.currencyInput.Value()); err == nil {
+			m.invoice.Currency = currency
+		}
+	}
 }
 
 func (m *InvoiceFormModel) addLineItem() error {
@@ -496,7 +720,19 @@ func (m *InvoiceFormModel) addLineItem() error {
 		return fmt.Errorf("invalid price")
 	}
 	
+	taxes, err := parseLineTaxes(m.lineItemInputs[3].Value(), m.taxes)
+	if err != nil {
+		return err
+	}
+	
+	discountRate, fixedDiscount, err := parseDiscount(m.lineItemInputs[4].Value())
+	if err != nil {
+		return err
+	}
+	
 	item := models.NewLineItem(desc, qty, price)
+	item.Taxes = taxes
+	item.SetDiscount(discountRate, fixedDiscount)
 	m.invoice.AddLineItem(*item)
 	
 	for i := range m.lineItemInputs {
@@ -530,13 +766,24 @@ func (m *InvoiceFormModel) updateLineItem() error {
 		return fmt.Errorf("invalid price")
 	}
 	
+	taxes, err := parseLineTaxes(m.lineItemInputs[3].Value(), m.taxes)
+	if err != nil {
+		return err
+	}
+	
+	discountRate, fixedDiscount, err := parseDiscount(m.lineItemInputs[4].Value())
+	if err != nil {
+		return err
+	}
+	
 	// Find and update the line item
 	for i, item := range m.invoice.LineItems {
 		if item.ID == m.editingLineItemID {
 			m.invoice.LineItems[i].Description = desc
 			m.invoice.LineItems[i].Quantity = qty
 			m.invoice.LineItems[i].UnitPrice = price
-			m.invoice.LineItems[i].UpdateTotal()
+			m.invoice.LineItems[i].Taxes = taxes
+			m.invoice.LineItems[i].SetDiscount(discountRate, fixedDiscount)
 			break
 		}
 	}
@@ -552,10 +799,37 @@ func (m *InvoiceFormModel) updateLineItem() error {
 	return nil
 }
 
+// checkEditable refuses to change an invoice that has been issued.
+func checkEditable(invoice *models.Invoice) error {
+	if invoice.Status != models.StatusDraft {
+		return fmt.Errorf("invoice %s is %s, and only drafts can be edited; issue a credit note to correct it", invoice.Number, invoice.Status)
+	}
+	return nil
+}
+
 func (m *InvoiceFormModel) saveInvoice() (tea.Model, tea.Cmd) {
+	if m.isEdit {
+		if err := checkEditable(m.invoice); err != nil {
+			m.err = err
+			return m, nil
+		}
+	}
+	currency, err := models.ParseCurrency(m.currencyInput.Value())
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	if _, _, err := parseDiscount(m.discountInput.Value()); err != nil {
+		m.err = err
+		return m, nil
+	}
+	if currency != m.invoice.Currency.Code() && (m.invoice.AmountPaid.IsPositive() || m.invoice.AmountCredited.IsPositive()) {
+		m.err = fmt.Errorf("cannot change the currency of an invoice with payments or credit notes")
+		return m, nil
+	}
 	m.updateInvoiceRates()
 	
-	var err error
+	
 	if m.isEdit {
 		err = m.storage.UpdateInvoice(m.invoice)
 	} else {
@@ -567,6 +841,18 @@ func (m *InvoiceFormModel) saveInvoice() (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 	
+	if err := m.markTimeBilled(); err != nil {
+		// The invoice exists now, so saving again must update it
+		m.isEdit = true
+		m.err = fmt.Errorf("invoice saved, but failed to mark time as billed: %w", err)
+		return m, nil
+	}
+	if err := m.markExpensesBilled(); err != nil {
+		m.isEdit = true
+		m.err = fmt.Errorf("invoice saved, but failed to mark expenses as billed: %w", err)
+		return m, nil
+	}
+	
 	return NewInvoiceListModel(m.storage, m.config), func() tea.Msg { return BackToInvoiceListMsg{} }
 }
 
@@ -616,7 +902,7 @@ func (m InvoiceFormModel) viewBasic() string {
 		offset = 1
 	}
 	
-	s.WriteString(formLabelStyle.Render("Discount %:"))
+	s.WriteString(formLabelStyle.Render("Discount:"))
 	s.WriteString(m.discountInput.View() + "\n")
 	
 	s.WriteString(formLabelStyle.Render("Tax %:"))
@@ -629,7 +915,10 @@ func (m InvoiceFormModel) viewBasic() string {
 	s.WriteString(m.serviceStartInput.View() + "\n")
 	
 	s.WriteString(formLabelStyle.Render("Service End:"))
-	s.WriteString(m.serviceEndInput.View() + "\n\n")
+	s.WriteString(m.serviceEndInput.View() + "\n")
+	
+	s.WriteString(formLabelStyle.Render("Currency:"))
+	s.WriteString(m.currencyInput.View() + "\n\n")
 	
 	nextButton := "[ Next: Line Items ]"
 	if m.basicFocusIndex == len(m.basicInputs)+offset {
@@ -690,10 +979,10 @@ func (m InvoiceFormModel) viewLineItems() string {
 		for i, item := range m.invoice.LineItems {
 			row := ""
 			cells := []string{
-				truncate(item.Description, widths[0]-2),
+				truncate(describeLineItem(item, m.invoice.Currency), widths[0]-2),
 				fmt.Sprintf("%.2f", item.Quantity.InexactFloat64()),
-				fmt.Sprintf("$%.2f", item.UnitPrice.InexactFloat64()),
-				fmt.Sprintf("$%.2f", item.Total.InexactFloat64()),
+				m.invoice.Currency.Format(item.UnitPrice),
+				m.invoice.Currency.Format(item.Total),
 			}
 			
 			for j, cell := range cells {
@@ -711,17 +1000,18 @@ func (m InvoiceFormModel) viewLineItems() string {
 			}
 		}
 		s.WriteString(strings.Repeat("─", 74) + "\n")
-		s.WriteString(fmt.Sprintf("%*s", 74, fmt.Sprintf("Subtotal: $%.2f", m.invoice.Subtotal.InexactFloat64())) + "\n")
-		if m.invoice.DiscountRate.GreaterThan(decimal.Zero) {
-			s.WriteString(fmt.Sprintf("%*s", 74, fmt.Sprintf("Discount: -$%.2f", m.invoice.Discount.InexactFloat64())) + "\n")
+		s.WriteString(fmt.Sprintf("%*s", 74, "Subtotal: "+m.invoice.Currency.Format(m.invoice.Subtotal)) + "\n")
+		if !m.invoice.Discount.IsZero() {
+			label := discountLabel(m.invoice.DiscountRate, m.invoice.FixedDiscount, m.invoice.Currency)
+			s.WriteString(fmt.Sprintf("%*s", 74, label+": "+m.invoice.Currency.Format(m.invoice.Discount.Neg())) + "\n")
 		}
-		if m.invoice.TaxRate.GreaterThan(decimal.Zero) {
-			s.WriteString(fmt.Sprintf("%*s", 74, fmt.Sprintf("Tax: $%.2f", m.invoice.Tax.InexactFloat64())) + "\n")
+		for _, t := range m.invoice.TaxBreakdown() {
+			s.WriteString(fmt.Sprintf("%*s", 74, taxLabel(t)+": "+m.invoice.Currency.Format(t.Amount)) + "\n")
 		}
-		s.WriteString(fmt.Sprintf("%*s", 74, fmt.Sprintf("Total: $%.2f", m.invoice.Total.InexactFloat64())) + "\n")
+		s.WriteString(fmt.Sprintf("%*s", 74, "Total: "+m.invoice.Currency.Format(m.invoice.Total)) + "\n")
 	}
 	
-	s.WriteString("\n" + helpStyle.Render("a add • e edit • d delete • s save • ↑/k up • ↓/j down • esc back"))
+	s.WriteString("\n" + helpStyle.Render("a add • t add unbilled time • x add unbilled expenses • e edit • d delete • s save • ↑/k up • ↓/j down • esc back"))
 	
 	return appStyle.Render(s.String())
 }
@@ -736,11 +1026,14 @@ func (m InvoiceFormModel) viewAddLineItem() string {
 		m.err = nil
 	}
 	
-	labels := []string{"Description:", "Quantity:", "Unit Price:"}
+	labels := []string{"Description:", "Quantity:", "Unit Price:", "Taxes:", "Discount:"}
 	for i, label := range labels {
 		s.WriteString(formLabelStyle.Render(label))
 		s.WriteString(m.lineItemInputs[i].View())
 		s.WriteString("\n")
+		if i == 0 {
+			s.WriteString(catalogHint(&m.lineItemInputs[0]))
+		}
 	}
 	
 	s.WriteString("\n")
@@ -766,11 +1059,14 @@ func (m InvoiceFormModel) viewEditLineItem() string {
 		m.err = nil
 	}
 	
-	labels := []string{"Description:", "Quantity:", "Unit Price:"}
+	labels := []string{"Description:", "Quantity:", "Unit Price:", "Taxes:", "Discount:"}
 	for i, label := range labels {
 		s.WriteString(formLabelStyle.Render(label))
 		s.WriteString(m.lineItemInputs[i].View())
 		s.WriteString("\n")
+		if i == 0 {
+			s.WriteString(catalogHint(&m.lineItemInputs[0]))
+		}
 	}
 	
 	s.WriteString("\n")
@@ -81,6 +81,10 @@ func (m InvoiceListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return NewInvoiceFormModel(m.storage, m.config, nil), nil
 			case "e":
 				if len(m.invoices) > 0 {
+					if err := checkEditable(&m.invoices[m.cursor]); err != nil {
+						m.err = err
+						return m, nil
+					}
 					return NewInvoiceFormModel(m.storage, m.config, &m.invoices[m.cursor]), nil
 				}
 			case "v":
@@ -89,6 +93,10 @@ func (m InvoiceListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			case "d":
 				if len(m.invoices) > 0 {
+					if err := checkDeletable(&m.invoices[m.cursor]); err != nil {
+						m.err = err
+						return m, nil
+					}
 					m.mode = invoiceListModeConfirmDelete
 					m.selectedForDelete = m.invoices[m.cursor].ID
 				}
@@ -100,7 +108,19 @@ func (m InvoiceListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case invoiceListModeConfirmDelete:
 			switch msg.String() {
 			case "y":
-				err := m.storage.DeleteInvoice(m.selectedForDelete)
+				var err error
+				if invoice := m.getInvoiceByID(m.selectedForDelete); invoice != nil {
+					err = checkDeletable(invoice)
+				}
+				if err == nil {
+					err = m.storage.DeleteInvoice(m.selectedForDelete)
+				}
+				if err == nil {
+					err = releaseBilledTime(m.storage, m.selectedForDelete)
+				}
+				if err == nil {
+					err = releaseBilledExpenses(m.storage, m.selectedForDelete)
+				}
 				if err != nil {
 					m.err = err
 				}
@@ -155,7 +175,7 @@ func (m InvoiceListModel) View() string {
 				invoice.Number,
 				truncate(invoice.ClientName, widths[1]-2),
 				invoice.Date.Format("2006-01-02"),
-				fmt.Sprintf("$%.2f", invoice.Total.InexactFloat64()),
+				invoice.Currency.Format(invoice.Total),
 				string(invoice.Status),
 			}
 			
@@ -175,6 +195,8 @@ func (m InvoiceListModel) View() string {
 						style = style.Inherit(statusPaidStyle)
 					case models.StatusOverdue:
 						style = style.Inherit(statusOverdueStyle)
+					case models.StatusVoid:
+						style = style.Inherit(statusVoidStyle)
 					}
 				}
 				
@@ -187,6 +209,17 @@ func (m InvoiceListModel) View() string {
 				s.WriteString("  " + row + "\n")
 			}
 		}
+		
+		// Balances are totalled per currency, never converted
+		outstanding := models.Amounts{}
+		for _, invoice := range m.invoices {
+			if invoice.AcceptsPayments() && invoice.BalanceDue().IsPositive() {
+				outstanding.Add(invoice.Currency, invoice.BalanceDue())
+			}
+		}
+		if len(outstanding) > 0 {
+			s.WriteString("\n" + formLabelStyle.Render("Outstanding:") + " " + outstanding.String() + "\n")
+		}
 	}
 	
 	s.WriteString("\n" + helpStyle.Render("a add • e edit • v view • d delete • ↑/k up • ↓/j down • esc back • q quit"))
@@ -203,4 +236,13 @@ func (m InvoiceListModel) getInvoiceByID(id string) *models.Invoice {
 	return nil
 }
 
+// checkDeletable refuses to delete an invoice that has been issued, as its
+// number must stay accounted for.
+func checkDeletable(invoice *models.Invoice) error {
+	if invoice.Status != models.StatusDraft {
+		return fmt.Errorf("invoice %s is %s, and only drafts can be deleted; void it instead", invoice.Number, invoice.Status)
+	}
+	return nil
+}
+
 type BackToInvoiceListMsg struct{}
\ No newline at end of file
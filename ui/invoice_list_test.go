@@ -0,0 +1,40 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/models"
+	"github.com/user/invoicer/storage"
+)
+
+func TestOnlyDraftsAreDeleted(t *testing.T) {
+	store, err := storage.NewJSONStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	draft := models.NewInvoice("c1", "Acme", "2026-01")
+	sent := models.NewInvoice("c1", "Acme", "2026-02")
+	sent.Status = models.StatusSent
+	for _, invoice := range []*models.Invoice{draft, sent} {
+		if err := store.SaveInvoice(invoice); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, invoice := range []*models.Invoice{sent, draft} {
+		m := NewInvoiceListModel(store, &config.Config{})
+		for m.invoices[m.cursor].ID != invoice.ID {
+			m.cursor++
+		}
+		model, _ := m.Update(runes("d")[0])
+		model, _ = model.Update(runes("y")[0])
+		_, err := store.GetInvoice(invoice.ID)
+		if deleted := err != nil; deleted != (invoice == draft) {
+			t.Errorf("%s invoice deleted: %v", invoice.Status, deleted)
+		}
+		if invoice == sent && model.(InvoiceListModel).err == nil {
+			t.Error("no reason given for keeping the sent invoice")
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/user/invoicer/models"
+)
+
+// enableCatalogSuggestions offers the catalog's SKUs and descriptions as
+// completions while a line item's description is typed.
+func enableCatalogSuggestions(input *textinput.Model, catalog []models.CatalogItem) {
+	if len(catalog) == 0 {
+		return
+	}
+	suggestions := make([]string, 0, 2*len(catalog))
+	for _, item := range catalog {
+		if item.SKU != "" {
+			suggestions = append(suggestions, item.SKU)
+		}
+		suggestions = append(suggestions, item.Description)
+	}
+	input.ShowSuggestions = true
+	input.SetSuggestions(suggestions)
+}
+
+// findCatalogItem returns the catalog item whose SKU or description is value,
+// ignoring case, or nil if there is none.
+func findCatalogItem(catalog []models.CatalogItem, value string) *models.CatalogItem {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	for i := range catalog {
+		if strings.EqualFold(catalog[i].SKU, value) || strings.EqualFold(catalog[i].Description, value) {
+			return &catalog[i]
+		}
+	}
+	return nil
+}
+
+// completeFromCatalog fills a line item's inputs from the catalog when focus
+// leaves the description. If accept is set, the highlighted suggestion
+// replaces what was typed and the item's price and taxes overwrite the
+// line's; otherwise a description that already names an item only fills the
+// inputs that are still empty. The line's original description is never
+// replaced, so tabbing through an edit leaves it alone.
+func completeFromCatalog(inputs []textinput.Model, catalog []models.CatalogItem, taxes []models.Tax, client *models.Client, original string, accept bool) {
+	desc := &inputs[0]
+	value := desc.Value()
+	picked := false
+	if accept && value != original {
+		if suggestion := desc.CurrentSuggestion(); suggestion != "" && suggestion != value {
+			value = suggestion
+			picked = true
+		}
+	}
+
+	item := findCatalogItem(catalog, value)
+	if item == nil {
+		return
+	}
+	desc.SetValue(item.Description)
+
+	price, lineTaxes := &inputs[2], &inputs[3]
+	if picked || strings.TrimSpace(price.Value()) == "" {
+		price.SetValue(item.PriceFor(client).String())
+	}
+	if picked || strings.TrimSpace(lineTaxes.Value()) == "" {
+		lineTaxes.SetValue(models.TaxNames(item.Taxes(taxes)))
+	}
+}
+
+// catalogHint tells the user how to pick from the catalog while the
+// description has matching suggestions.
+func catalogHint(input *textinput.Model) string {
+	if !input.Focused() {
+		return ""
+	}
+	matches := len(input.MatchedSuggestions())
+	if matches == 0 {
+		return ""
+	}
+	return dimStyle.Render(fmt.Sprintf("%d catalog matches • ↑/↓ choose • tab accept", matches)) + "\n"
+}
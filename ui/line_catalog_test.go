@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/shopspring/decimal"
+
+	"github.com/user/invoicer/models"
+)
+
+// lineInputs returns a line item's description, quantity, price and taxes
+// inputs holding values.
+func lineInputs(values ...string) []textinput.Model {
+	inputs := make([]textinput.Model, 4)
+	for i := range inputs {
+		inputs[i] = textinput.New()
+		inputs[i].SetValue(values[i])
+	}
+	return inputs
+}
+
+func TestCompleteFromCatalog(t *testing.T) {
+	vat := models.NewTax("VAT", decimal.NewFromInt(20), false, false)
+	catalog := []models.CatalogItem{
+		*models.NewCatalogItem("DEV", "Development", "hour", decimal.Zero, []string{vat.ID}),
+		*models.NewCatalogItem("", "Hosting", "month", decimal.NewFromInt(25), nil),
+	}
+	client := &models.Client{DefaultHourlyRate: decimal.NewFromInt(90)}
+
+	if item := findCatalogItem(catalog, " dev "); item == nil || item.Description != "Development" {
+		t.Errorf("findCatalogItem(dev) = %v", item)
+	}
+	if item := findCatalogItem(catalog, ""); item != nil {
+		t.Errorf("findCatalogItem(\"\") = %v", item)
+	}
+
+	// Typing a SKU fills in the rest
+	inputs := lineInputs("dev", "3", "", "")
+	completeFromCatalog(inputs, catalog, []models.Tax{*vat}, client, "", false)
+	if inputs[0].Value() != "Development" || inputs[2].Value() != "90" || inputs[3].Value() != "VAT" {
+		t.Errorf("completed line = %q, %q, %q", inputs[0].Value(), inputs[2].Value(), inputs[3].Value())
+	}
+
+	// What was typed is kept unless a suggestion is picked
+	inputs = lineInputs("Hosting", "1", "20", "")
+	completeFromCatalog(inputs, catalog, nil, client, "", false)
+	if inputs[2].Value() != "20" {
+		t.Errorf("price = %s, want the typed 20 kept", inputs[2].Value())
+	}
+
+	// Unknown descriptions are left alone
+	inputs = lineInputs("Travel", "1", "", "")
+	completeFromCatalog(inputs, catalog, nil, client, "", true)
+	if inputs[0].Value() != "Travel" || inputs[2].Value() != "" {
+		t.Errorf("free-form line = %q, %q", inputs[0].Value(), inputs[2].Value())
+	}
+}
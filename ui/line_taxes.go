@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/user/invoicer/models"
+)
+
+// parseLineTaxes looks up the comma separated tax names in input in the tax
+// catalogue, ignoring case. An empty input means the line is not taxed
+// beyond the document's own tax rate.
+func parseLineTaxes(input string, catalogue []models.Tax) ([]models.Tax, error) {
+	var taxes []models.Tax
+	for _, name := range strings.Split(input, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, t := range catalogue {
+			if strings.EqualFold(t.Name, name) {
+				taxes = append(taxes, t)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown tax %q", name)
+		}
+	}
+	return taxes, nil
+}
+
+// lineTaxesPlaceholder hints at the taxes that can be entered on a line.
+func lineTaxesPlaceholder(catalogue []models.Tax) string {
+	if len(catalogue) == 0 {
+		return "No taxes defined"
+	}
+	return models.TaxNames(catalogue)
+}
+
+// describeLineItem is the line's description followed by its discount and
+// taxes.
+func describeLineItem(item models.LineItem, currency models.CurrencyCode) string {
+	desc := item.Description
+	if item.HasDiscount() {
+		desc += fmt.Sprintf(" (less %s)", currency.Format(item.Discount()))
+	}
+	if len(item.Taxes) > 0 {
+		desc += fmt.Sprintf(" [%s]", models.TaxNames(item.Taxes))
+	}
+	return desc
+}
+
+// taxLabel names a row of a tax breakdown, e.g. "State (6.0%)".
+func taxLabel(t models.TaxAmount) string {
+	label := fmt.Sprintf("%s (%s%%)", t.Name, models.FormatPercent(t.Rate))
+	if t.Inclusive {
+		label += " incl."
+	}
+	return label
+}
@@ -11,6 +11,13 @@ type menuChoice int
 const (
 	menuClients menuChoice = iota
 	menuInvoices
+	menuEstimates
+	menuCreditNotes
+	menuRecurring
+	menuTime
+	menuExpenses
+	menuCatalog
+	menuTaxes
 	menuSettings
 	menuExit
 )
@@ -18,6 +25,13 @@ const (
 var menuItems = []string{
 	"Manage Clients",
 	"Manage Invoices",
+	"Estimates",
+	"Credit Notes",
+	"Recurring Invoices",
+	"Time Tracking",
+	"Expenses",
+	"Catalog",
+	"Tax Settings",
 	"Settings",
 	"Exit",
 }
@@ -27,6 +41,8 @@ type MainMenuModel struct {
 	choice  menuChoice
 	storage models.Storage
 	config  *config.Config
+	notice  string
+	isError bool
 }
 
 func NewMainMenuModel(storage models.Storage, cfg *config.Config) MainMenuModel {
@@ -36,6 +52,14 @@ func NewMainMenuModel(storage models.Storage, cfg *config.Config) MainMenuModel
 	}
 }
 
+// WithNotice shows message in a banner above the menu, e.g. a summary of
+// changes made at startup. It is cleared once the user leaves the menu.
+func (m MainMenuModel) WithNotice(message string, isError bool) MainMenuModel {
+	m.notice = message
+	m.isError = isError
+	return m
+}
+
 func (m MainMenuModel) Init() tea.Cmd {
 	return nil
 }
@@ -61,6 +85,21 @@ func (m MainMenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return NewClientListModel(m.storage, m.config), nil
 			case menuInvoices:
 				return NewInvoiceListModel(m.storage, m.config), nil
+			case menuEstimates:
+				return NewEstimateListModel(m.storage, m.config), nil
+			case menuCreditNotes:
+				return NewCreditNoteListModel(m.storage, m.config), nil
+			case menuRecurring:
+				return NewRecurringListModel(m.storage, m.config), nil
+			case menuTime:
+				list := NewTimeListModel(m.storage, m.config)
+				return list, list.Init()
+			case menuExpenses:
+				return NewExpenseListModel(m.storage, m.config), nil
+			case menuCatalog:
+				return NewCatalogListModel(m.storage, m.config), nil
+			case menuTaxes:
+				return NewTaxListModel(m.storage, m.config), nil
 			case menuSettings:
 				return NewSettingsModel(m.storage, m.config), nil
 			case menuExit:
@@ -74,6 +113,14 @@ func (m MainMenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m MainMenuModel) View() string {
 	s := titleStyle.Render("Invoice Manager") + "\n\n"
 	
+	if m.notice != "" {
+		if m.isError {
+			s += errorStyle.Render(m.notice) + "\n\n"
+		} else {
+			s += successStyle.Render(m.notice) + "\n\n"
+		}
+	}
+	
 	for i, item := range menuItems {
 		cursor := "  "
 		if m.cursor == i {
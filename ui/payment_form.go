@@ -0,0 +1,176 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/shopspring/decimal"
+	"github.com/user/invoicer/models"
+)
+
+type PaymentEnteredMsg struct {
+	Payment *models.Payment
+}
+
+type CancelPaymentMsg struct{}
+
+// PaymentFormModel collects a single payment against an invoice.
+type PaymentFormModel struct {
+	invoice    *models.Invoice
+	inputs     []textinput.Model
+	focusIndex int
+	err        error
+}
+
+func NewPaymentFormModel(invoice *models.Invoice) PaymentFormModel {
+	amountInput := textinput.New()
+	amountInput.Placeholder = "0.00"
+	amountInput.Width = 15
+	amountInput.SetValue(invoice.BalanceDue().StringFixed(invoice.Currency.MinorUnits()))
+	amountInput.Focus()
+
+	dateInput := textinput.New()
+	dateInput.Placeholder = "YYYY-MM-DD"
+	dateInput.Width = 15
+	dateInput.SetValue(time.Now().Format("2006-01-02"))
+
+	methodInput := textinput.New()
+	methodInput.Placeholder = "Bank transfer, Zelle, check..."
+	methodInput.Width = 30
+
+	referenceInput := textinput.New()
+	referenceInput.Placeholder = "Transaction or check number (optional)"
+	referenceInput.Width = 40
+
+	noteInput := textinput.New()
+	noteInput.Placeholder = "Optional note"
+	noteInput.Width = 40
+
+	return PaymentFormModel{
+		invoice: invoice,
+		inputs:  []textinput.Model{amountInput, dateInput, methodInput, referenceInput, noteInput},
+	}
+}
+
+func (m PaymentFormModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m PaymentFormModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			return m, func() tea.Msg { return CancelPaymentMsg{} }
+		case "tab", "shift+tab", "enter", "up", "down":
+			s := msg.String()
+
+			if s == "enter" && m.focusIndex == len(m.inputs) {
+				payment, err := m.buildPayment()
+				if err != nil {
+					m.err = err
+					return m, nil
+				}
+				return m, func() tea.Msg { return PaymentEnteredMsg{Payment: payment} }
+			}
+
+			if s == "up" || s == "shift+tab" {
+				m.focusIndex--
+			} else {
+				m.focusIndex++
+			}
+
+			if m.focusIndex > len(m.inputs) {
+				m.focusIndex = 0
+			} else if m.focusIndex < 0 {
+				m.focusIndex = len(m.inputs)
+			}
+
+			return m, m.updateFocus()
+		}
+	}
+
+	if m.focusIndex < len(m.inputs) {
+		var cmd tea.Cmd
+		m.inputs[m.focusIndex], cmd = m.inputs[m.focusIndex].Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m *PaymentFormModel) updateFocus() tea.Cmd {
+	cmds := make([]tea.Cmd, len(m.inputs))
+	for i := range m.inputs {
+		if i == m.focusIndex {
+			cmds[i] = m.inputs[i].Focus()
+			m.inputs[i].PromptStyle = formInputStyle
+			m.inputs[i].TextStyle = formInputStyle
+		} else {
+			m.inputs[i].Blur()
+			m.inputs[i].PromptStyle = dimStyle
+			m.inputs[i].TextStyle = dimStyle
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m PaymentFormModel) buildPayment() (*models.Payment, error) {
+	amount, err := decimal.NewFromString(strings.TrimSpace(m.inputs[0].Value()))
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount")
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(m.inputs[1].Value()), time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date, use YYYY-MM-DD")
+	}
+
+	method := strings.TrimSpace(m.inputs[2].Value())
+	if method == "" {
+		return nil, fmt.Errorf("payment method is required")
+	}
+
+	return models.NewPayment(
+		m.invoice.ID,
+		amount,
+		date,
+		method,
+		strings.TrimSpace(m.inputs[3].Value()),
+		strings.TrimSpace(m.inputs[4].Value()),
+	), nil
+}
+
+func (m PaymentFormModel) View() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render(fmt.Sprintf("Record Payment - Invoice %s", m.invoice.Number)) + "\n\n")
+	s.WriteString(formLabelStyle.Render("Balance Due:") + " "+m.invoice.Currency.Format(m.invoice.BalanceDue()) + "\n\n")
+
+	if m.err != nil {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)) + "\n\n")
+	}
+
+	labels := []string{"Amount:", "Date:", "Method:", "Reference:", "Note:"}
+	for i, label := range labels {
+		s.WriteString(formLabelStyle.Render(label))
+		s.WriteString(m.inputs[i].View())
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+
+	button := "[ Record Payment ]"
+	if m.focusIndex == len(m.inputs) {
+		button = selectedStyle.Render(button)
+	}
+	s.WriteString(button)
+
+	s.WriteString("\n\n" + helpStyle.Render("tab navigate • enter record • esc cancel"))
+
+	return appStyle.Render(s.String())
+}
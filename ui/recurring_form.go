@@ -0,0 +1,658 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/shopspring/decimal"
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/models"
+)
+
+type recurringFormMode int
+
+const (
+	recurringFormModeEditBasic recurringFormMode = iota
+	recurringFormModeSelectClient
+	recurringFormModeEditLineItems
+	recurringFormModeAddLineItem
+	recurringFormModeEditLineItem
+)
+
+// Indexes into RecurringFormModel.inputs
+const (
+	recurringInputName = iota
+	recurringInputCadence
+	recurringInputInterval
+	recurringInputNextRun
+	recurringInputDueDays
+	recurringInputDiscount
+	recurringInputTax
+	recurringInputCurrency
+)
+
+// RecurringFormModel creates or edits a recurring invoice schedule. The
+// first focus position is the client selector and the last the button that
+// moves on to the line items.
+type RecurringFormModel struct {
+	storage  models.Storage
+	config   *config.Config
+	schedule *models.RecurringInvoice
+	isEdit   bool
+	mode     recurringFormMode
+
+	clients      []models.Client
+	clientCursor int
+	taxes        []models.Tax
+	catalog      []models.CatalogItem
+
+	inputs     []textinput.Model
+	focusIndex int
+
+	lineItemInputs     []textinput.Model
+	lineItemFocusIndex int
+	lineItemCursor     int
+	editingIndex       int
+
+	err error
+}
+
+func NewRecurringFormModel(storage models.Storage, cfg *config.Config, schedule *models.RecurringInvoice) RecurringFormModel {
+	m := RecurringFormModel{
+		storage:  storage,
+		config:   cfg,
+		schedule: schedule,
+		isEdit:   schedule != nil,
+		mode:     recurringFormModeEditBasic,
+	}
+
+	clients, _ := storage.GetAllClients()
+	m.clients = clients
+	m.taxes, _ = storage.GetAllTaxes()
+	m.catalog, _ = storage.GetAllCatalogItems()
+
+	if !m.isEdit {
+		now := time.Now()
+		firstOfNextMonth := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, now.Location())
+		m.schedule = models.NewRecurringInvoice("", "", "", models.CadenceMonthly, 1, firstOfNextMonth)
+		if len(clients) > 0 {
+			m.schedule.ClientID = clients[0].ID
+			m.schedule.ClientName = clients[0].Name
+			m.schedule.Currency = clients[0].DefaultCurrency.Code()
+		}
+	} else {
+		for i, client := range clients {
+			if client.ID == schedule.ClientID {
+				m.clientCursor = i
+			}
+		}
+	}
+
+	placeholders := []string{"Monthly retainer", "monthly, quarterly or weekly", "1", "YYYY-MM-DD", "30", discountPlaceholder, "0", string(models.DefaultCurrency)}
+	widths := []int{40, 30, 10, 15, 10, 15, 10, 10}
+	m.inputs = make([]textinput.Model, len(placeholders))
+	for i := range m.inputs {
+		m.inputs[i] = textinput.New()
+		m.inputs[i].Placeholder = placeholders[i]
+		m.inputs[i].Width = widths[i]
+	}
+
+	m.inputs[recurringInputName].SetValue(m.schedule.Name)
+	m.inputs[recurringInputCadence].SetValue(string(m.schedule.Cadence))
+	m.inputs[recurringInputInterval].SetValue(strconv.Itoa(m.schedule.Interval))
+	m.inputs[recurringInputNextRun].SetValue(m.schedule.NextRunDate.Format("2006-01-02"))
+	m.inputs[recurringInputDueDays].SetValue(strconv.Itoa(m.schedule.DueDays))
+	m.inputs[recurringInputDiscount].SetValue(formatDiscountInput(m.schedule.DiscountRate, m.schedule.FixedDiscount))
+	m.inputs[recurringInputTax].SetValue(m.schedule.TaxRate.String())
+	m.inputs[recurringInputCurrency].SetValue(string(m.schedule.Currency.Code()))
+
+	m.setupLineItemInputs(nil)
+	return m
+}
+
+func (m *RecurringFormModel) setupLineItemInputs(item *models.LineItem) {
+	descInput := textinput.New()
+	descInput.Placeholder = "Description"
+	descInput.Width = 40
+	descInput.Focus()
+	enableCatalogSuggestions(&descInput, m.catalog)
+
+	qtyInput := textinput.New()
+	qtyInput.Placeholder = "1"
+	qtyInput.Width = 10
+
+	priceInput := textinput.New()
+	priceInput.Placeholder = "0.00"
+	priceInput.Width = 10
+
+	taxesInput := textinput.New()
+	taxesInput.Placeholder = lineTaxesPlaceholder(m.taxes)
+	taxesInput.Width = 40
+
+	discountInput := textinput.New()
+	discountInput.Placeholder = discountPlaceholder
+	discountInput.Width = 15
+
+	if item != nil {
+		descInput.SetValue(item.Description)
+		qtyInput.SetValue(item.Quantity.String())
+		priceInput.SetValue(item.UnitPrice.StringFixed(2))
+		taxesInput.SetValue(models.TaxNames(item.Taxes))
+		discountInput.SetValue(formatDiscountInput(item.DiscountRate, item.FixedDiscount))
+	}
+
+	m.lineItemInputs = []textinput.Model{descInput, qtyInput, priceInput, taxesInput, discountInput}
+	m.lineItemFocusIndex = 0
+}
+
+// fillFromCatalog completes the line item being entered from the catalog
+// when the description names a catalog item.
+func (m *RecurringFormModel) fillFromCatalog(accept bool) {
+	original := ""
+	if m.mode == recurringFormModeEditLineItem {
+		original = m.schedule.LineItems[m.editingIndex].Description
+	}
+	client, _ := m.storage.GetClient(m.schedule.ClientID)
+	completeFromCatalog(m.lineItemInputs, m.catalog, m.taxes, client, original, accept)
+}
+
+func (m RecurringFormModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m RecurringFormModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch m.mode {
+		case recurringFormModeEditBasic:
+			return m.updateBasicMode(msg)
+		case recurringFormModeSelectClient:
+			return m.updateSelectClientMode(msg)
+		case recurringFormModeEditLineItems:
+			return m.updateLineItemsMode(msg)
+		case recurringFormModeAddLineItem, recurringFormModeEditLineItem:
+			return m.updateLineItemInputMode(msg)
+		}
+	}
+
+	return m, nil
+}
+
+func (m RecurringFormModel) back() (tea.Model, tea.Cmd) {
+	return NewRecurringListModel(m.storage, m.config), func() tea.Msg { return BackToRecurringListMsg{} }
+}
+
+func (m RecurringFormModel) updateBasicMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		return m.back()
+	case "tab", "shift+tab", "enter", "up", "down":
+		s := msg.String()
+
+		// Client selector, the inputs and the next button
+		totalFocusItems := len(m.inputs) + 2
+
+		if s == "enter" && m.focusIndex == 0 {
+			m.mode = recurringFormModeSelectClient
+			return m, nil
+		}
+
+		if s == "enter" && m.focusIndex == totalFocusItems-1 {
+			if err := m.applyInputs(); err != nil {
+				m.err = err
+				return m, nil
+			}
+			m.err = nil
+			m.mode = recurringFormModeEditLineItems
+			return m, nil
+		}
+
+		if s == "up" || s == "shift+tab" {
+			m.focusIndex--
+		} else {
+			m.focusIndex++
+		}
+
+		if m.focusIndex >= totalFocusItems {
+			m.focusIndex = 0
+		} else if m.focusIndex < 0 {
+			m.focusIndex = totalFocusItems - 1
+		}
+
+		return m.updateFocus()
+	}
+
+	if i := m.focusIndex - 1; i >= 0 && i < len(m.inputs) {
+		var cmd tea.Cmd
+		m.inputs[i], cmd = m.inputs[i].Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m *RecurringFormModel) updateFocus() (RecurringFormModel, tea.Cmd) {
+	cmds := make([]tea.Cmd, len(m.inputs))
+
+	for i := range m.inputs {
+		if i == m.focusIndex-1 {
+			cmds[i] = m.inputs[i].Focus()
+			m.inputs[i].PromptStyle = formInputStyle
+			m.inputs[i].TextStyle = formInputStyle
+		} else {
+			m.inputs[i].Blur()
+			m.inputs[i].PromptStyle = dimStyle
+			m.inputs[i].TextStyle = dimStyle
+		}
+	}
+
+	return *m, tea.Batch(cmds...)
+}
+
+func (m RecurringFormModel) updateSelectClientMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = recurringFormModeEditBasic
+	case "up", "k":
+		if m.clientCursor > 0 {
+			m.clientCursor--
+		}
+	case "down", "j":
+		if m.clientCursor < len(m.clients)-1 {
+			m.clientCursor++
+		}
+	case "enter":
+		if len(m.clients) > 0 {
+			client := m.clients[m.clientCursor]
+			m.schedule.ClientID = client.ID
+			m.schedule.ClientName = client.Name
+			m.schedule.Currency = client.DefaultCurrency.Code()
+			m.inputs[recurringInputCurrency].SetValue(string(m.schedule.Currency))
+		}
+		m.mode = recurringFormModeEditBasic
+	}
+	return m, nil
+}
+
+func (m RecurringFormModel) updateLineItemsMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.mode = recurringFormModeEditBasic
+	case "a":
+		m.mode = recurringFormModeAddLineItem
+		m.setupLineItemInputs(nil)
+		return m, textinput.Blink
+	case "e":
+		if m.lineItemCursor < len(m.schedule.LineItems) {
+			m.mode = recurringFormModeEditLineItem
+			m.editingIndex = m.lineItemCursor
+			m.setupLineItemInputs(&m.schedule.LineItems[m.lineItemCursor])
+			return m, textinput.Blink
+		}
+	case "d":
+		if m.lineItemCursor < len(m.schedule.LineItems) {
+			m.schedule.LineItems = append(m.schedule.LineItems[:m.lineItemCursor], m.schedule.LineItems[m.lineItemCursor+1:]...)
+			if m.lineItemCursor >= len(m.schedule.LineItems) && m.lineItemCursor > 0 {
+				m.lineItemCursor--
+			}
+		}
+	case "up", "k":
+		if m.lineItemCursor > 0 {
+			m.lineItemCursor--
+		}
+	case "down", "j":
+		if m.lineItemCursor < len(m.schedule.LineItems)-1 {
+			m.lineItemCursor++
+		}
+	case "s":
+		if err := m.saveSchedule(); err != nil {
+			m.err = err
+			return m, nil
+		}
+		return m.back()
+	}
+	return m, nil
+}
+
+func (m RecurringFormModel) updateLineItemInputMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = recurringFormModeEditLineItems
+		return m, nil
+	case "tab", "shift+tab", "enter":
+		s := msg.String()
+
+		if s == "enter" && m.lineItemFocusIndex == len(m.lineItemInputs) {
+			item, err := m.buildLineItem()
+			if err != nil {
+				m.err = err
+				return m, nil
+			}
+			if m.mode == recurringFormModeEditLineItem {
+				m.schedule.LineItems[m.editingIndex] = *item
+			} else {
+				m.schedule.LineItems = append(m.schedule.LineItems, *item)
+			}
+			m.err = nil
+			m.mode = recurringFormModeEditLineItems
+			return m, nil
+		}
+
+		if m.lineItemFocusIndex == 0 {
+			m.fillFromCatalog(s == "tab")
+		}
+
+		if s == "shift+tab" {
+			m.lineItemFocusIndex--
+		} else {
+			m.lineItemFocusIndex++
+		}
+
+		if m.lineItemFocusIndex > len(m.lineItemInputs) {
+			m.lineItemFocusIndex = 0
+		} else if m.lineItemFocusIndex < 0 {
+			m.lineItemFocusIndex = len(m.lineItemInputs)
+		}
+
+		cmds := make([]tea.Cmd, len(m.lineItemInputs))
+		for i := range m.lineItemInputs {
+			if i == m.lineItemFocusIndex {
+				cmds[i] = m.lineItemInputs[i].Focus()
+			} else {
+				m.lineItemInputs[i].Blur()
+			}
+		}
+		return m, tea.Batch(cmds...)
+	}
+
+	cmds := make([]tea.Cmd, len(m.lineItemInputs))
+	for i := range m.lineItemInputs {
+		m.lineItemInputs[i], cmds[i] = m.lineItemInputs[i].Update(msg)
+	}
+	return m, tea.Batch(cmds...)
+}
+
+func (m *RecurringFormModel) buildLineItem() (*models.LineItem, error) {
+	desc := strings.TrimSpace(m.lineItemInputs[0].Value())
+	if desc == "" {
+		return nil, fmt.Errorf("description is required")
+	}
+
+	qtyStr := m.lineItemInputs[1].Value()
+	if qtyStr == "" {
+		qtyStr = "1"
+	}
+	qty, err := decimal.NewFromString(qtyStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quantity")
+	}
+
+	priceStr := m.lineItemInputs[2].Value()
+	if priceStr == "" {
+		priceStr = "0"
+	}
+	price, err := decimal.NewFromString(priceStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid price")
+	}
+
+	taxes, err := parseLineTaxes(m.lineItemInputs[3].Value(), m.taxes)
+	if err != nil {
+		return nil, err
+	}
+
+	discountRate, fixedDiscount, err := parseDiscount(m.lineItemInputs[4].Value())
+	if err != nil {
+		return nil, err
+	}
+
+	item := models.NewLineItem(desc, qty, price)
+	item.Taxes = taxes
+	item.SetDiscount(discountRate, fixedDiscount)
+	return item, nil
+}
+
+// applyInputs validates the basic inputs and copies them onto the schedule.
+func (m *RecurringFormModel) applyInputs() error {
+	if m.schedule.ClientID == "" {
+		return fmt.Errorf("select a client first")
+	}
+
+	name := strings.TrimSpace(m.inputs[recurringInputName].Value())
+	if name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	cadence := models.Cadence(strings.ToLower(strings.TrimSpace(m.inputs[recurringInputCadence].Value())))
+	switch cadence {
+	case models.CadenceWeekly, models.CadenceMonthly, models.CadenceQuarterly:
+	default:
+		return fmt.Errorf("cadence must be monthly, quarterly or weekly")
+	}
+
+	interval, err := strconv.Atoi(strings.TrimSpace(m.inputs[recurringInputInterval].Value()))
+	if err != nil || interval < 1 {
+		return fmt.Errorf("repeat every must be a whole number of at least 1")
+	}
+
+	nextRun, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(m.inputs[recurringInputNextRun].Value()), time.Local)
+	if err != nil {
+		return fmt.Errorf("invalid next run date, use YYYY-MM-DD")
+	}
+
+	dueDays, err := strconv.Atoi(strings.TrimSpace(m.inputs[recurringInputDueDays].Value()))
+	if err != nil || dueDays < 0 {
+		return fmt.Errorf("invalid due days")
+	}
+
+	discountRate, fixedDiscount, err := parseDiscount(m.inputs[recurringInputDiscount].Value())
+	if err != nil {
+		return err
+	}
+
+	tax, err := decimal.NewFromString(strings.TrimSpace(m.inputs[recurringInputTax].Value()))
+	if err != nil {
+		return fmt.Errorf("invalid tax")
+	}
+
+	currency, err := models.ParseCurrency(m.inputs[recurringInputCurrency].Value())
+	if err != nil {
+		return err
+	}
+
+	m.schedule.Name = name
+	m.schedule.Cadence = cadence
+	m.schedule.Interval = interval
+	if !nextRun.Equal(m.schedule.NextRunDate) {
+		// A moved run date becomes the new anchor for the day of the month
+		m.schedule.NextRunDate = nextRun
+		m.schedule.StartDate = nextRun
+	}
+	m.schedule.DueDays = dueDays
+	m.schedule.DiscountRate = discountRate
+	m.schedule.FixedDiscount = fixedDiscount
+	m.schedule.TaxRate = tax
+	m.schedule.Currency = currency
+	return nil
+}
+
+func (m *RecurringFormModel) saveSchedule() error {
+	if err := m.applyInputs(); err != nil {
+		return err
+	}
+	if len(m.schedule.LineItems) == 0 {
+		return fmt.Errorf("add at least one line item")
+	}
+
+	m.schedule.UpdatedAt = time.Now()
+	if m.isEdit {
+		return m.storage.UpdateRecurringInvoice(m.schedule)
+	}
+	return m.storage.SaveRecurringInvoice(m.schedule)
+}
+
+func (m RecurringFormModel) View() string {
+	switch m.mode {
+	case recurringFormModeSelectClient:
+		return m.viewSelectClient()
+	case recurringFormModeEditLineItems:
+		return m.viewLineItems()
+	case recurringFormModeAddLineItem, recurringFormModeEditLineItem:
+		return m.viewLineItemInput()
+	default:
+		return m.viewBasic()
+	}
+}
+
+func (m RecurringFormModel) viewBasic() string {
+	var s strings.Builder
+
+	title := "New Recurring Invoice"
+	if m.isEdit {
+		title = fmt.Sprintf("Edit Recurring Invoice %s", m.schedule.Name)
+	}
+	s.WriteString(titleStyle.Render(title) + "\n\n")
+
+	if m.err != nil {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)) + "\n\n")
+	}
+
+	clientText := "[ Select Client ]"
+	if m.focusIndex == 0 {
+		clientText = selectedStyle.Render(clientText)
+	}
+	s.WriteString(formLabelStyle.Render("Client:") + " " + m.schedule.ClientName + " " + clientText + "\n\n")
+
+	labels := []string{"Name:", "Cadence:", "Repeat Every:", "Next Run:", "Due Days:", "Discount:", "Tax %:", "Currency:"}
+	for i, label := range labels {
+		s.WriteString(formLabelStyle.Render(label))
+		s.WriteString(m.inputs[i].View() + "\n")
+	}
+
+	nextButton := "[ Next: Line Items ]"
+	if m.focusIndex == len(m.inputs)+1 {
+		nextButton = selectedStyle.Render(nextButton)
+	}
+	s.WriteString("\n" + nextButton)
+
+	s.WriteString("\n\n" + helpStyle.Render("tab navigate • enter select • esc cancel"))
+
+	return appStyle.Render(s.String())
+}
+
+func (m RecurringFormModel) viewSelectClient() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("Select Client") + "\n\n")
+
+	if len(m.clients) == 0 {
+		s.WriteString(dimStyle.Render("No clients found. Please add a client first.") + "\n")
+	} else {
+		for i, client := range m.clients {
+			if i == m.clientCursor {
+				s.WriteString(selectedListItemStyle.Render("> "+client.Name) + "\n")
+			} else {
+				s.WriteString(listItemStyle.Render(client.Name) + "\n")
+			}
+		}
+	}
+
+	s.WriteString("\n" + helpStyle.Render("↑/k up • ↓/j down • enter select • esc cancel"))
+
+	return appStyle.Render(s.String())
+}
+
+func (m RecurringFormModel) viewLineItems() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render(fmt.Sprintf("%s - Line Items", m.schedule.Name)) + "\n\n")
+
+	if m.err != nil {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)) + "\n\n")
+	}
+
+	if len(m.schedule.LineItems) == 0 {
+		s.WriteString(dimStyle.Render("No line items. Press 'a' to add.") + "\n")
+	} else {
+		headers := []string{"Description", "Qty", "Price", "Total"}
+		widths := []int{40, 10, 12, 12}
+
+		headerRow := ""
+		for i, h := range headers {
+			headerRow += tableCellStyle.Width(widths[i]).Render(h)
+		}
+		s.WriteString(tableHeaderStyle.Render(headerRow) + "\n")
+
+		for i, item := range m.schedule.LineItems {
+			row := ""
+			cells := []string{
+				truncate(describeLineItem(item, m.schedule.Currency), widths[0]-2),
+				fmt.Sprintf("%.2f", item.Quantity.InexactFloat64()),
+				m.schedule.Currency.Format(item.UnitPrice),
+				m.schedule.Currency.Format(item.Total),
+			}
+
+			for j, cell := range cells {
+				style := tableCellStyle.Width(widths[j])
+				if i == m.lineItemCursor {
+					style = style.Inherit(selectedStyle)
+				}
+				row += style.Render(cell)
+			}
+
+			if i == m.lineItemCursor {
+				s.WriteString("> " + row + "\n")
+			} else {
+				s.WriteString("  " + row + "\n")
+			}
+		}
+
+		preview := m.schedule.GenerateInvoice("")
+		s.WriteString(strings.Repeat("─", 74) + "\n")
+		s.WriteString(fmt.Sprintf("%*s", 74, "Total per invoice: "+preview.Currency.Format(preview.Total)) + "\n")
+	}
+
+	s.WriteString("\n" + helpStyle.Render("a add • e edit • d delete • s save • ↑/k up • ↓/j down • esc back"))
+
+	return appStyle.Render(s.String())
+}
+
+func (m RecurringFormModel) viewLineItemInput() string {
+	var s strings.Builder
+
+	title, button := "Add Line Item", "[ Add Item ]"
+	if m.mode == recurringFormModeEditLineItem {
+		title, button = "Edit Line Item", "[ Update Item ]"
+	}
+	s.WriteString(titleStyle.Render(title) + "\n\n")
+
+	if m.err != nil {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)) + "\n\n")
+	}
+
+	labels := []string{"Description:", "Quantity:", "Unit Price:", "Taxes:", "Discount:"}
+	for i, label := range labels {
+		s.WriteString(formLabelStyle.Render(label))
+		s.WriteString(m.lineItemInputs[i].View())
+		s.WriteString("\n")
+		if i == 0 {
+			s.WriteString(catalogHint(&m.lineItemInputs[0]))
+		}
+	}
+
+	if m.lineItemFocusIndex == len(m.lineItemInputs) {
+		button = selectedStyle.Render(button)
+	}
+	s.WriteString("\n" + button)
+
+	s.WriteString("\n\n" + helpStyle.Render("tab navigate • enter save item • esc cancel"))
+
+	return appStyle.Render(s.String())
+}
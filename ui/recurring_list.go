@@ -0,0 +1,198 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/models"
+	"github.com/user/invoicer/recurring"
+)
+
+type recurringListMode int
+
+const (
+	recurringListModeView recurringListMode = iota
+	recurringListModeConfirmDelete
+)
+
+type RecurringListModel struct {
+	schedules []models.RecurringInvoice
+	cursor    int
+	storage   models.Storage
+	config    *config.Config
+	mode      recurringListMode
+	message   string
+	err       error
+}
+
+func NewRecurringListModel(storage models.Storage, cfg *config.Config) RecurringListModel {
+	m := RecurringListModel{
+		storage: storage,
+		config:  cfg,
+		mode:    recurringListModeView,
+	}
+	m.loadSchedules()
+	return m
+}
+
+func (m *RecurringListModel) loadSchedules() {
+	schedules, err := m.storage.GetAllRecurringInvoices()
+	if err != nil {
+		m.err = err
+		return
+	}
+	m.schedules = schedules
+	m.err = nil
+	if m.cursor >= len(m.schedules) && m.cursor > 0 {
+		m.cursor = len(m.schedules) - 1
+	}
+}
+
+func (m RecurringListModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m RecurringListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.mode == recurringListModeConfirmDelete {
+			switch msg.String() {
+			case "y":
+				err := m.storage.DeleteRecurringInvoice(m.schedules[m.cursor].ID)
+				m.loadSchedules()
+				if err != nil {
+					m.err = err
+				}
+				m.mode = recurringListModeView
+			case "n", "esc":
+				m.mode = recurringListModeView
+			}
+			return m, nil
+		}
+
+		m.message = ""
+		switch msg.String() {
+		case "ctrl+c", "q":
+			return m, tea.Quit
+		case "esc":
+			return NewMainMenuModel(m.storage, m.config), nil
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.schedules)-1 {
+				m.cursor++
+			}
+		case "a":
+			return NewRecurringFormModel(m.storage, m.config, nil), nil
+		case "e", "enter":
+			if len(m.schedules) > 0 {
+				return NewRecurringFormModel(m.storage, m.config, &m.schedules[m.cursor]), nil
+			}
+		case "d":
+			if len(m.schedules) > 0 {
+				m.mode = recurringListModeConfirmDelete
+			}
+		case "p":
+			// Pause or resume the selected schedule
+			if len(m.schedules) > 0 {
+				schedule := &m.schedules[m.cursor]
+				schedule.Active = !schedule.Active
+				err := m.storage.UpdateRecurringInvoice(schedule)
+				m.loadSchedules()
+				if err != nil {
+					m.err = err
+				}
+			}
+		case "g":
+			// Generate the next invoice now instead of waiting for its run date
+			if len(m.schedules) > 0 {
+				invoice, err := recurring.NewGenerator(m.storage).RunSchedule(&m.schedules[m.cursor])
+				if invoice != nil {
+					m.message = fmt.Sprintf("Created draft invoice %s", invoice.Number)
+				}
+				m.err = err
+				if err == nil {
+					m.loadSchedules()
+				}
+			}
+		}
+	case BackToRecurringListMsg:
+		m.loadSchedules()
+	}
+	return m, nil
+}
+
+func (m RecurringListModel) View() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("Recurring Invoices") + "\n\n")
+
+	if m.err != nil {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)) + "\n\n")
+	}
+	if m.message != "" {
+		s.WriteString(successStyle.Render(m.message) + "\n\n")
+	}
+
+	if m.mode == recurringListModeConfirmDelete {
+		schedule := m.schedules[m.cursor]
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Delete recurring invoice '%s' for %s? (y/n)", schedule.Name, schedule.ClientName)) + "\n")
+		return appStyle.Render(s.String())
+	}
+
+	if len(m.schedules) == 0 {
+		s.WriteString(dimStyle.Render("No recurring invoices. Press 'a' to add one.") + "\n")
+	} else {
+		headers := []string{"Name", "Client", "Cadence", "Next Run", "Amount", "Status"}
+		widths := []int{22, 20, 16, 12, 12, 8}
+
+		headerRow := ""
+		for i, h := range headers {
+			headerRow += tableCellStyle.Width(widths[i]).Render(h)
+		}
+		s.WriteString(tableHeaderStyle.Render(headerRow) + "\n")
+
+		for i, schedule := range m.schedules {
+			status := "active"
+			if !schedule.Active {
+				status = "paused"
+			}
+			preview := schedule.GenerateInvoice("")
+			cells := []string{
+				truncate(schedule.Name, widths[0]-2),
+				truncate(schedule.ClientName, widths[1]-2),
+				schedule.Describe(),
+				schedule.NextRunDate.Format("2006-01-02"),
+				preview.Currency.Format(preview.Total),
+				status,
+			}
+
+			row := ""
+			for j, cell := range cells {
+				style := tableCellStyle.Width(widths[j])
+				if i == m.cursor {
+					style = style.Inherit(selectedStyle)
+				} else if !schedule.Active {
+					style = style.Inherit(dimStyle)
+				}
+				row += style.Render(cell)
+			}
+
+			if i == m.cursor {
+				s.WriteString("> " + row + "\n")
+			} else {
+				s.WriteString("  " + row + "\n")
+			}
+		}
+	}
+
+	s.WriteString("\n" + helpStyle.Render("a add • e edit • d delete • p pause/resume • g generate now • esc back • q quit"))
+
+	return appStyle.Render(s.String())
+}
+
+type BackToRecurringListMsg struct{}
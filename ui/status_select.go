@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"errors"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -22,28 +23,35 @@ type StatusSelectModel struct {
 	reasonInput   textinput.Model
 	focusIndex    int
 	statusOptions []models.InvoiceStatus
+	err           string
 }
 
 func NewStatusSelectModel(currentStatus models.InvoiceStatus) StatusSelectModel {
 	ti := textinput.New()
-	ti.Placeholder = "Optional reason for status change..."
+	ti.Placeholder = "Reason for status change..."
 	ti.CharLimit = 200
 	ti.Width = 50
 	
+	// Only offer the statuses the invoice can actually move to
+	options := models.AllowedTransitions(currentStatus)
+	selected := currentStatus
+	if len(options) > 0 {
+		selected = options[0]
+	}
+	
 	return StatusSelectModel{
 		currentStatus: currentStatus,
-		selectedStatus: currentStatus,
+		selectedStatus: selected,
 		reasonInput:   ti,
 		focusIndex:    0,
-		statusOptions: []models.InvoiceStatus{
-			models.StatusDraft,
-			models.StatusSent,
-			models.StatusPaid,
-			models.StatusOverdue,
-		},
+		statusOptions: options,
 	}
 }
 
+func (m StatusSelectModel) reasonRequired() bool {
+	return models.RequiresReason(m.currentStatus, m.selectedStatus)
+}
+
 func (m StatusSelectModel) Init() tea.Cmd {
 	return nil
 }
@@ -51,24 +59,42 @@ func (m StatusSelectModel) Init() tea.Cmd {
 func (m StatusSelectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		m.err = ""
+		typing := m.focusIndex == len(m.statusOptions)
 		switch msg.String() {
-		case "ctrl+c", "q":
+		case "ctrl+c":
 			return m, tea.Quit
+		case "q":
+			// A q typed into the reason is part of it
+			if !typing {
+				return m, tea.Quit
+			}
 		case "esc":
 			return m, func() tea.Msg { return CancelStatusChangeMsg{} }
 		case "enter":
-			if m.focusIndex < len(m.statusOptions) {
-				// Confirm status selection
-				return m, func() tea.Msg {
-					return StatusSelectedMsg{
-						Status: m.selectedStatus,
-						Reason: m.reasonInput.Value(),
-					}
+			if len(m.statusOptions) == 0 {
+				return m, nil
+			}
+			
+			// Confirm status selection, asking for a reason where one is needed
+			if err := models.CheckTransition(m.currentStatus, m.selectedStatus, m.reasonInput.Value()); err != nil {
+				m.err = err.Error()
+				if errors.Is(err, models.ErrReasonRequired) {
+					m.focusIndex = len(m.statusOptions)
+					m.reasonInput.Focus()
+				}
+				return m, nil
+			}
+			return m, func() tea.Msg {
+				return StatusSelectedMsg{
+					Status: m.selectedStatus,
+					Reason: strings.TrimSpace(m.reasonInput.Value()),
 				}
 			}
-			// If focused on reason input, do nothing on enter
-			return m, nil
 		case "up", "k":
+			if m.focusIndex == len(m.statusOptions) {
+				break
+			}
 			if m.focusIndex > 0 {
 				m.focusIndex--
 				if m.focusIndex < len(m.statusOptions) {
@@ -76,17 +102,22 @@ func (m StatusSelectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 		case "down", "j":
-			if m.focusIndex < len(m.statusOptions) {
+			if m.focusIndex < len(m.statusOptions)-1 {
 				m.focusIndex++
+				m.selectedStatus = m.statusOptions[m.focusIndex]
 			}
 		case "tab", "shift+tab":
 			// Toggle between status options and reason input
 			if m.focusIndex < len(m.statusOptions) {
 				m.focusIndex = len(m.statusOptions)
 				m.reasonInput.Focus()
-			} else {
+			} else if len(m.statusOptions) > 0 {
 				m.focusIndex = 0
-				m.selectedStatus = m.statusOptions[0]
+				for i, status := range m.statusOptions {
+					if status == m.selectedStatus {
+						m.focusIndex = i
+					}
+				}
 				m.reasonInput.Blur()
 			}
 		}
@@ -110,6 +141,9 @@ func (m StatusSelectModel) View() string {
 	s.WriteString(formLabelStyle.Render("Select New Status:") + "\n")
 	
 	// Status options
+	if len(m.statusOptions) == 0 {
+		s.WriteString(dimStyle.Render("  No further status changes are possible") + "\n")
+	}
 	for i, status := range m.statusOptions {
 		cursor := "  "
 		if i == m.focusIndex && m.focusIndex < len(m.statusOptions) {
@@ -127,13 +161,21 @@ func (m StatusSelectModel) View() string {
 	}
 	
 	// Reason input
-	s.WriteString("\n" + formLabelStyle.Render("Reason (optional):") + "\n")
+	if m.reasonRequired() {
+		s.WriteString("\n" + formLabelStyle.Render("Reason (required):") + "\n")
+	} else {
+		s.WriteString("\n" + formLabelStyle.Render("Reason (optional):") + "\n")
+	}
 	if m.focusIndex == len(m.statusOptions) {
 		s.WriteString(m.reasonInput.View() + "\n")
 	} else {
 		s.WriteString(dimStyle.Render(m.reasonInput.View()) + "\n")
 	}
 	
+	if m.err != "" {
+		s.WriteString("\n" + errorStyle.Render(m.err) + "\n")
+	}
+	
 	s.WriteString("\n" + helpStyle.Render("↑/↓ navigate • tab switch to reason • enter confirm • esc cancel"))
 	
 	return appStyle.Render(s.String())
@@ -149,6 +191,8 @@ func getStatusStyle(status models.InvoiceStatus) lipgloss.Style {
 		return statusPaidStyle
 	case models.StatusOverdue:
 		return statusOverdueStyle
+	case models.StatusVoid:
+		return statusVoidStyle
 	default:
 		return normalStyle
 	}
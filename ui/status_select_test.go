@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/user/invoicer/models"
+)
+
+// typeKeys sends keys to m. Their commands are not run, as the input's
+// cursor blink would wait for its timer.
+func typeKeys(m StatusSelectModel, keys ...tea.KeyMsg) StatusSelectModel {
+	for _, key := range keys {
+		model, _ := m.Update(key)
+		m = model.(StatusSelectModel)
+	}
+	return m
+}
+
+func runes(s string) []tea.KeyMsg {
+	var keys []tea.KeyMsg
+	for _, r := range s {
+		keys = append(keys, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return keys
+}
+
+func TestStatusReasonTakesAnyLetter(t *testing.T) {
+	m := NewStatusSelectModel(models.StatusSent)
+	m = typeKeys(m, tea.KeyMsg{Type: tea.KeyTab})
+	m = typeKeys(m, runes("quirk of the client, just keep it")...)
+	if got := m.reasonInput.Value(); got != "quirk of the client, just keep it" {
+		t.Errorf("reason = %q", got)
+	}
+
+	// Away from the reason, q still quits
+	_, cmd := NewStatusSelectModel(models.StatusSent).Update(runes("q")[0])
+	if cmd == nil {
+		t.Fatal("q in the status list did nothing")
+	}
+	if _, quit := cmd().(tea.QuitMsg); !quit {
+		t.Error("q in the status list did not quit")
+	}
+}
@@ -74,4 +74,8 @@ var (
 	
 	statusOverdueStyle = lipgloss.NewStyle().
 		Foreground(lipgloss.Color("196"))
+	
+	statusVoidStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Strikethrough(true)
 )
\ No newline at end of file
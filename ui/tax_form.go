@@ -0,0 +1,230 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/shopspring/decimal"
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/models"
+)
+
+type taxKind int
+
+const (
+	taxKindStandard taxKind = iota
+	taxKindCompound
+	taxKindInclusive
+)
+
+var taxKindLabels = []string{"Standard", "Compound", "Inclusive"}
+
+const (
+	taxFocusName = iota
+	taxFocusRate
+	taxFocusKind
+	taxFocusSave
+	taxFocusCount
+)
+
+type TaxFormModel struct {
+	nameInput  textinput.Model
+	rateInput  textinput.Model
+	kind       taxKind
+	focusIndex int
+	storage    models.Storage
+	config     *config.Config
+	tax        *models.Tax
+	isEdit     bool
+	err        error
+}
+
+func NewTaxFormModel(storage models.Storage, cfg *config.Config, tax *models.Tax) TaxFormModel {
+	nameInput := textinput.New()
+	nameInput.Placeholder = "VAT"
+	nameInput.Width = 25
+	nameInput.Focus()
+
+	rateInput := textinput.New()
+	rateInput.Placeholder = "20"
+	rateInput.Width = 10
+
+	kind := taxKindStandard
+	isEdit := tax != nil
+	if isEdit {
+		nameInput.SetValue(tax.Name)
+		rateInput.SetValue(tax.Rate.String())
+		if tax.Compound {
+			kind = taxKindCompound
+		} else if tax.Inclusive {
+			kind = taxKindInclusive
+		}
+	}
+
+	return TaxFormModel{
+		nameInput: nameInput,
+		rateInput: rateInput,
+		kind:      kind,
+		storage:   storage,
+		config:    cfg,
+		tax:       tax,
+		isEdit:    isEdit,
+	}
+}
+
+func (m TaxFormModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m TaxFormModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			return NewTaxListModel(m.storage, m.config), func() tea.Msg { return BackToTaxListMsg{} }
+		case " ", "left", "right":
+			if m.focusIndex == taxFocusKind {
+				if msg.String() == "left" {
+					m.kind = (m.kind + 2) % 3
+				} else {
+					m.kind = (m.kind + 1) % 3
+				}
+				return m, nil
+			}
+		case "tab", "shift+tab", "enter", "up", "down":
+			s := msg.String()
+
+			if s == "enter" && m.focusIndex == taxFocusSave {
+				if err := m.saveTax(); err != nil {
+					m.err = err
+					return m, nil
+				}
+				return NewTaxListModel(m.storage, m.config), func() tea.Msg { return BackToTaxListMsg{} }
+			}
+
+			if s == "up" || s == "shift+tab" {
+				m.focusIndex--
+			} else {
+				m.focusIndex++
+			}
+
+			if m.focusIndex >= taxFocusCount {
+				m.focusIndex = 0
+			} else if m.focusIndex < 0 {
+				m.focusIndex = taxFocusCount - 1
+			}
+
+			return m.updateFocus()
+		}
+	}
+
+	var cmd tea.Cmd
+	switch m.focusIndex {
+	case taxFocusName:
+		m.nameInput, cmd = m.nameInput.Update(msg)
+	case taxFocusRate:
+		m.rateInput, cmd = m.rateInput.Update(msg)
+	}
+	return m, cmd
+}
+
+func (m TaxFormModel) updateFocus() (TaxFormModel, tea.Cmd) {
+	cmds := []tea.Cmd{}
+	for i, input := range []*textinput.Model{&m.nameInput, &m.rateInput} {
+		if i == m.focusIndex {
+			cmds = append(cmds, input.Focus())
+			input.PromptStyle = formInputStyle
+			input.TextStyle = formInputStyle
+		} else {
+			input.Blur()
+			input.PromptStyle = dimStyle
+			input.TextStyle = dimStyle
+		}
+	}
+	return m, tea.Batch(cmds...)
+}
+
+func (m *TaxFormModel) saveTax() error {
+	name := strings.TrimSpace(m.nameInput.Value())
+
+	rate, err := decimal.NewFromString(strings.TrimSpace(m.rateInput.Value()))
+	if err != nil {
+		return fmt.Errorf("invalid rate: %v", err)
+	}
+
+	compound := m.kind == taxKindCompound
+	inclusive := m.kind == taxKindInclusive
+
+	taxes, err := m.storage.GetAllTaxes()
+	if err != nil {
+		return err
+	}
+	for _, t := range taxes {
+		if strings.EqualFold(t.Name, name) && (!m.isEdit || t.ID != m.tax.ID) {
+			return fmt.Errorf("a tax named %q already exists", t.Name)
+		}
+	}
+
+	if m.isEdit {
+		updated := *m.tax
+		updated.Update(name, rate, compound, inclusive)
+		if err := updated.Validate(); err != nil {
+			return err
+		}
+		return m.storage.UpdateTax(&updated)
+	}
+
+	tax := models.NewTax(name, rate, compound, inclusive)
+	if err := tax.Validate(); err != nil {
+		return err
+	}
+	return m.storage.SaveTax(tax)
+}
+
+func (m TaxFormModel) View() string {
+	var s strings.Builder
+
+	title := "New Tax"
+	if m.isEdit {
+		title = "Edit Tax"
+	}
+	s.WriteString(titleStyle.Render(title) + "\n\n")
+
+	if m.err != nil {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)) + "\n\n")
+	}
+
+	s.WriteString(formLabelStyle.Render("Name:"))
+	s.WriteString(m.nameInput.View() + "\n")
+
+	s.WriteString(formLabelStyle.Render("Rate (%):"))
+	s.WriteString(m.rateInput.View() + "\n")
+
+	kind := fmt.Sprintf("< %s >", taxKindLabels[m.kind])
+	if m.focusIndex == taxFocusKind {
+		kind = selectedStyle.Render(kind)
+	}
+	s.WriteString(formLabelStyle.Render("Type:") + kind + "\n")
+	switch m.kind {
+	case taxKindStandard:
+		s.WriteString(dimStyle.Render("Added on top of the line amount.") + "\n\n")
+	case taxKindCompound:
+		s.WriteString(dimStyle.Render("Added on top of the line amount plus the line's other taxes.") + "\n\n")
+	case taxKindInclusive:
+		s.WriteString(dimStyle.Render("Already included in the unit price; shown but not added.") + "\n\n")
+	}
+
+	saveButton := "[ Save ]"
+	if m.focusIndex == taxFocusSave {
+		saveButton = selectedStyle.Render(saveButton)
+	}
+	s.WriteString(saveButton)
+
+	s.WriteString("\n\n" + helpStyle.Render("tab/shift+tab navigate • space/←/→ change type • enter select • esc cancel"))
+
+	return appStyle.Render(s.String())
+}
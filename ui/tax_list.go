@@ -0,0 +1,168 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/models"
+)
+
+type taxListMode int
+
+const (
+	taxListModeView taxListMode = iota
+	taxListModeConfirmDelete
+)
+
+// TaxListModel is the settings screen for the tax catalogue. Line items copy
+// the taxes they use, so editing or deleting a tax here only affects lines
+// entered afterwards.
+type TaxListModel struct {
+	taxes   []models.Tax
+	cursor  int
+	storage models.Storage
+	config  *config.Config
+	mode    taxListMode
+	err     error
+}
+
+func NewTaxListModel(storage models.Storage, cfg *config.Config) TaxListModel {
+	m := TaxListModel{
+		storage: storage,
+		config:  cfg,
+		mode:    taxListModeView,
+	}
+	m.loadTaxes()
+	return m
+}
+
+func (m *TaxListModel) loadTaxes() {
+	taxes, err := m.storage.GetAllTaxes()
+	if err != nil {
+		m.err = err
+		return
+	}
+	m.taxes = taxes
+	m.err = nil
+}
+
+func (m TaxListModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m TaxListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch m.mode {
+		case taxListModeView:
+			switch msg.String() {
+			case "ctrl+c", "q":
+				return m, tea.Quit
+			case "esc":
+				return NewMainMenuModel(m.storage, m.config), nil
+			case "up", "k":
+				if m.cursor > 0 {
+					m.cursor--
+				}
+			case "down", "j":
+				if m.cursor < len(m.taxes)-1 {
+					m.cursor++
+				}
+			case "a":
+				return NewTaxFormModel(m.storage, m.config, nil), nil
+			case "e", "enter":
+				if len(m.taxes) > 0 {
+					return NewTaxFormModel(m.storage, m.config, &m.taxes[m.cursor]), nil
+				}
+			case "d":
+				if len(m.taxes) > 0 {
+					m.mode = taxListModeConfirmDelete
+				}
+			}
+		case taxListModeConfirmDelete:
+			switch msg.String() {
+			case "y":
+				if err := m.storage.DeleteTax(m.taxes[m.cursor].ID); err != nil {
+					m.err = err
+				} else {
+					m.loadTaxes()
+				}
+				m.mode = taxListModeView
+				if m.cursor >= len(m.taxes) && m.cursor > 0 {
+					m.cursor = len(m.taxes) - 1
+				}
+			case "n", "esc":
+				m.mode = taxListModeView
+			}
+		}
+	case BackToTaxListMsg:
+		m.loadTaxes()
+	}
+	return m, nil
+}
+
+func (m TaxListModel) View() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("Tax Settings") + "\n\n")
+
+	if m.err != nil {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)) + "\n\n")
+	}
+
+	if m.mode == taxListModeConfirmDelete {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Delete tax '%s'? (y/n)", m.taxes[m.cursor].Name)) + "\n")
+		return appStyle.Render(s.String())
+	}
+
+	if len(m.taxes) == 0 {
+		s.WriteString(dimStyle.Render("No taxes defined. Press 'a' to add one.") + "\n")
+	} else {
+		headers := []string{"Name", "Rate", "Applies"}
+		widths := []int{25, 10, 30}
+
+		headerRow := ""
+		for i, h := range headers {
+			headerRow += tableCellStyle.Width(widths[i]).Render(h)
+		}
+		s.WriteString(tableHeaderStyle.Render(headerRow) + "\n")
+
+		for i, tax := range m.taxes {
+			applies := "on the line amount"
+			if tax.Compound {
+				applies = "on the amount plus other taxes"
+			} else if tax.Inclusive {
+				applies = "included in the price"
+			}
+			cells := []string{
+				truncate(tax.Name, widths[0]-2),
+				fmt.Sprintf("%s%%", tax.Rate.String()),
+				applies,
+			}
+
+			row := ""
+			for j, cell := range cells {
+				style := tableCellStyle.Width(widths[j])
+				if i == m.cursor {
+					style = style.Inherit(selectedStyle)
+				}
+				row += style.Render(cell)
+			}
+
+			if i == m.cursor {
+				s.WriteString("> " + row + "\n")
+			} else {
+				s.WriteString("  " + row + "\n")
+			}
+		}
+	}
+
+	s.WriteString("\n" + dimStyle.Render("Enter tax names on a line item, separated by commas, to charge them.") + "\n")
+	s.WriteString("\n" + helpStyle.Render("a add • e edit • d delete • ↑/k up • ↓/j down • esc back • q quit"))
+
+	return appStyle.Render(s.String())
+}
+
+type BackToTaxListMsg struct{}
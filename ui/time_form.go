@@ -0,0 +1,385 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/shopspring/decimal"
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/models"
+)
+
+const (
+	timeFocusClient = iota
+	timeFocusProject
+	timeFocusDescription
+	timeFocusDate
+	timeFocusStart
+	timeFocusEnd
+	timeFocusDuration
+	timeFocusBillable
+	timeFocusSave
+	timeFocusCount
+)
+
+// TimeFormModel adds or edits a time entry, or starts the timer. When
+// starting the timer only the client, project, description and billable
+// flag are asked for.
+type TimeFormModel struct {
+	inputs      []textinput.Model
+	focusIndex  int
+	clients     []models.Client
+	clientIndex int
+	billable    bool
+	timer       bool
+	storage     models.Storage
+	config      *config.Config
+	entry       *models.TimeEntry
+	isEdit      bool
+	err         error
+}
+
+func NewTimeFormModel(storage models.Storage, cfg *config.Config, entry *models.TimeEntry, timer bool) TimeFormModel {
+	clients, err := storage.GetAllClients()
+	if err == nil && len(clients) == 0 {
+		err = errors.New("add a client before recording time")
+	}
+
+	// The inputs follow the client selector, so field i is inputs[i-timeFocusProject]
+	placeholders := []string{"Website redesign", "What was done", "YYYY-MM-DD", "HH:MM", "HH:MM", "1:30, 1.5 or 90m"}
+	widths := []int{30, 50, 15, 10, 10, 20}
+	inputs := make([]textinput.Model, len(placeholders))
+	for i := range inputs {
+		inputs[i] = textinput.New()
+		inputs[i].Placeholder = placeholders[i]
+		inputs[i].Width = widths[i]
+		inputs[i].PromptStyle = dimStyle
+		inputs[i].TextStyle = dimStyle
+	}
+
+	m := TimeFormModel{
+		inputs:   inputs,
+		clients:  clients,
+		billable: true,
+		timer:    timer,
+		storage:  storage,
+		config:   cfg,
+		entry:    entry,
+		isEdit:   entry != nil,
+		err:      err,
+	}
+
+	if m.isEdit {
+		for i, client := range clients {
+			if client.ID == entry.ClientID {
+				m.clientIndex = i
+			}
+		}
+		m.input(timeFocusProject).SetValue(entry.Project)
+		m.input(timeFocusDescription).SetValue(entry.Description)
+		m.input(timeFocusDate).SetValue(entry.Start.Format("2006-01-02"))
+		m.input(timeFocusStart).SetValue(entry.Start.Format("15:04"))
+		if entry.End != nil {
+			m.input(timeFocusEnd).SetValue(entry.End.Format("15:04"))
+		}
+		m.billable = entry.Billable
+	} else {
+		m.input(timeFocusDate).SetValue(time.Now().Format("2006-01-02"))
+	}
+
+	return m
+}
+
+// input returns the text input for the field at focus, or nil if the field
+// is not a text input.
+func (m *TimeFormModel) input(focus int) *textinput.Model {
+	if focus < timeFocusProject || focus > timeFocusDuration {
+		return nil
+	}
+	return &m.inputs[focus-timeFocusProject]
+}
+
+func (m TimeFormModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// skipped reports whether the field at focus is hidden.
+func (m TimeFormModel) skipped(focus int) bool {
+	return m.timer && focus >= timeFocusDate && focus <= timeFocusDuration
+}
+
+func (m TimeFormModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			return NewTimeListModel(m.storage, m.config), func() tea.Msg { return BackToTimeListMsg{} }
+		case " ", "left", "right":
+			switch m.focusIndex {
+			case timeFocusClient:
+				if len(m.clients) > 0 {
+					if msg.String() == "left" {
+						m.clientIndex = (m.clientIndex + len(m.clients) - 1) % len(m.clients)
+					} else {
+						m.clientIndex = (m.clientIndex + 1) % len(m.clients)
+					}
+				}
+				return m, nil
+			case timeFocusBillable:
+				m.billable = !m.billable
+				return m, nil
+			}
+		case "tab", "shift+tab", "enter", "up", "down":
+			s := msg.String()
+
+			if s == "enter" && m.focusIndex == timeFocusSave {
+				if err := m.saveEntry(); err != nil {
+					m.err = err
+					return m, nil
+				}
+				return NewTimeListModel(m.storage, m.config), func() tea.Msg { return BackToTimeListMsg{} }
+			}
+
+			for {
+				if s == "up" || s == "shift+tab" {
+					m.focusIndex--
+				} else {
+					m.focusIndex++
+				}
+
+				if m.focusIndex >= timeFocusCount {
+					m.focusIndex = 0
+				} else if m.focusIndex < 0 {
+					m.focusIndex = timeFocusCount - 1
+				}
+
+				if !m.skipped(m.focusIndex) {
+					break
+				}
+			}
+
+			return m.updateFocus()
+		}
+	}
+
+	var cmd tea.Cmd
+	if input := m.input(m.focusIndex); input != nil {
+		*input, cmd = input.Update(msg)
+	}
+	return m, cmd
+}
+
+func (m TimeFormModel) updateFocus() (TimeFormModel, tea.Cmd) {
+	cmds := []tea.Cmd{}
+	for i := range m.inputs {
+		if i == m.focusIndex-timeFocusProject {
+			cmds = append(cmds, m.inputs[i].Focus())
+			m.inputs[i].PromptStyle = formInputStyle
+			m.inputs[i].TextStyle = formInputStyle
+		} else {
+			m.inputs[i].Blur()
+			m.inputs[i].PromptStyle = dimStyle
+			m.inputs[i].TextStyle = dimStyle
+		}
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// parseWorkDuration reads a duration typed as hours and minutes ("1:30"),
+// decimal hours ("1.5") or a Go duration ("1h30m", "90m").
+func parseWorkDuration(input string) (time.Duration, error) {
+	input = strings.TrimSpace(input)
+	if hours, minutes, ok := strings.Cut(input, ":"); ok {
+		h, err := strconv.Atoi(hours)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", input)
+		}
+		mins, err := strconv.Atoi(minutes)
+		if err != nil || mins < 0 || mins > 59 {
+			return 0, fmt.Errorf("invalid duration %q", input)
+		}
+		return time.Duration(h)*time.Hour + time.Duration(mins)*time.Minute, nil
+	}
+	if hours, err := decimal.NewFromString(input); err == nil {
+		return time.Duration(hours.Mul(decimal.NewFromInt(int64(time.Hour))).IntPart()), nil
+	}
+	d, err := time.ParseDuration(input)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", input)
+	}
+	return d, nil
+}
+
+// entryTimes works out when the entry started and ended from the date,
+// start, end and duration fields. A duration takes precedence over the end
+// time, and an entry with neither is still running if it was before.
+func (m *TimeFormModel) entryTimes() (time.Time, *time.Time, error) {
+	date, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(m.input(timeFocusDate).Value()), time.Local)
+	if err != nil {
+		return time.Time{}, nil, fmt.Errorf("invalid date: %v", err)
+	}
+
+	clock := func(field int) (*time.Time, error) {
+		value := strings.TrimSpace(m.input(field).Value())
+		if value == "" {
+			return nil, nil
+		}
+		t, err := time.ParseInLocation("15:04", value, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time %q, use HH:MM", value)
+		}
+		at := time.Date(date.Year(), date.Month(), date.Day(), t.Hour(), t.Minute(), 0, 0, time.Local)
+		return &at, nil
+	}
+
+	start := date
+	if at, err := clock(timeFocusStart); err != nil {
+		return time.Time{}, nil, err
+	} else if at != nil {
+		start = *at
+	}
+
+	if value := strings.TrimSpace(m.input(timeFocusDuration).Value()); value != "" {
+		d, err := parseWorkDuration(value)
+		if err != nil {
+			return time.Time{}, nil, err
+		}
+		if d <= 0 {
+			return time.Time{}, nil, errors.New("duration must be positive")
+		}
+		end := start.Add(d)
+		return start, &end, nil
+	}
+
+	end, err := clock(timeFocusEnd)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	if end != nil && end.Before(start) {
+		// Work past midnight
+		next := end.AddDate(0, 0, 1)
+		end = &next
+	}
+	if end == nil && !(m.isEdit && m.entry.IsRunning()) {
+		return time.Time{}, nil, errors.New("enter an end time or a duration")
+	}
+	return start, end, nil
+}
+
+func (m *TimeFormModel) saveEntry() error {
+	if len(m.clients) == 0 {
+		return errors.New("add a client before recording time")
+	}
+	client := m.clients[m.clientIndex]
+	project := strings.TrimSpace(m.input(timeFocusProject).Value())
+	description := strings.TrimSpace(m.input(timeFocusDescription).Value())
+
+	if m.timer {
+		entries, err := m.storage.GetAllTimeEntries()
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if e.IsRunning() {
+				return fmt.Errorf("a timer is already running for %s", e.ClientName)
+			}
+		}
+		return m.storage.SaveTimeEntry(models.StartTimer(client.ID, client.Name, project, description, m.billable))
+	}
+
+	start, end, err := m.entryTimes()
+	if err != nil {
+		return err
+	}
+
+	if m.isEdit {
+		updated := *m.entry
+		updated.Update(client.ID, client.Name, project, description, start, end, m.billable)
+		if err := updated.Validate(); err != nil {
+			return err
+		}
+		return m.storage.UpdateTimeEntry(&updated)
+	}
+
+	entry := models.NewTimeEntry(client.ID, client.Name, project, description, start, *end, m.billable)
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+	return m.storage.SaveTimeEntry(entry)
+}
+
+func (m TimeFormModel) View() string {
+	var s strings.Builder
+
+	title := "Add Time"
+	if m.timer {
+		title = "Start Timer"
+	} else if m.isEdit {
+		title = "Edit Time"
+	}
+	s.WriteString(titleStyle.Render(title) + "\n\n")
+
+	if m.err != nil {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)) + "\n\n")
+	}
+
+	client := "< none >"
+	if len(m.clients) > 0 {
+		client = fmt.Sprintf("< %s >", m.clients[m.clientIndex].Name)
+	}
+	if m.focusIndex == timeFocusClient {
+		client = selectedStyle.Render(client)
+	}
+	s.WriteString(formLabelStyle.Render("Client:") + client + "\n")
+
+	fields := []struct {
+		focus int
+		label string
+	}{
+		{timeFocusProject, "Project:"},
+		{timeFocusDescription, "Description:"},
+		{timeFocusDate, "Date:"},
+		{timeFocusStart, "Start:"},
+		{timeFocusEnd, "End:"},
+		{timeFocusDuration, "Duration:"},
+	}
+	for _, f := range fields {
+		if m.skipped(f.focus) {
+			continue
+		}
+		s.WriteString(formLabelStyle.Render(f.label))
+		s.WriteString(m.input(f.focus).View() + "\n")
+	}
+	if !m.timer {
+		s.WriteString(dimStyle.Render("Enter an end time or a duration; a duration wins if both are given.") + "\n")
+	}
+
+	billable := "[ ] Billable"
+	if m.billable {
+		billable = "[x] Billable"
+	}
+	if m.focusIndex == timeFocusBillable {
+		billable = selectedStyle.Render(billable)
+	}
+	s.WriteString(formLabelStyle.Render("") + billable + "\n\n")
+
+	saveButton := "[ Save ]"
+	if m.timer {
+		saveButton = "[ Start Timer ]"
+	}
+	if m.focusIndex == timeFocusSave {
+		saveButton = selectedStyle.Render(saveButton)
+	}
+	s.WriteString(saveButton)
+
+	s.WriteString("\n\n" + helpStyle.Render("tab/shift+tab navigate • ←/→ change client • space toggle billable • enter select • esc cancel"))
+
+	return appStyle.Render(s.String())
+}
@@ -0,0 +1,265 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/invoicer/config"
+	"github.com/user/invoicer/models"
+)
+
+type timeListMode int
+
+const (
+	timeListModeView timeListMode = iota
+	timeListModeConfirmDelete
+)
+
+// timerTickMsg refreshes the running timer's elapsed time. It carries the
+// entry's ID so that ticks for a timer that has since been stopped die out.
+type timerTickMsg struct {
+	entryID string
+}
+
+type TimeListModel struct {
+	entries []models.TimeEntry
+	cursor  int
+	storage models.Storage
+	config  *config.Config
+	mode    timeListMode
+	err     error
+}
+
+func NewTimeListModel(storage models.Storage, cfg *config.Config) TimeListModel {
+	m := TimeListModel{
+		storage: storage,
+		config:  cfg,
+		mode:    timeListModeView,
+	}
+	m.loadEntries()
+	return m
+}
+
+func (m *TimeListModel) loadEntries() {
+	entries, err := m.storage.GetAllTimeEntries()
+	if err != nil {
+		m.err = err
+		return
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Start.After(entries[j].Start)
+	})
+	m.entries = entries
+	m.err = nil
+}
+
+// running returns the entry whose timer is running, if any.
+func (m TimeListModel) running() *models.TimeEntry {
+	for i := range m.entries {
+		if m.entries[i].IsRunning() {
+			return &m.entries[i]
+		}
+	}
+	return nil
+}
+
+func (m TimeListModel) tick() tea.Cmd {
+	entry := m.running()
+	if entry == nil {
+		return nil
+	}
+	id := entry.ID
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return timerTickMsg{entryID: id} })
+}
+
+func (m TimeListModel) Init() tea.Cmd {
+	return m.tick()
+}
+
+func (m TimeListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch m.mode {
+		case timeListModeView:
+			switch msg.String() {
+			case "ctrl+c", "q":
+				return m, tea.Quit
+			case "esc":
+				return NewMainMenuModel(m.storage, m.config), nil
+			case "up", "k":
+				if m.cursor > 0 {
+					m.cursor--
+				}
+			case "down", "j":
+				if m.cursor < len(m.entries)-1 {
+					m.cursor++
+				}
+			case "s":
+				if entry := m.running(); entry != nil {
+					stopped := *entry
+					stopped.Stop(time.Now())
+					if err := m.storage.UpdateTimeEntry(&stopped); err != nil {
+						m.err = err
+						return m, nil
+					}
+					m.loadEntries()
+					return m, nil
+				}
+				return NewTimeFormModel(m.storage, m.config, nil, true), nil
+			case "a":
+				return NewTimeFormModel(m.storage, m.config, nil, false), nil
+			case "e", "enter":
+				if len(m.entries) > 0 {
+					return NewTimeFormModel(m.storage, m.config, &m.entries[m.cursor], false), nil
+				}
+			case "d":
+				if len(m.entries) > 0 {
+					m.mode = timeListModeConfirmDelete
+				}
+			}
+		case timeListModeConfirmDelete:
+			switch msg.String() {
+			case "y":
+				if err := m.storage.DeleteTimeEntry(m.entries[m.cursor].ID); err != nil {
+					m.err = err
+				} else {
+					m.loadEntries()
+				}
+				m.mode = timeListModeView
+				if m.cursor >= len(m.entries) && m.cursor > 0 {
+					m.cursor = len(m.entries) - 1
+				}
+			case "n", "esc":
+				m.mode = timeListModeView
+			}
+		}
+	case timerTickMsg:
+		if entry := m.running(); entry != nil && entry.ID == msg.entryID {
+			return m, m.tick()
+		}
+	case BackToTimeListMsg:
+		m.loadEntries()
+		return m, m.tick()
+	}
+	return m, nil
+}
+
+// formatDuration renders d as hours and minutes, e.g. "1:05".
+func formatDuration(d time.Duration) string {
+	minutes := int(d.Round(time.Minute) / time.Minute)
+	return fmt.Sprintf("%d:%02d", minutes/60, minutes%60)
+}
+
+// formatElapsed renders d with seconds, for a running timer.
+func formatElapsed(d time.Duration) string {
+	seconds := int(d / time.Second)
+	return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+func timeEntryStatus(e models.TimeEntry) string {
+	switch {
+	case e.IsRunning():
+		return "running"
+	case e.IsBilled():
+		return "billed " + e.InvoiceNumber
+	case !e.Billable:
+		return "non-billable"
+	}
+	return "unbilled"
+}
+
+func (m TimeListModel) View() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("Time Tracking") + "\n\n")
+
+	if m.err != nil {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)) + "\n\n")
+	}
+
+	if m.mode == timeListModeConfirmDelete {
+		entry := m.entries[m.cursor]
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Delete %s of time for %s on %s? (y/n)",
+			formatDuration(entry.Duration()), entry.ClientName, entry.Start.Format("2006-01-02"))) + "\n")
+		return appStyle.Render(s.String())
+	}
+
+	if entry := m.running(); entry != nil {
+		label := entry.ClientName
+		if entry.Project != "" {
+			label += " / " + entry.Project
+		}
+		s.WriteString(successStyle.Render(fmt.Sprintf("● %s  %s", formatElapsed(entry.Duration()), label)) + "\n\n")
+	}
+
+	if len(m.entries) == 0 {
+		s.WriteString(dimStyle.Render("No time recorded. Press 's' to start the timer or 'a' to add time.") + "\n")
+	} else {
+		headers := []string{"Date", "Client", "Project", "Description", "Time", "Status"}
+		widths := []int{12, 20, 15, 25, 8, 16}
+
+		headerRow := ""
+		for i, h := range headers {
+			headerRow += tableCellStyle.Width(widths[i]).Render(h)
+		}
+		s.WriteString(tableHeaderStyle.Render(headerRow) + "\n")
+
+		for i, entry := range m.entries {
+			cells := []string{
+				entry.Start.Format("2006-01-02"),
+				truncate(entry.ClientName, widths[1]-2),
+				truncate(entry.Project, widths[2]-2),
+				truncate(entry.Description, widths[3]-2),
+				formatDuration(entry.Duration()),
+				truncate(timeEntryStatus(entry), widths[5]-2),
+			}
+
+			row := ""
+			for j, cell := range cells {
+				style := tableCellStyle.Width(widths[j])
+				if i == m.cursor {
+					style = style.Inherit(selectedStyle)
+				}
+				row += style.Render(cell)
+			}
+
+			if i == m.cursor {
+				s.WriteString("> " + row + "\n")
+			} else {
+				s.WriteString("  " + row + "\n")
+			}
+		}
+	}
+
+	timer := "s start timer"
+	if m.running() != nil {
+		timer = "s stop timer"
+	}
+	s.WriteString("\n" + helpStyle.Render(timer+" • a add • e edit • d delete • ↑/k up • ↓/j down • esc back • q quit"))
+
+	return appStyle.Render(s.String())
+}
+
+// releaseBilledTime marks the time billed on a deleted invoice as unbilled
+// again, so that it can be invoiced anew.
+func releaseBilledTime(storage models.Storage, invoiceID string) error {
+	entries, err := storage.GetAllTimeEntries()
+	if err != nil {
+		return err
+	}
+	for i := range entries {
+		if entries[i].InvoiceID != invoiceID {
+			continue
+		}
+		entries[i].MarkBilled("", "")
+		if err := storage.UpdateTimeEntry(&entries[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type BackToTimeListMsg struct{}